@@ -8,17 +8,20 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/chronnie/governance/models"
 )
 
-// Client is a helper for services to interact with the governance manager
+// Client is a typed client for the governance manager REST API
 type Client struct {
 	managerURL  string
 	httpClient  *http.Client
 	serviceName string
 	podName     string
+	authToken   string
 }
 
 // ClientConfig contains configuration for the client
@@ -27,6 +30,8 @@ type ClientConfig struct {
 	ServiceName string        // This service's name
 	PodName     string        // This pod's name
 	Timeout     time.Duration // HTTP request timeout
+	AuthToken   string        // Bearer token if the manager has auth enabled
+	HTTPClient  *http.Client  // Custom HTTP client, e.g. for TLS settings (nil = default client with Timeout)
 }
 
 // NewClient creates a new governance client
@@ -35,18 +40,31 @@ func NewClient(config *ClientConfig) *Client {
 		config.Timeout = 10 * time.Second
 	}
 
-	return &Client{
-		managerURL: config.ManagerURL,
-		httpClient: &http.Client{
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{
 			Timeout: config.Timeout,
-		},
+		}
+	}
+
+	return &Client{
+		managerURL:  strings.TrimSuffix(config.ManagerURL, "/"),
+		httpClient:  httpClient,
 		serviceName: config.ServiceName,
 		podName:     config.PodName,
+		authToken:   config.AuthToken,
 	}
 }
 
-// Register registers this service with the manager
-func (c *Client) Register(registration *models.ServiceRegistration) error {
+// HealthStatus is the manager's health as returned by GET /health
+type HealthStatus struct {
+	Status string                            `json:"status"` // healthy or unhealthy
+	Checks map[string]map[string]interface{} `json:"checks"` // Per component details, e.g. database, event_queue
+}
+
+// Register registers a service with the manager.
+// Empty service and pod names default to the client's ServiceName and PodName.
+func (c *Client) Register(ctx context.Context, registration *models.ServiceRegistration) error {
 	// Set service name and pod name if not already set
 	if registration.ServiceName == "" {
 		registration.ServiceName = c.serviceName
@@ -55,69 +73,162 @@ func (c *Client) Register(registration *models.ServiceRegistration) error {
 		registration.PodName = c.podName
 	}
 
-	// Marshal registration to JSON
-	jsonData, err := json.Marshal(registration)
-	if err != nil {
-		return fmt.Errorf("failed to marshal registration: %w", err)
+	if err := c.do(ctx, http.MethodPost, "/register", registration, nil); err != nil {
+		return fmt.Errorf("register failed: %w", err)
 	}
 
-	// Create HTTP request
-	url := c.managerURL + "/register"
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create register request: %w", err)
+	log.Printf("[Client] Successfully registered: service=%s, pod=%s", registration.ServiceName, registration.PodName)
+	return nil
+}
+
+// RequestOption configures a single request to the manager
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	namespace string
+}
+
+// InNamespace addresses a pod registered in namespace (see ServiceRegistration.Namespace)
+// instead of the default namespace
+func InNamespace(namespace string) RequestOption {
+	return func(o *requestOptions) {
+		o.namespace = namespace
 	}
+}
 
-	req.Header.Set("Content-Type", "application/json")
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	options := &requestOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
 
-	// Send request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send register request: %w", err)
+// Unregister unregisters a service pod from the manager.
+// Empty names default to the client's ServiceName and PodName. Pods of another namespace
+// than the default one need InNamespace.
+func (c *Client) Unregister(ctx context.Context, serviceName, podName string, opts ...RequestOption) error {
+	if serviceName == "" {
+		serviceName = c.serviceName
 	}
-	defer resp.Body.Close()
+	if podName == "" {
+		podName = c.podName
+	}
+	options := newRequestOptions(opts)
 
-	// Check response
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("register request failed with status %d: %s", resp.StatusCode, string(body))
+	query := url.Values{"service_name": {serviceName}, "pod_name": {podName}}
+	if options.namespace != "" {
+		query.Set("namespace", options.namespace)
+	}
+	if err := c.do(ctx, http.MethodDelete, "/unregister?"+query.Encode(), nil, nil); err != nil {
+		return fmt.Errorf("unregister failed: %w", err)
 	}
 
-	log.Printf("[Client] Successfully registered: service=%s, pod=%s", registration.ServiceName, registration.PodName)
+	log.Printf("[Client] Successfully unregistered: service=%s, pod=%s", serviceName, podName)
 	return nil
 }
 
-// Unregister unregisters this service from the manager
-func (c *Client) Unregister() error {
-	return c.UnregisterService(c.serviceName, c.podName)
+// Heartbeat renews the TTL of a pod registered with TTLSeconds.
+// Empty names default to the client's ServiceName and PodName. A pod that is not
+// registered (e.g. because it already expired) gives an error matching ErrNotFound.
+// Pods of another namespace than the default one need InNamespace.
+func (c *Client) Heartbeat(ctx context.Context, serviceName, podName string, opts ...RequestOption) error {
+	if serviceName == "" {
+		serviceName = c.serviceName
+	}
+	if podName == "" {
+		podName = c.podName
+	}
+	options := newRequestOptions(opts)
+
+	heartbeat := &models.HeartbeatRequest{Namespace: options.namespace, ServiceName: serviceName, PodName: podName}
+	if err := c.do(ctx, http.MethodPost, "/heartbeat", heartbeat, nil); err != nil {
+		return fmt.Errorf("heartbeat failed: %w", err)
+	}
+	return nil
+}
+
+// ListServices returns every registered pod, following pagination until the last page
+func (c *Client) ListServices(ctx context.Context) ([]*models.ServiceInfo, error) {
+	var services []*models.ServiceInfo
+	offset := 0
+	for {
+		var page struct {
+			Services   []*models.ServiceInfo `json:"services"`
+			NextOffset *int                  `json:"next_offset"`
+		}
+		path := fmt.Sprintf("/services?limit=%d&offset=%d", listServicesPageSize, offset)
+		if err := c.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+			return nil, fmt.Errorf("list services failed: %w", err)
+		}
+
+		services = append(services, page.Services...)
+		if page.NextOffset == nil {
+			return services, nil
+		}
+		offset = *page.NextOffset
+	}
+}
+
+// Health returns the manager's health. An unhealthy manager answers 503, which is
+// returned as an *APIError.
+func (c *Client) Health(ctx context.Context) (*HealthStatus, error) {
+	var health HealthStatus
+	if err := c.do(ctx, http.MethodGet, "/health", nil, &health); err != nil {
+		return nil, fmt.Errorf("health check failed: %w", err)
+	}
+	return &health, nil
 }
 
-// UnregisterService unregisters a specific service/pod from the manager
-func (c *Client) UnregisterService(serviceName, podName string) error {
-	// Create HTTP request
-	url := fmt.Sprintf("%s/unregister?service_name=%s&pod_name=%s", c.managerURL, serviceName, podName)
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodDelete, url, nil)
+// listServicesPageSize is the page size ListServices requests, the manager's maximum
+const listServicesPageSize = 1000
+
+// do sends a request with an optional JSON body and decodes a JSON response into out
+// (if not nil). Non-2xx responses are returned as *APIError.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.managerURL+path, reader)
 	if err != nil {
-		return fmt.Errorf("failed to create unregister request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
+	c.setAuthHeader(req)
 
-	// Send request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send unregister request: %w", err)
+		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// Check response
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("unregister request failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp)
 	}
 
-	log.Printf("[Client] Successfully unregistered: service=%s, pod=%s", serviceName, podName)
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
 	return nil
 }
 
+// setAuthHeader adds the bearer token to a request when one is configured
+func (c *Client) setAuthHeader(req *http.Request) {
+	if c.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.authToken)
+	}
+}
+
 // NotificationHandler is a function type for handling notifications
 type NotificationHandler func(payload *models.NotificationPayload)
 
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/chronnie/governance/models"
+)
+
+func TestRegister(t *testing.T) {
+	var received models.ServiceRegistration
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/register" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Expected bearer token, got %q", auth)
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	c := NewClient(&ClientConfig{ManagerURL: server.URL + "/", ServiceName: "user-service", PodName: "pod-1", AuthToken: "secret"})
+	err := c.Register(context.Background(), &models.ServiceRegistration{NotificationURL: "http://192.168.1.10:8080/notify"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Names default to the client's
+	if received.ServiceName != "user-service" || received.PodName != "pod-1" {
+		t.Errorf("Expected client names to be filled in, got %s/%s", received.ServiceName, received.PodName)
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/unregister" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("service_name") != "user service" || r.URL.Query().Get("pod_name") != "pod-1" {
+			t.Errorf("Unexpected query %q", r.URL.RawQuery)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	c := NewClient(&ClientConfig{ManagerURL: server.URL, PodName: "pod-1"})
+	if err := c.Unregister(context.Background(), "user service", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestListServicesFollowsPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		response := map[string]interface{}{
+			"services": []models.ServiceInfo{{ServiceName: "user-service", PodName: "pod-" + strconv.Itoa(offset)}},
+		}
+		if offset < 2 {
+			response["next_offset"] = offset + 1
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	services, err := NewClient(&ClientConfig{ManagerURL: server.URL}).ListServices(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(services) != 3 || services[2].PodName != "pod-2" {
+		t.Errorf("Expected 3 services from 3 pages, got %+v", services)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "healthy",
+			"checks": map[string]interface{}{"database": map[string]interface{}{"status": "disabled"}},
+		})
+	}))
+	defer server.Close()
+
+	health, err := NewClient(&ClientConfig{ManagerURL: server.URL}).Health(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if health.Status != "healthy" || health.Checks["database"]["status"] != "disabled" {
+		t.Errorf("Unexpected health: %+v", health)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	testCases := []struct {
+		status   int
+		expected error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusServiceUnavailable, ErrUnavailable},
+		{http.StatusInternalServerError, nil},
+	}
+
+	for _, tc := range testCases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "2")
+			http.Error(w, "something went wrong", tc.status)
+		}))
+
+		err := NewClient(&ClientConfig{ManagerURL: server.URL}).Unregister(context.Background(), "user-service", "pod-1")
+		server.Close()
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Status %d: expected *APIError, got %v", tc.status, err)
+		}
+		if apiErr.StatusCode != tc.status || apiErr.Message != "something went wrong" || apiErr.RetryAfter != 2*time.Second {
+			t.Errorf("Status %d: unexpected error %+v", tc.status, apiErr)
+		}
+		if tc.expected != nil && !errors.Is(err, tc.expected) {
+			t.Errorf("Status %d: expected errors.Is(%v)", tc.status, tc.expected)
+		}
+	}
+}
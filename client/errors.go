@@ -0,0 +1,67 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors matched by APIError with errors.Is, for the status codes callers usually handle
+var (
+	ErrBadRequest   = errors.New("bad request")         // 400, e.g. an invalid registration
+	ErrUnauthorized = errors.New("unauthorized")        // 401, missing or wrong auth token
+	ErrNotFound     = errors.New("not found")           // 404
+	ErrRateLimited  = errors.New("rate limited")        // 429, see APIError.RetryAfter
+	ErrUnavailable  = errors.New("manager unavailable") // 503, e.g. event queue full or unhealthy
+)
+
+// maxErrorBodySize caps how much of an error response body is kept as the message
+const maxErrorBodySize = 4096
+
+// APIError is returned for responses with a non-2xx status code
+type APIError struct {
+	StatusCode int
+	Message    string        // Response body, trimmed
+	RetryAfter time.Duration // From the Retry-After header, 0 if absent
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("manager returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("manager returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap maps the status code to one of the Err* values, so callers can use errors.Is
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusServiceUnavailable:
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// newAPIError builds an APIError from a non-2xx response
+func newAPIError(resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
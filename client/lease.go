@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/chronnie/governance/models"
+)
+
+// Lease keeps a pod registered with a TTL alive by sending heartbeats in the background,
+// see RegisterWithLease. Close it on shutdown.
+type Lease struct {
+	client       *Client
+	registration models.ServiceRegistration
+	cancel       context.CancelFunc
+	done         chan struct{} // Closed when the renewing goroutine has returned
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// leaseRenewals is how many heartbeats a lease sends per TTL, so a single lost heartbeat
+// does not let the pod expire
+const leaseRenewals = 3
+
+// RegisterWithLease registers a pod with a TTL and renews it every third of the TTL until
+// the lease is closed. A heartbeat the manager rejects because the pod already expired
+// registers it again; other failed heartbeats are logged and retried on the next renewal.
+// The registration must set TTLSeconds. Empty names default to the client's.
+func (c *Client) RegisterWithLease(ctx context.Context, registration *models.ServiceRegistration) (*Lease, error) {
+	if registration.TTLSeconds <= 0 {
+		return nil, errors.New("register with lease failed: ttl_seconds is required")
+	}
+	if err := c.Register(ctx, registration); err != nil {
+		return nil, err
+	}
+
+	leaseCtx, cancel := context.WithCancel(context.Background())
+	lease := &Lease{
+		client:       c,
+		registration: *registration,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+	go lease.renew(leaseCtx, time.Duration(registration.TTLSeconds)*time.Second/leaseRenewals)
+
+	return lease, nil
+}
+
+// renew sends a heartbeat every interval until ctx is cancelled
+func (l *Lease) renew(ctx context.Context, interval time.Duration) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Don't let a hanging request delay the next renewal
+			requestCtx, cancel := context.WithTimeout(ctx, interval)
+			l.renewOnce(requestCtx)
+			cancel()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// renewOnce sends one heartbeat, registering the pod again if it has expired
+func (l *Lease) renewOnce(ctx context.Context) {
+	err := l.client.Heartbeat(ctx, l.registration.ServiceName, l.registration.PodName, InNamespace(l.registration.Namespace))
+	if errors.Is(err, ErrNotFound) {
+		log.Printf("[Client] Lease expired, registering again: service=%s, pod=%s", l.registration.ServiceName, l.registration.PodName)
+		registration := l.registration
+		err = l.client.Register(ctx, &registration)
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Printf("[Client] Failed to renew lease: service=%s, pod=%s: %v", l.registration.ServiceName, l.registration.PodName, err)
+	}
+}
+
+// Close stops renewing the lease and unregisters the pod. Calling it again returns the
+// result of the first call.
+func (l *Lease) Close(ctx context.Context) error {
+	l.closeOnce.Do(func() {
+		l.cancel()
+		<-l.done
+		l.closeErr = l.client.Unregister(ctx, l.registration.ServiceName, l.registration.PodName, InNamespace(l.registration.Namespace))
+	})
+	return l.closeErr
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chronnie/governance/models"
+)
+
+func TestRegisterWithLease(t *testing.T) {
+	var registers, heartbeats, unregisters atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/register":
+			registers.Add(1)
+		case "/heartbeat":
+			// The first heartbeat finds the pod expired
+			if heartbeats.Add(1) == 1 {
+				http.Error(w, "Service not found", http.StatusNotFound)
+				return
+			}
+		case "/unregister":
+			unregisters.Add(1)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	c := NewClient(&ClientConfig{ManagerURL: server.URL, ServiceName: "user-service", PodName: "pod-1"})
+	lease, err := c.RegisterWithLease(context.Background(), &models.ServiceRegistration{
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		TTLSeconds:      1,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Renewed every third of the TTL
+	time.Sleep(800 * time.Millisecond)
+	if err := lease.Close(context.Background()); err != nil {
+		t.Fatalf("Unexpected error closing lease: %v", err)
+	}
+	renewed := heartbeats.Load()
+
+	if renewed < 2 {
+		t.Errorf("Expected at least 2 heartbeats, got %d", renewed)
+	}
+	if got := registers.Load(); got != 2 {
+		t.Errorf("Expected the expired pod to be registered again, got %d registrations", got)
+	}
+	if got := unregisters.Load(); got != 1 {
+		t.Errorf("Expected Close to unregister once, got %d", got)
+	}
+
+	time.Sleep(400 * time.Millisecond)
+	if got := heartbeats.Load(); got != renewed {
+		t.Errorf("Expected no heartbeats after Close, got %d more", got-renewed)
+	}
+
+	// Closing again doesn't unregister again
+	lease.Close(context.Background())
+	if got := unregisters.Load(); got != 1 {
+		t.Errorf("Expected a single unregister, got %d", got)
+	}
+}
+
+func TestRegisterWithLeaseNamespaced(t *testing.T) {
+	var registers, heartbeats atomic.Int32
+	var unregisterQuery atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/register":
+			registers.Add(1)
+		case "/heartbeat":
+			// Only the team-a pod is registered
+			var heartbeat models.HeartbeatRequest
+			json.NewDecoder(r.Body).Decode(&heartbeat)
+			if heartbeat.Namespace != "team-a" {
+				http.Error(w, "Service not found", http.StatusNotFound)
+				return
+			}
+			heartbeats.Add(1)
+		case "/unregister":
+			unregisterQuery.Store(r.URL.Query())
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	c := NewClient(&ClientConfig{ManagerURL: server.URL, ServiceName: "user-service", PodName: "pod-1"})
+	lease, err := c.RegisterWithLease(context.Background(), &models.ServiceRegistration{
+		Namespace:       "team-a",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		TTLSeconds:      1,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	time.Sleep(800 * time.Millisecond)
+	if err := lease.Close(context.Background()); err != nil {
+		t.Fatalf("Unexpected error closing lease: %v", err)
+	}
+
+	if got := heartbeats.Load(); got < 2 {
+		t.Errorf("Expected at least 2 heartbeats for the team-a pod, got %d", got)
+	}
+	if got := registers.Load(); got != 1 {
+		t.Errorf("Expected no re-registration, got %d registrations", got)
+	}
+	query, _ := unregisterQuery.Load().(url.Values)
+	if query.Get("namespace") != "team-a" || query.Get("service_name") != "user-service" {
+		t.Errorf("Expected Close to unregister the team-a pod, got query %v", query)
+	}
+}
+
+func TestRegisterWithLeaseRequiresTTL(t *testing.T) {
+	c := NewClient(&ClientConfig{ManagerURL: "http://127.0.0.1:1"})
+	_, err := c.RegisterWithLease(context.Background(), &models.ServiceRegistration{ServiceName: "user-service", PodName: "pod-1"})
+	if err == nil {
+		t.Error("Expected an error for a registration without TTL")
+	}
+}
@@ -4,16 +4,24 @@ import (
 	"context"
 
 	"github.com/chronnie/governance/models"
+	"go.uber.org/zap"
 )
 
 // EventName represents the type of event in the system
 type EventName string
 
 const (
-	EventRegister    EventName = "register"
-	EventUnregister  EventName = "unregister"
-	EventHealthCheck EventName = "health_check"
-	EventReconcile   EventName = "reconcile"
+	EventRegister          EventName = "register"
+	EventUnregister        EventName = "unregister"
+	EventHealthCheck       EventName = "health_check"
+	EventReconcile         EventName = "reconcile"
+	EventUnregisterService EventName = "unregister_service"
+	EventUpdate            EventName = "update"
+	EventHeartbeat         EventName = "heartbeat"
+	EventRepair            EventName = "repair"
+	EventImport            EventName = "import"
+	EventDatabaseSync      EventName = "database_sync"
+	EventEvictSubscriber   EventName = "evict_subscriber"
 )
 
 // Context keys for event data
@@ -21,6 +29,8 @@ type contextKey string
 
 const (
 	ContextKeyEventData contextKey = "event_data"
+	ContextKeyCaller    contextKey = "caller"
+	ContextKeyRequestID contextKey = "request_id"
 )
 
 // RegisterEvent is triggered when a service registers
@@ -38,7 +48,7 @@ func (e *RegisterEvent) HasDeadline() bool {
 
 // UnregisterEvent is triggered when a service unregisters
 type UnregisterEvent struct {
-	ServiceName string
+	ServiceName string // Qualified with the namespace, see models.QualifiedServiceName
 	PodName     string
 }
 
@@ -50,6 +60,48 @@ func (e *UnregisterEvent) HasDeadline() bool {
 	return true // Unregister events have deadline
 }
 
+// UnregisterServiceEvent is triggered to remove every pod of a service at once
+type UnregisterServiceEvent struct {
+	ServiceName string // Qualified service name
+}
+
+func (e *UnregisterServiceEvent) GetName() EventName {
+	return EventUnregisterService
+}
+
+func (e *UnregisterServiceEvent) HasDeadline() bool {
+	return true // Unregister events have deadline
+}
+
+// UpdateEvent is triggered when a registered pod's mutable fields change
+type UpdateEvent struct {
+	ServiceName string // Qualified service name
+	PodName     string
+	Update      *models.ServiceUpdate
+}
+
+func (e *UpdateEvent) GetName() EventName {
+	return EventUpdate
+}
+
+func (e *UpdateEvent) HasDeadline() bool {
+	return true // Update events have deadline
+}
+
+// HeartbeatEvent is triggered when a pod renews its registration TTL
+type HeartbeatEvent struct {
+	ServiceName string // Qualified service name
+	PodName     string
+}
+
+func (e *HeartbeatEvent) GetName() EventName {
+	return EventHeartbeat
+}
+
+func (e *HeartbeatEvent) HasDeadline() bool {
+	return true // Heartbeat events have deadline
+}
+
 // HealthCheckEvent is triggered to check service health
 type HealthCheckEvent struct {
 	ServiceKey string // format: service_name:pod_name
@@ -76,6 +128,59 @@ func (e *ReconcileEvent) HasDeadline() bool {
 	return false // Reconcile events don't have deadline
 }
 
+// RepairEvent is triggered to retry database writes that failed
+type RepairEvent struct {
+	// Empty struct - retries every pending repair
+}
+
+func (e *RepairEvent) GetName() EventName {
+	return EventRepair
+}
+
+func (e *RepairEvent) HasDeadline() bool {
+	return false // Repair events don't have deadline
+}
+
+// ImportEvent is triggered to replace the registry with an imported snapshot
+type ImportEvent struct {
+	Snapshot *models.Snapshot // Already validated
+}
+
+func (e *ImportEvent) GetName() EventName {
+	return EventImport
+}
+
+func (e *ImportEvent) HasDeadline() bool {
+	return false // Import events don't have deadline, large snapshots take a while
+}
+
+// DatabaseSyncEvent is triggered to reload services from the database after it reported changes
+type DatabaseSyncEvent struct {
+	Keys []string // Services to reload, nil reloads the whole database
+}
+
+func (e *DatabaseSyncEvent) GetName() EventName {
+	return EventDatabaseSync
+}
+
+func (e *DatabaseSyncEvent) HasDeadline() bool {
+	return false // Database sync events don't have deadline
+}
+
+// EvictSubscriberEvent is triggered to detach a subscriber from every service group
+// without unregistering it
+type EvictSubscriberEvent struct {
+	SubscriberKey string // format: service_name:pod_name
+}
+
+func (e *EvictSubscriberEvent) GetName() EventName {
+	return EventEvictSubscriber
+}
+
+func (e *EvictSubscriberEvent) HasDeadline() bool {
+	return true // Evict subscriber events have deadline
+}
+
 // Helper functions to create context with event data
 
 // NewRegisterContext creates a context with RegisterEvent data
@@ -93,9 +198,34 @@ func NewUnregisterContext(serviceName, podName string) context.Context {
 	})
 }
 
-// NewHealthCheckContext creates a context with HealthCheckEvent data
-func NewHealthCheckContext(serviceKey string) context.Context {
-	return context.WithValue(context.Background(), ContextKeyEventData, &HealthCheckEvent{
+// NewUnregisterServiceContext creates a context with UnregisterServiceEvent data
+func NewUnregisterServiceContext(serviceName string) context.Context {
+	return context.WithValue(context.Background(), ContextKeyEventData, &UnregisterServiceEvent{
+		ServiceName: serviceName,
+	})
+}
+
+// NewUpdateContext creates a context with UpdateEvent data
+func NewUpdateContext(serviceName, podName string, update *models.ServiceUpdate) context.Context {
+	return context.WithValue(context.Background(), ContextKeyEventData, &UpdateEvent{
+		ServiceName: serviceName,
+		PodName:     podName,
+		Update:      update,
+	})
+}
+
+// NewHeartbeatContext creates a context with HeartbeatEvent data
+func NewHeartbeatContext(serviceName, podName string) context.Context {
+	return context.WithValue(context.Background(), ContextKeyEventData, &HeartbeatEvent{
+		ServiceName: serviceName,
+		PodName:     podName,
+	})
+}
+
+// NewHealthCheckContext creates a context with HealthCheckEvent data. Unlike other events,
+// health checks derive from parent, so cancelling it aborts checks still queued or retrying.
+func NewHealthCheckContext(parent context.Context, serviceKey string) context.Context {
+	return context.WithValue(parent, ContextKeyEventData, &HealthCheckEvent{
 		ServiceKey: serviceKey,
 	})
 }
@@ -105,7 +235,72 @@ func NewReconcileContext() context.Context {
 	return context.WithValue(context.Background(), ContextKeyEventData, &ReconcileEvent{})
 }
 
+// NewRepairContext creates a context with RepairEvent data
+func NewRepairContext() context.Context {
+	return context.WithValue(context.Background(), ContextKeyEventData, &RepairEvent{})
+}
+
+// NewImportContext creates a context with ImportEvent data
+func NewImportContext(snapshot *models.Snapshot) context.Context {
+	return context.WithValue(context.Background(), ContextKeyEventData, &ImportEvent{
+		Snapshot: snapshot,
+	})
+}
+
+// NewDatabaseSyncContext creates a context with DatabaseSyncEvent data
+func NewDatabaseSyncContext(keys []string) context.Context {
+	return context.WithValue(context.Background(), ContextKeyEventData, &DatabaseSyncEvent{
+		Keys: keys,
+	})
+}
+
+// NewEvictSubscriberContext creates a context with EvictSubscriberEvent data
+func NewEvictSubscriberContext(subscriberKey string) context.Context {
+	return context.WithValue(context.Background(), ContextKeyEventData, &EvictSubscriberEvent{
+		SubscriberKey: subscriberKey,
+	})
+}
+
 // GetEventData extracts event data from context
 func GetEventData(ctx context.Context) interface{} {
 	return ctx.Value(ContextKeyEventData)
 }
+
+// WithCaller records who requested the event, for the audit trail
+func WithCaller(ctx context.Context, caller models.Caller) context.Context {
+	return context.WithValue(ctx, ContextKeyCaller, caller)
+}
+
+// GetCaller returns who requested the event. Events without a caller (health checks,
+// reconciles) were started by the manager itself.
+func GetCaller(ctx context.Context) models.Caller {
+	if caller, ok := ctx.Value(ContextKeyCaller).(models.Caller); ok {
+		return caller
+	}
+	return models.Caller{Principal: models.PrincipalSystem}
+}
+
+// WithRequestID records the ID of the API request that caused the event, so the event's
+// logs and notifications can be traced back to it. An empty id leaves ctx unchanged.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ContextKeyRequestID, id)
+}
+
+// GetRequestID returns the ID of the API request that caused the event, or "" for events
+// started by the manager itself
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(ContextKeyRequestID).(string)
+	return id
+}
+
+// RequestIDField returns the request ID of ctx as a log field, or a field that logs nothing
+// if there is none
+func RequestIDField(ctx context.Context) zap.Field {
+	if id := GetRequestID(ctx); id != "" {
+		return zap.String("request_id", id)
+	}
+	return zap.Skip()
+}
@@ -0,0 +1,18 @@
+package events
+
+import (
+	eventqueue "github.com/chronnie/go-event-queue"
+)
+
+// Middleware wraps an event handler to add cross-cutting behavior
+// (logging, timing, panic recovery, metrics, tracing) around it.
+type Middleware func(next eventqueue.EventHandlerFunc) eventqueue.EventHandlerFunc
+
+// Chain wraps handler with the given middlewares.
+// The first middleware is the outermost one, so it runs first and sees the final result.
+func Chain(handler eventqueue.EventHandlerFunc, middlewares ...Middleware) eventqueue.EventHandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
@@ -5,7 +5,6 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/chronnie/governance/manager"
 	"github.com/chronnie/governance/models"
@@ -16,18 +15,21 @@ func main() {
 	// export GOVERNANCE_LOG_ENABLED=true
 	// export GOVERNANCE_LOG_LEVEL=debug  # or info, warn, error
 	// export GOVERNANCE_LOG_FORMAT=json  # or console (default)
+	// export GOVERNANCE_LOG_FILE=/var/log/governance/manager.log  # rotating file instead of stdout
 
 	log.Println("Starting governance manager example...")
 
-	// Create manager configuration
-	config := &models.ManagerConfig{
-		ServerPort:           8080,
-		HealthCheckInterval:  30 * time.Second,
-		HealthCheckTimeout:   5 * time.Second,
-		HealthCheckRetry:     3,
-		NotificationInterval: 60 * time.Second,
-		NotificationTimeout:  5 * time.Second,
-		EventQueueSize:       1000,
+	// Load manager configuration: defaults, overridden by the optional config file and
+	// GOVERNANCE_* environment variables, e.g. GOVERNANCE_SERVER_PORT=9090
+	var config *models.ManagerConfig
+	var err error
+	if path := os.Getenv("GOVERNANCE_CONFIG_FILE"); path != "" {
+		config, err = models.LoadConfigFromFile(path)
+	} else {
+		config, err = models.LoadConfigFromEnv()
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
 	}
 
 	// Create and start manager
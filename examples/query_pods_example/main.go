@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -58,9 +59,10 @@ func main() {
 func simulateRegistrations(mgr *manager.Manager) {
 	// Get registry directly (this is for example purposes)
 	registry := mgr.GetRegistry()
+	ctx := context.Background()
 
 	// Register user-service pods
-	registry.Register(&models.ServiceRegistration{
+	registry.Register(ctx, &models.ServiceRegistration{
 		ServiceName: "user-service",
 		PodName:     "user-pod-1",
 		Providers: []models.ProviderInfo{
@@ -71,7 +73,7 @@ func simulateRegistrations(mgr *manager.Manager) {
 		Subscriptions:   []string{"order-service"},
 	})
 
-	registry.Register(&models.ServiceRegistration{
+	registry.Register(ctx, &models.ServiceRegistration{
 		ServiceName: "user-service",
 		PodName:     "user-pod-2",
 		Providers: []models.ProviderInfo{
@@ -83,7 +85,7 @@ func simulateRegistrations(mgr *manager.Manager) {
 	})
 
 	// Register order-service pods
-	registry.Register(&models.ServiceRegistration{
+	registry.Register(ctx, &models.ServiceRegistration{
 		ServiceName: "order-service",
 		PodName:     "order-pod-1",
 		Providers: []models.ProviderInfo{
@@ -95,7 +97,7 @@ func simulateRegistrations(mgr *manager.Manager) {
 		Subscriptions:   []string{"payment-service"},
 	})
 
-	registry.Register(&models.ServiceRegistration{
+	registry.Register(ctx, &models.ServiceRegistration{
 		ServiceName: "order-service",
 		PodName:     "order-pod-2",
 		Providers: []models.ProviderInfo{
@@ -107,7 +109,7 @@ func simulateRegistrations(mgr *manager.Manager) {
 		Subscriptions:   []string{"payment-service"},
 	})
 
-	registry.Register(&models.ServiceRegistration{
+	registry.Register(ctx, &models.ServiceRegistration{
 		ServiceName: "order-service",
 		PodName:     "order-pod-3",
 		Providers: []models.ProviderInfo{
@@ -120,7 +122,7 @@ func simulateRegistrations(mgr *manager.Manager) {
 	})
 
 	// Register payment-service pod
-	registry.Register(&models.ServiceRegistration{
+	registry.Register(ctx, &models.ServiceRegistration{
 		ServiceName: "payment-service",
 		PodName:     "payment-pod-1",
 		Providers: []models.ProviderInfo{
@@ -138,7 +140,7 @@ func simulateRegistrations(mgr *manager.Manager) {
 }
 
 func queryPodsByServiceGroup(mgr *manager.Manager, serviceName string) {
-	pods := mgr.GetServicePods(serviceName)
+	pods := mgr.GetServicePods(context.Background(), serviceName)
 
 	fmt.Printf("\nService: %s (Total Pods: %d)\n", serviceName, len(pods))
 	for i, pod := range pods {
@@ -156,7 +158,7 @@ func queryPodsByServiceGroup(mgr *manager.Manager, serviceName string) {
 }
 
 func queryAllServicePods(mgr *manager.Manager) {
-	allServicePods := mgr.GetAllServicePods()
+	allServicePods := mgr.GetAllServicePods(context.Background())
 
 	fmt.Printf("Total Service Groups: %d\n\n", len(allServicePods))
 
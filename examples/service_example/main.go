@@ -68,7 +68,7 @@ func main() {
 		Subscriptions:   []string{"order-service", "payment-service"}, // Subscribe to these services
 	}
 
-	if err := govClient.Register(registration); err != nil {
+	if err := govClient.Register(context.Background(), registration); err != nil {
 		log.Fatalf("Failed to register: %v", err)
 	}
 
@@ -88,7 +88,7 @@ func main() {
 	log.Println("Shutting down service...")
 
 	// Unregister from manager
-	if err := govClient.Unregister(); err != nil {
+	if err := govClient.Unregister(context.Background(), serviceName, podName); err != nil {
 		log.Printf("Failed to unregister: %v", err)
 	} else {
 		log.Println("Service unregistered successfully")
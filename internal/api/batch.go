@@ -0,0 +1,257 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RegisterBatchHandler handles POST /register/batch requests.
+// Each registration is validated and enqueued independently; see batchStatusCode
+// for how the overall HTTP status is derived from the per-item results.
+// In sync write mode an item only succeeds once it has been persisted.
+func (h *Handler) RegisterBatchHandler(w http.ResponseWriter, r *http.Request) {
+	logger.Info("API: Received batch register request",
+		zap.String("method", r.Method),
+		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
+	)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var registrations []models.ServiceRegistration
+	if err := json.NewDecoder(h.limitBody(w, r)).Decode(&registrations); err != nil {
+		logger.Error("API: Failed to decode batch registration request",
+			zap.Error(err),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		if rejectTooLarge(w, err) {
+			return
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(registrations) == 0 {
+		http.Error(w, "Batch must contain at least one registration", http.StatusBadRequest)
+		return
+	}
+
+	response := models.BatchResponse{Results: make([]models.BatchItemResult, 0, len(registrations))}
+	serverStatus := 0 // Status for failures other than validation, 0 if there were none
+	seen := make(map[string]bool)
+	added := make(map[string]int)              // New pods per namespace, which count against its quota
+	pending := make(map[int]eventqueue.IEvent) // Index into response.Results -> its event, in sync write mode
+
+	for i := range registrations {
+		registration := &registrations[i]
+		result := models.BatchItemResult{
+			Index:       i,
+			ServiceName: registration.ServiceName,
+			PodName:     registration.PodName,
+		}
+
+		if err := h.validateRegistration(registration); err != nil {
+			result.Result = models.BatchResultError
+			result.Error = err.Error()
+			response.Results = append(response.Results, result)
+			response.Failed++
+			continue
+		}
+
+		// Earlier items of the same batch count as existing registrations
+		key := models.ServiceKey(registration.QualifiedName(), registration.PodName)
+		_, exists := h.registry.Get(r.Context(), key)
+		isUpdate := exists || seen[key]
+
+		if !isUpdate {
+			if err := h.registry.CheckQuota(r.Context(), registration, added[registration.Namespace]); err != nil {
+				result.Result = models.BatchResultError
+				result.Error = err.Error()
+				response.Results = append(response.Results, result)
+				response.Failed++
+				if serverStatus == 0 {
+					serverStatus = http.StatusTooManyRequests
+				}
+				continue
+			}
+		}
+
+		event, err := h.enqueueRegister(r, registration)
+		if err != nil {
+			logger.Error("API: Failed to enqueue register event",
+				zap.String("service_name", registration.ServiceName),
+				zap.String("pod_name", registration.PodName),
+				zap.Error(err),
+				events.RequestIDField(r.Context()),
+			)
+			result.Result = models.BatchResultError
+			result.Error = "failed to process registration: " + err.Error()
+			response.Results = append(response.Results, result)
+			response.Failed++
+			serverStatus = enqueueErrorStatus(err)
+			continue
+		}
+
+		seen[key] = true
+		if !isUpdate {
+			added[registration.Namespace]++
+		}
+		result.Result = models.BatchResultCreated
+		if isUpdate {
+			result.Result = models.BatchResultUpdated
+		}
+		if h.syncWrites() {
+			pending[len(response.Results)] = event
+		}
+		response.Results = append(response.Results, result)
+		response.Succeeded++
+	}
+
+	// All items were enqueued before waiting, so the worker persists them back to back
+	for index, event := range pending {
+		if err := waitForEvent(r.Context(), event); err != nil {
+			result := &response.Results[index]
+			result.Result = models.BatchResultError
+			result.Error = "failed to register service: " + err.Error()
+			response.Succeeded--
+			response.Failed++
+			if errors.Is(err, registry.ErrQuotaExceeded) {
+				if serverStatus == 0 {
+					serverStatus = http.StatusTooManyRequests
+				}
+				continue
+			}
+			serverStatus = http.StatusInternalServerError
+		}
+	}
+
+	logger.Info("API: Batch registration processed",
+		zap.Int("succeeded", response.Succeeded),
+		zap.Int("failed", response.Failed),
+	)
+
+	writeBatchResponse(w, &response, serverStatus)
+}
+
+// UnregisterBatchHandler handles POST /unregister/batch requests.
+// The body is an array of {service_name, pod_name} objects; results follow the same
+// schema and status code rules as RegisterBatchHandler.
+func (h *Handler) UnregisterBatchHandler(w http.ResponseWriter, r *http.Request) {
+	logger.Info("API: Received batch unregister request",
+		zap.String("method", r.Method),
+		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
+	)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var requests []models.UnregisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+		logger.Error("API: Failed to decode batch unregistration request",
+			zap.Error(err),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(requests) == 0 {
+		http.Error(w, "Batch must contain at least one item", http.StatusBadRequest)
+		return
+	}
+
+	response := models.BatchResponse{Results: make([]models.BatchItemResult, 0, len(requests))}
+	serverStatus := 0 // Status for failures other than validation, 0 if there were none
+
+	for i, req := range requests {
+		result := models.BatchItemResult{
+			Index:       i,
+			ServiceName: req.ServiceName,
+			PodName:     req.PodName,
+		}
+
+		if req.ServiceName == "" || req.PodName == "" {
+			result.Result = models.BatchResultError
+			result.Error = "service_name and pod_name are required"
+			response.Results = append(response.Results, result)
+			response.Failed++
+			continue
+		}
+
+		serviceName := models.QualifiedServiceName(req.Namespace, req.ServiceName)
+		if _, exists := h.registry.Get(r.Context(), models.ServiceKey(serviceName, req.PodName)); !exists {
+			result.Result = models.BatchResultError
+			result.Error = "service not found"
+			response.Results = append(response.Results, result)
+			response.Failed++
+			continue
+		}
+
+		if err := h.enqueueUnregister(r, serviceName, req.PodName); err != nil {
+			logger.Error("API: Failed to enqueue unregister event",
+				zap.String("service_name", req.ServiceName),
+				zap.String("pod_name", req.PodName),
+				zap.Error(err),
+				events.RequestIDField(r.Context()),
+			)
+			result.Result = models.BatchResultError
+			result.Error = "failed to process unregistration: " + err.Error()
+			response.Results = append(response.Results, result)
+			response.Failed++
+			serverStatus = enqueueErrorStatus(err)
+			continue
+		}
+
+		result.Result = models.BatchResultDeleted
+		response.Results = append(response.Results, result)
+		response.Succeeded++
+	}
+
+	logger.Info("API: Batch unregistration processed",
+		zap.Int("succeeded", response.Succeeded),
+		zap.Int("failed", response.Failed),
+	)
+
+	writeBatchResponse(w, &response, serverStatus)
+}
+
+// writeBatchResponse writes the batch results with the overall status
+func writeBatchResponse(w http.ResponseWriter, response *models.BatchResponse, serverStatus int) {
+	status := batchStatusCode(response, serverStatus)
+	if status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+	writeJSON(w, status, response)
+}
+
+// batchStatusCode derives the overall status of a batch:
+// 200 if all items succeeded, 207 Multi-Status if results are mixed,
+// 400 if every item was rejected by validation, serverStatus (500, 503 when the event queue
+// was full, or 429 when a namespace quota was exceeded) if nothing succeeded and at least one
+// item failed otherwise.
+func batchStatusCode(response *models.BatchResponse, serverStatus int) int {
+	switch {
+	case response.Failed == 0:
+		return http.StatusOK
+	case response.Succeeded > 0:
+		return http.StatusMultiStatus
+	case serverStatus != 0:
+		return serverStatus
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/storage"
+)
+
+func newBatchRegistration(serviceName, podName string) models.ServiceRegistration {
+	return models.ServiceRegistration{
+		ServiceName:     serviceName,
+		PodName:         podName,
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	}
+}
+
+func postBatch(handler http.HandlerFunc, url string, body interface{}) (*httptest.ResponseRecorder, models.BatchResponse) {
+	jsonData, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
+	rec := httptest.NewRecorder()
+
+	handler(rec, req)
+
+	var response models.BatchResponse
+	json.NewDecoder(rec.Body).Decode(&response)
+	return rec, response
+}
+
+func TestRegisterBatchHandlerAllSucceeded(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	existing := newBatchRegistration("test-service", "pod-1")
+	reg.Register(context.Background(), &existing)
+
+	batch := []models.ServiceRegistration{
+		newBatchRegistration("test-service", "pod-1"),
+		newBatchRegistration("test-service", "pod-2"),
+		newBatchRegistration("test-service", "pod-2"),
+	}
+
+	rec, response := postBatch(handler.RegisterBatchHandler, "/register/batch", batch)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if response.Succeeded != 3 || response.Failed != 0 {
+		t.Errorf("Expected 3 succeeded and 0 failed, got %d and %d", response.Succeeded, response.Failed)
+	}
+
+	expected := []string{models.BatchResultUpdated, models.BatchResultCreated, models.BatchResultUpdated}
+	for i, result := range response.Results {
+		if result.Index != i || result.Result != expected[i] {
+			t.Errorf("Item %d: expected result %q, got %+v", i, expected[i], result)
+		}
+	}
+}
+
+func TestRegisterBatchHandlerMixed(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+
+	invalid := newBatchRegistration("", "pod-2")
+	batch := []models.ServiceRegistration{newBatchRegistration("test-service", "pod-1"), invalid}
+
+	rec, response := postBatch(handler.RegisterBatchHandler, "/register/batch", batch)
+
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("Expected status %d, got %d", http.StatusMultiStatus, rec.Code)
+	}
+	if response.Results[1].Result != models.BatchResultError || response.Results[1].Error == "" {
+		t.Errorf("Expected error result with detail for invalid item, got %+v", response.Results[1])
+	}
+}
+
+func TestRegisterBatchHandlerAllInvalid(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+
+	batch := []models.ServiceRegistration{newBatchRegistration("", "pod-1"), newBatchRegistration("svc", "")}
+
+	rec, response := postBatch(handler.RegisterBatchHandler, "/register/batch", batch)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if response.Failed != 2 {
+		t.Errorf("Expected 2 failed items, got %d", response.Failed)
+	}
+}
+
+func TestRegisterBatchHandlerQuotaExceeded(t *testing.T) {
+	reg := registry.NewRegistry(storage.NewDualStore(nil), registry.WithNamespaceQuotas(nil, 2))
+	queue := eventqueue.NewEventQueue(eventqueue.EventQueueConfig{BufferSize: 10})
+	queue.Start(context.Background())
+	defer queue.Stop()
+	handler := NewHandler(reg, queue)
+
+	existing := newBatchRegistration("test-service", "pod-1")
+	reg.Register(context.Background(), &existing)
+
+	// Earlier new pods of the batch count against the quota, updates don't
+	batch := []models.ServiceRegistration{
+		newBatchRegistration("test-service", "pod-2"),
+		newBatchRegistration("test-service", "pod-3"),
+		newBatchRegistration("test-service", "pod-1"),
+	}
+	rec, response := postBatch(handler.RegisterBatchHandler, "/register/batch", batch)
+
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("Expected status %d, got %d", http.StatusMultiStatus, rec.Code)
+	}
+	expected := []string{models.BatchResultCreated, models.BatchResultError, models.BatchResultUpdated}
+	for i, result := range response.Results {
+		if result.Result != expected[i] {
+			t.Errorf("Item %d: expected result %q, got %+v", i, expected[i], result)
+		}
+	}
+
+	// Once pod-2 is registered nothing else fits
+	reg.Register(context.Background(), &batch[0])
+	rec, _ = postBatch(handler.RegisterBatchHandler, "/register/batch", batch[1:2])
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status %d, got %d", http.StatusTooManyRequests, rec.Code)
+	}
+}
+
+func TestRegisterBatchHandlerEmpty(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+
+	rec, _ := postBatch(handler.RegisterBatchHandler, "/register/batch", []models.ServiceRegistration{})
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestUnregisterBatchHandler(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	existing := newBatchRegistration("test-service", "pod-1")
+	reg.Register(context.Background(), &existing)
+
+	batch := []models.UnregisterRequest{
+		{ServiceName: "test-service", PodName: "pod-1"},
+		{ServiceName: "test-service", PodName: "missing"},
+	}
+
+	rec, response := postBatch(handler.UnregisterBatchHandler, "/unregister/batch", batch)
+
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("Expected status %d, got %d", http.StatusMultiStatus, rec.Code)
+	}
+	if response.Results[0].Result != models.BatchResultDeleted {
+		t.Errorf("Expected first item deleted, got %+v", response.Results[0])
+	}
+	if response.Results[1].Result != models.BatchResultError {
+		t.Errorf("Expected second item error, got %+v", response.Results[1])
+	}
+}
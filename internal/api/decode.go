@@ -0,0 +1,64 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/chronnie/governance/models"
+)
+
+// limitBody returns the request body capped at maxBodySize bytes
+func (h *Handler) limitBody(w http.ResponseWriter, r *http.Request) io.Reader {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+	return r.Body
+}
+
+// rejectTooLarge responds with 413 if err comes from a body over the limit of limitBody,
+// and reports whether it did
+func rejectTooLarge(w http.ResponseWriter, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return false
+	}
+	http.Error(w, fmt.Sprintf("Request body larger than %d bytes", maxBytesErr.Limit), http.StatusRequestEntityTooLarge)
+	return true
+}
+
+// requiredRegistrationFields must be present in every registration body. Whether their
+// values are usable is up to ServiceRegistration.Validate.
+var requiredRegistrationFields = []string{"service_name", "pod_name", "providers", "notification_url"}
+
+// decodeRegistration strictly decodes a registration body: unknown fields (e.g. typos) and
+// missing required fields are rejected with a ValidationError naming the field, instead of
+// silently producing a partially empty registration
+func decodeRegistration(body io.Reader, registration *models.ServiceRegistration) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(registration); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return &ValidationError{Message: "unknown field " + field}
+		}
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, field := range requiredRegistrationFields {
+		if _, ok := fields[field]; !ok {
+			return &ValidationError{Message: fmt.Sprintf("missing required field %q", field)}
+		}
+	}
+	return nil
+}
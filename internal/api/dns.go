@@ -0,0 +1,144 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultDNSDomain is the zone origin of GET /dns/zone when no domain is given
+	defaultDNSDomain = "governance.local"
+
+	// dnsRecordTTL is the TTL of exported records in seconds. It is short because records
+	// follow health checks, so resolvers must not cache a pod long after it went unhealthy.
+	dnsRecordTTL = 30
+)
+
+// DNSZoneHandler handles GET /dns/zone?domain=<domain> requests.
+// Exports the healthy pods as an RFC 1035 zone file fragment: an SRV record per provider,
+// owned by _<service_name>._<tcp|udp>[.<namespace>], and an A or AAAA record per pod target,
+// named <pod_name>.<service_name>[.<namespace>]. Names are relative to domain
+// (governance.local by default). The fragment has no SOA or NS records, so it can be served
+// as is by a DNS server that loads it with $INCLUDE, and it is generated from the registry on
+// every request.
+func (h *Handler) DNSZoneHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	domain := strings.TrimSuffix(strings.ToLower(r.URL.Query().Get("domain")), ".")
+	if domain == "" {
+		domain = defaultDNSDomain
+	}
+	if !validDNSName(domain) {
+		http.Error(w, "domain must be a valid DNS name", http.StatusBadRequest)
+		return
+	}
+
+	revision := h.registry.Revision()
+	if notModified(w, r, servicesETag(r, revision)) {
+		return
+	}
+
+	records := dnsRecords(h.registry.GetByStatus(r.Context(), models.StatusHealthy))
+
+	logger.Debug("API: Exported DNS zone",
+		zap.String("domain", domain),
+		zap.Int("record_count", len(records)),
+	)
+
+	var zone strings.Builder
+	fmt.Fprintf(&zone, "; Healthy pods at registry revision %d\n", revision)
+	fmt.Fprintf(&zone, "$ORIGIN %s.\n", domain)
+	fmt.Fprintf(&zone, "$TTL %d\n", dnsRecordTTL)
+	for _, record := range records {
+		zone.WriteString(record)
+		zone.WriteByte('\n')
+	}
+
+	w.Header().Set("Content-Type", "text/dns; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(zone.String()))
+}
+
+// dnsRecords returns the SRV records of the providers of services and the address records of
+// their targets, sorted so that the zone only changes when the pods do. Pods whose names
+// don't map to DNS labels are skipped.
+func dnsRecords(services []*models.ServiceInfo) []string {
+	var srvRecords, addressRecords []string
+	for _, service := range services {
+		group := dnsLabel(service.ServiceName)
+		pod := dnsLabel(service.PodName)
+		if group == "" || pod == "" {
+			continue
+		}
+		suffix := ""
+		if service.Namespace != "" {
+			suffix = "." + service.Namespace // Namespaces already are DNS labels
+		}
+		target := pod + "." + group + suffix
+
+		addresses := make(map[string]bool)
+		for _, provider := range service.Providers {
+			proto := "tcp"
+			if slices.Contains(models.UDPProtocols, provider.Protocol) {
+				proto = "udp"
+			}
+			srvRecords = append(srvRecords, fmt.Sprintf("_%s._%s%s IN SRV 0 1 %d %s", group, proto, suffix, provider.Port, target))
+			addresses[provider.IP] = true
+		}
+
+		for ip := range addresses {
+			recordType := "A"
+			if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+				recordType = "AAAA"
+			}
+			addressRecords = append(addressRecords, fmt.Sprintf("%s IN %s %s", target, recordType, ip))
+		}
+	}
+
+	slices.Sort(srvRecords)
+	slices.Sort(addressRecords)
+	return append(srvRecords, addressRecords...)
+}
+
+// dnsLabel maps a service or pod name to a DNS label: lowercase, with characters other than
+// letters, digits and dashes replaced by dashes and no leading or trailing dashes
+func dnsLabel(name string) string {
+	label := strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-':
+			return c
+		case c >= 'A' && c <= 'Z':
+			return c - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, name)
+	label = strings.Trim(label, "-")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	return label
+}
+
+// validDNSName reports whether name is a dot separated list of DNS labels
+func validDNSName(name string) bool {
+	if len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || dnsLabel(label) != label {
+			return false
+		}
+	}
+	return true
+}
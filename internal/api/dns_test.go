@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chronnie/governance/models"
+)
+
+func TestDNSZoneHandler(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	register := func(namespace, serviceName, podName, ip string, status models.ServiceStatus, providers ...models.ProviderInfo) {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			Namespace:       namespace,
+			ServiceName:     serviceName,
+			PodName:         podName,
+			Providers:       providers,
+			NotificationURL: "http://" + ip + ":8080/notify",
+		})
+		reg.UpdateHealthStatus(context.Background(), models.ServiceKey(models.QualifiedServiceName(namespace, serviceName), podName), status)
+	}
+	register("", "smf", "pod-1", "192.168.1.10", models.StatusHealthy,
+		models.ProviderInfo{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080},
+		models.ProviderInfo{Protocol: models.ProtocolPFCP, IP: "192.168.1.10", Port: 8805},
+	)
+	register("", "smf", "pod-2", "192.168.1.11", models.StatusUnhealthy,
+		models.ProviderInfo{Protocol: models.ProtocolHTTP, IP: "192.168.1.11", Port: 8080},
+	)
+	register("team-a", "gateway", "Pod_1", "fd00::1", models.StatusHealthy,
+		models.ProviderInfo{Protocol: models.ProtocolGRPC, IP: "fd00::1", Port: 9090},
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/dns/zone?domain=example.com.", nil)
+	rec := httptest.NewRecorder()
+	handler.DNSZoneHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	expected := []string{
+		"$ORIGIN example.com.",
+		"$TTL 30",
+		"_gateway._tcp.team-a IN SRV 0 1 9090 pod-1.gateway.team-a",
+		"_smf._tcp IN SRV 0 1 8080 pod-1.smf",
+		"_smf._udp IN SRV 0 1 8805 pod-1.smf",
+		"pod-1.gateway.team-a IN AAAA fd00::1",
+		"pod-1.smf IN A 192.168.1.10",
+	}
+	if len(lines) != len(expected)+1 || !strings.HasPrefix(lines[0], ";") {
+		t.Fatalf("Expected a comment and %d lines, got %q", len(expected), lines)
+	}
+	for i, line := range expected {
+		if lines[i+1] != line {
+			t.Errorf("Line %d: expected %q, got %q", i+1, line, lines[i+1])
+		}
+	}
+
+	// Unchanged registry
+	req = httptest.NewRequest(http.MethodGet, "/dns/zone?domain=example.com.", nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	handler.DNSZoneHandler(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected status %d, got %d", http.StatusNotModified, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/dns/zone?domain=bad_domain", nil)
+	rec = httptest.NewRecorder()
+	handler.DNSZoneHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
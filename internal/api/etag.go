@@ -0,0 +1,31 @@
+package api
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+)
+
+// servicesETag returns the ETag of a service listing: a hash of the registry revision and the
+// request path and query, so it changes with the registry and differs between filters.
+// It is weak because uptime_seconds changes with time alone.
+func servicesETag(r *http.Request, revision int64) string {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%d %s?%s", revision, r.URL.Path, r.URL.Query().Encode())
+	return fmt.Sprintf(`W/"%016x"`, hash.Sum64())
+}
+
+// notModified sets the ETag response header and, if the request's If-None-Match already
+// has the ETag, responds with 304 Not Modified and reports that it did
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	for _, match := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		match = strings.TrimSpace(match)
+		if match == "*" || strings.TrimPrefix(match, "W/") == strings.TrimPrefix(etag, "W/") {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipMinSize is the smallest response worth compressing; below it the gzip header and
+// the CPU time cost more than they save
+const gzipMinSize = 1024
+
+var gzipWriters = sync.Pool{
+	New: func() any { return gzip.NewWriter(nil) },
+}
+
+// Gzip wraps a handler so its response is gzip-compressed for clients that send
+// Accept-Encoding: gzip. Responses smaller than gzipMinSize are sent as they are.
+func Gzip(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
+			next(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		defer gw.Close()
+		next(gw, r)
+	}
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(encoding, ";")
+		if strings.TrimSpace(name) != "gzip" {
+			continue
+		}
+		// gzip;q=0 explicitly refuses it
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter holds the response back until it has seen gzipMinSize bytes, then
+// compresses it. Smaller responses are written uncompressed on Close.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+	gz          *gzip.Writer // Set once compressing
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+
+	w.buf.Write(p)
+	if w.buf.Len() < gzipMinSize {
+		return len(p), nil
+	}
+
+	// Large enough: switch to compressing, starting with what was held back
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+
+	w.gz = gzipWriters.Get().(*gzip.Writer)
+	w.gz.Reset(w.ResponseWriter)
+	if _, err := w.gz.Write(w.buf.Bytes()); err != nil {
+		return 0, err
+	}
+	w.buf.Reset()
+	return len(p), nil
+}
+
+// Close finishes the response: the gzip stream if compressing, otherwise the small
+// response held back
+func (w *gzipResponseWriter) Close() error {
+	if w.gz == nil {
+		w.ResponseWriter.WriteHeader(w.status)
+		_, err := w.ResponseWriter.Write(w.buf.Bytes())
+		return err
+	}
+	err := w.gz.Close()
+	gzipWriters.Put(w.gz)
+	return err
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chronnie/governance/models"
+)
+
+func TestGzipServicesResponse(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	for i := range 50 {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     "user-service",
+			PodName:         fmt.Sprintf("pod-%d", i),
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+	}
+	services := Gzip(handler.ServicesHandler)
+
+	t.Run("accepted", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/services", nil)
+		req.Header.Set("Accept-Encoding", "deflate, gzip")
+		rec := httptest.NewRecorder()
+		services(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+		if rec.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Expected a gzip response, got headers %v", rec.Header())
+		}
+
+		body, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("Invalid gzip stream: %v", err)
+		}
+		var response struct {
+			Total int `json:"total"`
+		}
+		if err := json.NewDecoder(body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode decompressed body: %v", err)
+		}
+		if response.Total != 50 {
+			t.Errorf("Expected 50 services, got %d", response.Total)
+		}
+	})
+
+	t.Run("not accepted", func(t *testing.T) {
+		for _, acceptEncoding := range []string{"", "br", "gzip;q=0"} {
+			req := httptest.NewRequest(http.MethodGet, "/services", nil)
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+			rec := httptest.NewRecorder()
+			services(rec, req)
+
+			if rec.Header().Get("Content-Encoding") != "" {
+				t.Errorf("Accept-Encoding %q: expected an uncompressed response", acceptEncoding)
+			}
+			if !json.Valid(rec.Body.Bytes()) {
+				t.Errorf("Accept-Encoding %q: expected plain JSON", acceptEncoding)
+			}
+		}
+	})
+
+	t.Run("small response", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/services?name=order-service", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		services(rec, req)
+
+		if rec.Header().Get("Content-Encoding") != "" {
+			t.Error("Expected a small response to be sent uncompressed")
+		}
+		body, _ := io.ReadAll(rec.Body)
+		if !json.Valid(body) {
+			t.Errorf("Expected plain JSON, got %q", body)
+		}
+	})
+}
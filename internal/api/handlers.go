@@ -1,30 +1,173 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	eventqueue "github.com/chronnie/go-event-queue"
 	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/notifier"
+	"github.com/chronnie/governance/internal/queue"
 	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/internal/watch"
 	"github.com/chronnie/governance/models"
 	"github.com/chronnie/governance/pkg/logger"
+	"github.com/chronnie/governance/storage"
 	"go.uber.org/zap"
 )
 
+const (
+	// defaultServicesLimit is the page size for GET /services when no limit is given
+	defaultServicesLimit = 100
+	// maxServicesLimit caps the page size for GET /services
+	maxServicesLimit = 1000
+
+	// defaultChangesLimit is the page size for GET /changes when no limit is given
+	defaultChangesLimit = 100
+	// maxChangesLimit caps the page size for GET /changes
+	maxChangesLimit = 1000
+
+	// DefaultMaxBodySize caps register request bodies unless WithMaxBodySize sets another limit
+	DefaultMaxBodySize = 1 << 20 // 1 MiB
+
+	// readinessTimeout bounds the database ping done by GET /readyz and GET /health
+	readinessTimeout = 2 * time.Second
+
+	// watchKeepAliveInterval is how often GET /watch sends a comment to keep idle connections open
+	watchKeepAliveInterval = 30 * time.Second
+)
+
 // Handler handles HTTP requests for the governance manager
 type Handler struct {
 	registry   *registry.Registry
 	eventQueue eventqueue.IEventQueue
+	notifier   *notifier.Notifier // Optional, required for notification history
+	dualStore  *storage.DualStore // Optional, required for the change feed
+	watchHub   *watch.Hub         // Optional, required for live change streaming
+	authToken  string             // Bearer token for protected endpoints, empty disables auth
+	limiter    *rateLimiter       // Optional, per-client limit for endpoints wrapped with RateLimit
+
+	maxBodySize int64 // Largest register and batch register body accepted, in bytes
+
+	unknownRoutable bool // Whether ?healthy_only=true keeps pods with unknown status
+
+	queueRunning  func() bool              // Optional, reports whether the event queue is processing events
+	queueStats    func() models.QueueStats // Optional, required for GET /stats
+	lastReconcile func() time.Time         // Optional, reports when the last reconcile completed
+
+	databaseConnection func() models.DatabaseConnectionStats // Optional, reports the monitored database connection
+
+	setDrainMode func(bool)  // Optional, required for POST /drain
+	drainMode    func() bool // Optional, reports whether drain mode is on
+}
+
+// HandlerOption configures optional Handler dependencies
+type HandlerOption func(*Handler)
+
+// WithNotifier gives the handler access to the notifier for delivery introspection
+func WithNotifier(notif *notifier.Notifier) HandlerOption {
+	return func(h *Handler) {
+		h.notifier = notif
+	}
+}
+
+// WithDualStore gives the handler access to the storage layer (change feed)
+func WithDualStore(dualStore *storage.DualStore) HandlerOption {
+	return func(h *Handler) {
+		h.dualStore = dualStore
+	}
+}
+
+// WithWatchHub gives the handler access to the hub streaming live changes
+func WithWatchHub(hub *watch.Hub) HandlerOption {
+	return func(h *Handler) {
+		h.watchHub = hub
+	}
+}
+
+// WithQueueStatus lets GET /readyz check whether the event queue is running
+func WithQueueStatus(running func() bool) HandlerOption {
+	return func(h *Handler) {
+		h.queueRunning = running
+	}
+}
+
+// WithQueueStats lets GET /stats report event queue load
+func WithQueueStats(stats func() models.QueueStats) HandlerOption {
+	return func(h *Handler) {
+		h.queueStats = stats
+	}
+}
+
+// WithLastReconcile lets GET /health report when the last reconcile completed
+func WithLastReconcile(lastReconcile func() time.Time) HandlerOption {
+	return func(h *Handler) {
+		h.lastReconcile = lastReconcile
+	}
+}
+
+// WithDatabaseConnection lets GET /health and GET /stats report the database connection state
+// tracked by the database monitor
+func WithDatabaseConnection(stats func() models.DatabaseConnectionStats) HandlerOption {
+	return func(h *Handler) {
+		h.databaseConnection = stats
+	}
+}
+
+// WithDrainMode lets POST /drain turn drain mode on and off, and GET /stats report it
+func WithDrainMode(set func(bool), get func() bool) HandlerOption {
+	return func(h *Handler) {
+		h.setDrainMode = set
+		h.drainMode = get
+	}
+}
+
+// WithAuthToken sets the bearer token required by endpoints wrapped with RequireAuth
+func WithAuthToken(token string) HandlerOption {
+	return func(h *Handler) {
+		h.authToken = token
+	}
+}
+
+// WithMaxBodySize sets the largest body POST /register and POST /register/batch accept, in
+// bytes; larger ones are rejected with 413. Values <= 0 keep DefaultMaxBodySize.
+func WithMaxBodySize(size int64) HandlerOption {
+	return func(h *Handler) {
+		if size > 0 {
+			h.maxBodySize = size
+		}
+	}
+}
+
+// WithUnknownRoutable makes ?healthy_only=true keep pods with unknown status as well, e.g.
+// freshly registered pods that have not been checked yet
+func WithUnknownRoutable(routable bool) HandlerOption {
+	return func(h *Handler) {
+		h.unknownRoutable = routable
+	}
 }
 
 // NewHandler creates a new API handler
-func NewHandler(reg *registry.Registry, eventQueue eventqueue.IEventQueue) *Handler {
-	return &Handler{
-		registry:   reg,
-		eventQueue: eventQueue,
+func NewHandler(reg *registry.Registry, eventQueue eventqueue.IEventQueue, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		registry:    reg,
+		eventQueue:  eventQueue,
+		maxBodySize: DefaultMaxBodySize,
+	}
+
+	for _, opt := range opts {
+		opt(h)
 	}
+
+	return h
 }
 
 // RegisterHandler handles POST /register requests
@@ -32,6 +175,7 @@ func (h *Handler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	logger.Info("API: Received register request",
 		zap.String("method", r.Method),
 		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
 	)
 
 	if r.Method != http.MethodPost {
@@ -44,11 +188,19 @@ func (h *Handler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request body
 	var registration models.ServiceRegistration
-	if err := json.NewDecoder(r.Body).Decode(&registration); err != nil {
+	if err := decodeRegistration(h.limitBody(w, r), &registration); err != nil {
 		logger.Error("API: Failed to decode registration request",
 			zap.Error(err),
 			zap.String("remote_addr", r.RemoteAddr),
 		)
+		if rejectTooLarge(w, err) {
+			return
+		}
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			http.Error(w, "Invalid request body: "+validationErr.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
@@ -71,32 +223,63 @@ func (h *Handler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Checked again by the worker, this rejects new pods of a full namespace right away
+	if err := h.registry.CheckQuota(r.Context(), &registration, 0); err != nil {
+		logger.Warn("API: Registration over namespace quota",
+			zap.String("service_name", registration.ServiceName),
+			zap.String("pod_name", registration.PodName),
+			zap.Error(err),
+		)
+		writeQuotaExceeded(w, err)
+		return
+	}
+
 	logger.Info("API: Registration validated successfully",
 		zap.String("service_name", registration.ServiceName),
 		zap.String("pod_name", registration.PodName),
 	)
 
-	// Create context with event data
-	ctx := events.NewRegisterContext(&registration)
-
-	// Create and enqueue register event (with deadline for register events)
-	event := eventqueue.NewEvent(string(events.EventRegister), ctx, eventqueue.WithTimeout(5*time.Second))
-
-	if err := h.eventQueue.Enqueue(event); err != nil {
+	event, err := h.enqueueRegister(r, &registration)
+	if err != nil {
 		logger.Error("API: Failed to enqueue register event",
 			zap.String("service_name", registration.ServiceName),
 			zap.String("pod_name", registration.PodName),
 			zap.Error(err),
+			events.RequestIDField(r.Context()),
 		)
-		http.Error(w, "Failed to process registration", http.StatusInternalServerError)
+		writeEnqueueError(w, err, "Failed to process registration")
 		return
 	}
 
 	logger.Info("API: Register event enqueued successfully",
 		zap.String("service_name", registration.ServiceName),
 		zap.String("pod_name", registration.PodName),
+		events.RequestIDField(r.Context()),
 	)
 
+	// In sync write mode only acknowledge registrations that reached the database
+	if h.syncWrites() {
+		if err := waitForEvent(r.Context(), event); err != nil {
+			logger.Error("API: Registration failed",
+				zap.String("service_name", registration.ServiceName),
+				zap.String("pod_name", registration.PodName),
+				zap.Error(err),
+				events.RequestIDField(r.Context()),
+			)
+			if errors.Is(err, registry.ErrQuotaExceeded) {
+				writeQuotaExceeded(w, err)
+				return
+			}
+			http.Error(w, "Failed to register service: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{
+			"status":  "registered",
+			"message": "Service registered successfully",
+		})
+		return
+	}
+
 	// Return success response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
@@ -116,6 +299,7 @@ func (h *Handler) UnregisterHandler(w http.ResponseWriter, r *http.Request) {
 	logger.Info("API: Received unregister request",
 		zap.String("method", r.Method),
 		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
 	)
 
 	if r.Method != http.MethodDelete {
@@ -138,31 +322,28 @@ func (h *Handler) UnregisterHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Missing service_name or pod_name query parameters", http.StatusBadRequest)
 		return
 	}
+	serviceName = serviceNameOf(r, serviceName)
 
 	logger.Info("API: Unregister request validated",
 		zap.String("service_name", serviceName),
 		zap.String("pod_name", podName),
 	)
 
-	// Create context with event data
-	ctx := events.NewUnregisterContext(serviceName, podName)
-
-	// Create and enqueue unregister event (with deadline for unregister events)
-	event := eventqueue.NewEvent(string(events.EventUnregister), ctx, eventqueue.WithTimeout(5*time.Second))
-
-	if err := h.eventQueue.Enqueue(event); err != nil {
+	if err := h.enqueueUnregister(r, serviceName, podName); err != nil {
 		logger.Error("API: Failed to enqueue unregister event",
 			zap.String("service_name", serviceName),
 			zap.String("pod_name", podName),
 			zap.Error(err),
+			events.RequestIDField(r.Context()),
 		)
-		http.Error(w, "Failed to process unregistration", http.StatusInternalServerError)
+		writeEnqueueError(w, err, "Failed to process unregistration")
 		return
 	}
 
 	logger.Info("API: Unregister event enqueued successfully",
 		zap.String("service_name", serviceName),
 		zap.String("pod_name", podName),
+		events.RequestIDField(r.Context()),
 	)
 
 	// Return success response
@@ -179,11 +360,120 @@ func (h *Handler) UnregisterHandler(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
-// ServicesHandler handles GET /services requests (for debugging)
+// HeartbeatHandler handles POST /heartbeat requests.
+// Pushes back the expiry of a pod registered with a TTL. Returns 404 if the pod is not
+// registered, e.g. because it already expired, so the client knows to register again.
+func (h *Handler) HeartbeatHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var heartbeat models.HeartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&heartbeat); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if heartbeat.ServiceName == "" || heartbeat.PodName == "" {
+		http.Error(w, "service_name and pod_name are required", http.StatusBadRequest)
+		return
+	}
+
+	serviceName := models.QualifiedServiceName(heartbeat.Namespace, heartbeat.ServiceName)
+	if _, exists := h.registry.Get(r.Context(), models.ServiceKey(serviceName, heartbeat.PodName)); !exists {
+		http.Error(w, "Service not found", http.StatusNotFound)
+		return
+	}
+
+	ctx := fromRequest(events.NewHeartbeatContext(serviceName, heartbeat.PodName), r)
+	event := eventqueue.NewEvent(string(events.EventHeartbeat), ctx, eventqueue.WithTimeout(5*time.Second))
+	if err := h.eventQueue.Enqueue(event); err != nil {
+		logger.Error("API: Failed to enqueue heartbeat event",
+			zap.String("service_name", heartbeat.ServiceName),
+			zap.String("pod_name", heartbeat.PodName),
+			zap.Error(err),
+			events.RequestIDField(r.Context()),
+		)
+		writeEnqueueError(w, err, "Failed to process heartbeat")
+		return
+	}
+
+	logger.Debug("API: Heartbeat event enqueued",
+		zap.String("service_name", heartbeat.ServiceName),
+		zap.String("pod_name", heartbeat.PodName),
+		events.RequestIDField(r.Context()),
+	)
+
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"status":  "accepted",
+		"message": "Heartbeat queued successfully",
+	})
+}
+
+// UnregisterServiceHandler handles DELETE /unregister/service?service_name=<name> requests.
+// Every pod of the service is removed in one event, so subscribers get a single notification.
+func (h *Handler) UnregisterServiceHandler(w http.ResponseWriter, r *http.Request) {
+	logger.Info("API: Received unregister service request",
+		zap.String("method", r.Method),
+		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
+	)
+
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	serviceName := r.URL.Query().Get("service_name")
+	if serviceName == "" {
+		http.Error(w, "Missing service_name query parameter", http.StatusBadRequest)
+		return
+	}
+	serviceName = serviceNameOf(r, serviceName)
+
+	pods := h.registry.GetByServiceName(r.Context(), serviceName)
+	if len(pods) == 0 {
+		http.Error(w, "Service not found", http.StatusNotFound)
+		return
+	}
+
+	ctx := fromRequest(events.NewUnregisterServiceContext(serviceName), r)
+	event := eventqueue.NewEvent(string(events.EventUnregisterService), ctx, eventqueue.WithTimeout(5*time.Second))
+	if err := h.eventQueue.Enqueue(event); err != nil {
+		logger.Error("API: Failed to enqueue unregister service event",
+			zap.String("service_name", serviceName),
+			zap.Error(err),
+			events.RequestIDField(r.Context()),
+		)
+		writeEnqueueError(w, err, "Failed to process unregistration")
+		return
+	}
+
+	logger.Info("API: Unregister service event enqueued successfully",
+		zap.String("service_name", serviceName),
+		zap.Int("pod_count", len(pods)),
+		events.RequestIDField(r.Context()),
+	)
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"status":    "accepted",
+		"message":   "Service unregistration event queued successfully",
+		"pod_count": len(pods),
+	})
+}
+
+// ServicesHandler handles GET /services?limit=<n>&offset=<n>&status=<status>&name=<name>&protocol=<protocol>
+// requests (for debugging). Services are sorted by service name then pod name so pages are
+// stable across requests. With status, only pods in that health status are returned, e.g.
+// status=unhealthy for alerting. With protocol, only pods exposing a provider of that protocol
+// are returned, with their providers trimmed to it.
+// Responses carry an ETag; requests whose If-None-Match has it get 304 Not Modified as long as
+// the registry hasn't changed.
 func (h *Handler) ServicesHandler(w http.ResponseWriter, r *http.Request) {
 	logger.Debug("API: Received services query request",
 		zap.String("method", r.Method),
 		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
 	)
 
 	if r.Method != http.MethodGet {
@@ -194,78 +484,1421 @@ func (h *Handler) ServicesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	services := h.registry.GetAllServices()
+	limit, err := queryInt(r, "limit", defaultServicesLimit)
+	if err != nil || limit <= 0 {
+		http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+		return
+	}
+	if limit > maxServicesLimit {
+		limit = maxServicesLimit
+	}
+
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+
+	selector, err := parseLabelSelector(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	includeDeleted, err := queryBool(r, "include_deleted")
+	if err != nil {
+		http.Error(w, "include_deleted must be a boolean", http.StatusBadRequest)
+		return
+	}
+
+	healthyOnly, err := queryBool(r, "healthy_only")
+	if err != nil {
+		http.Error(w, "healthy_only must be a boolean", http.StatusBadRequest)
+		return
+	}
+
+	protocol, err := parseProtocol(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Read before listing, so the listing reflects at least this revision
+	revision := h.registry.Revision()
+
+	// Conditional requests from pollers skip the listing entirely while nothing changed
+	if notModified(w, r, servicesETag(r, revision)) {
+		return
+	}
+
+	// Without a namespace, services of every namespace are listed
+	namespace := r.URL.Query().Get("namespace")
+	serviceName := r.URL.Query().Get("name")
+	if serviceName != "" {
+		serviceName = models.QualifiedServiceName(namespace, serviceName)
+	}
+	var services []*models.ServiceInfo
+	if status := models.ServiceStatus(r.URL.Query().Get("status")); status != "" {
+		if !status.IsValid() {
+			http.Error(w, "status must be one of healthy, unhealthy, unknown, tombstone", http.StatusBadRequest)
+			return
+		}
+		services = redactHealthCheckHeaders(h.registry.GetByStatus(r.Context(), status))
+		if serviceName != "" {
+			services = slices.DeleteFunc(services, func(service *models.ServiceInfo) bool {
+				return service.QualifiedName() != serviceName
+			})
+		}
+	} else {
+		services = h.listServices(r.Context(), serviceName, includeDeleted)
+	}
+	if namespace != "" {
+		services = slices.DeleteFunc(services, func(service *models.ServiceInfo) bool {
+			return service.Namespace != namespace
+		})
+	}
+	services = filterByProtocol(filterByLabels(services, selector), protocol)
+	if healthyOnly {
+		services = h.filterRoutable(services)
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Namespace != services[j].Namespace {
+			return services[i].Namespace < services[j].Namespace
+		}
+		if services[i].ServiceName != services[j].ServiceName {
+			return services[i].ServiceName < services[j].ServiceName
+		}
+		return services[i].PodName < services[j].PodName
+	})
+
+	total := len(services)
+	start := min(offset, total)
+	end := min(start+limit, total)
+	page := services[start:end]
 
 	logger.Info("API: Retrieved all services",
-		zap.Int("service_count", len(services)),
+		zap.Int("service_count", total),
+		zap.Int("offset", offset),
+		zap.Int("limit", limit),
 	)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"count":    len(services),
-		"services": services,
-	})
+	response := map[string]interface{}{
+		"count":    len(page),
+		"total":    total,
+		"offset":   offset,
+		"limit":    limit,
+		"revision": revision,
+		"services": withUptime(page, time.Now()),
+	}
+	// Only present when there are more services after this page
+	if end < total {
+		response["next_offset"] = end
+	}
+
+	writeJSON(w, http.StatusOK, response)
 
 	logger.Debug("API: Sent services response",
-		zap.Int("service_count", len(services)),
+		zap.Int("service_count", len(page)),
 	)
 }
 
-// HealthHandler handles GET /health requests
-func (h *Handler) HealthHandler(w http.ResponseWriter, r *http.Request) {
-	logger.Debug("API: Received health check request",
+// ServiceHandler handles GET /services/{name} requests.
+// Returns the pods of a single service group in the same shape as ServicesHandler, with the
+// same label and protocol filters and ETag support.
+func (h *Handler) ServiceHandler(w http.ResponseWriter, r *http.Request) {
+	logger.Debug("API: Received service group query request",
+		zap.String("method", r.Method),
 		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
 	)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "healthy",
-	})
-}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-// validateRegistration validates a service registration
-func (h *Handler) validateRegistration(reg *models.ServiceRegistration) error {
-	if reg.ServiceName == "" {
-		return &ValidationError{Message: "service_name is required"}
+	serviceName := r.PathValue("name")
+	if serviceName == "" {
+		http.Error(w, "service name is required", http.StatusBadRequest)
+		return
 	}
-	if reg.PodName == "" {
-		return &ValidationError{Message: "pod_name is required"}
+
+	selector, err := parseLabelSelector(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	if len(reg.Providers) == 0 {
-		return &ValidationError{Message: "at least one provider is required"}
+
+	includeDeleted, err := queryBool(r, "include_deleted")
+	if err != nil {
+		http.Error(w, "include_deleted must be a boolean", http.StatusBadRequest)
+		return
 	}
-	if reg.HealthCheckURL == "" {
-		return &ValidationError{Message: "health_check_url is required"}
+
+	healthyOnly, err := queryBool(r, "healthy_only")
+	if err != nil {
+		http.Error(w, "healthy_only must be a boolean", http.StatusBadRequest)
+		return
 	}
-	if reg.NotificationURL == "" {
-		return &ValidationError{Message: "notification_url is required"}
+
+	protocol, err := parseProtocol(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	// Validate providers
-	for i, provider := range reg.Providers {
-		if provider.Protocol == "" {
-			return &ValidationError{Message: "provider protocol is required", Index: &i}
-		}
-		if provider.IP == "" {
-			return &ValidationError{Message: "provider IP is required", Index: &i}
-		}
-		if provider.Port <= 0 || provider.Port > 65535 {
-			return &ValidationError{Message: "provider port must be between 1 and 65535", Index: &i}
-		}
+	revision := h.registry.Revision()
+
+	// An ETag is only ever sent with a 200, so a matching one means the group still exists
+	if notModified(w, r, servicesETag(r, revision)) {
+		return
 	}
 
-	return nil
-}
+	services := filterByProtocol(filterByLabels(h.listServices(r.Context(), serviceNameOf(r, serviceName), includeDeleted), selector), protocol)
+	if healthyOnly {
+		services = h.filterRoutable(services)
+	}
+	if len(services) == 0 {
+		w.Header().Del("ETag")
+		logger.Debug("API: Service group not found",
+			zap.String("service_name", serviceName),
+		)
+		http.Error(w, "Service not found", http.StatusNotFound)
+		return
+	}
 
-// ValidationError represents a validation error
-type ValidationError struct {
-	Message string
-	Index   *int
+	logger.Debug("API: Sent service group response",
+		zap.String("service_name", serviceName),
+		zap.Int("pod_count", len(services)),
+	)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":    len(services),
+		"revision": revision,
+		"services": withUptime(services, time.Now()),
+	})
 }
 
-func (e *ValidationError) Error() string {
-	if e.Index != nil {
-		return e.Message + " (provider index: " + string(rune(*e.Index)) + ")"
+// ServiceHistoryHandler handles GET /services/{name}/{pod}/history requests.
+// Returns the pod's recent health status transitions, newest first, e.g. to spot flapping.
+func (h *Handler) ServiceHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	logger.Debug("API: Received service history request",
+		zap.String("method", r.Method),
+		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
+	)
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	serviceName := r.PathValue("name")
+	podName := r.PathValue("pod")
+	if serviceName == "" || podName == "" {
+		http.Error(w, "service name and pod name are required", http.StatusBadRequest)
+		return
+	}
+
+	key := models.ServiceKey(serviceNameOf(r, serviceName), podName)
+	transitions := h.registry.GetStatusHistory(key)
+
+	// Unknown pod: not registered and no history kept, e.g. for a tombstone
+	if _, exists := h.registry.Get(r.Context(), key); !exists && len(transitions) == 0 {
+		logger.Debug("API: Service not found",
+			zap.String("service_key", key),
+		)
+		http.Error(w, "Service not found", http.StatusNotFound)
+		return
 	}
-	return e.Message
+
+	logger.Debug("API: Retrieved service history",
+		zap.String("service_key", key),
+		zap.Int("transition_count", len(transitions)),
+	)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"service_name": serviceName,
+		"pod_name":     podName,
+		"count":        len(transitions),
+		"transitions":  transitions,
+	})
 }
+
+// UpdateServiceHandler handles PATCH /services/{name}/{pod} requests.
+// Updates providers, health check URL and labels of a registered pod in place, keeping its
+// registration time and subscriptions, and notifies subscribers with an update event.
+func (h *Handler) UpdateServiceHandler(w http.ResponseWriter, r *http.Request) {
+	logger.Info("API: Received update request",
+		zap.String("method", r.Method),
+		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
+	)
+
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	serviceName := r.PathValue("name")
+	podName := r.PathValue("pod")
+	if serviceName == "" || podName == "" {
+		http.Error(w, "service name and pod name are required", http.StatusBadRequest)
+		return
+	}
+
+	serviceName = serviceNameOf(r, serviceName)
+
+	var update models.ServiceUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	service, exists := h.registry.Get(r.Context(), models.ServiceKey(serviceName, podName))
+	if !exists {
+		http.Error(w, "Service not found", http.StatusNotFound)
+		return
+	}
+
+	// The updated pod must still be a valid registration
+	update.Apply(service)
+	if err := h.validateRegistration(service.Registration()); err != nil {
+		logger.Warn("API: Invalid update request",
+			zap.String("service_name", serviceName),
+			zap.String("pod_name", podName),
+			zap.Error(err),
+		)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := fromRequest(events.NewUpdateContext(serviceName, podName, &update), r)
+	event := eventqueue.NewEvent(string(events.EventUpdate), ctx, eventqueue.WithTimeout(5*time.Second))
+	if err := h.eventQueue.Enqueue(event); err != nil {
+		logger.Error("API: Failed to enqueue update event",
+			zap.String("service_name", serviceName),
+			zap.String("pod_name", podName),
+			zap.Error(err),
+			events.RequestIDField(r.Context()),
+		)
+		writeEnqueueError(w, err, "Failed to process update")
+		return
+	}
+
+	logger.Info("API: Update event enqueued successfully",
+		zap.String("service_name", serviceName),
+		zap.String("pod_name", podName),
+		events.RequestIDField(r.Context()),
+	)
+
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"status":  "accepted",
+		"message": "Update event queued successfully",
+	})
+}
+
+// subscriberInfo describes a subscriber in the subscriptions response
+type subscriberInfo struct {
+	SubscriberKey    string                      `json:"subscriber_key"`
+	NotificationURL  string                      `json:"notification_url"`
+	NotificationURLs map[models.EventType]string `json:"notification_urls,omitempty"` // Per event type overrides
+	LastNotifiedAt   time.Time                   `json:"last_notified_at,omitzero"`   // Last successful delivery, omitted if none yet
+}
+
+// SubscriptionsHandler handles GET /subscriptions[?group=<service_name>] requests.
+// With a group, returns the subscribers of that service group; without one,
+// returns the full subscription map keyed by service group.
+// DELETE requests are passed to EvictSubscriberHandler, which requires auth and is rate
+// limited while the query stays open.
+func (h *Handler) SubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	logger.Debug("API: Received subscriptions query request",
+		zap.String("method", r.Method),
+		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
+	)
+
+	if r.Method == http.MethodDelete {
+		h.RateLimit(h.RequireAuth(h.EvictSubscriberHandler))(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if group := r.URL.Query().Get("group"); group != "" {
+		group = serviceNameOf(r, group)
+		subscribers := h.subscribersOf(r.Context(), group)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"group":       group,
+			"count":       len(subscribers),
+			"subscribers": subscribers,
+		})
+		return
+	}
+
+	// Collect every subscribed group from the registered services
+	subscriptions := make(map[string][]subscriberInfo)
+	for _, service := range h.registry.GetAllServices(r.Context()) {
+		for _, group := range service.SubscriptionGroups() {
+			if _, seen := subscriptions[group]; !seen {
+				subscriptions[group] = h.subscribersOf(r.Context(), group)
+			}
+		}
+	}
+
+	logger.Debug("API: Sent subscriptions response",
+		zap.Int("group_count", len(subscriptions)),
+	)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":         len(subscriptions),
+		"subscriptions": subscriptions,
+	})
+}
+
+// EvictSubscriberHandler handles DELETE /subscriptions?subscriber=<service_name>:<pod_name>
+// requests. Detaches a misbehaving subscriber from every service group it subscribed to,
+// without unregistering it, and returns the groups it was detached from.
+func (h *Handler) EvictSubscriberHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := r.URL.Query().Get("subscriber")
+	if key == "" {
+		http.Error(w, "subscriber is required", http.StatusBadRequest)
+		return
+	}
+
+	service, exists := h.registry.Get(r.Context(), key)
+	if !exists {
+		http.Error(w, "Service not found", http.StatusNotFound)
+		return
+	}
+	groups := service.Subscriptions
+	if groups == nil {
+		groups = []string{}
+	}
+
+	ctx := fromRequest(events.NewEvictSubscriberContext(key), r)
+	event := eventqueue.NewEvent(string(events.EventEvictSubscriber), ctx, eventqueue.WithTimeout(5*time.Second))
+	if err := h.eventQueue.Enqueue(event); err != nil {
+		logger.Error("API: Failed to enqueue evict subscriber event",
+			zap.String("subscriber_key", key),
+			zap.Error(err),
+			events.RequestIDField(r.Context()),
+		)
+		writeEnqueueError(w, err, "Failed to evict subscriber")
+		return
+	}
+
+	// Only confirm once the subscriptions are gone
+	if err := waitForEvent(r.Context(), event); err != nil {
+		http.Error(w, "Failed to evict subscriber: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("API: Subscriber evicted",
+		zap.String("subscriber_key", key),
+		zap.Strings("service_groups", groups),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"subscriber": key,
+		"count":      len(groups),
+		"groups":     groups,
+	})
+}
+
+// subscribersOf returns the subscribers of a service group with their notification URLs
+// and when they were last notified
+func (h *Handler) subscribersOf(ctx context.Context, group string) []subscriberInfo {
+	services := h.registry.GetSubscriberServices(ctx, group)
+	subscribers := make([]subscriberInfo, 0, len(services))
+	for _, service := range services {
+		info := subscriberInfo{
+			SubscriberKey:    service.GetKey(),
+			NotificationURL:  service.NotificationURL,
+			NotificationURLs: service.NotificationURLs,
+		}
+		if h.notifier != nil {
+			info.LastNotifiedAt = h.notifier.LastNotifiedAt(info.SubscriberKey)
+		}
+		subscribers = append(subscribers, info)
+	}
+	sort.Slice(subscribers, func(i, j int) bool {
+		return subscribers[i].SubscriberKey < subscribers[j].SubscriberKey
+	})
+	return subscribers
+}
+
+// HealthHandler handles GET /health requests.
+// Reports the status of each subsystem (database, event queue, registry, reconcile) and an
+// overall status, which is unhealthy (503) if the database or event queue is unhealthy.
+func (h *Handler) HealthHandler(w http.ResponseWriter, r *http.Request) {
+	logger.Debug("API: Received health check request",
+		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
+	)
+
+	checks := map[string]map[string]interface{}{
+		"database":    h.databaseHealth(r.Context()),
+		"event_queue": h.queueHealth(),
+		"registry":    h.registryHealth(r.Context()),
+		"reconcile":   h.reconcileHealth(),
+	}
+
+	status, code := statusHealthy, http.StatusOK
+	for _, name := range []string{"database", "event_queue"} {
+		if checks[name]["status"] == statusUnhealthy {
+			status, code = statusUnhealthy, http.StatusServiceUnavailable
+		}
+	}
+
+	writeJSON(w, code, map[string]interface{}{
+		"status": status,
+		"checks": checks,
+	})
+}
+
+// StatsHandler handles GET /stats requests.
+// Reports event queue size, throughput and lag for capacity planning.
+func (h *Handler) StatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.queueStats == nil {
+		http.Error(w, "Queue stats are not available", http.StatusNotImplemented)
+		return
+	}
+
+	stats := map[string]interface{}{
+		"event_queue": h.queueStats(),
+	}
+	if h.dualStore != nil && h.dualStore.GetDatabase() != nil {
+		stats["database_repairs"] = h.dualStore.RepairStats()
+	}
+	if h.databaseConnection != nil {
+		stats["database_connection"] = h.databaseConnection()
+	}
+	if h.drainMode != nil {
+		stats["drain_mode"] = h.drainMode()
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// DatabaseDiffHandler handles GET /database/diff requests.
+// Compares the in-memory registry with the database and lists services and subscriptions
+// that differ, to diagnose lost asynchronous writes. Returns 501 without a database.
+func (h *Handler) DatabaseDiffHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.dualStore == nil || h.dualStore.GetDatabase() == nil {
+		http.Error(w, "No database configured", http.StatusNotImplemented)
+		return
+	}
+
+	diff, err := h.dualStore.Diff(r.Context())
+	if err != nil {
+		logger.Error("API: Failed to compare cache with database",
+			zap.Error(err),
+		)
+		http.Error(w, "Failed to read database", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"in_sync": diff.InSync(),
+		"diff":    diff,
+	})
+}
+
+// Health statuses reported by GET /health
+const (
+	statusHealthy   = "healthy"
+	statusUnhealthy = "unhealthy"
+	statusDisabled  = "disabled"
+	statusPending   = "pending"
+)
+
+// databaseHealth pings the database, if one is configured
+func (h *Handler) databaseHealth(ctx context.Context) map[string]interface{} {
+	if h.dualStore == nil || h.dualStore.GetDatabase() == nil {
+		return map[string]interface{}{"status": statusDisabled}
+	}
+
+	var result map[string]interface{}
+	start := time.Now()
+	if err := h.pingDatabase(ctx); err != nil {
+		result = map[string]interface{}{"status": statusUnhealthy, "error": err.Error()}
+	} else {
+		result = map[string]interface{}{
+			"status":     statusHealthy,
+			"latency_ms": time.Since(start).Milliseconds(),
+		}
+	}
+
+	if h.databaseConnection != nil {
+		connection := h.databaseConnection()
+		result["connection"] = connection.State
+		result["consecutive_failures"] = connection.ConsecutiveFailures
+		result["reconnects"] = connection.Reconnects
+	}
+	return result
+}
+
+// queueHealth reports whether the event queue is running and how many events are waiting
+func (h *Handler) queueHealth() map[string]interface{} {
+	result := map[string]interface{}{"status": statusHealthy}
+	if h.queueRunning != nil && !h.queueRunning() {
+		result["status"] = statusUnhealthy
+		result["error"] = "event queue is not running"
+	}
+	if h.eventQueue != nil {
+		result["queue_size"] = h.eventQueue.GetQueueSize()
+	}
+	return result
+}
+
+// registryHealth reports the number of registered services
+func (h *Handler) registryHealth(ctx context.Context) map[string]interface{} {
+	return map[string]interface{}{
+		"status":   statusHealthy,
+		"services": len(h.registry.GetAllServices(ctx)),
+	}
+}
+
+// reconcileHealth reports when the last reconcile completed
+func (h *Handler) reconcileHealth() map[string]interface{} {
+	if h.lastReconcile == nil {
+		return map[string]interface{}{"status": statusDisabled}
+	}
+
+	last := h.lastReconcile()
+	if last.IsZero() {
+		return map[string]interface{}{"status": statusPending}
+	}
+	return map[string]interface{}{
+		"status":   statusHealthy,
+		"last_run": last,
+	}
+}
+
+// pingDatabase pings the database with a short timeout. No database counts as reachable.
+func (h *Handler) pingDatabase(ctx context.Context) error {
+	if h.dualStore == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	return h.dualStore.Ping(ctx)
+}
+
+// LivenessHandler handles GET /livez requests.
+// Always succeeds while the process is able to serve HTTP.
+func (h *Handler) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "alive",
+	})
+}
+
+// ReadinessHandler handles GET /readyz requests.
+// Returns 200 when the event queue is running and the database (if any) answers a ping,
+// otherwise 503 with the reason.
+func (h *Handler) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	if reason := h.notReadyReason(r.Context()); reason != "" {
+		logger.Warn("API: Readiness check failed",
+			zap.String("reason", reason),
+		)
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"status": "not_ready",
+			"reason": reason,
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "ready",
+	})
+}
+
+// notReadyReason returns why the manager can't serve traffic, or "" if it is ready
+func (h *Handler) notReadyReason(ctx context.Context) string {
+	if h.queueRunning != nil && !h.queueRunning() {
+		return "event queue is not running"
+	}
+
+	if err := h.pingDatabase(ctx); err != nil {
+		return "database unreachable: " + err.Error()
+	}
+
+	return ""
+}
+
+// SubscriberNotificationsHandler handles GET /subscribers/{key}/notifications requests.
+// Returns the most recent notifications sent to the subscriber, newest first.
+func (h *Handler) SubscriberNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	logger.Debug("API: Received subscriber notifications query request",
+		zap.String("method", r.Method),
+		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
+	)
+
+	if r.Method != http.MethodGet {
+		logger.Warn("API: Invalid method for subscriber notifications endpoint",
+			zap.String("method", r.Method),
+		)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	subscriberKey := r.PathValue("key")
+	if subscriberKey == "" {
+		http.Error(w, "Missing subscriber key", http.StatusBadRequest)
+		return
+	}
+
+	if h.notifier == nil {
+		http.Error(w, "Notification history is not available", http.StatusNotImplemented)
+		return
+	}
+
+	records := h.notifier.GetNotificationHistory(subscriberKey)
+
+	// Unknown subscriber: nothing registered and nothing ever sent
+	if _, exists := h.registry.Get(r.Context(), subscriberKey); !exists && len(records) == 0 {
+		logger.Debug("API: Subscriber not found",
+			zap.String("subscriber_key", subscriberKey),
+		)
+		http.Error(w, "Subscriber not found", http.StatusNotFound)
+		return
+	}
+
+	logger.Info("API: Retrieved subscriber notification history",
+		zap.String("subscriber_key", subscriberKey),
+		zap.Int("record_count", len(records)),
+	)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"subscriber_key": subscriberKey,
+		"count":          len(records),
+		"notifications":  records,
+	})
+}
+
+// BreakersHandler handles GET /breakers requests.
+// Lists the circuit breaker state of every notification URL with recent failures.
+func (h *Handler) BreakersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.notifier == nil {
+		http.Error(w, "Notification breakers are not available", http.StatusNotImplemented)
+		return
+	}
+
+	breakers := h.notifier.GetBreakerStatuses()
+
+	open := 0
+	for _, breaker := range breakers {
+		if breaker.State != notifier.BreakerClosed {
+			open++
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":    len(breakers),
+		"open":     open,
+		"breakers": breakers,
+	})
+}
+
+// QuotasHandler handles GET /quotas requests.
+// Lists the live pods of every namespace that has pods or a quota, against its quota.
+func (h *Handler) QuotasHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	namespaces := h.registry.GetNamespaceUsage(r.Context())
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":      len(namespaces),
+		"namespaces": namespaces,
+	})
+}
+
+// ResetBreakerHandler handles POST /breakers/reset?url=<notification_url> requests.
+// Force-closes the URL's circuit after its subscriber was fixed, instead of waiting for the
+// next probe. Responds with 404 if the URL has no recent failures.
+func (h *Handler) ResetBreakerHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.notifier == nil {
+		http.Error(w, "Notification breakers are not available", http.StatusNotImplemented)
+		return
+	}
+
+	url := r.URL.Query().Get("url")
+	if url == "" {
+		http.Error(w, "Missing url query parameter", http.StatusBadRequest)
+		return
+	}
+
+	if !h.notifier.ResetBreaker(url) {
+		http.Error(w, "No circuit breaker for this URL", http.StatusNotFound)
+		return
+	}
+
+	logger.Info("API: Notification circuit breaker reset",
+		zap.String("notification_url", url),
+		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
+	)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"notification_url": url,
+		"state":            notifier.BreakerClosed,
+	})
+}
+
+// ReconcileHandler handles POST /reconcile requests.
+// Enqueues a reconcile event right away instead of waiting for the reconcile timer,
+// e.g. after editing the database by hand.
+func (h *Handler) ReconcileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logger.Warn("API: Invalid method for reconcile endpoint",
+			zap.String("method", r.Method),
+		)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.enqueueReconcile(r); err != nil {
+		logger.Error("API: Failed to enqueue reconcile event",
+			zap.Error(err),
+			events.RequestIDField(r.Context()),
+		)
+		writeEnqueueError(w, err, "Failed to trigger reconcile")
+		return
+	}
+
+	logger.Info("API: Reconcile event enqueued on demand",
+		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
+	)
+
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"status":  "accepted",
+		"message": "Reconcile event queued successfully",
+	})
+}
+
+// HealthCheckHandler handles POST /healthcheck?key=service:pod requests.
+// Checks one pod right away instead of waiting for the scheduler and returns its status once
+// the worker has applied the result. Like scheduled checks the result counts towards the
+// health thresholds, so a single failure may not flip the status yet.
+func (h *Handler) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		http.Error(w, "key is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, exists := h.registry.Get(r.Context(), key); !exists {
+		http.Error(w, "Service not found", http.StatusNotFound)
+		return
+	}
+
+	// The check is cancelled if the client goes away, leaving the status unchanged
+	event := eventqueue.NewEvent(string(events.EventHealthCheck), fromRequest(events.NewHealthCheckContext(r.Context(), key), r))
+	if err := h.eventQueue.Enqueue(event); err != nil {
+		logger.Error("API: Failed to enqueue health check event",
+			zap.String("service_key", key),
+			zap.Error(err),
+			events.RequestIDField(r.Context()),
+		)
+		writeEnqueueError(w, err, "Failed to trigger health check")
+		return
+	}
+
+	logger.Info("API: Health check enqueued on demand",
+		zap.String("service_key", key),
+		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
+	)
+
+	if err := waitForEvent(r.Context(), event); err != nil {
+		http.Error(w, "Health check failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	service, exists := h.registry.Get(r.Context(), key)
+	if !exists {
+		http.Error(w, "Service not found", http.StatusNotFound) // Unregistered meanwhile
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"key":               key,
+		"status":            service.Status,
+		"last_health_check": service.LastHealthCheck,
+	})
+}
+
+// LogLevelHandler handles PUT /loglevel requests.
+// Changes the log level at runtime, e.g. to debug a live incident without a restart.
+func (h *Handler) LogLevelHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Level string `json:"level"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	previous := logger.Level()
+	if err := logger.SetLevel(request.Level); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logger.Warn("API: Log level changed",
+		zap.String("previous", previous),
+		zap.String("level", logger.Level()),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"level": logger.Level(),
+	})
+}
+
+// DrainHandler handles POST /drain requests with a body of {"enabled": true|false}.
+// Drain mode pauses scheduled health checks and suppresses health status notifications, e.g.
+// during planned maintenance of downstream targets; the API and reconciles keep running.
+func (h *Handler) DrainHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.setDrainMode == nil {
+		http.Error(w, "Drain mode is not available", http.StatusNotImplemented)
+		return
+	}
+
+	var request struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Enabled == nil {
+		http.Error(w, "Invalid request body, expected {\"enabled\": true|false}", http.StatusBadRequest)
+		return
+	}
+
+	h.setDrainMode(*request.Enabled)
+
+	logger.Warn("API: Drain mode changed",
+		zap.Bool("enabled", *request.Enabled),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+
+	writeJSON(w, http.StatusOK, map[string]bool{
+		"drain_mode": *request.Enabled,
+	})
+}
+
+// DeadLettersHandler handles GET /notifications/deadletters requests.
+// Lists notifications that could not be delivered, newest first.
+func (h *Handler) DeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.notifier == nil {
+		http.Error(w, "Dead letters are not available", http.StatusNotImplemented)
+		return
+	}
+
+	letters := h.notifier.GetDeadLetters()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":        len(letters),
+		"dead_letters": letters,
+	})
+}
+
+// ReplayDeadLetterHandler handles POST /notifications/deadletters/{id}/replay requests.
+// Removes the dead letter and delivers its notification again in the background.
+func (h *Handler) ReplayDeadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid dead letter id", http.StatusBadRequest)
+		return
+	}
+
+	if h.notifier == nil {
+		http.Error(w, "Dead letters are not available", http.StatusNotImplemented)
+		return
+	}
+
+	if !h.notifier.ReplayDeadLetter(id) {
+		http.Error(w, "Dead letter not found", http.StatusNotFound)
+		return
+	}
+
+	logger.Info("API: Replaying dead letter",
+		zap.Uint64("id", id),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"status":  "accepted",
+		"message": "Dead letter queued for redelivery",
+		"id":      id,
+	})
+}
+
+// enqueueErrorStatus maps an enqueue failure to a status code: 503 when the event queue is
+// full (the client should retry later), 500 otherwise
+func enqueueErrorStatus(err error) int {
+	if errors.Is(err, queue.ErrQueueFull) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// writeQuotaExceeded responds to a registration rejected by its namespace quota
+// (see registry.ErrQuotaExceeded) with 429 and the quota_exceeded code
+func writeQuotaExceeded(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusTooManyRequests, map[string]string{
+		"error":   "quota_exceeded",
+		"message": err.Error(),
+	})
+}
+
+// writeEnqueueError responds to a request whose event could not be enqueued
+func writeEnqueueError(w http.ResponseWriter, err error, message string) {
+	status := enqueueErrorStatus(err)
+	if status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+		message += ": event queue is full"
+	}
+	http.Error(w, message, status)
+}
+
+// enqueueRegister creates and enqueues a register event for request r (with deadline for register events)
+func (h *Handler) enqueueRegister(r *http.Request, registration *models.ServiceRegistration) (eventqueue.IEvent, error) {
+	ctx := fromRequest(events.NewRegisterContext(registration), r)
+	event := eventqueue.NewEvent(string(events.EventRegister), ctx, eventqueue.WithTimeout(5*time.Second))
+	return event, h.eventQueue.Enqueue(event)
+}
+
+// syncWrites reports whether registrations are only acknowledged once they are persisted
+func (h *Handler) syncWrites() bool {
+	return h.dualStore != nil && h.dualStore.SyncWrites()
+}
+
+// waitForEvent waits until the worker has handled event and returns the handler's error.
+// Returns ctx.Err() if the client goes away first.
+func waitForEvent(ctx context.Context, event eventqueue.IEvent) error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := event.Wait()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// serviceNameOf qualifies a service name from request r with its namespace query parameter,
+// see models.QualifiedServiceName. Without the parameter the name is in the default namespace.
+func serviceNameOf(r *http.Request, serviceName string) string {
+	return models.QualifiedServiceName(r.URL.Query().Get("namespace"), serviceName)
+}
+
+// enqueueUnregister creates and enqueues an unregister event for request r (with deadline for unregister events)
+func (h *Handler) enqueueUnregister(r *http.Request, serviceName, podName string) error {
+	ctx := fromRequest(events.NewUnregisterContext(serviceName, podName), r)
+	event := eventqueue.NewEvent(string(events.EventUnregister), ctx, eventqueue.WithTimeout(5*time.Second))
+	return h.eventQueue.Enqueue(event)
+}
+
+// enqueueReconcile creates and enqueues a reconcile event for request r (no deadline, like scheduled reconciles)
+func (h *Handler) enqueueReconcile(r *http.Request) error {
+	ctx := withSpan(events.WithRequestID(events.NewReconcileContext(), events.GetRequestID(r.Context())), r)
+	event := eventqueue.NewEvent(string(events.EventReconcile), ctx)
+	return h.eventQueue.Enqueue(event)
+}
+
+// ChangesHandler handles GET /changes?since=<seq>&limit=<n> requests.
+// Returns registry changes with a sequence greater than since, oldest first, so that
+// reconnecting subscribers can catch up by passing the last sequence they processed.
+func (h *Handler) ChangesHandler(w http.ResponseWriter, r *http.Request) {
+	logger.Debug("API: Received changes query request",
+		zap.String("method", r.Method),
+		zap.String("remote_addr", r.RemoteAddr),
+		events.RequestIDField(r.Context()),
+	)
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.dualStore == nil {
+		http.Error(w, "Change feed is not available", http.StatusNotImplemented)
+		return
+	}
+
+	var since int64
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			http.Error(w, "since must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	limit, err := queryInt(r, "limit", defaultChangesLimit)
+	if err != nil || limit <= 0 {
+		http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+		return
+	}
+	if limit > maxChangesLimit {
+		limit = maxChangesLimit
+	}
+
+	changes, err := h.dualStore.GetChangesSince(r.Context(), since, limit)
+	if err != nil {
+		logger.Error("API: Failed to read change feed",
+			zap.Int64("since", since),
+			zap.Error(err),
+		)
+		http.Error(w, "Failed to read change feed", http.StatusInternalServerError)
+		return
+	}
+	if changes == nil {
+		changes = []*models.ChangeRecord{}
+	}
+
+	// Cursor for the next request: last sequence returned, or the input cursor if nothing new
+	lastSequence := since
+	if len(changes) > 0 {
+		lastSequence = changes[len(changes)-1].Sequence
+	}
+
+	logger.Debug("API: Sent changes response",
+		zap.Int64("since", since),
+		zap.Int("change_count", len(changes)),
+	)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":         len(changes),
+		"last_sequence": lastSequence,
+		"changes":       changes,
+	})
+}
+
+// WatchHandler handles GET /watch?service_name=<name> requests.
+// Streams registry changes (register, unregister, health status updates) as Server-Sent
+// Events until the client disconnects. service_name is optional and limits the stream to
+// one service. Each event's id is the change feed sequence, so a client that falls behind
+// or reconnects can catch up with GET /changes?since=<id>.
+func (h *Handler) WatchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.watchHub == nil {
+		http.Error(w, "Watch is not available", http.StatusNotImplemented)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	serviceName := r.URL.Query().Get("service_name")
+	if serviceName != "" {
+		serviceName = serviceNameOf(r, serviceName)
+	}
+	watcher := h.watchHub.Subscribe(serviceName)
+	defer h.watchHub.Unsubscribe(watcher)
+
+	logger.Info("API: Watcher connected",
+		zap.String("service_name", serviceName),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(watchKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case change, ok := <-watcher.Changes():
+			if !ok {
+				// Hub closed or watcher too slow
+				logger.Info("API: Watcher disconnected by server",
+					zap.String("service_name", serviceName),
+					zap.String("remote_addr", r.RemoteAddr),
+				)
+				return
+			}
+
+			data, err := json.Marshal(change)
+			if err != nil {
+				logger.Error("API: Failed to encode change", zap.Error(err))
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", change.Sequence, change.EventType, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			logger.Info("API: Watcher disconnected",
+				zap.String("service_name", serviceName),
+				zap.String("remote_addr", r.RemoteAddr),
+			)
+			return
+		}
+	}
+}
+
+// parseLabelSelector parses repeated ?label=key=value query parameters into a selector
+func parseLabelSelector(r *http.Request) (map[string]string, error) {
+	values := r.URL.Query()["label"]
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	selector := make(map[string]string, len(values))
+	for _, value := range values {
+		key, labelValue, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label selector %q, expected key=value", value)
+		}
+		selector[key] = labelValue
+	}
+	return selector, nil
+}
+
+// listServices returns the pods of serviceName, or of every service if it is empty.
+// With includeDeleted, unregistered pods still kept as tombstones are included.
+func (h *Handler) listServices(ctx context.Context, serviceName string, includeDeleted bool) []*models.ServiceInfo {
+	var services []*models.ServiceInfo
+	if serviceName == "" {
+		services = h.registry.GetAllServices(ctx)
+	} else {
+		services = h.registry.GetByServiceName(ctx, serviceName)
+	}
+
+	if includeDeleted {
+		for _, tombstone := range h.registry.GetTombstones(ctx) {
+			if serviceName == "" || tombstone.QualifiedName() == serviceName {
+				services = append(services, tombstone)
+			}
+		}
+	}
+	return redactHealthCheckHeaders(services)
+}
+
+// serviceView is a pod as returned by GET /services, with its uptime
+type serviceView struct {
+	*models.ServiceInfo
+	UptimeSeconds *int64 `json:",omitempty"` // Seconds since the pod registered, absent for tombstones
+}
+
+// withUptime wraps services in views reporting their uptime at now
+func withUptime(services []*models.ServiceInfo, now time.Time) []serviceView {
+	views := make([]serviceView, len(services))
+	for i, service := range services {
+		views[i].ServiceInfo = service
+		if !service.IsTombstone() && !service.RegisteredAt.IsZero() {
+			uptime := int64(now.Sub(service.RegisteredAt) / time.Second)
+			views[i].UptimeSeconds = &uptime
+		}
+	}
+	return views
+}
+
+// redactedHeaderValue replaces health check header values in API responses
+const redactedHeaderValue = "[REDACTED]"
+
+// redactHealthCheckHeaders hides health check header values, which often hold credentials
+// (e.g. Authorization). Header names are kept so clients can see what is configured.
+func redactHealthCheckHeaders(services []*models.ServiceInfo) []*models.ServiceInfo {
+	for _, service := range services {
+		service.HealthCheckHeaders = redactHeaders(service.HealthCheckHeaders)
+		if len(service.HealthChecks) == 0 {
+			continue
+		}
+		// The slice may be shared with the store as well
+		checks := make([]models.HealthCheck, len(service.HealthChecks))
+		for i, check := range service.HealthChecks {
+			check.Headers = redactHeaders(check.Headers)
+			checks[i] = check
+		}
+		service.HealthChecks = checks
+	}
+	return services
+}
+
+// redactHeaders returns a copy of headers with every value replaced. The map may be shared
+// with the store, so it is never edited in place.
+func redactHeaders(headers map[string]string) map[string]string {
+	if len(headers) == 0 {
+		return headers
+	}
+	redacted := make(map[string]string, len(headers))
+	for name := range headers {
+		redacted[name] = redactedHeaderValue
+	}
+	return redacted
+}
+
+// filterRoutable keeps the healthy services, and those not checked yet or whose checks were
+// inconclusive if the handler treats unknown as routable
+func (h *Handler) filterRoutable(services []*models.ServiceInfo) []*models.ServiceInfo {
+	return slices.DeleteFunc(services, func(service *models.ServiceInfo) bool {
+		return service.Status != models.StatusHealthy && (service.Status != models.StatusUnknown || !h.unknownRoutable)
+	})
+}
+
+// filterByLabels keeps the services matching every label in selector
+func filterByLabels(services []*models.ServiceInfo, selector map[string]string) []*models.ServiceInfo {
+	if len(selector) == 0 {
+		return services
+	}
+
+	result := make([]*models.ServiceInfo, 0, len(services))
+	for _, service := range services {
+		if service.MatchesLabels(selector) {
+			result = append(result, service)
+		}
+	}
+	return result
+}
+
+// parseProtocol parses the optional ?protocol= query parameter, rejecting unknown protocols
+func parseProtocol(r *http.Request) (models.Protocol, error) {
+	protocol := models.Protocol(r.URL.Query().Get("protocol"))
+	if protocol != "" && !protocol.IsValid() {
+		return "", fmt.Errorf("protocol must be one of http, tcp, pfcp, gtp, udp, grpc")
+	}
+	return protocol, nil
+}
+
+// filterByProtocol keeps only pods with a provider of the given protocol, with their
+// providers trimmed to that protocol. An empty protocol keeps everything.
+func filterByProtocol(services []*models.ServiceInfo, protocol models.Protocol) []*models.ServiceInfo {
+	if protocol == "" {
+		return services
+	}
+
+	result := make([]*models.ServiceInfo, 0, len(services))
+	for _, service := range services {
+		var providers []models.ProviderInfo
+		for _, provider := range service.Providers {
+			if provider.Protocol == protocol {
+				providers = append(providers, provider)
+			}
+		}
+		if len(providers) > 0 {
+			service.Providers = providers // Services are copies, safe to trim
+			result = append(result, service)
+		}
+	}
+	return result
+}
+
+// queryInt parses an integer query parameter, returning def when it's absent
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// queryBool parses a boolean query parameter, returning false when it's absent
+func queryBool(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// validateRegistration validates a service registration (see models.ServiceRegistration.Validate)
+// and checks that the registry accepts its number of subscriptions
+func (h *Handler) validateRegistration(reg *models.ServiceRegistration) error {
+	if err := reg.Validate(); err != nil {
+		return err
+	}
+	return h.registry.CheckSubscriptions(reg.Subscriptions)
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// ValidationError represents a validation error
+type ValidationError = models.ValidationError
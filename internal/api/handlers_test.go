@@ -1,18 +1,37 @@
 package api
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/notifier"
+	"github.com/chronnie/governance/internal/queue"
 	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/internal/watch"
 	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
 	"github.com/chronnie/governance/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func setupTestHandler() (*Handler, *registry.Registry, eventqueue.IEventQueue) {
@@ -24,8 +43,8 @@ func setupTestHandler() (*Handler, *registry.Registry, eventqueue.IEventQueue) {
 	}
 	queue := eventqueue.NewEventQueue(queueConfig)
 
-	// Start queue in background
-	go queue.Start(context.Background())
+	// Start queue (non-blocking, processing runs in background)
+	queue.Start(context.Background())
 
 	handler := NewHandler(reg, queue)
 	return handler, reg, queue
@@ -64,6 +83,170 @@ func TestRegisterHandlerSuccess(t *testing.T) {
 	}
 }
 
+func TestRegisterHandlerTooManySubscriptions(t *testing.T) {
+	reg := registry.NewRegistry(storage.NewDualStore(nil), registry.WithMaxSubscriptions(1))
+	handler := NewHandler(reg, eventqueue.NewEventQueue(eventqueue.EventQueueConfig{BufferSize: 1}))
+
+	body, _ := json.Marshal(&models.ServiceRegistration{
+		ServiceName:     "test-service",
+		PodName:         "test-pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		Subscriptions:   []string{"service-a", "service-b"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.RegisterHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "too many subscriptions") {
+		t.Errorf("Expected the limit in the error, got %q", rec.Body.String())
+	}
+}
+
+func TestRegisterHandlerQuotaExceeded(t *testing.T) {
+	reg := registry.NewRegistry(storage.NewDualStore(nil), registry.WithNamespaceQuotas(map[string]int{"team-a": 1}, 0))
+	queue := eventqueue.NewEventQueue(eventqueue.EventQueueConfig{BufferSize: 10})
+	queue.Start(context.Background())
+	defer queue.Stop()
+	handler := NewHandler(reg, queue)
+
+	registration := &models.ServiceRegistration{
+		Namespace:       "team-a",
+		ServiceName:     "test-service",
+		PodName:         "test-pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	}
+	reg.Register(context.Background(), registration)
+
+	register := func(podName string) *httptest.ResponseRecorder {
+		next := *registration
+		next.PodName = podName
+		body, _ := json.Marshal(&next)
+		rec := httptest.NewRecorder()
+		handler.RegisterHandler(rec, httptest.NewRequest(http.MethodPost, "/register", bytes.NewReader(body)))
+		return rec
+	}
+
+	rec := register("test-pod-2")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", http.StatusTooManyRequests, rec.Code)
+	}
+	var response map[string]string
+	json.NewDecoder(rec.Body).Decode(&response)
+	if response["error"] != "quota_exceeded" || !strings.Contains(response["message"], "team-a") {
+		t.Errorf("Expected the quota_exceeded code naming the namespace, got %v", response)
+	}
+
+	// The registered pod may still re-register
+	if rec := register("test-pod-1"); rec.Code != http.StatusAccepted {
+		t.Errorf("Expected status %d for a re-registration, got %d", http.StatusAccepted, rec.Code)
+	}
+}
+
+func TestRegisterHandlerQueueFull(t *testing.T) {
+	reg := registry.NewRegistry(storage.NewDualStore(nil))
+	eventQueue := queue.NewInstrumentedQueue(eventqueue.NewEventQueue(eventqueue.EventQueueConfig{BufferSize: 1}), 1, 0)
+
+	// One event blocks the worker, the next fills the buffer
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	eventQueue.RegisterHandler(string(events.EventRegister), eventqueue.EventHandlerFunc(func(ctx context.Context, event eventqueue.IEvent) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}))
+	eventQueue.Start(context.Background())
+	defer eventQueue.Stop()
+	defer close(release)
+
+	handler := NewHandler(reg, eventQueue)
+	register := func() *httptest.ResponseRecorder {
+		body, _ := json.Marshal(&models.ServiceRegistration{
+			ServiceName:     "test-service",
+			PodName:         "test-pod-1",
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.RegisterHandler(rec, req)
+		return rec
+	}
+
+	if rec := register(); rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+	<-started
+	if rec := register(); rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+
+	rec := register()
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status %d when the queue is full, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+}
+
+func TestRegisterHandlerSyncWrites(t *testing.T) {
+	dualStore := storage.NewDualStore(nil, storage.WithSyncWrites())
+	reg := registry.NewRegistry(dualStore)
+	eventQueue := eventqueue.NewEventQueue(eventqueue.EventQueueConfig{BufferSize: 10})
+
+	// The worker fails registrations of pod-2 as if the database write failed
+	eventQueue.RegisterHandler(string(events.EventRegister), eventqueue.EventHandlerFunc(func(ctx context.Context, event eventqueue.IEvent) error {
+		registration := events.GetEventData(ctx).(*events.RegisterEvent).Registration
+		if registration.PodName == "test-pod-2" {
+			return errors.New("database unavailable")
+		}
+		_, err := reg.Register(ctx, registration)
+		return err
+	}))
+	eventQueue.Start(context.Background())
+	defer eventQueue.Stop()
+
+	handler := NewHandler(reg, eventQueue, WithDualStore(dualStore))
+	register := func(podName string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(&models.ServiceRegistration{
+			ServiceName:     "test-service",
+			PodName:         podName,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.RegisterHandler(rec, req)
+		return rec
+	}
+
+	// Acknowledged only once registered, so it is visible right away
+	if rec := register("test-pod-1"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if _, exists := reg.Get(context.Background(), "test-service:test-pod-1"); !exists {
+		t.Error("Expected service to be registered when the response is sent")
+	}
+
+	rec := register("test-pod-2")
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "database unavailable") {
+		t.Errorf("Expected the error in the response, got %q", rec.Body.String())
+	}
+}
+
 func TestRegisterHandlerInvalidJSON(t *testing.T) {
 	handler, _, queue := setupTestHandler()
 	defer queue.Stop()
@@ -78,6 +261,89 @@ func TestRegisterHandlerInvalidJSON(t *testing.T) {
 	}
 }
 
+func TestRegisterHandlerStrictFields(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+
+	testCases := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{
+			name:  "unknown field",
+			body:  `{"service_name":"user-service","pod_name":"pod-1","providers":[{"protocol":"http","ip":"192.168.1.10","port":8080}],"health_check_url":"http://192.168.1.10:8080/health","notification_url":"http://192.168.1.10:8080/notify","subscription":["order-service"]}`,
+			field: `unknown field "subscription"`,
+		},
+		{
+			name:  "unknown provider field",
+			body:  `{"service_name":"user-service","pod_name":"pod-1","providers":[{"protocol":"http","address":"192.168.1.10","port":8080}],"notification_url":"http://192.168.1.10:8080/notify"}`,
+			field: `unknown field "address"`,
+		},
+		{
+			name:  "missing field",
+			body:  `{"service_name":"user-service","providers":[{"protocol":"http","ip":"192.168.1.10","port":8080}],"health_check_url":"http://192.168.1.10:8080/health","notification_url":"http://192.168.1.10:8080/notify"}`,
+			field: `missing required field "pod_name"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBufferString(tc.body))
+			rec := httptest.NewRecorder()
+
+			handler.RegisterHandler(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tc.field) {
+				t.Errorf("Expected error naming %s, got %q", tc.field, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRegisterHandlerBodyTooLarge(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+	WithMaxBodySize(1024)(handler)
+
+	registration := models.ServiceRegistration{
+		ServiceName: "test-service",
+		PodName:     "test-pod-1",
+		Providers: []models.ProviderInfo{
+			{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080},
+		},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		Labels:          map[string]string{"padding": strings.Repeat("x", 2048)},
+	}
+
+	testCases := []struct {
+		name    string
+		handler http.HandlerFunc
+		body    interface{}
+	}{
+		{"register", handler.RegisterHandler, registration},
+		{"batch", handler.RegisterBatchHandler, []models.ServiceRegistration{registration}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonData, _ := json.Marshal(tc.body)
+			req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(jsonData))
+			rec := httptest.NewRecorder()
+
+			tc.handler(rec, req)
+
+			if rec.Code != http.StatusRequestEntityTooLarge {
+				t.Errorf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+			}
+		})
+	}
+}
+
 func TestRegisterHandlerMissingServiceName(t *testing.T) {
 	handler, _, queue := setupTestHandler()
 	defer queue.Stop()
@@ -176,6 +442,52 @@ func TestUnregisterHandlerMethodNotAllowed(t *testing.T) {
 	}
 }
 
+func TestUnregisterServiceHandler(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	// Unknown service
+	req := httptest.NewRequest(http.MethodDelete, "/unregister/service?service_name=user-service", nil)
+	rec := httptest.NewRecorder()
+	handler.UnregisterServiceHandler(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+
+	// Missing service_name
+	req = httptest.NewRequest(http.MethodDelete, "/unregister/service", nil)
+	rec = httptest.NewRecorder()
+	handler.UnregisterServiceHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	for _, pod := range []string{"pod-1", "pod-2"} {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     "user-service",
+			PodName:         pod,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/unregister/service?service_name=user-service", nil)
+	rec = httptest.NewRecorder()
+	handler.UnregisterServiceHandler(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+
+	var response struct {
+		PodCount int `json:"pod_count"`
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+	if response.PodCount != 2 {
+		t.Errorf("Expected pod_count 2, got %d", response.PodCount)
+	}
+}
+
 func TestServicesHandler(t *testing.T) {
 	handler, reg, queue := setupTestHandler()
 	defer queue.Stop()
@@ -190,7 +502,7 @@ func TestServicesHandler(t *testing.T) {
 			NotificationURL: "http://192.168.1.10:8080/notify",
 			Subscriptions:   []string{},
 		}
-		reg.Register(registration)
+		reg.Register(context.Background(), registration)
 	}
 
 	req := httptest.NewRequest(http.MethodGet, "/services", nil)
@@ -211,129 +523,2070 @@ func TestServicesHandler(t *testing.T) {
 	}
 }
 
-func TestServicesHandlerMethodNotAllowed(t *testing.T) {
-	handler, _, queue := setupTestHandler()
+func TestServicesHandlerTimestamps(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
 	defer queue.Stop()
 
-	req := httptest.NewRequest(http.MethodPost, "/services", nil)
-	rec := httptest.NewRecorder()
+	service, _ := reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "test-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	})
+	service.RegisteredAt = time.Now().Add(-90 * time.Second)
+	reg.Restore(context.Background(), []*models.ServiceInfo{service})
 
+	req := httptest.NewRequest(http.MethodGet, "/services", nil)
+	rec := httptest.NewRecorder()
 	handler.ServicesHandler(rec, req)
 
-	if rec.Code != http.StatusMethodNotAllowed {
-		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	var response struct {
+		Services []map[string]interface{} `json:"services"`
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+	if len(response.Services) != 1 {
+		t.Fatalf("Expected 1 service, got %d", len(response.Services))
+	}
+	pod := response.Services[0]
+
+	if _, err := time.Parse(time.RFC3339, pod["RegisteredAt"].(string)); err != nil {
+		t.Errorf("Expected RegisteredAt in RFC 3339, got %v", pod["RegisteredAt"])
+	}
+	if _, exists := pod["LastHealthCheck"]; exists {
+		t.Errorf("Expected no LastHealthCheck before the first check, got %v", pod["LastHealthCheck"])
+	}
+	if uptime, _ := pod["UptimeSeconds"].(float64); uptime < 90 || uptime > 100 {
+		t.Errorf("Expected an uptime of about 90 seconds, got %v", pod["UptimeSeconds"])
 	}
 }
 
-func TestHealthHandler(t *testing.T) {
-	handler, _, queue := setupTestHandler()
+func TestServicesHandlerPagination(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
 	defer queue.Stop()
 
-	req := httptest.NewRequest(http.MethodGet, "/health", nil)
-	rec := httptest.NewRecorder()
+	// Register out of order to check the response is sorted
+	for _, key := range [][2]string{{"b-service", "pod-2"}, {"a-service", "pod-1"}, {"b-service", "pod-1"}, {"a-service", "pod-2"}, {"c-service", "pod-1"}} {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     key[0],
+			PodName:         key[1],
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+	}
 
-	handler.HealthHandler(rec, req)
+	type pageResponse struct {
+		Count      int                   `json:"count"`
+		Total      int                   `json:"total"`
+		NextOffset *int                  `json:"next_offset"`
+		Services   []*models.ServiceInfo `json:"services"`
+	}
 
-	if rec.Code != http.StatusOK {
-		t.Errorf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	testCases := []struct {
+		name       string
+		query      string
+		expected   []string
+		nextOffset *int
+	}{
+		{"first page", "?limit=2", []string{"a-service:pod-1", "a-service:pod-2"}, intPtr(2)},
+		{"middle page", "?limit=2&offset=2", []string{"b-service:pod-1", "b-service:pod-2"}, intPtr(4)},
+		{"last page", "?limit=2&offset=4", []string{"c-service:pod-1"}, nil},
+		{"offset beyond end", "?offset=10", []string{}, nil},
+		{"default limit", "", []string{"a-service:pod-1", "a-service:pod-2", "b-service:pod-1", "b-service:pod-2", "c-service:pod-1"}, nil},
 	}
 
-	var response map[string]string
-	json.NewDecoder(rec.Body).Decode(&response)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/services"+tc.query, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServicesHandler(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+			}
+
+			var response pageResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if response.Total != 5 {
+				t.Errorf("Expected total 5, got %d", response.Total)
+			}
+			if response.Count != len(tc.expected) || len(response.Services) != len(tc.expected) {
+				t.Fatalf("Expected %d services, got %d", len(tc.expected), len(response.Services))
+			}
+			for i, key := range tc.expected {
+				if response.Services[i].GetKey() != key {
+					t.Errorf("Expected service %d to be %s, got %s", i, key, response.Services[i].GetKey())
+				}
+			}
+			if (tc.nextOffset == nil) != (response.NextOffset == nil) ||
+				(tc.nextOffset != nil && *tc.nextOffset != *response.NextOffset) {
+				t.Errorf("Unexpected next_offset %v", response.NextOffset)
+			}
+		})
+	}
 
-	if response["status"] != "healthy" {
-		t.Errorf("Expected status 'healthy', got '%s'", response["status"])
+	// Invalid parameters
+	for _, query := range []string{"?limit=0", "?limit=abc", "?offset=-1"} {
+		req := httptest.NewRequest(http.MethodGet, "/services"+query, nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServicesHandler(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Query %s: expected status %d, got %d", query, http.StatusBadRequest, rec.Code)
+		}
 	}
 }
 
-func TestValidateRegistration(t *testing.T) {
-	handler, _, queue := setupTestHandler()
+func TestServicesHandlerLabelFilter(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
 	defer queue.Stop()
 
-	// Valid registration
-	validReg := &models.ServiceRegistration{
-		ServiceName: "test-service",
-		PodName:     "test-pod-1",
-		Providers: []models.ProviderInfo{
-			{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080},
-		},
-		HealthCheckURL:  "http://192.168.1.10:8080/health",
-		NotificationURL: "http://192.168.1.10:8080/notify",
-		Subscriptions:   []string{},
+	pods := []struct {
+		name   string
+		labels map[string]string
+	}{
+		{"pod-1", map[string]string{"version": "stable", "region": "eu"}},
+		{"pod-2", map[string]string{"version": "canary", "region": "eu"}},
+		{"pod-3", map[string]string{"version": "canary", "region": "us"}},
+		{"pod-4", nil},
 	}
-
-	err := handler.validateRegistration(validReg)
-	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
+	for _, pod := range pods {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     "user-service",
+			PodName:         pod.name,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+			Labels:          pod.labels,
+		})
 	}
 
-	// Test missing service name
-	invalidReg := &models.ServiceRegistration{
-		PodName: "test-pod-1",
-		Providers: []models.ProviderInfo{
-			{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080},
-		},
-		HealthCheckURL:  "http://192.168.1.10:8080/health",
-		NotificationURL: "http://192.168.1.10:8080/notify",
-	}
-	err = handler.validateRegistration(invalidReg)
-	if err == nil {
-		t.Error("Expected error for missing service name")
+	testCases := []struct {
+		query    string
+		expected int
+	}{
+		{"?label=version=canary", 2},
+		{"?label=version=canary&label=region=eu", 1},
+		{"?label=version=beta", 0},
+		{"", 4},
 	}
 
-	// Test missing pod name
-	invalidReg = &models.ServiceRegistration{
-		ServiceName: "test-service",
-		Providers: []models.ProviderInfo{
-			{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080},
-		},
-		HealthCheckURL:  "http://192.168.1.10:8080/health",
-		NotificationURL: "http://192.168.1.10:8080/notify",
-	}
-	err = handler.validateRegistration(invalidReg)
-	if err == nil {
-		t.Error("Expected error for missing pod name")
-	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/services"+tc.query, nil)
+		rec := httptest.NewRecorder()
 
-	// Test empty providers
-	invalidReg = &models.ServiceRegistration{
-		ServiceName:     "test-service",
-		PodName:         "test-pod-1",
-		Providers:       []models.ProviderInfo{},
-		HealthCheckURL:  "http://192.168.1.10:8080/health",
-		NotificationURL: "http://192.168.1.10:8080/notify",
-	}
-	err = handler.validateRegistration(invalidReg)
-	if err == nil {
-		t.Error("Expected error for empty providers")
-	}
+		handler.ServicesHandler(rec, req)
 
-	// Test invalid port
-	invalidReg = &models.ServiceRegistration{
-		ServiceName: "test-service",
-		PodName:     "test-pod-1",
-		Providers: []models.ProviderInfo{
-			{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 99999},
-		},
-		HealthCheckURL:  "http://192.168.1.10:8080/health",
-		NotificationURL: "http://192.168.1.10:8080/notify",
+		var response struct {
+			Total int `json:"total"`
+		}
+		json.NewDecoder(rec.Body).Decode(&response)
+
+		if response.Total != tc.expected {
+			t.Errorf("Query %q: expected %d services, got %d", tc.query, tc.expected, response.Total)
+		}
 	}
-	err = handler.validateRegistration(invalidReg)
-	if err == nil {
-		t.Error("Expected error for invalid port")
+
+	// Malformed selector
+	req := httptest.NewRequest(http.MethodGet, "/services?label=version", nil)
+	rec := httptest.NewRecorder()
+	handler.ServicesHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
 	}
 }
 
-func TestValidationError(t *testing.T) {
-	err := &ValidationError{Message: "test error"}
-	if err.Error() != "test error" {
-		t.Errorf("Expected 'test error', got '%s'", err.Error())
+func TestServicesHandlerStatusFilter(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	for _, pod := range []string{"pod-1", "pod-2", "pod-3"} {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     "user-service",
+			PodName:         pod,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+	}
+	reg.UpdateHealthStatus(context.Background(), "user-service:pod-1", models.StatusUnhealthy)
+	reg.UpdateHealthStatus(context.Background(), "user-service:pod-2", models.StatusHealthy)
+
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{"?status=unhealthy", "pod-1"},
+		{"?status=healthy", "pod-2"},
+		{"?status=unknown", "pod-3"},
 	}
 
-	index := 2
-	err = &ValidationError{Message: "test error", Index: &index}
-	errMsg := err.Error()
-	if errMsg == "test error" {
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/services"+tc.query, nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServicesHandler(rec, req)
+
+		var response struct {
+			Services []models.ServiceInfo `json:"services"`
+		}
+		json.NewDecoder(rec.Body).Decode(&response)
+
+		if len(response.Services) != 1 || response.Services[0].PodName != tc.expected {
+			t.Errorf("Query %q: expected only %s, got %+v", tc.query, tc.expected, response.Services)
+		}
+	}
+
+	// Unknown status
+	req := httptest.NewRequest(http.MethodGet, "/services?status=degraded", nil)
+	rec := httptest.NewRecorder()
+	handler.ServicesHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestServicesHandlerHealthyOnly(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	for _, podName := range []string{"pod-1", "pod-2", "pod-3"} {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     "user-service",
+			PodName:         podName,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+	}
+	reg.UpdateHealthStatus(context.Background(), "user-service:pod-1", models.StatusUnhealthy)
+	reg.UpdateHealthStatus(context.Background(), "user-service:pod-2", models.StatusHealthy)
+	// pod-3 has not been checked yet
+
+	podNames := func(handlerFunc http.HandlerFunc, target string) []string {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.SetPathValue("name", "user-service")
+		rec := httptest.NewRecorder()
+		handlerFunc(rec, req)
+
+		var response struct {
+			Services []models.ServiceInfo `json:"services"`
+		}
+		json.NewDecoder(rec.Body).Decode(&response)
+		names := []string{}
+		for _, service := range response.Services {
+			names = append(names, service.PodName)
+		}
+		return names
+	}
+
+	if names := podNames(handler.ServicesHandler, "/services?healthy_only=true"); !slices.Equal(names, []string{"pod-2"}) {
+		t.Errorf("Expected only the healthy pod, got %v", names)
+	}
+	if names := podNames(handler.ServiceHandler, "/services/user-service?healthy_only=true"); !slices.Equal(names, []string{"pod-2"}) {
+		t.Errorf("Expected only the healthy pod of the group, got %v", names)
+	}
+	if names := podNames(handler.ServicesHandler, "/services?healthy_only=false"); len(names) != 3 {
+		t.Errorf("Expected every pod, got %v", names)
+	}
+
+	// Unknown pods are routable when configured so
+	WithUnknownRoutable(true)(handler)
+	if names := podNames(handler.ServicesHandler, "/services?healthy_only=true"); !slices.Equal(names, []string{"pod-2", "pod-3"}) {
+		t.Errorf("Expected the healthy and unknown pods, got %v", names)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/services?healthy_only=maybe", nil)
+	rec := httptest.NewRecorder()
+	handler.ServicesHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestServicesHandlerProtocolFilter(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	register := func(serviceName, podName string, providers ...models.ProviderInfo) {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     serviceName,
+			PodName:         podName,
+			Providers:       providers,
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+	}
+	register("smf", "pod-1",
+		models.ProviderInfo{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080},
+		models.ProviderInfo{Protocol: models.ProtocolPFCP, IP: "192.168.1.10", Port: 8805},
+	)
+	register("smf", "pod-2", models.ProviderInfo{Protocol: models.ProtocolHTTP, IP: "192.168.1.11", Port: 8080})
+	register("upf", "pod-1", models.ProviderInfo{Protocol: models.ProtocolPFCP, IP: "192.168.1.20", Port: 8805})
+
+	req := httptest.NewRequest(http.MethodGet, "/services?name=smf&protocol=pfcp", nil)
+	rec := httptest.NewRecorder()
+	handler.ServicesHandler(rec, req)
+
+	var response struct {
+		Services []models.ServiceInfo `json:"services"`
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+
+	if len(response.Services) != 1 || response.Services[0].GetKey() != "smf:pod-1" {
+		t.Fatalf("Expected only smf:pod-1, got %+v", response.Services)
+	}
+	providers := response.Services[0].Providers
+	if len(providers) != 1 || providers[0].Protocol != models.ProtocolPFCP {
+		t.Errorf("Expected providers trimmed to pfcp, got %+v", providers)
+	}
+
+	// The registry itself is untouched
+	if service, _ := reg.Get(context.Background(), "smf:pod-1"); len(service.Providers) != 2 {
+		t.Errorf("Expected stored providers to be kept, got %+v", service.Providers)
+	}
+
+	// Without name every service is filtered
+	req = httptest.NewRequest(http.MethodGet, "/services?protocol=pfcp", nil)
+	rec = httptest.NewRecorder()
+	handler.ServicesHandler(rec, req)
+	json.NewDecoder(rec.Body).Decode(&response)
+	if len(response.Services) != 2 {
+		t.Errorf("Expected 2 pods with a pfcp provider, got %d", len(response.Services))
+	}
+
+	// Unknown protocol
+	req = httptest.NewRequest(http.MethodGet, "/services?protocol=sctp", nil)
+	rec = httptest.NewRecorder()
+	handler.ServicesHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestServicesHandlerNamespaceFilter(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	register := func(namespace, serviceName string) {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			Namespace:       namespace,
+			ServiceName:     serviceName,
+			PodName:         "pod-1",
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+	}
+	register("", "smf")
+	register("team-a", "smf")
+	register("team-a", "upf")
+	register("team-b", "smf")
+
+	testCases := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"smf:pod-1", "team-a/smf:pod-1", "team-a/upf:pod-1", "team-b/smf:pod-1"}},
+		{"?namespace=team-a", []string{"team-a/smf:pod-1", "team-a/upf:pod-1"}},
+		{"?namespace=team-a&name=smf", []string{"team-a/smf:pod-1"}},
+		{"?name=smf", []string{"smf:pod-1"}},
+		{"?namespace=team-c", []string{}},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/services"+tc.query, nil)
+		rec := httptest.NewRecorder()
+		handler.ServicesHandler(rec, req)
+
+		var response struct {
+			Services []models.ServiceInfo `json:"services"`
+		}
+		json.NewDecoder(rec.Body).Decode(&response)
+
+		keys := []string{}
+		for _, service := range response.Services {
+			keys = append(keys, service.GetKey())
+		}
+		if !slices.Equal(keys, tc.expected) {
+			t.Errorf("Query %q: expected %v, got %v", tc.query, tc.expected, keys)
+		}
+	}
+}
+
+func TestServicesHandlerRedactsHealthCheckHeaders(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:        "user-service",
+		PodName:            "pod-1",
+		Providers:          []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:     "http://192.168.1.10:8080/health",
+		HealthCheckHeaders: map[string]string{"Authorization": "Bearer secret"},
+		HealthChecks: []models.HealthCheck{
+			{URL: "http://192.168.1.10:8080/ready", Headers: map[string]string{"X-Token": "other-secret"}},
+		},
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/services", nil)
+	rec := httptest.NewRecorder()
+	handler.ServicesHandler(rec, req)
+
+	if strings.Contains(rec.Body.String(), "secret") {
+		t.Errorf("Expected header value to be redacted, got %s", rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "Authorization") {
+		t.Error("Expected header name to be listed")
+	}
+
+	// The stored value is untouched
+	service, _ := reg.Get(context.Background(), "user-service:pod-1")
+	if service.HealthCheckHeaders["Authorization"] != "Bearer secret" {
+		t.Errorf("Expected stored header to be kept, got %q", service.HealthCheckHeaders["Authorization"])
+	}
+	if service.HealthChecks[0].Headers["X-Token"] != "other-secret" {
+		t.Errorf("Expected stored health check header to be kept, got %q", service.HealthChecks[0].Headers["X-Token"])
+	}
+}
+
+func TestServicesHandlerIncludeDeleted(t *testing.T) {
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore, registry.WithTombstoneGracePeriod(time.Minute))
+	handler := NewHandler(reg, eventqueue.NewEventQueue(eventqueue.EventQueueConfig{BufferSize: 10}))
+
+	for _, pod := range []string{"pod-1", "pod-2"} {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     "user-service",
+			PodName:         pod,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+	}
+	reg.Unregister(context.Background(), "user-service", "pod-2")
+
+	testCases := []struct {
+		query    string
+		expected int
+	}{
+		{"", 1},
+		{"?include_deleted=false", 1},
+		{"?include_deleted=true", 2},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/services"+tc.query, nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServicesHandler(rec, req)
+
+		var response struct {
+			Total int `json:"total"`
+		}
+		json.NewDecoder(rec.Body).Decode(&response)
+
+		if response.Total != tc.expected {
+			t.Errorf("Query %q: expected %d services, got %d", tc.query, tc.expected, response.Total)
+		}
+	}
+
+	// The tombstone is reported with its status for the service group
+	req := httptest.NewRequest(http.MethodGet, "/services/user-service?include_deleted=true", nil)
+	req.SetPathValue("name", "user-service")
+	rec := httptest.NewRecorder()
+	handler.ServiceHandler(rec, req)
+
+	var response struct {
+		Services []*models.ServiceInfo `json:"services"`
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+
+	tombstones := 0
+	for _, service := range response.Services {
+		if service.Status == models.StatusTombstone && service.IsTombstone() {
+			tombstones++
+		}
+	}
+	if len(response.Services) != 2 || tombstones != 1 {
+		t.Errorf("Expected 2 pods with 1 tombstone, got %d pods with %d tombstones", len(response.Services), tombstones)
+	}
+
+	// Invalid flag
+	req = httptest.NewRequest(http.MethodGet, "/services?include_deleted=maybe", nil)
+	rec = httptest.NewRecorder()
+	handler.ServicesHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid include_deleted, got %d", rec.Code)
+	}
+}
+func TestUpdateServiceHandler(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	})
+
+	testCases := []struct {
+		name     string
+		method   string
+		pod      string
+		body     string
+		expected int
+	}{
+		{"valid update", http.MethodPatch, "pod-1", `{"providers": [{"protocol": "http", "ip": "192.168.1.10", "port": 9090}]}`, http.StatusAccepted},
+		{"labels only", http.MethodPatch, "pod-1", `{"labels": {"version": "canary"}}`, http.StatusAccepted},
+		{"unknown pod", http.MethodPatch, "pod-2", `{"labels": {"version": "canary"}}`, http.StatusNotFound},
+		{"invalid port", http.MethodPatch, "pod-1", `{"providers": [{"protocol": "http", "ip": "192.168.1.10", "port": 0}]}`, http.StatusBadRequest},
+		{"health url removed without tcp provider", http.MethodPatch, "pod-1", `{"health_check_url": ""}`, http.StatusBadRequest},
+		{"invalid body", http.MethodPatch, "pod-1", `{`, http.StatusBadRequest},
+		{"wrong method", http.MethodPost, "pod-1", `{}`, http.StatusMethodNotAllowed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/services/user-service/"+tc.pod, strings.NewReader(tc.body))
+			req.SetPathValue("name", "user-service")
+			req.SetPathValue("pod", tc.pod)
+			rec := httptest.NewRecorder()
+
+			handler.UpdateServiceHandler(rec, req)
+
+			if rec.Code != tc.expected {
+				t.Errorf("Expected status %d, got %d: %s", tc.expected, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHeartbeatHandler(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		TTLSeconds:      30,
+	})
+
+	testCases := []struct {
+		name     string
+		method   string
+		body     string
+		expected int
+	}{
+		{"registered pod", http.MethodPost, `{"service_name": "user-service", "pod_name": "pod-1"}`, http.StatusAccepted},
+		{"unknown pod", http.MethodPost, `{"service_name": "user-service", "pod_name": "pod-2"}`, http.StatusNotFound},
+		{"missing pod name", http.MethodPost, `{"service_name": "user-service"}`, http.StatusBadRequest},
+		{"invalid body", http.MethodPost, `{`, http.StatusBadRequest},
+		{"wrong method", http.MethodGet, `{}`, http.StatusMethodNotAllowed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/heartbeat", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+
+			handler.HeartbeatHandler(rec, req)
+
+			if rec.Code != tc.expected {
+				t.Errorf("Expected status %d, got %d: %s", tc.expected, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestWatchHandler(t *testing.T) {
+	hub := watch.NewHub()
+	handler := NewHandler(nil, nil, WithWatchHub(hub))
+
+	server := httptest.NewServer(http.HandlerFunc(handler.WatchHandler))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/watch?service_name=user-service")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %q", ct)
+	}
+
+	// Headers are flushed after subscribing, so the watcher is registered
+	hub.Publish(&models.ChangeRecord{Sequence: 1, EventType: models.EventTypeRegister, ServiceName: "order-service"})
+	hub.Publish(&models.ChangeRecord{Sequence: 2, EventType: models.EventTypeUpdate, ServiceName: "user-service", Status: models.StatusUnhealthy})
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+
+	if lines[0] != "id: 2" || lines[1] != "event: update" {
+		t.Errorf("Unexpected event header: %q", lines[:2])
+	}
+
+	var change models.ChangeRecord
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &change); err != nil {
+		t.Fatalf("Failed to decode event data: %v", err)
+	}
+	if change.ServiceName != "user-service" || change.Status != models.StatusUnhealthy {
+		t.Errorf("Unexpected change: %+v", change)
+	}
+
+	// Closing the hub ends the stream
+	hub.Close()
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Errorf("Expected stream to end cleanly, got %v", err)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestServicesHandlerETag(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	service, _ := reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "test-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	})
+
+	get := func(target, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServicesHandler(rec, req)
+		return rec
+	}
+
+	rec := get("/services", "")
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d %q", rec.Code, etag)
+	}
+
+	rec = get("/services", etag)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("Expected an empty 304 while unchanged, got %d with %d bytes", rec.Code, rec.Body.Len())
+	}
+
+	// Other filters are a different listing
+	if rec := get("/services?status=healthy", etag); rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("Expected 200 with another ETag for a filtered listing, got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+
+	reg.UpdateHealthStatus(context.Background(), service.GetKey(), models.StatusHealthy)
+	if rec := get("/services", etag); rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("Expected 200 with a new ETag after a change, got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+func TestServicesHandlerRevision(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	service, _ := reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "test-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServicesHandler(rec, httptest.NewRequest(http.MethodGet, "/services", nil))
+
+	var response struct {
+		Revision int64 `json:"revision"`
+		Services []struct {
+			Revision int64
+		} `json:"services"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Revision != reg.Revision() {
+		t.Errorf("Expected registry revision %d, got %d", reg.Revision(), response.Revision)
+	}
+	if len(response.Services) != 1 || response.Services[0].Revision != service.Revision {
+		t.Errorf("Expected the pod at revision %d, got %+v", service.Revision, response.Services)
+	}
+}
+
+func TestServiceHistoryHandler(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	service, _ := reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "test-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	})
+	reg.UpdateHealthStatus(context.Background(), service.GetKey(), models.StatusHealthy)
+	reg.UpdateHealthStatus(context.Background(), service.GetKey(), models.StatusUnhealthy)
+
+	get := func(pod string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/services/test-service/"+pod+"/history", nil)
+		req.SetPathValue("name", "test-service")
+		req.SetPathValue("pod", pod)
+		rec := httptest.NewRecorder()
+		handler.ServiceHistoryHandler(rec, req)
+		return rec
+	}
+
+	rec := get("pod-1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	var response struct {
+		Count       int                       `json:"count"`
+		Transitions []models.StatusTransition `json:"transitions"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Count != 2 || response.Transitions[0].NewStatus != models.StatusUnhealthy || response.Transitions[1].OldStatus != models.StatusUnknown {
+		t.Errorf("Expected unknown -> healthy -> unhealthy newest first, got %+v", response.Transitions)
+	}
+
+	if rec := get("pod-2"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown pod, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestServicesHandlerMethodNotAllowed(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+
+	req := httptest.NewRequest(http.MethodPost, "/services", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServicesHandler(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
+
+func TestServiceHandler(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	for _, name := range []string{"user-service", "user-service", "order-service"} {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     name,
+			PodName:         "pod-" + string(rune('0'+len(reg.GetAllServices(context.Background())))),
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/services/user-service", nil)
+	req.SetPathValue("name", "user-service")
+	rec := httptest.NewRecorder()
+
+	handler.ServiceHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var response map[string]interface{}
+	json.NewDecoder(rec.Body).Decode(&response)
+
+	count := int(response["count"].(float64))
+	if count != 2 {
+		t.Errorf("Expected count 2, got %d", count)
+	}
+
+	// Unknown group
+	req = httptest.NewRequest(http.MethodGet, "/services/unknown", nil)
+	req.SetPathValue("name", "unknown")
+	rec = httptest.NewRecorder()
+
+	handler.ServiceHandler(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestSubscriptionsHandler(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "order-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		Subscriptions:   []string{"user-service", "payment-service"},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/subscriptions?group=user-service", nil)
+	rec := httptest.NewRecorder()
+
+	handler.SubscriptionsHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var groupResponse struct {
+		Count       int              `json:"count"`
+		Subscribers []subscriberInfo `json:"subscribers"`
+	}
+	json.NewDecoder(rec.Body).Decode(&groupResponse)
+
+	if groupResponse.Count != 1 || groupResponse.Subscribers[0].SubscriberKey != "order-service:pod-1" {
+		t.Errorf("Unexpected subscribers: %+v", groupResponse.Subscribers)
+	}
+	if groupResponse.Subscribers[0].NotificationURL != "http://192.168.1.10:8080/notify" {
+		t.Errorf("Unexpected notification URL: %s", groupResponse.Subscribers[0].NotificationURL)
+	}
+	if !groupResponse.Subscribers[0].LastNotifiedAt.IsZero() {
+		t.Errorf("Expected no last notification time before any delivery, got %v", groupResponse.Subscribers[0].LastNotifiedAt)
+	}
+
+	// Full subscription map
+	req = httptest.NewRequest(http.MethodGet, "/subscriptions", nil)
+	rec = httptest.NewRecorder()
+
+	handler.SubscriptionsHandler(rec, req)
+
+	var allResponse struct {
+		Count         int                         `json:"count"`
+		Subscriptions map[string][]subscriberInfo `json:"subscriptions"`
+	}
+	json.NewDecoder(rec.Body).Decode(&allResponse)
+
+	if allResponse.Count != 2 {
+		t.Errorf("Expected 2 groups, got %d", allResponse.Count)
+	}
+	if len(allResponse.Subscriptions["payment-service"]) != 1 {
+		t.Errorf("Expected 1 subscriber for payment-service, got %d", len(allResponse.Subscriptions["payment-service"]))
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	rec := httptest.NewRecorder()
+
+	handler.HealthHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var response map[string]string
+	json.NewDecoder(rec.Body).Decode(&response)
+
+	if response["status"] != "healthy" {
+		t.Errorf("Expected status 'healthy', got '%s'", response["status"])
+	}
+}
+
+func TestValidateRegistration(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+
+	// Valid registration
+	validReg := &models.ServiceRegistration{
+		ServiceName: "test-service",
+		PodName:     "test-pod-1",
+		Providers: []models.ProviderInfo{
+			{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080},
+		},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		Subscriptions:   []string{},
+	}
+
+	err := handler.validateRegistration(validReg)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// Test missing service name
+	invalidReg := &models.ServiceRegistration{
+		PodName: "test-pod-1",
+		Providers: []models.ProviderInfo{
+			{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080},
+		},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	}
+	err = handler.validateRegistration(invalidReg)
+	if err == nil {
+		t.Error("Expected error for missing service name")
+	}
+
+	// Test missing pod name
+	invalidReg = &models.ServiceRegistration{
+		ServiceName: "test-service",
+		Providers: []models.ProviderInfo{
+			{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080},
+		},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	}
+	err = handler.validateRegistration(invalidReg)
+	if err == nil {
+		t.Error("Expected error for missing pod name")
+	}
+
+	// Test empty providers
+	invalidReg = &models.ServiceRegistration{
+		ServiceName:     "test-service",
+		PodName:         "test-pod-1",
+		Providers:       []models.ProviderInfo{},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	}
+	err = handler.validateRegistration(invalidReg)
+	if err == nil {
+		t.Error("Expected error for empty providers")
+	}
+
+	// Test invalid port
+	invalidReg = &models.ServiceRegistration{
+		ServiceName: "test-service",
+		PodName:     "test-pod-1",
+		Providers: []models.ProviderInfo{
+			{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 99999},
+		},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	}
+	err = handler.validateRegistration(invalidReg)
+	if err == nil {
+		t.Error("Expected error for invalid port")
+	}
+
+	// Health check URL is optional when a TCP provider can be dialed instead
+	tcpReg := &models.ServiceRegistration{
+		ServiceName: "test-service",
+		PodName:     "test-pod-1",
+		Providers: []models.ProviderInfo{
+			{Protocol: models.ProtocolTCP, IP: "192.168.1.10", Port: 9000},
+		},
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	}
+	if err := handler.validateRegistration(tcpReg); err != nil {
+		t.Errorf("Expected no error for TCP provider without health check URL, got %v", err)
+	}
+
+	// UDP providers are probed instead
+	tcpReg.Providers[0].Protocol = models.ProtocolUDP
+	if err := handler.validateRegistration(tcpReg); err != nil {
+		t.Errorf("Expected no error for UDP provider without health check URL, got %v", err)
+	}
+
+	tcpReg.Providers[0].Protocol = models.ProtocolHTTP
+	if err := handler.validateRegistration(tcpReg); err == nil {
+		t.Error("Expected error for missing health check URL without a checkable provider")
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := &ValidationError{Message: "test error"}
+	if err.Error() != "test error" {
+		t.Errorf("Expected 'test error', got '%s'", err.Error())
+	}
+
+	index := 2
+	err = &ValidationError{Message: "test error", Index: &index}
+	errMsg := err.Error()
+	if errMsg == "test error" {
 		t.Error("Expected error message to include index")
 	}
 }
+
+func TestSubscriberNotificationsHandler(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	notif := notifier.NewNotifier(time.Second)
+	handler.notifier = notif
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscriber, _ := reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "subscriber",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  server.URL,
+		NotificationURL: server.URL,
+		Subscriptions:   []string{"target-service"},
+	})
+
+	notif.NotifySubscribers(context.Background(), []*models.ServiceInfo{subscriber}, &models.NotificationPayload{
+		EventID:     42,
+		ServiceName: "target-service",
+		EventType:   models.EventTypeRegister,
+		Timestamp:   time.Now(),
+	})
+	time.Sleep(100 * time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodGet, "/subscribers/subscriber:pod-1/notifications", nil)
+	req.SetPathValue("key", "subscriber:pod-1")
+	rec := httptest.NewRecorder()
+
+	handler.SubscriberNotificationsHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var response struct {
+		Count         int                         `json:"count"`
+		Notifications []models.NotificationRecord `json:"notifications"`
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+
+	if response.Count != 1 {
+		t.Fatalf("Expected 1 notification, got %d", response.Count)
+	}
+	if response.Notifications[0].EventID != 42 || !response.Notifications[0].Success {
+		t.Errorf("Unexpected notification record: %+v", response.Notifications[0])
+	}
+}
+
+func TestSubscriberNotificationsHandlerNotFound(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+	handler.notifier = notifier.NewNotifier(time.Second)
+
+	req := httptest.NewRequest(http.MethodGet, "/subscribers/unknown:pod/notifications", nil)
+	req.SetPathValue("key", "unknown:pod")
+	rec := httptest.NewRecorder()
+
+	handler.SubscriberNotificationsHandler(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestRequireAuth(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+	handler.authToken = "secret"
+
+	protected := handler.RequireAuth(handler.HealthHandler)
+
+	testCases := []struct {
+		header   string
+		expected int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"secret", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		rec := httptest.NewRecorder()
+
+		protected(rec, req)
+
+		if rec.Code != tc.expected {
+			t.Errorf("Authorization %q: expected status %d, got %d", tc.header, tc.expected, rec.Code)
+		}
+	}
+}
+
+func TestLogLevelHandler(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+
+	previous := logger.Level()
+	t.Cleanup(func() { logger.SetLevel(previous) })
+
+	req := httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"DEBUG"}`))
+	w := httptest.NewRecorder()
+	handler.LogLevelHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var response map[string]string
+	json.NewDecoder(w.Body).Decode(&response)
+	if response["level"] != "debug" || logger.Level() != "debug" {
+		t.Errorf("Expected level debug, got %q (effective %q)", response["level"], logger.Level())
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"verbose"}`))
+	w = httptest.NewRecorder()
+	handler.LogLevelHandler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown level, got %d", w.Code)
+	}
+	if logger.Level() != "debug" {
+		t.Errorf("Expected level to stay debug, got %q", logger.Level())
+	}
+}
+
+func TestCallerOf(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/register", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+
+	if caller := callerOf(req); caller.Principal != "anonymous" || caller.RemoteAddr != "10.0.0.1:5000" {
+		t.Errorf("Unexpected caller without token: %+v", caller)
+	}
+
+	req.Header.Set("Authorization", "Bearer secret")
+	caller := callerOf(req)
+	if !strings.HasPrefix(caller.Principal, "token:") || strings.Contains(caller.Principal, "secret") {
+		t.Errorf("Expected token fingerprint, got %q", caller.Principal)
+	}
+	if callerOf(req) != caller {
+		t.Error("Expected the same token to give the same principal")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := RequestID(func(w http.ResponseWriter, r *http.Request) {
+		seen = events.GetRequestID(r.Context())
+	})
+
+	testCases := []struct {
+		header string
+		kept   bool
+	}{
+		{"req-123", true},
+		{"", false},
+		{"has space", false},
+		{strings.Repeat("x", maxRequestIDLength+1), false},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodPost, "/register", nil)
+		if tc.header != "" {
+			req.Header.Set(RequestIDHeader, tc.header)
+		}
+		rec := httptest.NewRecorder()
+
+		handler(rec, req)
+
+		if seen == "" || rec.Header().Get(RequestIDHeader) != seen {
+			t.Errorf("Header %q: expected the request ID %q in the context to be echoed, got %q", tc.header, seen, rec.Header().Get(RequestIDHeader))
+		}
+		if (seen == tc.header) != tc.kept {
+			t.Errorf("Header %q: expected kept=%v, got request ID %q", tc.header, tc.kept, seen)
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	var eventSpan trace.SpanContext
+	mux := http.NewServeMux()
+	mux.HandleFunc("/services/{name}", func(w http.ResponseWriter, r *http.Request) {
+		eventSpan = trace.SpanContextFromContext(fromRequest(context.Background(), r))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	handler := Trace(mux.ServeHTTP)
+
+	// Continues the caller's trace
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/services/user-service", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	handler(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /services/{name}" {
+		t.Errorf("Expected span named after the route, got %q", span.Name())
+	}
+	if span.SpanContext().TraceID().String() != traceID {
+		t.Errorf("Expected the caller's trace %s, got %s", traceID, span.SpanContext().TraceID())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("Expected error status for a 503, got %v", span.Status().Code)
+	}
+	if eventSpan.SpanID() != span.SpanContext().SpanID() {
+		t.Error("Expected queued events to carry the request span")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+	WithRateLimit(RateLimitPolicy{Rate: 1, Burst: 2, KeyHeader: "X-API-Key"})(handler)
+
+	limited := handler.RateLimit(handler.HealthHandler)
+
+	call := func(remoteAddr, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.RemoteAddr = remoteAddr
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		rec := httptest.NewRecorder()
+		limited(rec, req)
+		return rec
+	}
+
+	// Burst of 2, then limited
+	for i := range 2 {
+		if rec := call("10.0.0.1:1234", ""); rec.Code != http.StatusOK {
+			t.Fatalf("Request %d: expected status 200, got %d", i+1, rec.Code)
+		}
+	}
+	rec := call("10.0.0.1:5678", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected Retry-After 1, got %q", rec.Header().Get("Retry-After"))
+	}
+
+	// Other clients have their own buckets
+	if rec := call("10.0.0.2:1234", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected other IP to be allowed, got %d", rec.Code)
+	}
+	if rec := call("10.0.0.1:1234", "key-1"); rec.Code != http.StatusOK {
+		t.Errorf("Expected API key client to be allowed, got %d", rec.Code)
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	limiter := newRateLimiter(RateLimitPolicy{Rate: 10})
+	now := time.Now()
+
+	// Burst defaults to the rate
+	for i := range 10 {
+		if ok, _ := limiter.allow("client", now); !ok {
+			t.Fatalf("Request %d: expected to be allowed", i+1)
+		}
+	}
+	ok, retryAfter := limiter.allow("client", now)
+	if ok || retryAfter != 100*time.Millisecond {
+		t.Fatalf("Expected limit with 100ms retry, got allowed=%v retry=%v", ok, retryAfter)
+	}
+
+	if ok, _ := limiter.allow("client", now.Add(100*time.Millisecond)); !ok {
+		t.Error("Expected a token after 100ms")
+	}
+
+	// Full buckets are swept
+	limiter.allow("other", now.Add(2*rateLimitSweepInterval))
+	if len(limiter.buckets) != 1 {
+		t.Errorf("Expected idle bucket to be swept, got %d buckets", len(limiter.buckets))
+	}
+}
+
+func TestRateLimiterConcurrent(t *testing.T) {
+	limiter := newRateLimiter(RateLimitPolicy{Rate: 1, Burst: 50})
+	now := time.Now()
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Go(func() {
+			if ok, _ := limiter.allow("client", now); ok {
+				allowed.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+
+	if allowed.Load() != 50 {
+		t.Errorf("Expected exactly 50 allowed requests, got %d", allowed.Load())
+	}
+}
+
+func TestChangesHandler(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+	dualStore := storage.NewDualStore(nil)
+	handler.dualStore = dualStore
+
+	ctx := context.Background()
+	for _, pod := range []string{"pod-1", "pod-2", "pod-3"} {
+		change := &models.ChangeRecord{
+			Timestamp:   time.Now(),
+			EventType:   models.EventTypeRegister,
+			ServiceKey:  "user-service:" + pod,
+			ServiceName: "user-service",
+			PodName:     pod,
+			Status:      models.StatusUnknown,
+		}
+		if _, err := dualStore.AppendChange(ctx, change); err != nil {
+			t.Fatalf("Failed to append change: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/changes?since=1&limit=1", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ChangesHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var response struct {
+		Count        int                    `json:"count"`
+		LastSequence int64                  `json:"last_sequence"`
+		Changes      []*models.ChangeRecord `json:"changes"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.Count != 1 || len(response.Changes) != 1 {
+		t.Fatalf("Expected 1 change, got %d", len(response.Changes))
+	}
+	if response.Changes[0].PodName != "pod-2" {
+		t.Errorf("Expected pod-2, got %s", response.Changes[0].PodName)
+	}
+	if response.LastSequence != response.Changes[0].Sequence {
+		t.Errorf("Expected last_sequence %d, got %d", response.Changes[0].Sequence, response.LastSequence)
+	}
+
+	// Invalid cursor
+	req = httptest.NewRequest(http.MethodGet, "/changes?since=abc", nil)
+	rec = httptest.NewRecorder()
+	handler.ChangesHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestQuotasHandler(t *testing.T) {
+	reg := registry.NewRegistry(storage.NewDualStore(nil), registry.WithNamespaceQuotas(map[string]int{"team-a": 5, "team-b": 2}, 0))
+	handler := NewHandler(reg, eventqueue.NewEventQueue(eventqueue.EventQueueConfig{BufferSize: 1}))
+	WithAuthToken("secret")(handler)
+
+	for _, pod := range []struct{ namespace, name string }{{"", "pod-1"}, {"team-a", "pod-1"}, {"team-a", "pod-2"}} {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			Namespace:       pod.namespace,
+			ServiceName:     "gateway",
+			PodName:         pod.name,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+	}
+
+	quotas := handler.RequireAuth(handler.QuotasHandler)
+	rec := httptest.NewRecorder()
+	quotas(rec, httptest.NewRequest(http.MethodGet, "/quotas", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d without a token, got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/quotas", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	quotas(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var response struct {
+		Count      int                     `json:"count"`
+		Namespaces []models.NamespaceUsage `json:"namespaces"`
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+	expected := []models.NamespaceUsage{
+		{Namespace: "", Pods: 1, Quota: 0},
+		{Namespace: "team-a", Pods: 2, Quota: 5},
+		{Namespace: "team-b", Pods: 0, Quota: 2},
+	}
+	if response.Count != len(expected) || !slices.Equal(response.Namespaces, expected) {
+		t.Errorf("Expected usage %v, got %v", expected, response.Namespaces)
+	}
+}
+
+func TestBreakersHandler(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	notif := notifier.NewNotifier(time.Second, notifier.WithCircuitBreaker(notifier.BreakerPolicy{FailureThreshold: 1}))
+	handler.notifier = notif
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	subscriber, _ := reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "subscriber",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  server.URL,
+		NotificationURL: server.URL,
+		Subscriptions:   []string{"target-service"},
+	})
+	notif.NotifySubscribers(context.Background(), []*models.ServiceInfo{subscriber}, &models.NotificationPayload{
+		ServiceName: "target-service",
+		EventType:   models.EventTypeRegister,
+	})
+
+	var response struct {
+		Open     int                      `json:"open"`
+		Breakers []notifier.BreakerStatus `json:"breakers"`
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for response.Open == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+
+		req := httptest.NewRequest(http.MethodGet, "/breakers", nil)
+		rec := httptest.NewRecorder()
+		handler.BreakersHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+		json.NewDecoder(rec.Body).Decode(&response)
+	}
+
+	if response.Open != 1 || len(response.Breakers) != 1 || response.Breakers[0].NotificationURL != server.URL {
+		t.Fatalf("Expected one open breaker for %s, got %+v", server.URL, response)
+	}
+	if response.Breakers[0].NextProbeAt.IsZero() {
+		t.Errorf("Expected the open breaker to report its next probe, got %+v", response.Breakers[0])
+	}
+
+	// Resetting closes the circuit
+	req := httptest.NewRequest(http.MethodPost, "/breakers/reset?url="+url.QueryEscape(server.URL), nil)
+	rec := httptest.NewRecorder()
+	handler.ResetBreakerHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if statuses := notif.GetBreakerStatuses(); len(statuses) != 0 {
+		t.Errorf("Expected no breakers after the reset, got %+v", statuses)
+	}
+
+	testCases := []struct {
+		method   string
+		target   string
+		expected int
+	}{
+		{http.MethodPost, "/breakers/reset?url=" + url.QueryEscape(server.URL), http.StatusNotFound}, // Already closed
+		{http.MethodPost, "/breakers/reset", http.StatusBadRequest},
+		{http.MethodGet, "/breakers/reset?url=" + url.QueryEscape(server.URL), http.StatusMethodNotAllowed},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, tc.target, nil)
+		rec := httptest.NewRecorder()
+		handler.ResetBreakerHandler(rec, req)
+		if rec.Code != tc.expected {
+			t.Errorf("%s %s: expected status %d, got %d", tc.method, tc.target, tc.expected, rec.Code)
+		}
+	}
+}
+
+func TestStatsHandler(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+
+	req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+	rec := httptest.NewRecorder()
+	handler.StatsHandler(rec, req)
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status %d without queue stats, got %d", http.StatusNotImplemented, rec.Code)
+	}
+
+	handler.queueStats = func() models.QueueStats {
+		return models.QueueStats{Size: 5, Capacity: 1000, Enqueued: 105, Processed: 100, EventsPerSecond: 1.5, LagMillis: 20}
+	}
+
+	rec = httptest.NewRecorder()
+	handler.StatsHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var response struct {
+		EventQueue models.QueueStats `json:"event_queue"`
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+	if response.EventQueue.Size != 5 || response.EventQueue.Processed != 100 || response.EventQueue.EventsPerSecond != 1.5 {
+		t.Errorf("Unexpected stats: %+v", response.EventQueue)
+	}
+}
+
+func TestHealthCheckHandler(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	var caller atomic.Value
+	queue.RegisterHandler(string(events.EventHealthCheck), eventqueue.EventHandlerFunc(func(ctx context.Context, event eventqueue.IEvent) error {
+		caller.Store(events.GetCaller(ctx))
+		key := events.GetEventData(ctx).(*events.HealthCheckEvent).ServiceKey
+		reg.UpdateHealthStatus(ctx, key, models.StatusHealthy)
+		return nil
+	}))
+
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	})
+
+	testCases := []struct {
+		name   string
+		method string
+		target string
+		status int
+	}{
+		{"wrong method", http.MethodGet, "/healthcheck?key=user-service:pod-1", http.StatusMethodNotAllowed},
+		{"missing key", http.MethodPost, "/healthcheck", http.StatusBadRequest},
+		{"unknown key", http.MethodPost, "/healthcheck?key=user-service:pod-2", http.StatusNotFound},
+		{"registered key", http.MethodPost, "/healthcheck?key=user-service:pod-1", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.HealthCheckHandler(rec, httptest.NewRequest(tc.method, tc.target, nil))
+			if rec.Code != tc.status {
+				t.Fatalf("Expected status %d, got %d", tc.status, rec.Code)
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Key             string               `json:"key"`
+				Status          models.ServiceStatus `json:"status"`
+				LastHealthCheck time.Time            `json:"last_health_check"`
+			}
+			json.NewDecoder(rec.Body).Decode(&response)
+			if response.Key != "user-service:pod-1" || response.Status != models.StatusHealthy || response.LastHealthCheck.IsZero() {
+				t.Errorf("Expected the status after the check, got %+v", response)
+			}
+			// The event carries the request's caller like every other API event
+			if got, _ := caller.Load().(models.Caller); got.RemoteAddr == "" {
+				t.Errorf("Expected the caller of the request on the event, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestSubscriptionsHandlerDelete(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+	WithAuthToken("secret")(handler)
+
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "order-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		Subscriptions:   []string{"user-service"},
+	})
+
+	// Queries stay open, evictions go through auth
+	rec := httptest.NewRecorder()
+	handler.SubscriptionsHandler(rec, httptest.NewRequest(http.MethodGet, "/subscriptions", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d for the query, got %d", http.StatusOK, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.SubscriptionsHandler(rec, httptest.NewRequest(http.MethodDelete, "/subscriptions?subscriber=order-service:pod-1", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for an eviction without token, got %d", http.StatusUnauthorized, rec.Code)
+	}
+	if subscribers := reg.GetSubscribers(context.Background(), "user-service"); len(subscribers) != 1 {
+		t.Errorf("Expected the subscription to stay, got %v", subscribers)
+	}
+}
+
+func TestEvictSubscriberHandler(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	queue.RegisterHandler(string(events.EventEvictSubscriber), eventqueue.EventHandlerFunc(func(ctx context.Context, event eventqueue.IEvent) error {
+		_, err := reg.RemoveAllSubscriptions(ctx, events.GetEventData(ctx).(*events.EvictSubscriberEvent).SubscriberKey)
+		return err
+	}))
+
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "order-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		Subscriptions:   []string{"user-service", "payment-service"},
+	})
+
+	testCases := []struct {
+		name   string
+		method string
+		target string
+		status int
+	}{
+		{"wrong method", http.MethodPost, "/subscriptions?subscriber=order-service:pod-1", http.StatusMethodNotAllowed},
+		{"missing subscriber", http.MethodDelete, "/subscriptions", http.StatusBadRequest},
+		{"unknown subscriber", http.MethodDelete, "/subscriptions?subscriber=order-service:pod-2", http.StatusNotFound},
+		{"registered subscriber", http.MethodDelete, "/subscriptions?subscriber=order-service:pod-1", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.EvictSubscriberHandler(rec, httptest.NewRequest(tc.method, tc.target, nil))
+			if rec.Code != tc.status {
+				t.Fatalf("Expected status %d, got %d", tc.status, rec.Code)
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Subscriber string   `json:"subscriber"`
+				Count      int      `json:"count"`
+				Groups     []string `json:"groups"`
+			}
+			json.NewDecoder(rec.Body).Decode(&response)
+			if response.Subscriber != "order-service:pod-1" || response.Count != 2 {
+				t.Errorf("Expected to be detached from 2 groups, got %+v", response)
+			}
+		})
+	}
+
+	// Detached from its groups, but still registered
+	if subscribers := reg.GetSubscribers(context.Background(), "user-service"); len(subscribers) != 0 {
+		t.Errorf("Expected no subscribers left, got %v", subscribers)
+	}
+	if _, exists := reg.Get(context.Background(), "order-service:pod-1"); !exists {
+		t.Error("Subscriber should still be registered")
+	}
+}
+
+func TestDrainHandler(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+
+	rec := httptest.NewRecorder()
+	handler.DrainHandler(rec, httptest.NewRequest(http.MethodPost, "/drain", strings.NewReader(`{"enabled": true}`)))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status %d without drain mode support, got %d", http.StatusNotImplemented, rec.Code)
+	}
+
+	var draining bool
+	WithDrainMode(func(enabled bool) { draining = enabled }, func() bool { return draining })(handler)
+
+	testCases := []struct {
+		name     string
+		method   string
+		body     string
+		status   int
+		draining bool
+	}{
+		{"wrong method", http.MethodGet, "", http.StatusMethodNotAllowed, false},
+		{"missing enabled", http.MethodPost, `{}`, http.StatusBadRequest, false},
+		{"enable", http.MethodPost, `{"enabled": true}`, http.StatusOK, true},
+		{"disable", http.MethodPost, `{"enabled": false}`, http.StatusOK, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.DrainHandler(rec, httptest.NewRequest(tc.method, "/drain", strings.NewReader(tc.body)))
+			if rec.Code != tc.status {
+				t.Fatalf("Expected status %d, got %d", tc.status, rec.Code)
+			}
+			if draining != tc.draining {
+				t.Errorf("Expected drain mode %v, got %v", tc.draining, draining)
+			}
+		})
+	}
+}
+
+func TestReconcileHandler(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+
+	reconciled := make(chan struct{}, 1)
+	queue.RegisterHandler(string(events.EventReconcile), eventqueue.EventHandlerFunc(func(ctx context.Context, event eventqueue.IEvent) error {
+		reconciled <- struct{}{}
+		return nil
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/reconcile", nil)
+	rec := httptest.NewRecorder()
+	handler.ReconcileHandler(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d for GET, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/reconcile", nil)
+	rec = httptest.NewRecorder()
+	handler.ReconcileHandler(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+
+	select {
+	case <-reconciled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected reconcile event to be processed")
+	}
+}
+
+func TestDeadLettersHandler(t *testing.T) {
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+
+	store := notifier.NewMemoryDeadLetterStore(10)
+	handler.notifier = notifier.NewNotifier(time.Second, notifier.WithDeadLetterStore(store))
+
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	defer server.Close()
+
+	store.Add(models.DeadLetter{
+		NotificationURL: server.URL,
+		Payload:         &models.NotificationPayload{ServiceName: "user-service", EventType: models.EventTypeRegister},
+		Error:           "Service Unavailable",
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/notifications/deadletters", nil)
+	rec := httptest.NewRecorder()
+	handler.DeadLettersHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var response struct {
+		Count       int                 `json:"count"`
+		DeadLetters []models.DeadLetter `json:"dead_letters"`
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+	if response.Count != 1 || response.DeadLetters[0].Payload.ServiceName != "user-service" {
+		t.Fatalf("Unexpected response: %+v", response)
+	}
+
+	testCases := []struct {
+		id       string
+		expected int
+	}{
+		{"abc", http.StatusBadRequest},
+		{"99", http.StatusNotFound},
+		{strconv.FormatUint(response.DeadLetters[0].ID, 10), http.StatusAccepted},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodPost, "/notifications/deadletters/"+tc.id+"/replay", nil)
+		req.SetPathValue("id", tc.id)
+		rec := httptest.NewRecorder()
+		handler.ReplayDeadLetterHandler(rec, req)
+		if rec.Code != tc.expected {
+			t.Errorf("Replay %s: expected status %d, got %d", tc.id, tc.expected, rec.Code)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for received.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if received.Load() != 1 {
+		t.Errorf("Expected replayed notification to be delivered, got %d", received.Load())
+	}
+}
+
+func TestLivenessHandler(t *testing.T) {
+	handler := NewHandler(nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+	rec := httptest.NewRecorder()
+	handler.LivenessHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+}
+
+// failingPingStore is a database whose Ping always fails
+type failingPingStore struct {
+	storage.DatabaseStore
+}
+
+func (failingPingStore) Ping(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestReadinessHandler(t *testing.T) {
+	var running atomic.Bool
+	testCases := []struct {
+		name     string
+		running  bool
+		db       storage.DatabaseStore
+		expected int
+		reason   string
+	}{
+		{"ready without database", true, nil, http.StatusOK, ""},
+		{"queue stopped", false, nil, http.StatusServiceUnavailable, "event queue is not running"},
+		{"database unreachable", true, failingPingStore{}, http.StatusServiceUnavailable, "database unreachable: connection refused"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			running.Store(tc.running)
+			handler := NewHandler(nil, nil,
+				WithDualStore(storage.NewDualStore(tc.db)),
+				WithQueueStatus(running.Load),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rec := httptest.NewRecorder()
+			handler.ReadinessHandler(rec, req)
+
+			if rec.Code != tc.expected {
+				t.Errorf("Expected status %d, got %d", tc.expected, rec.Code)
+			}
+
+			var response map[string]string
+			json.NewDecoder(rec.Body).Decode(&response)
+			if response["reason"] != tc.reason {
+				t.Errorf("Expected reason %q, got %q", tc.reason, response["reason"])
+			}
+		})
+	}
+}
+
+// listingStore is a database holding a fixed set of services and no subscriptions
+type listingStore struct {
+	storage.DatabaseStore
+	services []*models.ServiceInfo
+}
+
+func (s listingStore) GetAllServices(ctx context.Context) ([]*models.ServiceInfo, error) {
+	return s.services, nil
+}
+
+func (s listingStore) GetAllSubscriptions(ctx context.Context) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func TestDatabaseDiffHandler(t *testing.T) {
+	// Without a database there is nothing to compare
+	handler, _, queue := setupTestHandler()
+	defer queue.Stop()
+	rec := httptest.NewRecorder()
+	handler.DatabaseDiffHandler(rec, httptest.NewRequest(http.MethodGet, "/database/diff", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status %d without database, got %d", http.StatusNotImplemented, rec.Code)
+	}
+
+	db := listingStore{services: []*models.ServiceInfo{{ServiceName: "user-service", PodName: "pod-1", Status: models.StatusHealthy}}}
+	handler = NewHandler(nil, nil, WithDualStore(storage.NewDualStore(db)))
+
+	rec = httptest.NewRecorder()
+	handler.DatabaseDiffHandler(rec, httptest.NewRequest(http.MethodGet, "/database/diff", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var response struct {
+		InSync bool             `json:"in_sync"`
+		Diff   models.StoreDiff `json:"diff"`
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+	if response.InSync {
+		t.Error("Expected cache and database to differ")
+	}
+	if len(response.Diff.OnlyInDatabase) != 1 || response.Diff.OnlyInDatabase[0] != "user-service:pod-1" {
+		t.Errorf("Expected pod-1 only in database, got %v", response.Diff.OnlyInDatabase)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.DatabaseDiffHandler(rec, httptest.NewRequest(http.MethodPost, "/database/diff", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
+
+func TestHealthHandlerChecks(t *testing.T) {
+	handler, reg, queue := setupTestHandler()
+	defer queue.Stop()
+
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	})
+
+	var lastReconcile time.Time
+	handler.dualStore = storage.NewDualStore(failingPingStore{})
+	handler.lastReconcile = func() time.Time { return lastReconcile }
+	handler.databaseConnection = func() models.DatabaseConnectionStats {
+		return models.DatabaseConnectionStats{State: models.ConnectionDisconnected, ConsecutiveFailures: 4}
+	}
+
+	type check struct {
+		Status     string                 `json:"status"`
+		Error      string                 `json:"error"`
+		Connection models.ConnectionState `json:"connection"`
+		Failures   int                    `json:"consecutive_failures"`
+		Services   int                    `json:"services"`
+		LastRun    time.Time              `json:"last_run"`
+	}
+	var response struct {
+		Status string           `json:"status"`
+		Checks map[string]check `json:"checks"`
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	rec := httptest.NewRecorder()
+	handler.HealthHandler(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+
+	if response.Status != "unhealthy" {
+		t.Errorf("Expected overall status 'unhealthy', got '%s'", response.Status)
+	}
+	if db := response.Checks["database"]; db.Status != "unhealthy" || db.Error != "connection refused" {
+		t.Errorf("Expected unhealthy database check, got %+v", db)
+	}
+	if db := response.Checks["database"]; db.Connection != models.ConnectionDisconnected || db.Failures != 4 {
+		t.Errorf("Expected the monitored connection state in the database check, got %+v", db)
+	}
+	if q := response.Checks["event_queue"]; q.Status != "healthy" {
+		t.Errorf("Expected healthy event queue check, got %+v", q)
+	}
+	if r := response.Checks["registry"]; r.Services != 1 {
+		t.Errorf("Expected 1 registered service, got %d", r.Services)
+	}
+	if rc := response.Checks["reconcile"]; rc.Status != "pending" {
+		t.Errorf("Expected pending reconcile before the first run, got %+v", rc)
+	}
+
+	// Database recovers and a reconcile has run
+	handler.dualStore = storage.NewDualStore(nil)
+	lastReconcile = time.Now()
+
+	rec = httptest.NewRecorder()
+	handler.HealthHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+	if response.Checks["database"].Status != "disabled" || response.Checks["reconcile"].LastRun.IsZero() {
+		t.Errorf("Unexpected checks: %+v", response.Checks)
+	}
+}
+
+func TestExportImportHandlers(t *testing.T) {
+	ctx := context.Background()
+	source, sourceReg, sourceQueue := setupTestHandler()
+	defer sourceQueue.Stop()
+
+	for _, podName := range []string{"test-pod-1", "test-pod-2"} {
+		_, err := sourceReg.Register(ctx, &models.ServiceRegistration{
+			ServiceName:     "test-service",
+			PodName:         podName,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+		if err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	source.ExportHandler(rec, httptest.NewRequest(http.MethodGet, "/export", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	exported := rec.Body.Bytes()
+
+	// The target worker only restores the registry
+	reg := registry.NewRegistry(storage.NewDualStore(nil))
+	eventQueue := eventqueue.NewEventQueue(eventqueue.EventQueueConfig{BufferSize: 10})
+	eventQueue.RegisterHandler(string(events.EventImport), eventqueue.EventHandlerFunc(func(ctx context.Context, event eventqueue.IEvent) error {
+		_, err := reg.Restore(ctx, events.GetEventData(ctx).(*events.ImportEvent).Snapshot.Services)
+		return err
+	}))
+	eventQueue.Start(ctx)
+	defer eventQueue.Stop()
+	target := NewHandler(reg, eventQueue)
+
+	importSnapshot := func(body []byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		target.ImportHandler(rec, httptest.NewRequest(http.MethodPost, "/import", bytes.NewReader(body)))
+		return rec
+	}
+
+	// Invalid snapshots are rejected before anything is applied
+	if rec := importSnapshot([]byte(`{"version":99,"services":[]}`)); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for unsupported version, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	rec = importSnapshot(exported)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if pods := reg.GetByServiceName(ctx, "test-service"); len(pods) != 2 {
+		t.Errorf("Expected 2 imported pods, got %d", len(pods))
+	}
+
+	rec = httptest.NewRecorder()
+	target.ImportHandler(rec, httptest.NewRequest(http.MethodGet, "/import", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// rateLimitSweepInterval is how often buckets of clients that have gone quiet are dropped
+const rateLimitSweepInterval = time.Minute
+
+// RateLimitPolicy controls the per-client token bucket applied by RateLimit.
+// Each client may send Burst requests at once and then Rate requests per second.
+// The zero value disables rate limiting.
+type RateLimitPolicy struct {
+	Rate      float64 // Requests per second per client (<= 0 disables)
+	Burst     int     // Bucket size (<= 0 = Rate rounded up, at least 1)
+	KeyHeader string  // Header identifying clients, e.g. X-API-Key (empty or missing = client IP)
+}
+
+// WithRateLimit limits requests to endpoints wrapped with RateLimit per client
+func WithRateLimit(policy RateLimitPolicy) HandlerOption {
+	return func(h *Handler) {
+		if policy.Rate > 0 {
+			h.limiter = newRateLimiter(policy)
+		}
+	}
+}
+
+// RateLimit wraps a handler so each client can only call it at the configured rate.
+// Requests over the limit get 429 with a Retry-After header. When no limit is configured
+// the handler is returned unchanged.
+func (h *Handler) RateLimit(next http.HandlerFunc) http.HandlerFunc {
+	if h.limiter == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := h.limiter.clientKey(r)
+		if ok, retryAfter := h.limiter.allow(client, time.Now()); !ok {
+			logger.Warn("API: Rate limit exceeded",
+				zap.String("path", r.URL.Path),
+				zap.String("client", client),
+				zap.Duration("retry_after", retryAfter),
+			)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// rateLimiter keeps a token bucket per client.
+// It is used from concurrent HTTP handlers, so access is guarded by a mutex.
+type rateLimiter struct {
+	mu        sync.Mutex
+	policy    RateLimitPolicy
+	buckets   map[string]*tokenBucket // Key: client key
+	lastSweep time.Time
+}
+
+// tokenBucket holds the tokens a client had at its last request
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(policy RateLimitPolicy) *rateLimiter {
+	if policy.Burst <= 0 {
+		policy.Burst = max(1, int(math.Ceil(policy.Rate)))
+	}
+	return &rateLimiter{
+		policy:    policy,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// clientKey identifies the client of a request by the configured header, or by IP
+func (l *rateLimiter) clientKey(r *http.Request) string {
+	if l.policy.KeyHeader != "" {
+		if key := r.Header.Get(l.policy.KeyHeader); key != "" {
+			return l.policy.KeyHeader + ":" + key
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// allow takes a token from the client's bucket. If the bucket is empty it returns false
+// and how long until the next token is available.
+func (l *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	burst := float64(l.policy.Burst)
+	bucket, exists := l.buckets[client]
+	if !exists {
+		bucket = &tokenBucket{tokens: burst, last: now}
+		l.buckets[client] = bucket
+	}
+
+	// Refill for the time since the client's last request
+	if elapsed := now.Sub(bucket.last); elapsed > 0 {
+		bucket.tokens = min(burst, bucket.tokens+elapsed.Seconds()*l.policy.Rate)
+		bucket.last = now
+	}
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.policy.Rate * float64(time.Second))
+		return false, wait
+	}
+
+	bucket.tokens--
+	return true, 0
+}
+
+// sweep drops buckets that have refilled completely, they behave like new ones.
+// Called with mu held.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	burst := float64(l.policy.Burst)
+	for client, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.policy.Rate >= burst {
+			delete(l.buckets, client)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+
+	"github.com/chronnie/governance/events"
+)
+
+// RequestIDHeader carries the ID correlating a request with the events, logs and
+// notifications it causes
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength caps the length of request IDs accepted from clients
+const maxRequestIDLength = 128
+
+// RequestID wraps a handler so every request has a request ID: the client's X-Request-ID if
+// it sent a usable one, a random one otherwise. The ID is echoed in the response header and
+// stored in the request context, from where handlers pass it on to the events they queue.
+func RequestID(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = rand.Text()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next(w, r.WithContext(events.WithRequestID(r.Context(), id)))
+	}
+}
+
+// validRequestID reports whether a client-supplied request ID is safe to log and forward:
+// non-empty, not too long and printable ASCII only
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// fromRequest tags an event context with the caller, request ID and trace span of r
+func fromRequest(ctx context.Context, r *http.Request) context.Context {
+	ctx = events.WithCaller(ctx, callerOf(r))
+	return withSpan(events.WithRequestID(ctx, events.GetRequestID(r.Context())), r)
+}
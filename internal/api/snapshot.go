@@ -0,0 +1,102 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ExportHandler handles GET /export requests.
+// Returns a versioned snapshot of the whole registry, tombstones included, that POST /import
+// accepts. Health check headers are not redacted, so the route requires auth.
+func (h *Handler) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	snapshot, err := h.registry.Snapshot(r.Context())
+	if err != nil {
+		logger.Error("API: Failed to export snapshot",
+			zap.Error(err),
+		)
+		http.Error(w, "Failed to export snapshot", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("API: Snapshot exported",
+		zap.Int("services", len(snapshot.Services)),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// ImportHandler handles POST /import requests.
+// Replaces the registry with the snapshot in the body. The whole snapshot is validated
+// before anything changes; an invalid one is rejected with 400 and the registry is untouched.
+// Responds once the import has been applied and subscribers notified.
+func (h *Handler) ImportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := models.ParseSnapshot(data)
+	if err != nil {
+		logger.Warn("API: Invalid snapshot",
+			zap.Error(err),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.enqueueImport(r, snapshot)
+	if err != nil {
+		logger.Error("API: Failed to enqueue import event",
+			zap.Error(err),
+			events.RequestIDField(r.Context()),
+		)
+		writeEnqueueError(w, err, "Failed to import snapshot")
+		return
+	}
+
+	if err := waitForEvent(r.Context(), event); err != nil {
+		logger.Error("API: Snapshot import failed",
+			zap.Error(err),
+			events.RequestIDField(r.Context()),
+		)
+		http.Error(w, "Failed to import snapshot: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Warn("API: Registry replaced from snapshot",
+		zap.Int("services", len(snapshot.Services)),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "imported",
+		"message":  "Snapshot imported successfully",
+		"services": len(snapshot.Services),
+	})
+}
+
+// enqueueImport creates and enqueues an import event for a validated snapshot sent with request r
+func (h *Handler) enqueueImport(r *http.Request, snapshot *models.Snapshot) (eventqueue.IEvent, error) {
+	ctx := fromRequest(events.NewImportContext(snapshot), r)
+	event := eventqueue.NewEvent(string(events.EventImport), ctx)
+	return event, h.eventQueue.Enqueue(event)
+}
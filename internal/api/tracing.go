@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans of the API handlers
+const tracerName = "github.com/chronnie/governance/internal/api"
+
+// Trace wraps a handler so each request runs in a server span of the global OpenTelemetry
+// tracer provider, continuing the trace of the caller's traceparent header if it sent one.
+// Handlers pass the span on to the events they queue. Without a tracer provider installed
+// (otel.SetTracerProvider) spans are no-ops.
+func Trace(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagation.TraceContext{}.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := otel.Tracer(tracerName).Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		r = r.WithContext(ctx)
+		next(recorder, r)
+
+		// The mux sets the matched pattern, e.g. "/services/{name}", on the way in
+		if r.Pattern != "" {
+			_, route, _ := strings.Cut(r.Pattern, "/") // Drop a method prefix
+			span.SetName(r.Method + " /" + route)
+			span.SetAttributes(attribute.String("http.route", "/"+route))
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	}
+}
+
+// withSpan makes the span of r's context the parent of an event's spans
+func withSpan(ctx context.Context, r *http.Request) context.Context {
+	return trace.ContextWithSpanContext(ctx, trace.SpanContextFromContext(r.Context()))
+}
+
+// statusRecorder remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush keeps streaming responses such as /watch working through the recorder
+func (w *statusRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
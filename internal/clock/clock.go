@@ -0,0 +1,50 @@
+// Package clock abstracts the passage of time, so time-dependent behavior such as TTL
+// expiry, notification dwell time and scheduling can be tested without sleeping.
+package clock
+
+import "time"
+
+// Clock tells the time and creates tickers and timers
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Ticker delivers ticks on C at intervals, like time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Timer calls a function once its duration has passed, like a time.Timer from time.AfterFunc
+type Timer interface {
+	// Stop prevents the call and reports whether it did, false if it already happened or
+	// the timer was stopped before
+	Stop() bool
+}
+
+// Real is the system clock
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
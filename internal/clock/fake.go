@@ -0,0 +1,138 @@
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock for tests that only moves when Advance is called.
+// Tickers drop ticks their receiver isn't ready for, like time.Ticker; timer functions
+// run synchronously within Advance, so their effects are visible once it returns.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+	timers  []*fakeTimer
+}
+
+// NewFake creates a fake clock set to now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the fake time
+func (c *Fake) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker creates a ticker firing every d of fake time
+func (c *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTicker{clock: c, interval: d, next: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// AfterFunc calls f once d of fake time has passed
+func (c *Fake) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d, firing the tickers and timers that came due
+// in order
+func (c *Fake) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		at, fire := c.nextDue(end)
+		if fire == nil {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+		c.now = at
+		c.mu.Unlock()
+
+		fire()
+	}
+}
+
+// nextDue finds the earliest ticker or timer due no later than end, removes or reschedules
+// it and returns what to run. Must be called with mu held.
+func (c *Fake) nextDue(end time.Time) (time.Time, func()) {
+	var ticker *fakeTicker
+	for _, t := range c.tickers {
+		if !t.next.After(end) && (ticker == nil || t.next.Before(ticker.next)) {
+			ticker = t
+		}
+	}
+	var timer *fakeTimer
+	for _, t := range c.timers {
+		if !t.at.After(end) && (timer == nil || t.at.Before(timer.at)) {
+			timer = t
+		}
+	}
+
+	switch {
+	case timer != nil && (ticker == nil || !ticker.next.Before(timer.at)):
+		c.timers = slices.DeleteFunc(c.timers, func(t *fakeTimer) bool { return t == timer })
+		return timer.at, timer.f
+	case ticker != nil:
+		at := ticker.next
+		ticker.next = at.Add(ticker.interval)
+		return at, func() {
+			select {
+			case ticker.c <- at:
+			default:
+			}
+		}
+	}
+	return time.Time{}, nil
+}
+
+type fakeTicker struct {
+	clock    *Fake
+	interval time.Duration
+	next     time.Time // Guarded by clock.mu
+	c        chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.tickers = slices.DeleteFunc(t.clock.tickers, func(other *fakeTicker) bool { return other == t })
+}
+
+type fakeTimer struct {
+	clock *Fake
+	at    time.Time
+	f     func()
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	pending := slices.Contains(t.clock.timers, t)
+	t.clock.timers = slices.DeleteFunc(t.clock.timers, func(other *fakeTimer) bool { return other == t })
+	return pending
+}
@@ -0,0 +1,62 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeTicker(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+	ticker := c.NewTicker(time.Second)
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case <-ticker.C():
+		t.Fatal("Expected no tick before the interval passed")
+	default:
+	}
+
+	// Ticks the receiver isn't ready for are dropped
+	c.Advance(3 * time.Second)
+	if tick := <-ticker.C(); !tick.Equal(start.Add(time.Second)) {
+		t.Errorf("Expected the first tick at 1s, got %v", tick.Sub(start))
+	}
+	select {
+	case <-ticker.C():
+		t.Fatal("Expected a single buffered tick")
+	default:
+	}
+	if got := c.Now().Sub(start); got != 3500*time.Millisecond {
+		t.Errorf("Expected the clock at 3.5s, got %v", got)
+	}
+
+	ticker.Stop()
+	c.Advance(time.Second)
+	select {
+	case <-ticker.C():
+		t.Error("Expected no tick after Stop")
+	default:
+	}
+}
+
+func TestFakeAfterFunc(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+
+	var fired []time.Duration
+	c.AfterFunc(2*time.Second, func() { fired = append(fired, c.Now().Sub(start)) })
+	c.AfterFunc(time.Second, func() { fired = append(fired, c.Now().Sub(start)) })
+	stopped := c.AfterFunc(time.Second, func() { t.Error("Expected a stopped timer not to fire") })
+	if !stopped.Stop() {
+		t.Error("Expected Stop to report a pending timer")
+	}
+
+	c.Advance(5 * time.Second)
+	if len(fired) != 2 || fired[0] != time.Second || fired[1] != 2*time.Second {
+		t.Errorf("Expected timers to fire in order at their due time, got %v", fired)
+	}
+	if stopped.Stop() {
+		t.Error("Expected Stop to report false the second time")
+	}
+}
@@ -0,0 +1,165 @@
+package notifier
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultBreakerCooldown is how long a circuit stays open when BreakerPolicy.Cooldown is not set
+const DefaultBreakerCooldown = 30 * time.Second
+
+// errCircuitOpen is recorded for notifications skipped because the URL's circuit is open
+var errCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerPolicy controls the per-URL circuit breaker.
+// After FailureThreshold consecutive failed deliveries to a notification URL the circuit
+// opens and notifications to it are skipped for Cooldown. Then a single probe is let
+// through (half-open): success closes the circuit, failure opens it for another Cooldown.
+// Only unreachable subscribers count as failures (no response or 5xx after all retries);
+// a 4xx response means the subscriber is up. The zero value disables the breaker.
+type BreakerPolicy struct {
+	FailureThreshold int           // Consecutive failures before opening (<= 0 disables)
+	Cooldown         time.Duration // How long the circuit stays open before probing
+}
+
+// BreakerState is the state of a notification URL's circuit
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// BreakerStatus describes the circuit of one notification URL
+type BreakerStatus struct {
+	NotificationURL     string       `json:"notification_url"`
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	OpenedAt            time.Time    `json:"opened_at,omitzero"`
+	NextProbeAt         time.Time    `json:"next_probe_at,omitzero"` // When an open circuit lets the next probe through
+}
+
+// circuitBreakers tracks a breaker per notification URL.
+// It is used from notification goroutines, so access is guarded by a mutex.
+type circuitBreakers struct {
+	mu       sync.Mutex
+	policy   BreakerPolicy
+	breakers map[string]*breaker // Key: notification URL, only URLs with failures
+}
+
+// breaker is the circuit state of a single URL
+type breaker struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreakers(policy BreakerPolicy) *circuitBreakers {
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = DefaultBreakerCooldown
+	}
+	return &circuitBreakers{
+		policy:   policy,
+		breakers: make(map[string]*breaker),
+	}
+}
+
+// enabled reports whether the breaker is configured
+func (c *circuitBreakers) enabled() bool {
+	return c.policy.FailureThreshold > 0
+}
+
+// allow reports whether a notification to url may be sent.
+// Once the cooldown has passed, exactly one caller is let through as the half-open probe;
+// it must report the outcome with done.
+func (c *circuitBreakers) allow(url string) bool {
+	if !c.enabled() {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, exists := c.breakers[url]
+	if !exists {
+		return true
+	}
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < c.policy.Cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		return true
+	case BreakerHalfOpen:
+		return false // A probe is already in flight
+	default:
+		return true
+	}
+}
+
+// done records the outcome of a delivery to url
+func (c *circuitBreakers) done(url string, reachable bool) {
+	if !c.enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if reachable {
+		delete(c.breakers, url)
+		return
+	}
+
+	b, exists := c.breakers[url]
+	if !exists {
+		b = &breaker{state: BreakerClosed}
+		c.breakers[url] = b
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= c.policy.FailureThreshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// statuses returns the circuits of all URLs with recent failures, sorted by URL
+func (c *circuitBreakers) statuses() []BreakerStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make([]BreakerStatus, 0, len(c.breakers))
+	for url, b := range c.breakers {
+		status := BreakerStatus{
+			NotificationURL:     url,
+			State:               b.state,
+			ConsecutiveFailures: b.failures,
+			OpenedAt:            b.openedAt,
+		}
+		if b.state == BreakerOpen {
+			status.NextProbeAt = b.openedAt.Add(c.policy.Cooldown)
+		}
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NotificationURL < result[j].NotificationURL
+	})
+	return result
+}
+
+// reset closes the circuit of url and forgets its failures.
+// Returns false if url had no recent failures.
+func (c *circuitBreakers) reset(url string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, exists := c.breakers[url]
+	delete(c.breakers, url)
+	return exists
+}
@@ -0,0 +1,108 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chronnie/governance/models"
+)
+
+// DefaultDeadLetterSize is the number of dead letters kept by MemoryDeadLetterStore by default
+const DefaultDeadLetterSize = 1000
+
+// DeadLetterStore receives notifications whose delivery finally failed (after retries, or
+// skipped by an open circuit breaker). Implementations must be safe for concurrent use.
+type DeadLetterStore interface {
+	// Add stores a dead letter, assigning its ID
+	Add(letter models.DeadLetter)
+	// List returns the stored dead letters, newest first
+	List() []models.DeadLetter
+	// Remove deletes a dead letter and returns it, false if it doesn't exist
+	Remove(id uint64) (models.DeadLetter, bool)
+}
+
+// WithDeadLetterStore replaces the default in-memory dead-letter store
+func WithDeadLetterStore(store DeadLetterStore) NotifierOption {
+	return func(n *Notifier) {
+		if store != nil {
+			n.deadLetters = store
+		}
+	}
+}
+
+// MemoryDeadLetterStore keeps the most recent dead letters in a bounded ring buffer.
+// When full, the oldest dead letter is dropped.
+type MemoryDeadLetterStore struct {
+	mu      sync.Mutex
+	size    int
+	letters []models.DeadLetter // Oldest first
+	nextID  uint64
+}
+
+var _ DeadLetterStore = (*MemoryDeadLetterStore)(nil)
+
+// NewMemoryDeadLetterStore creates a store keeping up to size dead letters.
+// Values <= 0 use DefaultDeadLetterSize.
+func NewMemoryDeadLetterStore(size int) *MemoryDeadLetterStore {
+	if size <= 0 {
+		size = DefaultDeadLetterSize
+	}
+	return &MemoryDeadLetterStore{size: size}
+}
+
+// Add stores a dead letter, dropping the oldest one when the store is full
+func (s *MemoryDeadLetterStore) Add(letter models.DeadLetter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	letter.ID = s.nextID
+
+	if len(s.letters) >= s.size {
+		s.letters = s.letters[1:]
+	}
+	s.letters = append(s.letters, letter)
+}
+
+// List returns the stored dead letters, newest first
+func (s *MemoryDeadLetterStore) List() []models.DeadLetter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]models.DeadLetter, 0, len(s.letters))
+	for i := len(s.letters) - 1; i >= 0; i-- {
+		result = append(result, s.letters[i])
+	}
+	return result
+}
+
+// Remove deletes a dead letter and returns it
+func (s *MemoryDeadLetterStore) Remove(id uint64) (models.DeadLetter, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, letter := range s.letters {
+		if letter.ID == id {
+			s.letters = append(s.letters[:i], s.letters[i+1:]...)
+			return letter, true
+		}
+	}
+	return models.DeadLetter{}, false
+}
+
+// GetDeadLetters returns notifications that could not be delivered, newest first
+func (n *Notifier) GetDeadLetters() []models.DeadLetter {
+	return n.deadLetters.List()
+}
+
+// ReplayDeadLetter removes a dead letter and delivers its notification again in the
+// background. If delivery fails again it is dead-lettered under a new ID.
+// Returns false if no dead letter has the ID.
+func (n *Notifier) ReplayDeadLetter(id uint64) bool {
+	letter, exists := n.deadLetters.Remove(id)
+	if !exists {
+		return false
+	}
+	n.deliver(context.Background(), letter.NotificationURL, letter.Payload, letter.SubscriberKey)
+	return true
+}
@@ -0,0 +1,79 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/chronnie/governance/models"
+)
+
+// MaxHealthCheckBodySize caps how much of a health check response body is read for matching.
+// Larger bodies are truncated: substring matches only see the beginning and JSON matches fail.
+const MaxHealthCheckBodySize = 64 * 1024
+
+// matchBody reads the response body (up to MaxHealthCheckBodySize) and checks it against match.
+// Returns nil if the body satisfies every condition, otherwise why it doesn't.
+func matchBody(body io.Reader, match *models.HealthCheckMatch) error {
+	data, err := io.ReadAll(io.LimitReader(body, MaxHealthCheckBodySize))
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if match.BodyContains != "" && !strings.Contains(string(data), match.BodyContains) {
+		return fmt.Errorf("body does not contain %q", match.BodyContains)
+	}
+
+	if match.JSONPath != "" {
+		var document any
+		if err := json.Unmarshal(data, &document); err != nil {
+			return fmt.Errorf("body is not valid JSON: %w", err)
+		}
+
+		value, ok := lookupJSONPath(document, match.JSONPath)
+		if !ok {
+			return fmt.Errorf("body has no %s", match.JSONPath)
+		}
+		if actual := jsonValueString(value); actual != match.JSONValue {
+			return fmt.Errorf("%s is %q, expected %q", match.JSONPath, actual, match.JSONValue)
+		}
+	}
+
+	return nil
+}
+
+// lookupJSONPath follows a dot-separated path of object keys and array indexes
+func lookupJSONPath(document any, path string) (any, bool) {
+	current := document
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, exists := node[segment]
+			if !exists {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// jsonValueString formats a decoded JSON value for comparison: strings as is, anything
+// else (numbers, booleans, null, objects) in its JSON encoding
+func jsonValueString(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(value) // Decoded JSON values always encode
+	return string(data)
+}
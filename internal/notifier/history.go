@@ -0,0 +1,95 @@
+package notifier
+
+import (
+	"sync"
+	"time"
+
+	"github.com/chronnie/governance/models"
+)
+
+// DefaultHistorySize is the number of notification records kept per subscriber
+const DefaultHistorySize = 20
+
+// notificationHistory keeps a bounded ring buffer of recent notifications per subscriber,
+// and when each subscriber last got one delivered.
+// It is written from notification goroutines, so access is guarded by a mutex.
+type notificationHistory struct {
+	mu            sync.Mutex
+	size          int
+	records       map[string]*recordRing // Key: subscriber key
+	lastDelivered map[string]time.Time   // Key: subscriber key, kept even once the ring has no successes left
+}
+
+// recordRing is a fixed-size ring buffer of notification records
+type recordRing struct {
+	records []models.NotificationRecord
+	next    int
+	full    bool
+}
+
+func newNotificationHistory(size int) *notificationHistory {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &notificationHistory{
+		size:          size,
+		records:       make(map[string]*recordRing),
+		lastDelivered: make(map[string]time.Time),
+	}
+}
+
+// add appends a record to the subscriber's ring, overwriting the oldest entry when full
+func (h *notificationHistory) add(subscriberKey string, record models.NotificationRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ring, exists := h.records[subscriberKey]
+	if !exists {
+		ring = &recordRing{records: make([]models.NotificationRecord, h.size)}
+		h.records[subscriberKey] = ring
+	}
+
+	ring.records[ring.next] = record
+	ring.next = (ring.next + 1) % h.size
+	if ring.next == 0 {
+		ring.full = true
+	}
+}
+
+// get returns the subscriber's records, newest first
+func (h *notificationHistory) get(subscriberKey string) []models.NotificationRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ring, exists := h.records[subscriberKey]
+	if !exists {
+		return []models.NotificationRecord{}
+	}
+
+	count := ring.next
+	if ring.full {
+		count = h.size
+	}
+
+	result := make([]models.NotificationRecord, 0, count)
+	for i := 1; i <= count; i++ {
+		result = append(result, ring.records[(ring.next-i+h.size)%h.size])
+	}
+	return result
+}
+
+// delivered records that a notification was delivered to the subscriber at the given time
+func (h *notificationHistory) delivered(subscriberKey string, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastDelivered[subscriberKey] = at
+}
+
+// lastDeliveredAt returns when the subscriber last got a notification delivered, zero if never
+func (h *notificationHistory) lastDeliveredAt(subscriberKey string) time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.lastDelivered[subscriberKey]
+}
@@ -0,0 +1,106 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// DefaultNATSSubjectPrefix is used when a nats:// notification URL has no path
+const DefaultNATSSubjectPrefix = "governance"
+
+// NATSTransport publishes notifications to NATS instead of POSTing them over HTTP.
+// For a notification URL nats://[user:pass@]host:port/<prefix>, the JSON payload is
+// published on subject "<prefix>.<service_name>", e.g. governance.user-service.
+// One connection is kept per server and reused by all subscribers on it.
+type NATSTransport struct {
+	mu    sync.Mutex
+	conns map[string]*nats.Conn // Key: server URL without path
+	opts  []nats.Option
+}
+
+// NewNATSTransport creates a NATS transport. opts are applied to every connection.
+func NewNATSTransport(opts ...nats.Option) *NATSTransport {
+	return &NATSTransport{
+		conns: make(map[string]*nats.Conn),
+		opts:  opts,
+	}
+}
+
+// Send publishes the notification and waits for the server to acknowledge it.
+// NATS has no status codes, so the returned code is always 0.
+func (t *NATSTransport) Send(ctx context.Context, target *url.URL, payload *models.NotificationPayload, body []byte) (int, error) {
+	conn, err := t.conn(target)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := conn.Publish(natsSubject(target, payload.Namespace, payload.ServiceName), body); err != nil {
+		return 0, err
+	}
+
+	// Publish only buffers; flushing confirms the server received it
+	if err := conn.FlushWithContext(ctx); err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+// Close closes all connections
+func (t *NATSTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for server, conn := range t.conns {
+		conn.Close()
+		delete(t.conns, server)
+	}
+	return nil
+}
+
+// conn returns the connection to the target's server, connecting if needed
+func (t *NATSTransport) conn(target *url.URL) (*nats.Conn, error) {
+	if target.Host == "" {
+		return nil, errors.New("nats notification URL must include a host")
+	}
+	server := (&url.URL{Scheme: target.Scheme, User: target.User, Host: target.Host}).String()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if conn, exists := t.conns[server]; exists && !conn.IsClosed() {
+		return conn, nil
+	}
+
+	conn, err := nats.Connect(server, t.opts...)
+	if err != nil {
+		return nil, err
+	}
+	t.conns[server] = conn
+
+	logger.Info("Notifier: Connected to NATS",
+		zap.String("server", target.Host),
+	)
+	return conn, nil
+}
+
+// natsSubject derives the subject for a service from the URL path (the subject prefix).
+// Services outside the default namespace get their namespace as an extra token, so
+// equally named services of different namespaces publish to different subjects.
+func natsSubject(target *url.URL, namespace, serviceName string) string {
+	prefix := strings.ReplaceAll(strings.Trim(target.Path, "/"), "/", ".")
+	if prefix == "" {
+		prefix = DefaultNATSSubjectPrefix
+	}
+	if namespace != "" {
+		prefix += "." + namespace
+	}
+	return prefix + "." + serviceName
+}
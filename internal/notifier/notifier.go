@@ -1,36 +1,190 @@
 package notifier
 
 import (
-	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
+	neturl "net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/chronnie/governance/models"
 	"github.com/chronnie/governance/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
 // Notifier handles sending notifications to subscribers
 type Notifier struct {
-	httpClient *http.Client
-	timeout    time.Duration
+	httpClient    *http.Client
+	timeout       time.Duration
+	historySize   int
+	history       *notificationHistory
+	retryPolicy   RetryPolicy
+	sem           chan struct{} // Bounds concurrent deliveries
+	breakers      *circuitBreakers
+	breakerPolicy BreakerPolicy
+	deliveryHook  func(models.DeliveryResult) // Optional, called with every final delivery result
+	transports    map[string]Transport        // Key: notification URL scheme
+	deadLetters   DeadLetterStore             // Receives notifications that could not be delivered
+}
+
+// tracerName identifies the spans of notification deliveries and health checks
+const tracerName = "github.com/chronnie/governance/internal/notifier"
+
+// DefaultMaxConcurrency is the number of notifications delivered concurrently by default
+const DefaultMaxConcurrency = 100
+
+// NotifierOption configures optional Notifier behavior
+type NotifierOption func(*Notifier)
+
+// WithHistorySize sets how many recent notifications are kept per subscriber.
+// Values <= 0 use DefaultHistorySize.
+func WithHistorySize(size int) NotifierOption {
+	return func(n *Notifier) {
+		n.historySize = size
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to deliver notifications, e.g. to use a
+// custom transport. The notifier still bounds each request with its own timeout.
+// A nil client keeps the default timeout-configured client.
+func WithHTTPClient(client *http.Client) NotifierOption {
+	return func(n *Notifier) {
+		if client != nil {
+			n.httpClient = client
+		}
+	}
+}
+
+// WithRetryPolicy enables retries for failed notifications, see RetryPolicy
+func WithRetryPolicy(policy RetryPolicy) NotifierOption {
+	return func(n *Notifier) {
+		n.retryPolicy = policy
+	}
+}
+
+// WithCircuitBreaker skips notifications to URLs that keep failing, see BreakerPolicy
+func WithCircuitBreaker(policy BreakerPolicy) NotifierOption {
+	return func(n *Notifier) {
+		n.breakerPolicy = policy
+	}
+}
+
+// WithTransport delivers notifications whose URL has the given scheme (e.g. "nats") over
+// the transport. HTTP transports for "http" and "https" are registered by default.
+func WithTransport(scheme string, transport Transport) NotifierOption {
+	return func(n *Notifier) {
+		n.transports[strings.ToLower(scheme)] = transport
+	}
+}
+
+// WithDeliveryHook registers a function called with the final result of every notification
+// delivery (success, failure after retries, or skipped by the circuit breaker).
+// The hook runs on the delivery goroutine while it holds a concurrency slot, so it should
+// return quickly; hand slow work off to another goroutine.
+func WithDeliveryHook(hook func(models.DeliveryResult)) NotifierOption {
+	return func(n *Notifier) {
+		n.deliveryHook = hook
+	}
+}
+
+// WithMaxConcurrency limits how many notifications are delivered at the same time.
+// Further notifications wait for a free slot before a goroutine is started for them, which
+// holds up the caller. Values <= 0 use DefaultMaxConcurrency.
+func WithMaxConcurrency(limit int) NotifierOption {
+	return func(n *Notifier) {
+		if limit > 0 {
+			n.sem = make(chan struct{}, limit)
+		}
+	}
 }
 
 // NewNotifier creates a new notifier with given timeout
-func NewNotifier(timeout time.Duration) *Notifier {
-	return &Notifier{
+func NewNotifier(timeout time.Duration, opts ...NotifierOption) *Notifier {
+	n := &Notifier{
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
-		timeout: timeout,
+		timeout:    timeout,
+		sem:        make(chan struct{}, DefaultMaxConcurrency),
+		transports: make(map[string]Transport),
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	httpTransport := NewHTTPTransport(n.httpClient)
+	for _, scheme := range []string{"http", "https"} {
+		if _, exists := n.transports[scheme]; !exists {
+			n.transports[scheme] = httpTransport
+		}
+	}
+	if n.deadLetters == nil {
+		n.deadLetters = NewMemoryDeadLetterStore(DefaultDeadLetterSize)
 	}
+	n.history = newNotificationHistory(n.historySize)
+	n.breakers = newCircuitBreakers(n.breakerPolicy)
+
+	return n
+}
+
+// GetNotificationHistory returns recent notifications sent to a subscriber, newest first
+func (n *Notifier) GetNotificationHistory(subscriberKey string) []models.NotificationRecord {
+	return n.history.get(subscriberKey)
 }
 
-// NotifySubscribers sends notification to all subscribers
-// Does not retry on failure as per requirements
-func (n *Notifier) NotifySubscribers(subscribers []*models.ServiceInfo, payload *models.NotificationPayload) {
+// LastNotifiedAt returns when a notification was last delivered to a subscriber, or the zero
+// time if none has been since the manager started
+func (n *Notifier) LastNotifiedAt(subscriberKey string) time.Time {
+	return n.history.lastDeliveredAt(subscriberKey)
+}
+
+// Close releases resources held by transports, such as NATS connections
+func (n *Notifier) Close() error {
+	var errs []error
+	for _, transport := range n.transports {
+		if closer, ok := transport.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetBreakerStatuses returns the circuit breaker state of every notification URL with
+// recent failures. URLs without failures are closed and not listed.
+func (n *Notifier) GetBreakerStatuses() []BreakerStatus {
+	return n.breakers.statuses()
+}
+
+// ResetBreaker force-closes the circuit of a notification URL, e.g. once its subscriber is
+// fixed, so notifications are sent again right away. Returns false if the URL had no recent
+// failures.
+func (n *Notifier) ResetBreaker(url string) bool {
+	return n.breakers.reset(url)
+}
+
+// NotifySubscribers sends notification to all subscribers in the background. It returns once
+// every delivery has started, waiting for free slots when WithMaxConcurrency is reached.
+// Failed deliveries are only retried when a RetryPolicy is configured.
+// Each delivery is traced in a span that is a child of ctx's span; ctx being cancelled
+// doesn't stop deliveries already started.
+func (n *Notifier) NotifySubscribers(ctx context.Context, subscribers []*models.ServiceInfo, payload *models.NotificationPayload) {
 	logger.Debug("Notifier: NotifySubscribers called",
 		zap.Int("subscriber_count", len(subscribers)),
 		zap.String("event_type", string(payload.EventType)),
@@ -38,29 +192,54 @@ func (n *Notifier) NotifySubscribers(subscribers []*models.ServiceInfo, payload
 	)
 
 	for _, subscriber := range subscribers {
+		url := subscriber.NotificationURLFor(payload.EventType)
 		logger.Debug("Notifier: Sending notification to subscriber",
 			zap.String("subscriber_key", subscriber.GetKey()),
-			zap.String("notification_url", subscriber.NotificationURL),
+			zap.String("notification_url", url),
 			zap.String("event_type", string(payload.EventType)),
 		)
-		go n.sendNotification(subscriber.NotificationURL, payload, subscriber.GetKey())
+		n.deliver(ctx, url, payload, subscriber.GetKey())
 	}
 }
 
-// NotifySubscriber sends notification to a single subscriber
-func (n *Notifier) NotifySubscriber(notificationURL string, payload *models.NotificationPayload) {
+// NotifySubscriber sends notification to a single subscriber, see NotifySubscribers
+func (n *Notifier) NotifySubscriber(ctx context.Context, notificationURL string, payload *models.NotificationPayload) {
 	logger.Debug("Notifier: NotifySubscriber called",
 		zap.String("notification_url", notificationURL),
 		zap.String("event_type", string(payload.EventType)),
 	)
-	go n.sendNotification(notificationURL, payload, "")
+	n.deliver(ctx, notificationURL, payload, "")
 }
 
-// sendNotification sends HTTP POST notification to a URL
-func (n *Notifier) sendNotification(url string, payload *models.NotificationPayload, subscriberKey string) {
-	ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+// deliver waits for a free concurrency slot, then sends the notification in a goroutine that
+// releases the slot when done. Waiting before starting the goroutine bounds the number of
+// delivery goroutines too; while all slots are busy the caller (e.g. the event worker) blocks.
+func (n *Notifier) deliver(ctx context.Context, url string, payload *models.NotificationPayload, subscriberKey string) {
+	n.sem <- struct{}{}
+	go func() {
+		defer func() { <-n.sem }()
+		n.sendNotification(ctx, url, payload, subscriberKey)
+	}()
+}
+
+// sendNotification sends HTTP POST notification to a URL, retrying per the notifier's RetryPolicy
+func (n *Notifier) sendNotification(parent context.Context, url string, payload *models.NotificationPayload, subscriberKey string) {
+	// All attempts (and the delays between them) share one deadline so a wedged
+	// subscriber can't hold the goroutine forever. The delivery outlives the event that
+	// caused it, so only the parent's values (its span) are kept.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), n.timeout*time.Duration(n.retryPolicy.attempts()))
 	defer cancel()
 
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "notify "+string(payload.EventType),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("service.name", payload.ServiceName),
+			attribute.String("subscriber.key", subscriberKey),
+			attribute.Int64("event.id", int64(payload.EventID)),
+		),
+	)
+	defer span.End()
+
 	logFields := []zap.Field{
 		zap.String("notification_url", url),
 		zap.String("event_type", string(payload.EventType)),
@@ -69,163 +248,760 @@ func (n *Notifier) sendNotification(url string, payload *models.NotificationPayl
 	if subscriberKey != "" {
 		logFields = append(logFields, zap.String("subscriber_key", subscriberKey))
 	}
+	if payload.RequestID != "" {
+		logFields = append(logFields, zap.String("request_id", payload.RequestID))
+	}
 
 	logger.Debug("Notifier: Sending HTTP POST notification", logFields...)
 
+	// Record the delivery result in the subscriber's history, report it to the hook and
+	// dead-letter the notification if it could not be delivered
+	record := models.NotificationRecord{
+		EventID:         payload.EventID,
+		Timestamp:       time.Now(),
+		ServiceName:     payload.ServiceName,
+		EventType:       payload.EventType,
+		NotificationURL: url,
+	}
+	defer func() {
+		span.SetAttributes(attribute.Int("notification.attempts", record.Attempts))
+		if record.StatusCode != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", record.StatusCode))
+		}
+		if !record.Success {
+			span.SetStatus(otelcodes.Error, record.Error)
+		}
+
+		if subscriberKey != "" {
+			n.history.add(subscriberKey, record)
+		}
+		if n.deliveryHook != nil {
+			n.deliveryHook(models.DeliveryResult{SubscriberKey: subscriberKey, NotificationRecord: record})
+		}
+		if !record.Success {
+			n.deadLetters.Add(models.DeadLetter{
+				Timestamp:       time.Now(),
+				SubscriberKey:   subscriberKey,
+				NotificationURL: url,
+				Payload:         payload,
+				StatusCode:      record.StatusCode,
+				Error:           record.Error,
+				Attempts:        record.Attempts,
+			})
+		}
+	}()
+
 	// Marshal payload to JSON
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		logger.Error("Notifier: Failed to marshal notification payload",
 			append(logFields, zap.Error(err))...)
+		record.Error = err.Error()
 		return
 	}
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	transport, target, err := n.transportFor(url)
 	if err != nil {
-		logger.Error("Notifier: Failed to create notification request",
+		logger.Error("Notifier: Invalid notification URL",
 			append(logFields, zap.Error(err))...)
+		record.Error = err.Error()
 		return
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send request
-	resp, err := n.httpClient.Do(req)
-	if err != nil {
-		logger.Error("Notifier: Failed to send notification",
-			append(logFields, zap.Error(err))...)
+	// Skip subscribers that keep failing instead of waiting for their timeout every time
+	if !n.breakers.allow(url) {
+		logger.Warn("Notifier: Circuit breaker open, skipping notification", logFields...)
+		record.Error = errCircuitOpen.Error()
 		return
 	}
-	defer resp.Body.Close()
 
-	// Check response status
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		logger.Warn("Notifier: Notification returned non-success status",
-			append(logFields, zap.Int("status_code", resp.StatusCode))...)
-		return
-	}
+	for attempt := 1; ; attempt++ {
+		record.Attempts = attempt
 
-	logger.Info("Notifier: Successfully sent notification",
-		append(logFields, zap.Int("status_code", resp.StatusCode))...)
-}
+		statusCode, err := n.send(ctx, transport, target, payload, jsonData)
+		record.StatusCode = statusCode
+		if err == nil {
+			n.breakers.done(url, true)
+			record.Success = true
+			record.Error = ""
+			if subscriberKey != "" {
+				n.history.delivered(subscriberKey, time.Now())
+			}
+			logger.Info("Notifier: Successfully sent notification",
+				append(logFields, zap.Int("status_code", statusCode), zap.Int("attempt", attempt))...)
+			return
+		}
+		record.Error = err.Error()
 
-// BuildNotificationPayload creates a notification payload from service pods
-func BuildNotificationPayload(serviceName string, eventType models.EventType, pods []*models.ServiceInfo) *models.NotificationPayload {
-	podInfos := make([]models.PodInfo, 0, len(pods))
+		// 4xx means the subscriber rejected the payload, retrying won't help
+		retryable := statusCode == 0 || statusCode >= 500
+		if !retryable || attempt >= n.retryPolicy.attempts() {
+			n.breakers.done(url, !retryable)
+			logger.Error("Notifier: Failed to send notification",
+				append(logFields, zap.Int("status_code", statusCode), zap.Int("attempt", attempt), zap.Error(err))...)
+			return
+		}
 
-	for _, pod := range pods {
-		podInfos = append(podInfos, models.PodInfo{
-			PodName:   pod.PodName,
-			Status:    pod.Status,
-			Providers: pod.Providers,
-		})
+		delay := n.retryPolicy.backoff(attempt)
+		logger.Warn("Notifier: Notification failed, retrying after backoff",
+			append(logFields, zap.Int("status_code", statusCode), zap.Int("attempt", attempt),
+				zap.Duration("backoff", delay), zap.Error(err))...)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			n.breakers.done(url, false)
+			logger.Error("Notifier: Notification retry deadline exceeded",
+				append(logFields, zap.Int("attempt", attempt))...)
+			record.Error = ctx.Err().Error()
+			return
+		}
 	}
+}
+
+// send performs a single delivery attempt over the transport
+func (n *Notifier) send(ctx context.Context, transport Transport, target *neturl.URL, payload *models.NotificationPayload, body []byte) (int, error) {
+	// Each attempt gets at most the notifier timeout, within the overall deadline
+	attemptCtx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	return transport.Send(attemptCtx, target, payload, body)
+}
 
+// BuildNotificationPayload creates a notification payload from service pods.
+// serviceName is qualified (see models.QualifiedServiceName); the payload has the namespace
+// and service name separately.
+func BuildNotificationPayload(serviceName string, eventType models.EventType, pods []*models.ServiceInfo) *models.NotificationPayload {
+	namespace, serviceName := models.SplitQualifiedServiceName(serviceName)
 	return &models.NotificationPayload{
+		Namespace:   namespace,
 		ServiceName: serviceName,
 		EventType:   eventType,
 		Timestamp:   time.Now(),
-		Pods:        podInfos,
+		Pods:        podInfos(pods),
 	}
 }
 
+// BuildDeltaPayload creates the delta format of a full notification payload, holding only
+// the pods that changed
+func BuildDeltaPayload(full *models.NotificationPayload, changed []*models.ServiceInfo) *models.NotificationPayload {
+	delta := *full
+	delta.Pods = podInfos(changed)
+	delta.Format = models.NotificationFormatDelta
+	return &delta
+}
+
+// podInfos converts pods to their notification representation, sorted by pod name with
+// each pod's providers sorted by protocol, IP and port. Pods are listed from a map, so
+// without sorting identical states would produce differently ordered payloads and defeat
+// subscribers diffing consecutive notifications.
+func podInfos(pods []*models.ServiceInfo) []models.PodInfo {
+	infos := make([]models.PodInfo, 0, len(pods))
+	for _, pod := range pods {
+		infos = append(infos, models.PodInfo{
+			PodName:         pod.PodName,
+			Status:          pod.Status,
+			Providers:       sortedProviders(pod.Providers),
+			HealthCheckURL:  pod.HealthCheckURL,
+			NotificationURL: pod.NotificationURL,
+			Labels:          pod.Labels,
+			Revision:        pod.Revision,
+		})
+	}
+	slices.SortFunc(infos, func(a, b models.PodInfo) int {
+		return strings.Compare(a.PodName, b.PodName)
+	})
+	return infos
+}
+
+// sortedProviders returns a sorted copy of providers, leaving the pod's own list untouched
+func sortedProviders(providers []models.ProviderInfo) []models.ProviderInfo {
+	sorted := slices.Clone(providers)
+	slices.SortFunc(sorted, func(a, b models.ProviderInfo) int {
+		return cmp.Or(
+			strings.Compare(string(a.Protocol), string(b.Protocol)),
+			strings.Compare(a.IP, b.IP),
+			cmp.Compare(a.Port, b.Port),
+		)
+	})
+	return sorted
+}
+
 // HealthChecker performs health checks on services
 type HealthChecker struct {
-	httpClient *http.Client
-	timeout    time.Duration
-	maxRetries int
+	httpClient    *http.Client
+	timeout       time.Duration
+	maxRetries    int
+	maxBackoff    time.Duration             // Upper bound for a single backoff between attempts
+	retryBudget   time.Duration             // Upper bound for the backoffs of one check added up
+	healthyStatus func(statusCode int) bool // Decides which HTTP status codes count as healthy
+}
+
+const (
+	// DefaultHealthCheckMaxBackoff caps a single backoff between health check attempts
+	DefaultHealthCheckMaxBackoff = 30 * time.Second
+	// DefaultHealthCheckRetryBudget caps the total backoff of one health check
+	DefaultHealthCheckRetryBudget = 60 * time.Second
+)
+
+// HealthCheckerOption configures optional HealthChecker behavior
+type HealthCheckerOption func(*HealthChecker)
+
+// WithHealthCheckHTTPClient sets the HTTP client used for health checks.
+// Each attempt is still bounded by the checker's timeout.
+// A nil client keeps the default timeout-configured client.
+func WithHealthCheckHTTPClient(client *http.Client) HealthCheckerOption {
+	return func(hc *HealthChecker) {
+		if client != nil {
+			hc.httpClient = client
+		}
+	}
+}
+
+// WithHealthyStatus sets the matcher deciding which HTTP status codes count as healthy.
+// A nil matcher keeps the default (200-299).
+func WithHealthyStatus(matcher func(statusCode int) bool) HealthCheckerOption {
+	return func(hc *HealthChecker) {
+		if matcher != nil {
+			hc.healthyStatus = matcher
+		}
+	}
+}
+
+// WithHealthyStatusCodes treats exactly the given HTTP status codes as healthy.
+// An empty list keeps the default (200-299).
+func WithHealthyStatusCodes(codes ...int) HealthCheckerOption {
+	if len(codes) == 0 {
+		return func(hc *HealthChecker) {}
+	}
+
+	accepted := make(map[int]bool, len(codes))
+	for _, code := range codes {
+		accepted[code] = true
+	}
+	return WithHealthyStatus(func(statusCode int) bool {
+		return accepted[statusCode]
+	})
+}
+
+// WithRetryBackoff bounds the exponential backoff between health check attempts: a single
+// backoff never exceeds maxBackoff, and a check stops retrying once its next backoff would
+// take the total waited past budget. Values <= 0 keep DefaultHealthCheckMaxBackoff and
+// DefaultHealthCheckRetryBudget.
+func WithRetryBackoff(maxBackoff, budget time.Duration) HealthCheckerOption {
+	return func(hc *HealthChecker) {
+		if maxBackoff > 0 {
+			hc.maxBackoff = maxBackoff
+		}
+		if budget > 0 {
+			hc.retryBudget = budget
+		}
+	}
+}
+
+// isSuccessStatus is the default healthy status matcher (any 2xx)
+func isSuccessStatus(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
 }
 
 // NewHealthChecker creates a new health checker
-func NewHealthChecker(timeout time.Duration, maxRetries int) *HealthChecker {
-	return &HealthChecker{
+func NewHealthChecker(timeout time.Duration, maxRetries int, opts ...HealthCheckerOption) *HealthChecker {
+	hc := &HealthChecker{
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
-		timeout:    timeout,
-		maxRetries: maxRetries,
+		timeout:       timeout,
+		maxRetries:    maxRetries,
+		maxBackoff:    DefaultHealthCheckMaxBackoff,
+		retryBudget:   DefaultHealthCheckRetryBudget,
+		healthyStatus: isSuccessStatus,
+	}
+
+	for _, opt := range opts {
+		opt(hc)
 	}
+
+	return hc
+}
+
+// HTTPCheck describes the request made by an HTTP health check.
+// The zero value is a plain GET.
+type HTTPCheck struct {
+	Method  string                   // HTTP method, GET if empty
+	Headers map[string]string        // Extra request headers
+	Match   *models.HealthCheckMatch // Required response body, nil to only check the status code
+}
+
+// CheckHealth performs an HTTP GET health check with retries
+// Returns true if healthy, false if unhealthy
+func (hc *HealthChecker) CheckHealth(ctx context.Context, healthCheckURL string) bool {
+	return hc.CheckHTTP(ctx, healthCheckURL, HTTPCheck{})
 }
 
-// CheckHealth performs health check with retries
+// CheckHTTP performs an HTTP health check with retries, using the method and headers of check.
+// With check.Match set, the response body must match as well as the status code.
 // Returns true if healthy, false if unhealthy
-func (hc *HealthChecker) CheckHealth(healthCheckURL string) bool {
+func (hc *HealthChecker) CheckHTTP(ctx context.Context, healthCheckURL string, check HTTPCheck) bool {
+	return hc.httpStatus(ctx, healthCheckURL, check) == models.StatusHealthy
+}
+
+// httpStatus is CheckHTTP reporting StatusUnknown when the target could not be reached
+func (hc *HealthChecker) httpStatus(ctx context.Context, healthCheckURL string, check HTTPCheck) models.ServiceStatus {
 	logger.Debug("HealthChecker: Starting health check",
 		zap.String("health_check_url", healthCheckURL),
+		zap.String("method", check.method()),
 		zap.Int("max_retries", hc.maxRetries),
 		zap.Duration("timeout", hc.timeout),
 	)
 
-	for attempt := 0; attempt <= hc.maxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff: 1s, 2s, 4s...
-			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
-			logger.Debug("HealthChecker: Retrying after backoff",
+	return hc.withRetries(ctx, healthCheckURL, func(attempt int) models.ServiceStatus {
+		return hc.attemptHTTP(ctx, healthCheckURL, check, attempt)
+	})
+}
+
+// CheckHTTPChecks performs several HTTP health checks under a single retry loop.
+// With mode HealthCheckAny one passing check is enough, otherwise (HealthCheckAll or empty)
+// every check must pass in the same attempt. An attempt stops at the first check that
+// decides its outcome.
+// Returns true if healthy, false if unhealthy
+func (hc *HealthChecker) CheckHTTPChecks(ctx context.Context, checks []models.HealthCheck, mode models.HealthCheckMode) bool {
+	return hc.httpChecksStatus(ctx, checks, mode) == models.StatusHealthy
+}
+
+// httpChecksStatus is CheckHTTPChecks reporting StatusUnknown when the outcome depends on a
+// check whose target could not be reached
+func (hc *HealthChecker) httpChecksStatus(ctx context.Context, checks []models.HealthCheck, mode models.HealthCheckMode) models.ServiceStatus {
+	if len(checks) == 0 {
+		return models.StatusUnhealthy
+	}
+
+	urls := make([]string, len(checks))
+	for i, check := range checks {
+		urls[i] = check.URL
+	}
+	target := strings.Join(urls, ", ")
+
+	logger.Debug("HealthChecker: Starting health checks",
+		zap.Strings("health_check_urls", urls),
+		zap.String("mode", string(mode)),
+		zap.Int("max_retries", hc.maxRetries),
+		zap.Duration("timeout", hc.timeout),
+	)
+
+	anyMode := mode == models.HealthCheckAny
+	return hc.withRetries(ctx, target, func(attempt int) models.ServiceStatus {
+		// In any mode the pod is only definitely unhealthy if every check says so
+		result := models.StatusHealthy
+		if anyMode {
+			result = models.StatusUnhealthy
+		}
+		for _, check := range checks {
+			observed := hc.attemptHTTP(ctx, check.URL, HTTPCheck{
+				Method:  check.Method,
+				Headers: check.Headers,
+				Match:   check.Match,
+			}, attempt)
+			if (observed == models.StatusHealthy) == anyMode {
+				return observed
+			}
+			if observed == models.StatusUnknown {
+				result = observed
+			}
+		}
+		return result
+	})
+}
+
+// method returns the HTTP method of the check, GET if unset
+func (check HTTPCheck) method() string {
+	if check.Method == "" {
+		return http.MethodGet
+	}
+	return check.Method
+}
+
+// attemptHTTP performs a single HTTP health check attempt. A request that gets no response
+// (e.g. DNS failure, connection refused, timeout) is StatusUnknown; a response with an
+// unhealthy status code or body is StatusUnhealthy.
+func (hc *HealthChecker) attemptHTTP(ctx context.Context, healthCheckURL string, check HTTPCheck, attempt int) models.ServiceStatus {
+	ctx, cancel := context.WithTimeout(ctx, hc.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, check.method(), healthCheckURL, nil)
+	if err != nil {
+		logger.Error("HealthChecker: Failed to create health check request",
+			zap.String("health_check_url", healthCheckURL),
+			zap.Int("attempt", attempt+1),
+			zap.Error(err),
+		)
+		return models.StatusUnhealthy
+	}
+	for name, value := range check.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := hc.httpClient.Do(req)
+	if err != nil {
+		logger.Warn("HealthChecker: Health check request failed",
+			zap.String("health_check_url", healthCheckURL),
+			zap.Int("attempt", attempt+1),
+			zap.Int("total_attempts", hc.maxRetries+1),
+			zap.Error(err),
+		)
+		return models.StatusUnknown
+	}
+
+	defer resp.Body.Close()
+
+	// 2xx by default, see WithHealthyStatus
+	if !hc.healthyStatus(resp.StatusCode) {
+		logger.Warn("HealthChecker: Health check returned unhealthy status",
+			zap.String("health_check_url", healthCheckURL),
+			zap.Int("attempt", attempt+1),
+			zap.Int("total_attempts", hc.maxRetries+1),
+			zap.Int("status_code", resp.StatusCode),
+		)
+		return models.StatusUnhealthy
+	}
+
+	if check.Match != nil {
+		if err := matchBody(resp.Body, check.Match); err != nil {
+			logger.Warn("HealthChecker: Health check body did not match",
 				zap.String("health_check_url", healthCheckURL),
-				zap.Int("attempt", attempt),
-				zap.Int("max_retries", hc.maxRetries),
-				zap.Duration("backoff", backoff),
+				zap.Int("attempt", attempt+1),
+				zap.Int("total_attempts", hc.maxRetries+1),
+				zap.Error(err),
 			)
-			time.Sleep(backoff)
+			return models.StatusUnhealthy
 		}
+	}
+
+	logger.Debug("HealthChecker: Health check passed",
+		zap.String("health_check_url", healthCheckURL),
+		zap.Int("status_code", resp.StatusCode),
+		zap.Int("attempt", attempt+1),
+	)
+	return models.StatusHealthy
+}
 
-		ctx, cancel := context.WithTimeout(context.Background(), hc.timeout)
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthCheckURL, nil)
+// CheckTCP performs a TCP connect health check with retries.
+// A successful connection to address (host:port) is treated as healthy.
+func (hc *HealthChecker) CheckTCP(ctx context.Context, address string) bool {
+	return hc.tcpStatus(ctx, address) == models.StatusHealthy
+}
+
+// tcpStatus is CheckTCP reporting StatusUnknown when the connection could not be made
+// (e.g. DNS failure, connection refused, timeout), like a request without response in attemptHTTP
+func (hc *HealthChecker) tcpStatus(ctx context.Context, address string) models.ServiceStatus {
+	logger.Debug("HealthChecker: Starting TCP health check",
+		zap.String("address", address),
+		zap.Int("max_retries", hc.maxRetries),
+		zap.Duration("timeout", hc.timeout),
+	)
+
+	return hc.withRetries(ctx, address, func(attempt int) models.ServiceStatus {
+		dialer := net.Dialer{Timeout: hc.timeout}
+		conn, err := dialer.DialContext(ctx, "tcp", address)
 		if err != nil {
-			cancel()
-			logger.Error("HealthChecker: Failed to create health check request",
-				zap.String("health_check_url", healthCheckURL),
+			logger.Warn("HealthChecker: TCP health check failed",
+				zap.String("address", address),
+				zap.Int("attempt", attempt+1),
+				zap.Int("total_attempts", hc.maxRetries+1),
+				zap.Error(err),
+			)
+			return models.StatusUnknown
+		}
+		conn.Close()
+
+		logger.Debug("HealthChecker: TCP health check passed",
+			zap.String("address", address),
+			zap.Int("attempt", attempt+1),
+		)
+		return models.StatusHealthy
+	})
+}
+
+// CheckGRPC performs a gRPC health check (grpc.health.v1.Health/Check) with retries.
+// The server's overall health is queried; only SERVING is treated as healthy.
+func (hc *HealthChecker) CheckGRPC(ctx context.Context, address string) bool {
+	return hc.grpcStatus(ctx, address) == models.StatusHealthy
+}
+
+// grpcStatus is CheckGRPC reporting StatusUnknown when the server could not be reached
+func (hc *HealthChecker) grpcStatus(ctx context.Context, address string) models.ServiceStatus {
+	logger.Debug("HealthChecker: Starting gRPC health check",
+		zap.String("address", address),
+		zap.Int("max_retries", hc.maxRetries),
+		zap.Duration("timeout", hc.timeout),
+	)
+
+	return hc.withRetries(ctx, address, func(attempt int) models.ServiceStatus {
+		conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			logger.Error("HealthChecker: Failed to create gRPC client",
+				zap.String("address", address),
 				zap.Int("attempt", attempt+1),
 				zap.Error(err),
 			)
-			continue
+			return models.StatusUnhealthy
 		}
+		defer conn.Close()
 
-		resp, err := hc.httpClient.Do(req)
-		cancel()
+		ctx, cancel := context.WithTimeout(ctx, hc.timeout)
+		defer cancel()
 
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
 		if err != nil {
-			logger.Warn("HealthChecker: Health check request failed",
-				zap.String("health_check_url", healthCheckURL),
+			logger.Warn("HealthChecker: gRPC health check failed",
+				zap.String("address", address),
 				zap.Int("attempt", attempt+1),
 				zap.Int("total_attempts", hc.maxRetries+1),
 				zap.Error(err),
 			)
-			continue
+			if code := status.Code(err); code == codes.Unavailable || code == codes.DeadlineExceeded {
+				return models.StatusUnknown
+			}
+			return models.StatusUnhealthy
+		}
+
+		if resp.GetStatus() == healthpb.HealthCheckResponse_SERVING {
+			logger.Debug("HealthChecker: gRPC health check passed",
+				zap.String("address", address),
+				zap.Int("attempt", attempt+1),
+			)
+			return models.StatusHealthy
 		}
 
-		resp.Body.Close()
+		logger.Warn("HealthChecker: gRPC health check returned unhealthy status",
+			zap.String("address", address),
+			zap.Int("attempt", attempt+1),
+			zap.Int("total_attempts", hc.maxRetries+1),
+			zap.String("status", resp.GetStatus().String()),
+		)
+		return models.StatusUnhealthy
+	})
+}
 
-		// Consider 2xx as healthy
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			logger.Debug("HealthChecker: Health check passed",
-				zap.String("health_check_url", healthCheckURL),
-				zap.Int("status_code", resp.StatusCode),
+// udpRefusalWait bounds how long a UDP probe without ExpectReply waits for the host to
+// report the port unreachable
+const udpRefusalWait = 200 * time.Millisecond
+
+// CheckUDP performs a UDP liveness probe with retries, for PFCP, GTP and UDP providers.
+// The probe payload is sent to address (host:port). With probe.ExpectReply any reply within
+// the timeout is healthy; otherwise the pod is healthy unless sending fails or an ICMP port
+// unreachable comes back shortly after. A nil probe sends a zero-length datagram.
+// UDP has no response that says a pod is unhealthy, so a failed probe is StatusUnknown
+// (see GetServiceHealthStatus), like a request without response in attemptHTTP.
+func (hc *HealthChecker) CheckUDP(ctx context.Context, address string, probe *models.UDPProbe) bool {
+	return hc.udpStatus(ctx, address, probe) == models.StatusHealthy
+}
+
+// udpStatus is CheckUDP reporting StatusUnknown when the probe failed
+func (hc *HealthChecker) udpStatus(ctx context.Context, address string, probe *models.UDPProbe) models.ServiceStatus {
+	if probe == nil {
+		probe = &models.UDPProbe{}
+	}
+
+	logger.Debug("HealthChecker: Starting UDP health check",
+		zap.String("address", address),
+		zap.Bool("expect_reply", probe.ExpectReply),
+		zap.Int("max_retries", hc.maxRetries),
+		zap.Duration("timeout", hc.timeout),
+	)
+
+	return hc.withRetries(ctx, address, func(attempt int) models.ServiceStatus {
+		dialer := net.Dialer{Timeout: hc.timeout}
+		conn, err := dialer.DialContext(ctx, "udp", address)
+		if err != nil {
+			logger.Warn("HealthChecker: UDP health check failed",
+				zap.String("address", address),
 				zap.Int("attempt", attempt+1),
+				zap.Int("total_attempts", hc.maxRetries+1),
+				zap.Error(err),
 			)
-			return true
+			return models.StatusUnknown
 		}
+		defer conn.Close()
 
-		logger.Warn("HealthChecker: Health check returned unhealthy status",
-			zap.String("health_check_url", healthCheckURL),
+		wait := hc.timeout
+		if !probe.ExpectReply {
+			wait = min(hc.timeout, udpRefusalWait)
+		}
+		conn.SetDeadline(time.Now().Add(wait))
+
+		if _, err := conn.Write(probe.Payload); err != nil {
+			logger.Warn("HealthChecker: UDP health check send failed",
+				zap.String("address", address),
+				zap.Int("attempt", attempt+1),
+				zap.Int("total_attempts", hc.maxRetries+1),
+				zap.Error(err),
+			)
+			return models.StatusUnknown
+		}
+
+		// A refused port surfaces as a read error on the connected socket
+		buf := make([]byte, 1)
+		_, err = conn.Read(buf)
+		var netErr net.Error
+		timedOut := errors.As(err, &netErr) && netErr.Timeout()
+
+		if err == nil || (timedOut && !probe.ExpectReply) {
+			logger.Debug("HealthChecker: UDP health check passed",
+				zap.String("address", address),
+				zap.Bool("reply", err == nil),
+				zap.Int("attempt", attempt+1),
+			)
+			return models.StatusHealthy
+		}
+
+		logger.Warn("HealthChecker: UDP health check failed",
+			zap.String("address", address),
 			zap.Int("attempt", attempt+1),
 			zap.Int("total_attempts", hc.maxRetries+1),
-			zap.Int("status_code", resp.StatusCode),
+			zap.Error(err),
 		)
+		return models.StatusUnknown
+	})
+}
+
+// withRetries runs check up to maxRetries+1 times with exponential backoff between attempts,
+// until an attempt is healthy. Otherwise it returns the status of the last attempt, also when
+// it stops early because the next backoff would exceed the retry budget. A cancelled ctx,
+// also while waiting out a backoff, gives StatusUnknown.
+func (hc *HealthChecker) withRetries(ctx context.Context, target string, check func(attempt int) models.ServiceStatus) models.ServiceStatus {
+	var waited time.Duration
+	last := models.StatusUnknown
+	for attempt := 0; attempt <= hc.maxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := hc.backoff(attempt)
+			if waited+backoff > hc.retryBudget {
+				logger.Error("HealthChecker: Health check failed, retry budget exhausted",
+					zap.String("target", target),
+					zap.Int("total_attempts", attempt),
+					zap.Duration("waited", waited),
+					zap.Duration("retry_budget", hc.retryBudget),
+					zap.String("status", string(last)),
+				)
+				return last
+			}
+			waited += backoff
+
+			logger.Debug("HealthChecker: Retrying after backoff",
+				zap.String("target", target),
+				zap.Int("attempt", attempt),
+				zap.Int("max_retries", hc.maxRetries),
+				zap.Duration("backoff", backoff),
+			)
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+			}
+		}
+
+		if ctx.Err() != nil {
+			logger.Debug("HealthChecker: Health check cancelled",
+				zap.String("target", target),
+				zap.Int("attempt", attempt+1),
+				zap.Error(ctx.Err()),
+			)
+			return models.StatusUnknown
+		}
+
+		last = check(attempt)
+		if last == models.StatusHealthy {
+			return last
+		}
 	}
 
 	logger.Error("HealthChecker: Health check failed after all retries",
-		zap.String("health_check_url", healthCheckURL),
+		zap.String("target", target),
 		zap.Int("total_attempts", hc.maxRetries+1),
+		zap.String("status", string(last)),
 	)
-	return false
+	return last
 }
 
-// GetHealthStatus performs health check and returns status
-func (hc *HealthChecker) GetHealthStatus(healthCheckURL string) models.ServiceStatus {
-	if hc.CheckHealth(healthCheckURL) {
-		return models.StatusHealthy
+// backoff returns the wait before the given retry (1-based): 1s, 2s, 4s... capped at maxBackoff
+func (hc *HealthChecker) backoff(attempt int) time.Duration {
+	backoff := time.Second << uint(min(attempt-1, 30))
+	if backoff > hc.maxBackoff {
+		backoff = hc.maxBackoff
+	}
+	return backoff
+}
+
+// CheckService checks a service using the mode that fits it:
+// HTTP (with the service's method and headers) when it has a HealthCheckURL or HealthChecks,
+// combined per its HealthCheckMode, otherwise the gRPC health protocol against its
+// first gRPC provider, otherwise a TCP connect to its first TCP provider, otherwise a UDP
+// probe (see CheckUDP) to its first PFCP, GTP or UDP provider.
+// Whatever the mode, a target that could not be reached (DNS failure, connection refused,
+// timeout) is not healthy, but GetServiceHealthStatus reports it as StatusUnknown rather than
+// StatusUnhealthy; only an answer from the pod makes it unhealthy.
+func (hc *HealthChecker) CheckService(ctx context.Context, service *models.ServiceInfo) bool {
+	return hc.serviceStatus(ctx, service) == models.StatusHealthy
+}
+
+// serviceStatus performs the check CheckService picks for the service, in a span that is a
+// child of ctx's span
+func (hc *HealthChecker) serviceStatus(ctx context.Context, service *models.ServiceInfo) models.ServiceStatus {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "health check",
+		trace.WithAttributes(attribute.String("service.key", service.GetKey())),
+	)
+	defer span.End()
+
+	status := hc.checkService(ctx, service)
+	span.SetAttributes(attribute.String("health.status", string(status)))
+	if status != models.StatusHealthy {
+		span.SetStatus(otelcodes.Error, string(status))
 	}
+	return status
+}
+
+// checkService picks and performs the check for the service
+func (hc *HealthChecker) checkService(ctx context.Context, service *models.ServiceInfo) models.ServiceStatus {
+	if len(service.HealthChecks) > 0 {
+		return hc.httpChecksStatus(ctx, service.HTTPHealthChecks(), service.HealthCheckMode)
+	}
+	if service.HealthCheckURL != "" {
+		return hc.httpStatus(ctx, service.HealthCheckURL, HTTPCheck{
+			Method:  service.HealthCheckMethod,
+			Headers: service.HealthCheckHeaders,
+			Match:   service.HealthCheckMatch,
+		})
+	}
+
+	if provider, ok := service.Provider(models.ProtocolGRPC); ok {
+		return hc.grpcStatus(ctx, net.JoinHostPort(provider.IP, strconv.Itoa(provider.Port)))
+	}
+
+	if provider, ok := service.TCPProvider(); ok {
+		return hc.tcpStatus(ctx, net.JoinHostPort(provider.IP, strconv.Itoa(provider.Port)))
+	}
+
+	if provider, ok := service.UDPProvider(); ok {
+		return hc.udpStatus(ctx, net.JoinHostPort(provider.IP, strconv.Itoa(provider.Port)), service.UDPProbe)
+	}
+
+	logger.Warn("HealthChecker: Service has no health check URL, gRPC, TCP or UDP provider",
+		zap.String("service_key", service.GetKey()),
+	)
 	return models.StatusUnhealthy
 }
+
+// GetServiceHealthStatus checks a service (see CheckService) and returns its status:
+// StatusHealthy, StatusUnhealthy if the pod failed the check, or StatusUnknown if the check
+// could not reach a verdict, e.g. because the health check host name did not resolve or the
+// HTTP, gRPC, TCP or UDP target could not be reached
+func (hc *HealthChecker) GetServiceHealthStatus(ctx context.Context, service *models.ServiceInfo) models.ServiceStatus {
+	return hc.serviceStatus(ctx, service)
+}
+
+// GetHealthStatus performs health check and returns status
+// like GetServiceHealthStatus
+func (hc *HealthChecker) GetHealthStatus(ctx context.Context, healthCheckURL string) models.ServiceStatus {
+	return hc.httpStatus(ctx, healthCheckURL, HTTPCheck{})
+}
@@ -1,13 +1,28 @@
 package notifier
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/chronnie/governance/models"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func TestNewNotifier(t *testing.T) {
@@ -49,7 +64,7 @@ func TestCheckHealthSuccess(t *testing.T) {
 	defer server.Close()
 
 	hc := NewHealthChecker(5*time.Second, 3)
-	healthy := hc.CheckHealth(server.URL)
+	healthy := hc.CheckHealth(context.Background(), server.URL)
 
 	if !healthy {
 		t.Error("Expected health check to pass")
@@ -64,16 +79,59 @@ func TestCheckHealthFailure(t *testing.T) {
 	defer server.Close()
 
 	hc := NewHealthChecker(1*time.Second, 1) // Low retry for faster test
-	healthy := hc.CheckHealth(server.URL)
+	healthy := hc.CheckHealth(context.Background(), server.URL)
 
 	if healthy {
 		t.Error("Expected health check to fail")
 	}
 }
 
+func TestHealthCheckRetryBackoff(t *testing.T) {
+	hc := NewHealthChecker(time.Second, 100, WithRetryBackoff(5*time.Second, 0))
+
+	testCases := []struct {
+		attempt int
+		backoff time.Duration
+	}{
+		{1, 1 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second}, // Capped
+		{100, 5 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		if backoff := hc.backoff(tc.attempt); backoff != tc.backoff {
+			t.Errorf("Attempt %d: expected backoff %v, got %v", tc.attempt, tc.backoff, backoff)
+		}
+	}
+}
+
+func TestHealthCheckRetryBudget(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	// The first backoff (1s) already exceeds the budget, so there is no retry
+	hc := NewHealthChecker(time.Second, 100, WithRetryBackoff(0, 500*time.Millisecond))
+
+	start := time.Now()
+	if hc.CheckHealth(context.Background(), server.URL) {
+		t.Error("Expected health check to fail")
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("Expected the check to give up without waiting, took %v", elapsed)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected 1 attempt, got %d", got)
+	}
+}
+
 func TestCheckHealthInvalidURL(t *testing.T) {
 	hc := NewHealthChecker(1*time.Second, 1)
-	healthy := hc.CheckHealth("http://invalid-url-that-does-not-exist:99999/health")
+	healthy := hc.CheckHealth(context.Background(), "http://invalid-url-that-does-not-exist:99999/health")
 
 	if healthy {
 		t.Error("Expected health check to fail for invalid URL")
@@ -88,17 +146,283 @@ func TestGetHealthStatus(t *testing.T) {
 	defer server.Close()
 
 	hc := NewHealthChecker(5*time.Second, 3)
-	status := hc.GetHealthStatus(server.URL)
+	status := hc.GetHealthStatus(context.Background(), server.URL)
 
 	if status != models.StatusHealthy {
 		t.Errorf("Expected status 'healthy', got '%s'", status)
 	}
 
 	// Test unhealthy status
-	status = hc.GetHealthStatus("http://invalid-url:99999/health")
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	status = hc.GetHealthStatus(context.Background(), failing.URL)
 	if status != models.StatusUnhealthy {
 		t.Errorf("Expected status 'unhealthy', got '%s'", status)
 	}
+
+	// Test unknown status: no response says nothing about the service
+	status = hc.GetHealthStatus(context.Background(), "http://invalid-url:99999/health")
+	if status != models.StatusUnknown {
+		t.Errorf("Expected status 'unknown', got '%s'", status)
+	}
+}
+
+func TestCheckServiceTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+
+	service := &models.ServiceInfo{
+		ServiceName: "upf",
+		PodName:     "pod-1",
+		Providers: []models.ProviderInfo{
+			{Protocol: models.ProtocolPFCP, IP: "127.0.0.1", Port: 8805},
+			{Protocol: models.ProtocolTCP, IP: "127.0.0.1", Port: addr.Port},
+		},
+	}
+
+	hc := NewHealthChecker(1*time.Second, 0)
+	if status := hc.GetServiceHealthStatus(context.Background(), service); status != models.StatusHealthy {
+		t.Errorf("Expected status 'healthy', got '%s'", status)
+	}
+
+	// Closed listener: connection refused says nothing about the service, like for HTTP
+	listener.Close()
+	if status := hc.GetServiceHealthStatus(context.Background(), service); status != models.StatusUnknown {
+		t.Errorf("Expected status 'unknown', got '%s'", status)
+	}
+}
+
+func TestCheckServiceUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := conn.LocalAddr().(*net.UDPAddr)
+
+	// Echo server
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			conn.WriteTo(buf[:n], from)
+		}
+	}()
+
+	service := &models.ServiceInfo{
+		ServiceName: "upf",
+		PodName:     "pod-1",
+		Providers:   []models.ProviderInfo{{Protocol: models.ProtocolPFCP, IP: "127.0.0.1", Port: addr.Port}},
+	}
+
+	hc := NewHealthChecker(500*time.Millisecond, 0)
+	if status := hc.GetServiceHealthStatus(context.Background(), service); status != models.StatusHealthy {
+		t.Errorf("Expected status 'healthy' without reply expected, got '%s'", status)
+	}
+
+	service.UDPProbe = &models.UDPProbe{Payload: []byte("ping"), ExpectReply: true}
+	if status := hc.GetServiceHealthStatus(context.Background(), service); status != models.StatusHealthy {
+		t.Errorf("Expected status 'healthy' with echoed reply, got '%s'", status)
+	}
+
+	// Closed socket: nothing replies
+	conn.Close()
+	if status := hc.GetServiceHealthStatus(context.Background(), service); status != models.StatusUnknown {
+		t.Errorf("Expected status 'unknown', got '%s'", status)
+	}
+
+	// Port unreachable
+	service.UDPProbe = nil
+	if status := hc.GetServiceHealthStatus(context.Background(), service); status != models.StatusUnknown {
+		t.Errorf("Expected status 'unknown' without reply expected, got '%s'", status)
+	}
+}
+
+func TestCheckServiceGRPC(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+
+	healthServer := health.NewServer()
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	// The TCP provider points nowhere; the gRPC provider must be used instead
+	service := &models.ServiceInfo{
+		ServiceName: "user-service",
+		PodName:     "pod-1",
+		Providers: []models.ProviderInfo{
+			{Protocol: models.ProtocolTCP, IP: "127.0.0.1", Port: 1},
+			{Protocol: models.ProtocolGRPC, IP: "127.0.0.1", Port: addr.Port},
+		},
+	}
+
+	hc := NewHealthChecker(1*time.Second, 0)
+	if status := hc.GetServiceHealthStatus(context.Background(), service); status != models.StatusHealthy {
+		t.Errorf("Expected status 'healthy', got '%s'", status)
+	}
+
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	if status := hc.GetServiceHealthStatus(context.Background(), service); status != models.StatusUnhealthy {
+		t.Errorf("Expected status 'unhealthy', got '%s'", status)
+	}
+}
+
+func TestCheckServicePrefersHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The TCP provider points nowhere; the HTTP URL must be used instead
+	service := &models.ServiceInfo{
+		ServiceName:    "user-service",
+		PodName:        "pod-1",
+		HealthCheckURL: server.URL,
+		Providers:      []models.ProviderInfo{{Protocol: models.ProtocolTCP, IP: "127.0.0.1", Port: 1}},
+	}
+
+	hc := NewHealthChecker(1*time.Second, 0)
+	if !hc.CheckService(context.Background(), service) {
+		t.Error("Expected HTTP health check to be used and pass")
+	}
+}
+
+func TestCheckServiceHTTPMethodAndHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only answers HEAD with the right token
+		if r.Method != http.MethodHead || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	service := &models.ServiceInfo{
+		ServiceName:        "user-service",
+		PodName:            "pod-1",
+		HealthCheckURL:     server.URL,
+		HealthCheckMethod:  http.MethodHead,
+		HealthCheckHeaders: map[string]string{"Authorization": "Bearer secret"},
+	}
+
+	hc := NewHealthChecker(1*time.Second, 0)
+	if !hc.CheckService(context.Background(), service) {
+		t.Error("Expected HEAD health check with headers to pass")
+	}
+
+	// Default GET without headers is rejected by this endpoint
+	if hc.CheckHealth(context.Background(), server.URL) {
+		t.Error("Expected plain GET health check to fail")
+	}
+}
+
+func TestCheckServiceHealthChecks(t *testing.T) {
+	var failingCalls atomic.Int32
+	passing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer passing.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failingCalls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	service := &models.ServiceInfo{
+		ServiceName:    "user-service",
+		PodName:        "pod-1",
+		HealthCheckURL: passing.URL,
+		HealthChecks:   []models.HealthCheck{{URL: failing.URL}},
+	}
+
+	hc := NewHealthChecker(1*time.Second, 0)
+	if hc.CheckService(context.Background(), service) {
+		t.Error("Expected health checks to fail when one of them fails by default")
+	}
+
+	service.HealthCheckMode = models.HealthCheckAll
+	if hc.CheckService(context.Background(), service) {
+		t.Error("Expected health checks to fail in all mode")
+	}
+
+	// The passing health check URL decides the attempt, the failing check is never tried
+	failingCalls.Store(0)
+	service.HealthCheckMode = models.HealthCheckAny
+	if !hc.CheckService(context.Background(), service) {
+		t.Error("Expected health checks to pass in any mode")
+	}
+	if calls := failingCalls.Load(); calls != 0 {
+		t.Errorf("Expected failing check to be skipped, got %d calls", calls)
+	}
+
+	service.HealthCheckURL = ""
+	if hc.CheckService(context.Background(), service) {
+		t.Error("Expected health checks to fail in any mode when none passes")
+	}
+}
+
+func TestCheckHTTPBodyMatch(t *testing.T) {
+	body := `{"status": "draining", "checks": [{"name": "db", "ok": true}], "version": 2}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name     string
+		match    models.HealthCheckMatch
+		expected bool
+	}{
+		{"substring present", models.HealthCheckMatch{BodyContains: `"checks"`}, true},
+		{"substring absent", models.HealthCheckMatch{BodyContains: "serving"}, false},
+		{"string value mismatch", models.HealthCheckMatch{JSONPath: "status", JSONValue: "up"}, false},
+		{"string value match", models.HealthCheckMatch{JSONPath: "status", JSONValue: "draining"}, true},
+		{"array index and boolean", models.HealthCheckMatch{JSONPath: "checks.0.ok", JSONValue: "true"}, true},
+		{"number", models.HealthCheckMatch{JSONPath: "version", JSONValue: "2"}, true},
+		{"missing path", models.HealthCheckMatch{JSONPath: "checks.1.ok", JSONValue: "true"}, false},
+		{"both conditions", models.HealthCheckMatch{BodyContains: "db", JSONPath: "status", JSONValue: "up"}, false},
+	}
+
+	hc := NewHealthChecker(1*time.Second, 0)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if healthy := hc.CheckHTTP(context.Background(), server.URL, HTTPCheck{Match: &tc.match}); healthy != tc.expected {
+				t.Errorf("Expected healthy=%v, got %v", tc.expected, healthy)
+			}
+		})
+	}
+}
+
+func TestCheckHTTPBodyMatchLimit(t *testing.T) {
+	// The marker lies beyond the read limit, and the truncated body is not valid JSON
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"padding": "` + strings.Repeat("x", MaxHealthCheckBodySize) + `", "status": "up"}`))
+	}))
+	defer server.Close()
+
+	hc := NewHealthChecker(1*time.Second, 0)
+	if hc.CheckHTTP(context.Background(), server.URL, HTTPCheck{Match: &models.HealthCheckMatch{BodyContains: `"status": "up"`}}) {
+		t.Error("Expected match beyond the body limit to fail")
+	}
+	if hc.CheckHTTP(context.Background(), server.URL, HTTPCheck{Match: &models.HealthCheckMatch{JSONPath: "status", JSONValue: "up"}}) {
+		t.Error("Expected truncated JSON body to fail")
+	}
 }
 
 func TestBuildNotificationPayload(t *testing.T) {
@@ -110,6 +434,8 @@ func TestBuildNotificationPayload(t *testing.T) {
 			Providers: []models.ProviderInfo{
 				{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080},
 			},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
 		},
 		{
 			ServiceName: "test-service",
@@ -138,6 +464,59 @@ func TestBuildNotificationPayload(t *testing.T) {
 	if payload.Pods[0].Status != models.StatusHealthy {
 		t.Errorf("Expected status 'healthy', got '%s'", payload.Pods[0].Status)
 	}
+	if payload.Pods[0].HealthCheckURL != "http://192.168.1.10:8080/health" || payload.Pods[0].NotificationURL != "http://192.168.1.10:8080/notify" {
+		t.Errorf("Expected pod URLs to be copied, got %+v", payload.Pods[0])
+	}
+
+	// Pods without URLs (e.g. TCP health checks) omit them
+	data, _ := json.Marshal(payload.Pods[1])
+	if strings.Contains(string(data), "health_check_url") || strings.Contains(string(data), "notification_url") {
+		t.Errorf("Expected empty URLs to be omitted, got %s", data)
+	}
+}
+
+func TestBuildNotificationPayloadStableOrder(t *testing.T) {
+	var pods []*models.ServiceInfo
+	for i := range 20 {
+		pods = append(pods, &models.ServiceInfo{
+			ServiceName: "test-service",
+			PodName:     fmt.Sprintf("pod-%02d", i),
+			Status:      models.StatusHealthy,
+			Providers: []models.ProviderInfo{
+				{Protocol: models.ProtocolPFCP, IP: "192.168.1.10", Port: 8805},
+				{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 9090},
+				{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080},
+			},
+		})
+	}
+
+	first, _ := json.Marshal(BuildNotificationPayload("test-service", models.EventTypeReconcile, pods).Pods)
+	for range 10 {
+		// Pods come in whatever order the registry lists them
+		shuffled := slices.Clone(pods)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+		payload := BuildNotificationPayload("test-service", models.EventTypeReconcile, shuffled)
+		if data, _ := json.Marshal(payload.Pods); string(data) != string(first) {
+			t.Fatalf("Expected the same pods in the same order, got %s, want %s", data, first)
+		}
+	}
+
+	payload := BuildNotificationPayload("test-service", models.EventTypeReconcile, pods)
+	if payload.Pods[0].PodName != "pod-00" || payload.Pods[19].PodName != "pod-19" {
+		t.Errorf("Expected pods sorted by name, got %s first and %s last", payload.Pods[0].PodName, payload.Pods[19].PodName)
+	}
+	expected := []models.ProviderInfo{
+		{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080},
+		{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 9090},
+		{Protocol: models.ProtocolPFCP, IP: "192.168.1.10", Port: 8805},
+	}
+	if !slices.Equal(payload.Pods[0].Providers, expected) {
+		t.Errorf("Expected providers sorted by protocol, IP and port, got %+v", payload.Pods[0].Providers)
+	}
+	if pods[0].Providers[0].Protocol != models.ProtocolPFCP {
+		t.Error("Expected the pod's own providers to keep their order")
+	}
 }
 
 func TestNotifySubscriberSuccess(t *testing.T) {
@@ -171,7 +550,7 @@ func TestNotifySubscriberSuccess(t *testing.T) {
 		Pods:        []models.PodInfo{},
 	}
 
-	notif.NotifySubscriber(server.URL, payload)
+	notif.NotifySubscriber(context.Background(), server.URL, payload)
 
 	// Wait for async notification to complete
 	time.Sleep(100 * time.Millisecond)
@@ -200,10 +579,427 @@ func TestNotifySubscribersFailed(t *testing.T) {
 	}
 
 	// Should not panic on failure
-	notif.NotifySubscriber(server.URL, payload)
+	notif.NotifySubscriber(context.Background(), server.URL, payload)
 	time.Sleep(100 * time.Millisecond)
 }
 
+func TestNotificationRetry(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail twice, then succeed
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notif := NewNotifier(1*time.Second, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond}))
+	payload := &models.NotificationPayload{ServiceName: "test-service", EventType: models.EventTypeRegister}
+
+	notif.sendNotification(context.Background(), server.URL, payload, "order-service:pod-1")
+
+	history := notif.GetNotificationHistory("order-service:pod-1")
+	if len(history) != 1 {
+		t.Fatalf("Expected 1 history record, got %d", len(history))
+	}
+	if !history[0].Success || history[0].Attempts != 3 {
+		t.Errorf("Expected success after 3 attempts, got success=%v attempts=%d", history[0].Success, history[0].Attempts)
+	}
+}
+
+func TestNotificationNoRetryOn4xx(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	notif := NewNotifier(1*time.Second, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond}))
+	payload := &models.NotificationPayload{ServiceName: "test-service", EventType: models.EventTypeRegister}
+
+	notif.sendNotification(context.Background(), server.URL, payload, "order-service:pod-1")
+
+	if attempts.Load() != 1 {
+		t.Errorf("Expected 1 attempt for 4xx response, got %d", attempts.Load())
+	}
+}
+
+func TestNotificationRetryDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	// Backoff far exceeds the overall deadline (timeout * attempts)
+	notif := NewNotifier(100*time.Millisecond, WithRetryPolicy(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Minute, MaxDelay: time.Minute}))
+	payload := &models.NotificationPayload{ServiceName: "test-service", EventType: models.EventTypeRegister}
+
+	start := time.Now()
+	notif.sendNotification(context.Background(), server.URL, payload, "")
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected retries to stop at the deadline, took %v", elapsed)
+	}
+}
+
+func TestNotificationCircuitBreaker(t *testing.T) {
+	var hits atomic.Int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if healthy.Load() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	notif := NewNotifier(1*time.Second, WithCircuitBreaker(BreakerPolicy{FailureThreshold: 2, Cooldown: 100 * time.Millisecond}))
+	payload := &models.NotificationPayload{ServiceName: "test-service", EventType: models.EventTypeRegister}
+
+	// Two failures open the circuit, the third notification is skipped
+	for range 3 {
+		notif.sendNotification(context.Background(), server.URL, payload, "order-service:pod-1")
+	}
+	if hits.Load() != 2 {
+		t.Errorf("Expected 2 requests before the circuit opened, got %d", hits.Load())
+	}
+	if history := notif.GetNotificationHistory("order-service:pod-1"); history[0].Error != errCircuitOpen.Error() {
+		t.Errorf("Expected skipped notification in history, got error %q", history[0].Error)
+	}
+
+	statuses := notif.GetBreakerStatuses()
+	if len(statuses) != 1 || statuses[0].State != BreakerOpen || statuses[0].ConsecutiveFailures != 2 {
+		t.Fatalf("Expected one open breaker with 2 failures, got %+v", statuses)
+	}
+	if !statuses[0].NextProbeAt.Equal(statuses[0].OpenedAt.Add(100 * time.Millisecond)) {
+		t.Errorf("Expected the next probe one cooldown after opening, got %+v", statuses[0])
+	}
+
+	// After the cooldown a failed probe reopens the circuit
+	time.Sleep(150 * time.Millisecond)
+	notif.sendNotification(context.Background(), server.URL, payload, "")
+	notif.sendNotification(context.Background(), server.URL, payload, "")
+	if hits.Load() != 3 {
+		t.Errorf("Expected a single probe after cooldown, got %d requests", hits.Load())
+	}
+
+	// A successful probe closes the circuit
+	healthy.Store(true)
+	time.Sleep(150 * time.Millisecond)
+	notif.sendNotification(context.Background(), server.URL, payload, "")
+	notif.sendNotification(context.Background(), server.URL, payload, "")
+	if hits.Load() != 5 {
+		t.Errorf("Expected notifications to flow after recovery, got %d requests", hits.Load())
+	}
+	if statuses := notif.GetBreakerStatuses(); len(statuses) != 0 {
+		t.Errorf("Expected no breakers after recovery, got %+v", statuses)
+	}
+}
+
+func TestNotificationCircuitBreakerIgnores4xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	notif := NewNotifier(1*time.Second, WithCircuitBreaker(BreakerPolicy{FailureThreshold: 1}))
+	payload := &models.NotificationPayload{ServiceName: "test-service", EventType: models.EventTypeRegister}
+
+	notif.sendNotification(context.Background(), server.URL, payload, "")
+	if statuses := notif.GetBreakerStatuses(); len(statuses) != 0 {
+		t.Errorf("Expected 4xx not to trip the breaker, got %+v", statuses)
+	}
+}
+
+func TestNotificationDeadLetter(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notif := NewNotifier(1*time.Second, WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: 10 * time.Millisecond}))
+	payload := &models.NotificationPayload{ServiceName: "user-service", EventType: models.EventTypeRegister}
+	notif.sendNotification(context.Background(), server.URL, payload, "subscriber:pod-1")
+
+	letters := notif.GetDeadLetters()
+	if len(letters) != 1 {
+		t.Fatalf("Expected 1 dead letter, got %d", len(letters))
+	}
+	letter := letters[0]
+	if letter.NotificationURL != server.URL || letter.SubscriberKey != "subscriber:pod-1" ||
+		letter.StatusCode != http.StatusServiceUnavailable || letter.Attempts != 2 || letter.Payload != payload {
+		t.Errorf("Unexpected dead letter: %+v", letter)
+	}
+
+	// Replay once the subscriber is back
+	fail.Store(false)
+	if notif.ReplayDeadLetter(letter.ID + 1) {
+		t.Error("Expected replay of unknown dead letter to fail")
+	}
+	if !notif.ReplayDeadLetter(letter.ID) {
+		t.Fatal("Expected replay to succeed")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for received.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if received.Load() != 1 {
+		t.Errorf("Expected replayed notification to be delivered, got %d", received.Load())
+	}
+	if letters := notif.GetDeadLetters(); len(letters) != 0 {
+		t.Errorf("Expected no dead letters after successful replay, got %d", len(letters))
+	}
+}
+
+func TestMemoryDeadLetterStore(t *testing.T) {
+	store := NewMemoryDeadLetterStore(2)
+	for _, url := range []string{"http://a", "http://b", "http://c"} {
+		store.Add(models.DeadLetter{NotificationURL: url})
+	}
+
+	// Oldest dropped, newest first
+	letters := store.List()
+	if len(letters) != 2 || letters[0].NotificationURL != "http://c" || letters[1].NotificationURL != "http://b" {
+		t.Fatalf("Unexpected dead letters: %+v", letters)
+	}
+	if letters[0].ID != 3 || letters[1].ID != 2 {
+		t.Errorf("Expected IDs 3 and 2, got %d and %d", letters[0].ID, letters[1].ID)
+	}
+
+	if _, exists := store.Remove(1); exists {
+		t.Error("Expected dropped dead letter to be gone")
+	}
+	if letter, exists := store.Remove(2); !exists || letter.NotificationURL != "http://b" {
+		t.Errorf("Expected to remove http://b, got %+v", letter)
+	}
+	if letters := store.List(); len(letters) != 1 {
+		t.Errorf("Expected 1 dead letter left, got %d", len(letters))
+	}
+}
+
+func TestNotifierDeliveryHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	results := make(chan models.DeliveryResult, 1)
+	notif := NewNotifier(1*time.Second,
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: 10 * time.Millisecond}),
+		WithDeliveryHook(func(result models.DeliveryResult) {
+			results <- result
+		}),
+	)
+
+	subscriber := &models.ServiceInfo{ServiceName: "order-service", PodName: "pod-1", NotificationURL: server.URL}
+	notif.NotifySubscribers(context.Background(), []*models.ServiceInfo{subscriber}, &models.NotificationPayload{
+		EventID:     7,
+		ServiceName: "test-service",
+		EventType:   models.EventTypeUnregister,
+	})
+
+	select {
+	case result := <-results:
+		if result.SubscriberKey != "order-service:pod-1" || result.NotificationURL != server.URL {
+			t.Errorf("Unexpected subscriber in result: %+v", result)
+		}
+		if result.Success || result.StatusCode != http.StatusServiceUnavailable || result.Attempts != 2 {
+			t.Errorf("Expected failure with 503 after 2 attempts, got %+v", result)
+		}
+		if result.EventID != 7 || result.EventType != models.EventTypeUnregister {
+			t.Errorf("Unexpected event in result: %+v", result)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected delivery hook to be called")
+	}
+}
+
+// fakeTransport records the notifications it is asked to send
+type fakeTransport struct {
+	sent chan *url.URL
+}
+
+func (f *fakeTransport) Send(ctx context.Context, target *url.URL, payload *models.NotificationPayload, body []byte) (int, error) {
+	f.sent <- target
+	return 0, nil
+}
+
+func TestNotifierTransportByScheme(t *testing.T) {
+	transport := &fakeTransport{sent: make(chan *url.URL, 1)}
+	results := make(chan models.DeliveryResult, 1)
+	notif := NewNotifier(time.Second,
+		WithTransport("nats", transport),
+		WithDeliveryHook(func(result models.DeliveryResult) {
+			results <- result
+		}),
+	)
+
+	subscriber := &models.ServiceInfo{ServiceName: "order-service", PodName: "pod-1", NotificationURL: "nats://localhost:4222/events"}
+	notif.NotifySubscribers(context.Background(), []*models.ServiceInfo{subscriber}, &models.NotificationPayload{ServiceName: "test-service"})
+
+	select {
+	case target := <-transport.sent:
+		if target.Host != "localhost:4222" || target.Path != "/events" {
+			t.Errorf("Unexpected target URL: %s", target)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected notification to be sent via the nats transport")
+	}
+
+	if result := <-results; !result.Success {
+		t.Errorf("Expected successful delivery, got %+v", result)
+	}
+}
+
+func TestNotifierUnknownScheme(t *testing.T) {
+	results := make(chan models.DeliveryResult, 1)
+	notif := NewNotifier(time.Second, WithDeliveryHook(func(result models.DeliveryResult) {
+		results <- result
+	}))
+
+	subscriber := &models.ServiceInfo{ServiceName: "order-service", PodName: "pod-1", NotificationURL: "nats://localhost:4222"}
+	notif.NotifySubscribers(context.Background(), []*models.ServiceInfo{subscriber}, &models.NotificationPayload{ServiceName: "test-service"})
+
+	select {
+	case result := <-results:
+		if result.Success || result.Error == "" {
+			t.Errorf("Expected failure for scheme without transport, got %+v", result)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected delivery hook to be called")
+	}
+}
+
+func TestNATSSubject(t *testing.T) {
+	tests := []struct {
+		url       string
+		namespace string
+		expected  string
+	}{
+		{"nats://localhost:4222", "", "governance.user-service"},
+		{"nats://localhost:4222/", "", "governance.user-service"},
+		{"nats://localhost:4222/events", "", "events.user-service"},
+		{"nats://localhost:4222/prod/registry/", "", "prod.registry.user-service"},
+		{"nats://localhost:4222", "team-a", "governance.team-a.user-service"},
+		{"nats://localhost:4222/events", "team-a", "events.team-a.user-service"},
+	}
+
+	for _, tt := range tests {
+		target, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", tt.url, err)
+		}
+		if subject := natsSubject(target, tt.namespace, "user-service"); subject != tt.expected {
+			t.Errorf("natsSubject(%s, %q) = %s, expected %s", tt.url, tt.namespace, subject, tt.expected)
+		}
+	}
+}
+
+func TestNotifierMaxConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	var wg sync.WaitGroup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer wg.Done()
+		current := inFlight.Add(1)
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notif := NewNotifier(1*time.Second, WithMaxConcurrency(2))
+	payload := &models.NotificationPayload{ServiceName: "test-service", EventType: models.EventTypeReconcile}
+
+	subscribers := make([]*models.ServiceInfo, 10)
+	for i := range subscribers {
+		subscribers[i] = &models.ServiceInfo{ServiceName: "order-service", PodName: "pod", NotificationURL: server.URL}
+	}
+	wg.Add(len(subscribers))
+	notif.NotifySubscribers(context.Background(), subscribers, payload)
+	wg.Wait()
+
+	if peak.Load() > 2 {
+		t.Errorf("Expected at most 2 concurrent notifications, got %d", peak.Load())
+	}
+}
+
+func TestNotifierMaxConcurrencyBoundsGoroutines(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notif := NewNotifier(1*time.Second, WithMaxConcurrency(2))
+	payload := &models.NotificationPayload{ServiceName: "test-service", EventType: models.EventTypeReconcile}
+
+	subscribers := make([]*models.ServiceInfo, 3)
+	for i := range subscribers {
+		subscribers[i] = &models.ServiceInfo{ServiceName: "order-service", PodName: "pod", NotificationURL: server.URL}
+	}
+
+	// The third delivery must wait for a slot instead of starting a goroutine
+	done := make(chan struct{})
+	go func() {
+		notif.NotifySubscribers(context.Background(), subscribers, payload)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Expected NotifySubscribers to wait for a free slot")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected NotifySubscribers to return once a slot was freed")
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	testCases := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 300 * time.Millisecond}, // Capped
+		{10, 300 * time.Millisecond},
+	}
+
+	for _, tc := range testCases {
+		delay := policy.backoff(tc.attempt)
+		if delay < tc.max/2 || delay >= tc.max {
+			t.Errorf("Attempt %d: expected delay in [%v, %v), got %v", tc.attempt, tc.max/2, tc.max, delay)
+		}
+	}
+}
+
 func TestNotifyMultipleSubscribers(t *testing.T) {
 	count := 0
 
@@ -228,7 +1024,7 @@ func TestNotifyMultipleSubscribers(t *testing.T) {
 		{NotificationURL: server.URL},
 	}
 
-	notif.NotifySubscribers(subscribers, payload)
+	notif.NotifySubscribers(context.Background(), subscribers, payload)
 
 	// Wait for async notifications to complete
 	time.Sleep(200 * time.Millisecond)
@@ -238,6 +1034,48 @@ func TestNotifyMultipleSubscribers(t *testing.T) {
 	}
 }
 
+func TestNotifySubscribersRoutesByEventType(t *testing.T) {
+	received := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notif := NewNotifier(5 * time.Second)
+	subscribers := []*models.ServiceInfo{{
+		ServiceName:      "alerting",
+		PodName:          "pod-1",
+		NotificationURL:  server.URL + "/notify",
+		NotificationURLs: map[models.EventType]string{models.EventTypeUpdate: server.URL + "/alerts"},
+	}}
+
+	testCases := []struct {
+		eventType models.EventType
+		path      string
+	}{
+		{models.EventTypeUpdate, "/alerts"},
+		{models.EventTypeRegister, "/notify"}, // No override, falls back to the default URL
+	}
+
+	for _, tc := range testCases {
+		notif.NotifySubscribers(context.Background(), subscribers, &models.NotificationPayload{
+			ServiceName: "user-service",
+			EventType:   tc.eventType,
+			Timestamp:   time.Now(),
+		})
+
+		select {
+		case path := <-received:
+			if path != tc.path {
+				t.Errorf("Expected %s notification at %s, got %s", tc.eventType, tc.path, path)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected a %s notification", tc.eventType)
+		}
+	}
+}
+
 func TestHealthCheckWith2xxStatusCodes(t *testing.T) {
 	testCases := []int{200, 201, 204, 299}
 
@@ -247,7 +1085,7 @@ func TestHealthCheckWith2xxStatusCodes(t *testing.T) {
 		}))
 
 		hc := NewHealthChecker(5*time.Second, 1)
-		healthy := hc.CheckHealth(server.URL)
+		healthy := hc.CheckHealth(context.Background(), server.URL)
 
 		server.Close()
 
@@ -266,7 +1104,7 @@ func TestHealthCheckWithNon2xxStatusCodes(t *testing.T) {
 		}))
 
 		hc := NewHealthChecker(1*time.Second, 1)
-		healthy := hc.CheckHealth(server.URL)
+		healthy := hc.CheckHealth(context.Background(), server.URL)
 
 		server.Close()
 
@@ -276,6 +1114,33 @@ func TestHealthCheckWithNon2xxStatusCodes(t *testing.T) {
 	}
 }
 
+func TestHealthCheckWithHealthyStatusCodes(t *testing.T) {
+	statusCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	hc := NewHealthChecker(1*time.Second, 0, WithHealthyStatusCodes(http.StatusOK, http.StatusServiceUnavailable))
+
+	testCases := []struct {
+		statusCode int
+		expected   bool
+	}{
+		{http.StatusOK, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusNoContent, false},
+		{http.StatusInternalServerError, false},
+	}
+
+	for _, tc := range testCases {
+		statusCode = tc.statusCode
+		if healthy := hc.CheckHealth(context.Background(), server.URL); healthy != tc.expected {
+			t.Errorf("Status %d: expected healthy=%v, got %v", tc.statusCode, tc.expected, healthy)
+		}
+	}
+}
+
 func TestHealthCheckRetry(t *testing.T) {
 	attempts := 0
 	maxRetries := 2
@@ -288,7 +1153,7 @@ func TestHealthCheckRetry(t *testing.T) {
 	defer server.Close()
 
 	hc := NewHealthChecker(500*time.Millisecond, maxRetries)
-	healthy := hc.CheckHealth(server.URL)
+	healthy := hc.CheckHealth(context.Background(), server.URL)
 
 	if healthy {
 		t.Error("Expected health check to fail")
@@ -300,3 +1165,199 @@ func TestHealthCheckRetry(t *testing.T) {
 		t.Errorf("Expected %d attempts, got %d", expectedAttempts, attempts)
 	}
 }
+
+func TestHealthCheckCancelledDuringBackoff(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	// Retries would back off 1s + 2s + 4s without cancellation
+	hc := NewHealthChecker(500*time.Millisecond, 3)
+	start := time.Now()
+	if hc.CheckHealth(ctx, server.URL) {
+		t.Error("Expected cancelled health check to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected health check to stop on cancellation, took %v", elapsed)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("Expected no retries after cancellation, got %d attempts", attempts.Load())
+	}
+}
+
+func TestNotificationHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	notif := NewNotifier(time.Second, WithHistorySize(2))
+	subscriber := &models.ServiceInfo{ServiceName: "subscriber", PodName: "pod-1", NotificationURL: server.URL}
+
+	for i := 1; i <= 3; i++ {
+		notif.NotifySubscribers(context.Background(), []*models.ServiceInfo{subscriber}, &models.NotificationPayload{
+			EventID:     uint64(i),
+			ServiceName: "test-service",
+			EventType:   models.EventTypeUpdate,
+			Timestamp:   time.Now(),
+		})
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	history := notif.GetNotificationHistory(subscriber.GetKey())
+	if len(history) != 2 {
+		t.Fatalf("Expected history bounded to 2 records, got %d", len(history))
+	}
+
+	// Newest first, oldest record evicted
+	if history[0].EventID != 3 || history[1].EventID != 2 {
+		t.Errorf("Expected event IDs [3 2], got [%d %d]", history[0].EventID, history[1].EventID)
+	}
+	if history[0].Success || history[0].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected failed delivery with status 503, got %+v", history[0])
+	}
+
+	if len(notif.GetNotificationHistory("unknown:pod")) != 0 {
+		t.Error("Expected empty history for unknown subscriber")
+	}
+}
+
+func TestLastNotifiedAt(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notif := NewNotifier(time.Second, WithHistorySize(1))
+	subscriber := &models.ServiceInfo{ServiceName: "subscriber", PodName: "pod-1", NotificationURL: server.URL}
+	notify := func() {
+		notif.NotifySubscribers(context.Background(), []*models.ServiceInfo{subscriber}, &models.NotificationPayload{
+			ServiceName: "test-service",
+			EventType:   models.EventTypeUpdate,
+			Timestamp:   time.Now(),
+		})
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if !notif.LastNotifiedAt(subscriber.GetKey()).IsZero() {
+		t.Fatal("Expected no last notification before any delivery")
+	}
+
+	before := time.Now()
+	notify()
+	delivered := notif.LastNotifiedAt(subscriber.GetKey())
+	if delivered.Before(before) {
+		t.Fatalf("Expected last notification after %v, got %v", before, delivered)
+	}
+
+	// Failed deliveries don't count, even once the success left the history
+	failing.Store(true)
+	notify()
+	if got := notif.LastNotifiedAt(subscriber.GetKey()); !got.Equal(delivered) {
+		t.Errorf("Expected last notification to stay %v after a failure, got %v", delivered, got)
+	}
+}
+
+func TestNotifierWithHTTPClient(t *testing.T) {
+	received := make(chan struct{}, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The TLS test server is only reachable through its own client
+	notif := NewNotifier(time.Second, WithHTTPClient(server.Client()))
+	if notif.httpClient != server.Client() {
+		t.Fatal("Expected injected HTTP client to be used")
+	}
+
+	notif.NotifySubscriber(context.Background(), server.URL, &models.NotificationPayload{ServiceName: "test-service"})
+
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Error("Notification was not received through injected client")
+	}
+
+	if NewNotifier(time.Second, WithHTTPClient(nil)).httpClient == nil {
+		t.Error("Expected default client when nil is injected")
+	}
+}
+
+func TestHealthCheckerWithHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	hc := NewHealthChecker(time.Second, 0, WithHealthCheckHTTPClient(server.Client()))
+	if !hc.CheckHealth(context.Background(), server.URL) {
+		t.Error("Expected health check to pass through injected client")
+	}
+
+	// Default client does not trust the test certificate
+	if NewHealthChecker(time.Second, 0).CheckHealth(context.Background(), server.URL) {
+		t.Error("Expected health check to fail with default client")
+	}
+}
+
+func TestNotificationTraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	traceparent := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent <- r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "event")
+	notif := NewNotifier(time.Second)
+	notif.NotifySubscriber(ctx, server.URL, &models.NotificationPayload{
+		ServiceName: "test-service",
+		EventType:   models.EventTypeUpdate,
+	})
+	parent.End()
+
+	select {
+	case header := <-traceparent:
+		if !strings.Contains(header, parent.SpanContext().TraceID().String()) {
+			t.Errorf("Expected traceparent in the trace %s, got %q", parent.SpanContext().TraceID(), header)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Notification was not received")
+	}
+
+	// The delivery span ends after the response is read
+	deadline := time.Now().Add(time.Second)
+	for len(recorder.Ended()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	var found bool
+	for _, span := range recorder.Ended() {
+		if span.Name() == "notify update" {
+			found = true
+			if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Error("Expected the delivery span to be a child of the event span")
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected a delivery span")
+	}
+}
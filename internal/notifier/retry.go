@@ -0,0 +1,49 @@
+package notifier
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// DefaultRetryBaseDelay is the delay before the first retry when RetryPolicy.BaseDelay is not set
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	// DefaultRetryMaxDelay caps the backoff when RetryPolicy.MaxDelay is not set
+	DefaultRetryMaxDelay = 10 * time.Second
+)
+
+// RetryPolicy controls how failed notifications are retried.
+// Connection errors and 5xx responses are retried; 4xx responses are not.
+// All attempts of a notification share a deadline of timeout * MaxAttempts.
+// The zero value disables retries.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first one (<= 1 disables retries)
+	BaseDelay   time.Duration // Delay before the first retry, doubled for each further retry
+	MaxDelay    time.Duration // Upper bound for a single backoff delay
+}
+
+// attempts returns the total number of attempts, at least 1
+func (p RetryPolicy) attempts() int {
+	return max(p.MaxAttempts, 1)
+}
+
+// backoff returns the delay after the given failed attempt (1-based):
+// exponential, capped at MaxDelay, with jitter in [delay/2, delay)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+
+	delay := base << uint(min(attempt-1, 30))
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half)
+}
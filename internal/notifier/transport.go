@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/chronnie/governance/models"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Transport delivers an encoded notification to a subscriber's notification URL.
+// Send returns a status code when the transport has one (HTTP), 0 otherwise, and an error
+// if delivery failed. Errors with status 0 or >= 500 are retried, see RetryPolicy.
+type Transport interface {
+	Send(ctx context.Context, target *url.URL, payload *models.NotificationPayload, body []byte) (int, error)
+}
+
+// HTTPTransport delivers notifications as JSON HTTP POST requests to the notification URL.
+// Notifications caused by an API request carry its ID in the X-Request-ID header, and
+// traced notifications carry the delivery span in the W3C traceparent header.
+type HTTPTransport struct {
+	client *http.Client
+}
+
+// NewHTTPTransport creates an HTTP transport using the given client
+func NewHTTPTransport(client *http.Client) *HTTPTransport {
+	return &HTTPTransport{client: client}
+}
+
+// Send performs a single notification request.
+// Returns the response status code (0 if no response was received) and an error
+// for transport failures and non-2xx responses.
+func (t *HTTPTransport) Send(ctx context.Context, target *url.URL, payload *models.NotificationPayload, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if payload.RequestID != "" {
+		req.Header.Set("X-Request-ID", payload.RequestID)
+	}
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, errors.New(http.StatusText(resp.StatusCode))
+	}
+	return resp.StatusCode, nil
+}
+
+// transportFor returns the transport registered for the URL's scheme
+func (n *Notifier) transportFor(notificationURL string) (Transport, *url.URL, error) {
+	target, err := url.Parse(notificationURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transport, ok := n.transports[strings.ToLower(target.Scheme)]
+	if !ok {
+		return nil, nil, fmt.Errorf("no transport for notification URL scheme %q", target.Scheme)
+	}
+	return transport, target, nil
+}
@@ -0,0 +1,139 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/models"
+)
+
+// rateWindowSeconds is the window EventsPerSecond is averaged over
+const rateWindowSeconds = 60
+
+// fullRetryInterval is how often a blocked Enqueue checks whether the queue has room again
+const fullRetryInterval = 10 * time.Millisecond
+
+// ErrQueueFull is returned by Enqueue when the queue has no room for the event
+var ErrQueueFull = errors.New("event queue is full")
+
+// InstrumentedQueue wraps an event queue and counts what flows through it.
+// go-event-queue only reports its current size, so enqueues are counted here and handlers
+// are wrapped to count processed events and how long they waited.
+// It also decides what happens when the queue is full: Enqueue waits up to fullTimeout for
+// room and then fails with ErrQueueFull, counting the rejected event.
+type InstrumentedQueue struct {
+	eventqueue.IEventQueue
+	capacity    int
+	fullTimeout time.Duration // 0 rejects immediately
+
+	enqueued  atomic.Uint64
+	rejected  atomic.Uint64
+	processed atomic.Uint64
+	lastWait  atomic.Int64 // Nanoseconds the last processed event waited
+	rate      rateWindow
+}
+
+var _ eventqueue.IEventQueue = (*InstrumentedQueue)(nil)
+
+// NewInstrumentedQueue wraps queue, whose buffer holds capacity events.
+// When the queue is full, Enqueue waits up to fullTimeout for room (0 = not at all).
+func NewInstrumentedQueue(queue eventqueue.IEventQueue, capacity int, fullTimeout time.Duration) *InstrumentedQueue {
+	return &InstrumentedQueue{IEventQueue: queue, capacity: capacity, fullTimeout: max(fullTimeout, 0)}
+}
+
+// Enqueue adds an event to the wrapped queue, counting it if accepted.
+// If the queue stays full for fullTimeout the event is rejected with ErrQueueFull.
+func (q *InstrumentedQueue) Enqueue(event eventqueue.IEvent) error {
+	deadline := time.Now().Add(q.fullTimeout)
+	for {
+		err := q.IEventQueue.Enqueue(event)
+		if err == nil {
+			q.enqueued.Add(1)
+			return nil
+		}
+		if !isQueueFull(err) {
+			return err
+		}
+		if !time.Now().Before(deadline) {
+			q.rejected.Add(1)
+			return fmt.Errorf("%w (capacity %d)", ErrQueueFull, q.capacity)
+		}
+		time.Sleep(min(fullRetryInterval, time.Until(deadline)))
+	}
+}
+
+// isQueueFull reports whether go-event-queue rejected an event for lack of room.
+// It only returns an unexported error string, so that is what we match.
+func isQueueFull(err error) bool {
+	return err.Error() == "queue is full"
+}
+
+// RegisterHandler registers the handler wrapped so processed events are counted
+func (q *InstrumentedQueue) RegisterHandler(eventType string, handler eventqueue.IEventHandler) {
+	q.IEventQueue.RegisterHandler(eventType, eventqueue.EventHandlerFunc(func(ctx context.Context, event eventqueue.IEvent) error {
+		now := time.Now()
+		q.lastWait.Store(int64(now.Sub(event.GetTimestamp())))
+
+		err := handler.Handle(ctx, event)
+
+		q.processed.Add(1)
+		q.rate.add(now)
+		return err
+	}))
+}
+
+// Stats returns the current queue load
+func (q *InstrumentedQueue) Stats() models.QueueStats {
+	return models.QueueStats{
+		Size:            q.GetQueueSize(),
+		Capacity:        q.capacity,
+		Enqueued:        q.enqueued.Load(),
+		Rejected:        q.rejected.Load(),
+		Processed:       q.processed.Load(),
+		EventsPerSecond: q.rate.perSecond(time.Now()),
+		LagMillis:       time.Duration(q.lastWait.Load()).Milliseconds(),
+	}
+}
+
+// rateWindow counts events in one-second buckets over the last rateWindowSeconds
+type rateWindow struct {
+	mu      sync.Mutex
+	counts  [rateWindowSeconds]uint64
+	seconds [rateWindowSeconds]int64 // Unix second each bucket currently counts
+}
+
+// add counts an event at the given time
+func (r *rateWindow) add(now time.Time) {
+	second := now.Unix()
+	i := second % rateWindowSeconds
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.seconds[i] != second {
+		r.seconds[i] = second
+		r.counts[i] = 0
+	}
+	r.counts[i]++
+}
+
+// perSecond returns the average events per second over the window ending at now
+func (r *rateWindow) perSecond(now time.Time) float64 {
+	oldest := now.Unix() - rateWindowSeconds
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var total uint64
+	for i, second := range r.seconds {
+		if second > oldest {
+			total += r.counts[i]
+		}
+	}
+	return float64(total) / rateWindowSeconds
+}
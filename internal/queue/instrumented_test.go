@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+)
+
+func TestInstrumentedQueueStats(t *testing.T) {
+	q := NewInstrumentedQueue(eventqueue.NewEventQueue(eventqueue.EventQueueConfig{BufferSize: 10}), 10, 0)
+
+	processed := make(chan struct{}, 10)
+	q.RegisterHandler(string(events.EventReconcile), eventqueue.EventHandlerFunc(func(ctx context.Context, event eventqueue.IEvent) error {
+		processed <- struct{}{}
+		return nil
+	}))
+
+	q.Start(context.Background())
+	defer q.Stop()
+
+	for range 3 {
+		if err := q.Enqueue(eventqueue.NewEvent(string(events.EventReconcile), events.NewReconcileContext())); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+	for range 3 {
+		select {
+		case <-processed:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected events to be processed")
+		}
+	}
+
+	stats := q.Stats()
+	if stats.Capacity != 10 || stats.Enqueued != 3 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	// The counter is updated after the handler returns
+	deadline := time.Now().Add(time.Second)
+	for q.Stats().Processed < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if stats := q.Stats(); stats.Processed != 3 || stats.EventsPerSecond != 3.0/rateWindowSeconds {
+		t.Errorf("Expected 3 processed events, got %+v", stats)
+	}
+}
+
+// fullQueue returns a started queue with a buffer of 1 that is full: one event is blocked
+// in its handler until release is closed and one is waiting
+func fullQueue(t *testing.T, fullTimeout time.Duration) (*InstrumentedQueue, chan struct{}) {
+	q := NewInstrumentedQueue(eventqueue.NewEventQueue(eventqueue.EventQueueConfig{BufferSize: 1}), 1, fullTimeout)
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	q.RegisterHandler(string(events.EventReconcile), eventqueue.EventHandlerFunc(func(ctx context.Context, event eventqueue.IEvent) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}))
+	q.Start(context.Background())
+
+	q.Enqueue(eventqueue.NewEvent(string(events.EventReconcile), events.NewReconcileContext()))
+	<-started
+	if err := q.Enqueue(eventqueue.NewEvent(string(events.EventReconcile), events.NewReconcileContext())); err != nil {
+		t.Fatalf("Failed to fill queue: %v", err)
+	}
+	return q, release
+}
+
+func TestInstrumentedQueueRejectsWhenFull(t *testing.T) {
+	q, release := fullQueue(t, 0)
+	defer q.Stop()
+	defer close(release)
+
+	err := q.Enqueue(eventqueue.NewEvent(string(events.EventReconcile), events.NewReconcileContext()))
+	if !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Expected ErrQueueFull, got %v", err)
+	}
+	if stats := q.Stats(); stats.Rejected != 1 || stats.Enqueued != 2 {
+		t.Errorf("Expected 1 rejected and 2 enqueued events, got %+v", stats)
+	}
+}
+
+func TestInstrumentedQueueWaitsForRoom(t *testing.T) {
+	q, release := fullQueue(t, 2*time.Second)
+	defer q.Stop()
+
+	time.AfterFunc(50*time.Millisecond, func() { close(release) })
+
+	if err := q.Enqueue(eventqueue.NewEvent(string(events.EventReconcile), events.NewReconcileContext())); err != nil {
+		t.Fatalf("Expected enqueue to wait for room, got %v", err)
+	}
+	if stats := q.Stats(); stats.Rejected != 0 || stats.Enqueued != 3 {
+		t.Errorf("Expected 3 enqueued events, got %+v", stats)
+	}
+}
+
+func TestRateWindow(t *testing.T) {
+	var r rateWindow
+	now := time.Unix(1000, 0)
+
+	for range 30 {
+		r.add(now)
+	}
+	r.add(now.Add(-90 * time.Second)) // Outside the window
+
+	if rate := r.perSecond(now); rate != 0.5 {
+		t.Errorf("Expected 0.5 events per second, got %v", rate)
+	}
+	if rate := r.perSecond(now.Add(rateWindowSeconds * time.Second)); rate != 0 {
+		t.Errorf("Expected old events to fall out of the window, got %v", rate)
+	}
+}
@@ -0,0 +1,121 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/models"
+)
+
+// ShardedQueue processes events on several independent sequential queues.
+// Events are routed by service name, so all events of one service are handled in FIFO
+// order by the same worker while unrelated services are processed concurrently.
+// Events without a service (reconcile) go to the first shard.
+type ShardedQueue struct {
+	shards []eventqueue.IEventQueue
+}
+
+var _ eventqueue.IEventQueue = (*ShardedQueue)(nil)
+
+// NewShardedQueue creates a queue with the given number of shards (at least 1).
+// config.BufferSize is split evenly between shards so total capacity stays the same.
+func NewShardedQueue(config eventqueue.EventQueueConfig, shards int) *ShardedQueue {
+	shards = max(shards, 1)
+
+	shardConfig := eventqueue.EventQueueConfig{
+		BufferSize:     max((config.BufferSize+shards-1)/shards, 1),
+		ProcessingMode: eventqueue.Sequential,
+	}
+
+	q := &ShardedQueue{shards: make([]eventqueue.IEventQueue, shards)}
+	for i := range q.shards {
+		q.shards[i] = eventqueue.NewEventQueue(shardConfig)
+	}
+	return q
+}
+
+// Enqueue adds an event to the shard of its service
+func (q *ShardedQueue) Enqueue(event eventqueue.IEvent) error {
+	return q.shards[q.shardFor(event)].Enqueue(event)
+}
+
+// Start starts every shard
+func (q *ShardedQueue) Start(ctx context.Context) error {
+	for _, shard := range q.shards {
+		if err := shard.Start(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop stops every shard concurrently, returning once all have drained
+func (q *ShardedQueue) Stop() error {
+	errs := make([]error, len(q.shards))
+
+	var wg sync.WaitGroup
+	for i, shard := range q.shards {
+		wg.Go(func() {
+			errs[i] = shard.Stop()
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// RegisterHandler registers the handler on every shard
+func (q *ShardedQueue) RegisterHandler(eventType string, handler eventqueue.IEventHandler) {
+	for _, shard := range q.shards {
+		shard.RegisterHandler(eventType, handler)
+	}
+}
+
+// GetQueueSize returns the number of events waiting across all shards
+func (q *ShardedQueue) GetQueueSize() int {
+	size := 0
+	for _, shard := range q.shards {
+		size += shard.GetQueueSize()
+	}
+	return size
+}
+
+// shardFor returns the index of the shard handling the event's service
+func (q *ShardedQueue) shardFor(event eventqueue.IEvent) int {
+	serviceName := serviceNameOf(event)
+	if serviceName == "" || len(q.shards) == 1 {
+		return 0
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(serviceName))
+	return int(h.Sum32() % uint32(len(q.shards)))
+}
+
+// serviceNameOf extracts the service an event applies to, or "" for global events
+func serviceNameOf(event eventqueue.IEvent) string {
+	switch data := events.GetEventData(event.GetContext()).(type) {
+	case *events.RegisterEvent:
+		return data.Registration.QualifiedName()
+	case *events.UnregisterEvent:
+		return data.ServiceName
+	case *events.UnregisterServiceEvent:
+		return data.ServiceName
+	case *events.UpdateEvent:
+		return data.ServiceName
+	case *events.HeartbeatEvent:
+		return data.ServiceName
+	case *events.HealthCheckEvent:
+		serviceName, _, _ := models.ParseServiceKey(data.ServiceKey)
+		return serviceName
+	case *events.EvictSubscriberEvent:
+		serviceName, _, _ := models.ParseServiceKey(data.SubscriberKey)
+		return serviceName
+	default:
+		return ""
+	}
+}
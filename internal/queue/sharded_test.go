@@ -0,0 +1,89 @@
+package queue
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/models"
+)
+
+func TestShardedQueueRoutesByService(t *testing.T) {
+	q := NewShardedQueue(eventqueue.EventQueueConfig{BufferSize: 100}, 4)
+
+	register := eventqueue.NewEvent(string(events.EventRegister), events.NewRegisterContext(&models.ServiceRegistration{ServiceName: "user-service", PodName: "pod-1"}))
+	healthCheck := eventqueue.NewEvent(string(events.EventHealthCheck), events.NewHealthCheckContext(context.Background(), "user-service:pod-2"))
+	heartbeat := eventqueue.NewEvent(string(events.EventHeartbeat), events.NewHeartbeatContext("user-service", "pod-1"))
+	if q.shardFor(register) != q.shardFor(healthCheck) || q.shardFor(register) != q.shardFor(heartbeat) {
+		t.Error("Expected events of the same service on the same shard")
+	}
+
+	// Namespaced services are routed by their qualified name, whatever the event carries
+	namespaced := eventqueue.NewEvent(string(events.EventRegister), events.NewRegisterContext(&models.ServiceRegistration{Namespace: "team-a", ServiceName: "user-service", PodName: "pod-1"}))
+	unregister := eventqueue.NewEvent(string(events.EventUnregister), events.NewUnregisterContext("team-a/user-service", "pod-1"))
+	namespacedHealthCheck := eventqueue.NewEvent(string(events.EventHealthCheck), events.NewHealthCheckContext(context.Background(), "team-a/user-service:pod-1"))
+	if q.shardFor(namespaced) != q.shardFor(unregister) || q.shardFor(namespaced) != q.shardFor(namespacedHealthCheck) {
+		t.Error("Expected events of the same namespaced service on the same shard")
+	}
+
+	reconcile := eventqueue.NewEvent(string(events.EventReconcile), events.NewReconcileContext())
+	if q.shardFor(reconcile) != 0 {
+		t.Errorf("Expected reconcile on shard 0, got %d", q.shardFor(reconcile))
+	}
+}
+
+func TestShardedQueueProcessesServicesConcurrently(t *testing.T) {
+	q := NewShardedQueue(eventqueue.EventQueueConfig{BufferSize: 100}, 8)
+
+	// Find two services on different shards
+	slowService, fastService := "service-0", ""
+	slowShard := q.shardFor(eventqueue.NewEvent("", events.NewUnregisterServiceContext(slowService)))
+	for i := 1; fastService == ""; i++ {
+		name := "service-" + strconv.Itoa(i)
+		if q.shardFor(eventqueue.NewEvent("", events.NewUnregisterServiceContext(name))) != slowShard {
+			fastService = name
+		}
+	}
+
+	release := make(chan struct{})
+	fastDone := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+
+	q.RegisterHandler(string(events.EventUnregisterService), eventqueue.EventHandlerFunc(func(ctx context.Context, event eventqueue.IEvent) error {
+		serviceName := events.GetEventData(ctx).(*events.UnregisterServiceEvent).ServiceName
+		if serviceName == slowService {
+			<-release
+		}
+		mu.Lock()
+		order = append(order, serviceName)
+		mu.Unlock()
+		if serviceName == fastService {
+			close(fastDone)
+		}
+		return nil
+	}))
+	q.Start(context.Background())
+
+	q.Enqueue(eventqueue.NewEvent(string(events.EventUnregisterService), events.NewUnregisterServiceContext(slowService)))
+	q.Enqueue(eventqueue.NewEvent(string(events.EventUnregisterService), events.NewUnregisterServiceContext(fastService)))
+
+	// The fast service must not wait behind the blocked one
+	select {
+	case <-fastDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected unrelated service to be processed while another shard is blocked")
+	}
+	close(release)
+
+	if err := q.Stop(); err != nil {
+		t.Fatalf("Unexpected stop error: %v", err)
+	}
+	if len(order) != 2 || order[0] != fastService {
+		t.Errorf("Expected %s to finish first, got %v", fastService, order)
+	}
+}
@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"sync"
+
+	"github.com/chronnie/governance/models"
+)
+
+// DefaultStatusHistorySize is the number of status transitions kept per pod
+const DefaultStatusHistorySize = 20
+
+// statusHistory keeps a bounded ring buffer of recent status transitions per pod.
+// Rings are dropped when their pod is deleted, so memory stays bounded by the number of
+// registered pods. It is written from event workers, so access is guarded by a mutex.
+type statusHistory struct {
+	mu          sync.Mutex
+	size        int
+	transitions map[string]*transitionRing // Key: service key
+}
+
+// transitionRing is a fixed-size ring buffer of status transitions
+type transitionRing struct {
+	transitions []models.StatusTransition
+	next        int
+	full        bool
+}
+
+func newStatusHistory(size int) *statusHistory {
+	if size <= 0 {
+		size = DefaultStatusHistorySize
+	}
+	return &statusHistory{
+		size:        size,
+		transitions: make(map[string]*transitionRing),
+	}
+}
+
+// add appends a transition to the pod's ring, overwriting the oldest entry when full
+func (h *statusHistory) add(key string, transition models.StatusTransition) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ring, exists := h.transitions[key]
+	if !exists {
+		ring = &transitionRing{transitions: make([]models.StatusTransition, h.size)}
+		h.transitions[key] = ring
+	}
+
+	ring.transitions[ring.next] = transition
+	ring.next = (ring.next + 1) % h.size
+	if ring.next == 0 {
+		ring.full = true
+	}
+}
+
+// get returns the pod's transitions, newest first
+func (h *statusHistory) get(key string) []models.StatusTransition {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ring, exists := h.transitions[key]
+	if !exists {
+		return []models.StatusTransition{}
+	}
+
+	count := ring.next
+	if ring.full {
+		count = h.size
+	}
+
+	result := make([]models.StatusTransition, 0, count)
+	for i := 1; i <= count; i++ {
+		result = append(result, ring.transitions[(ring.next-i+h.size)%h.size])
+	}
+	return result
+}
+
+// forget drops the pod's transitions once it is deleted
+func (h *statusHistory) forget(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.transitions, key)
+}
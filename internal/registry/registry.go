@@ -2,58 +2,207 @@ package registry
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/chronnie/governance/internal/clock"
 	"github.com/chronnie/governance/models"
 	"github.com/chronnie/governance/pkg/logger"
 	"github.com/chronnie/governance/storage"
 	"go.uber.org/zap"
 )
 
-// Registry manages all registered services using a pluggable storage backend
-// No locks needed because it's accessed only by single event queue worker
+// Registry manages all registered services using a pluggable storage backend.
+// Apart from a revision counter and the status history it keeps no state of its own, so it
+// is as safe for concurrent use as its store; storage.DualStore and memory.MemoryStore are.
+// Every method takes the caller's context, which is passed to the store so that request
+// deadlines and cancellation reach the database.
+// Methods taking a service name expect the qualified name (see models.QualifiedServiceName),
+// which is the plain service name in the default namespace.
 type Registry struct {
 	store storage.RegistryStore
-	ctx   context.Context
+	clock clock.Clock
+
+	// Unregistered pods are kept as tombstones for this long before PurgeTombstones
+	// removes them (0 = delete immediately)
+	tombstoneGracePeriod time.Duration
+
+	// Most service groups one pod may subscribe to (0 = unlimited)
+	maxSubscriptions int
+
+	// Most live pods per namespace, see WithNamespaceQuotas (0 = unlimited)
+	namespaceQuotas       map[string]int
+	defaultNamespaceQuota int
+
+	// Incremented by every mutation, see Revision
+	revision atomic.Int64
+
+	// Recent status transitions per pod, see GetStatusHistory
+	statusHistorySize int
+	history           *statusHistory
+}
+
+// RegistryOption configures optional Registry settings
+type RegistryOption func(*Registry)
+
+// WithTombstoneGracePeriod enables soft-delete: Unregister marks a pod as a tombstone
+// instead of deleting it, and PurgeTombstones deletes it once the grace period has passed.
+// Tombstones are hidden from Get, GetByServiceName and GetAllServices but returned by
+// GetTombstones, so clients can tell a pod that just left from one that never existed.
+func WithTombstoneGracePeriod(gracePeriod time.Duration) RegistryOption {
+	return func(r *Registry) {
+		r.tombstoneGracePeriod = gracePeriod
+	}
+}
+
+// WithMaxSubscriptions caps how many service groups one pod may subscribe to, so a single
+// subscriber can't make every reconcile fan out to thousands of groups. Register rejects
+// registrations over the limit; Restore doesn't apply it. 0 means no limit.
+func WithMaxSubscriptions(max int) RegistryOption {
+	return func(r *Registry) {
+		r.maxSubscriptions = max
+	}
+}
+
+// WithNamespaceQuotas caps how many live pods each namespace (tenant) may register, so one
+// tenant can't exhaust the registry. quotas maps namespaces ("" is the default namespace) to
+// their limit, the others get defaultQuota; 0 means no limit. Register rejects new pods over
+// the limit with ErrQuotaExceeded, while pods already registered can always re-register.
+// Restore doesn't apply it. The limit is soft: registrations of the same namespace handled
+// concurrently may overshoot it.
+func WithNamespaceQuotas(quotas map[string]int, defaultQuota int) RegistryOption {
+	return func(r *Registry) {
+		r.namespaceQuotas = quotas
+		r.defaultNamespaceQuota = defaultQuota
+	}
+}
+
+// WithStatusHistorySize sets how many recent status transitions are kept per pod.
+// Values <= 0 use DefaultStatusHistorySize.
+func WithStatusHistorySize(size int) RegistryOption {
+	return func(r *Registry) {
+		r.statusHistorySize = size
+	}
+}
+
+// WithClock makes the registry take registration, expiry, health check and tombstone times
+// from c instead of the system clock
+func WithClock(c clock.Clock) RegistryOption {
+	return func(r *Registry) {
+		r.clock = c
+	}
 }
 
 // NewRegistry creates a new registry with the given storage backend
-func NewRegistry(store storage.RegistryStore) *Registry {
-	return &Registry{
+func NewRegistry(store storage.RegistryStore, opts ...RegistryOption) *Registry {
+	r := &Registry{
 		store: store,
-		ctx:   context.Background(),
+		clock: clock.Real,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.history = newStatusHistory(r.statusHistorySize)
+	// Starting at the current time in microseconds keeps revisions increasing across
+	// restarts, so services loaded from a database have lower revisions than new writes,
+	// while staying exact in JSON numbers
+	r.revision.Store(r.clock.Now().UnixMicro())
+	return r
+}
+
+// Revision returns the registry's monotonic revision. It increases with every mutation, so
+// callers can tell whether anything may have changed without comparing services, e.g. for
+// ETags, and every write of a service stamps the service's Revision from it. Revisions are
+// not contiguous: a mutation may advance it by more than one.
+// It is bumped again after a mutation is stored: a read that saw the new data may report the
+// old revision, but never the other way around.
+func (r *Registry) Revision() int64 {
+	return r.revision.Load()
+}
+
+// Invalidate bumps the revision after the store was changed without going through the
+// registry, e.g. by a database sync
+func (r *Registry) Invalidate() {
+	r.revision.Add(1)
+}
+
+// save stamps a new revision on service and stores it
+func (r *Registry) save(ctx context.Context, service *models.ServiceInfo) error {
+	service.Revision = r.revision.Add(1)
+	return r.store.SaveService(ctx, service)
 }
 
-// Register adds or updates a service in the registry
-func (r *Registry) Register(reg *models.ServiceRegistration) *models.ServiceInfo {
+// ErrQuotaExceeded is returned (wrapped) when registering a new pod would take its namespace
+// over its quota, see WithNamespaceQuotas
+var ErrQuotaExceeded = errors.New("namespace quota exceeded")
+
+// Register adds or updates a service in the registry.
+// Returns an error if the service could not be stored, e.g. a failed database write in sync mode,
+// if it subscribes to more service groups than allowed (see CheckSubscriptions) or if its
+// namespace is full (see CheckQuota).
+func (r *Registry) Register(ctx context.Context, reg *models.ServiceRegistration) (*models.ServiceInfo, error) {
+	defer r.Invalidate()
+
 	logger.Debug("Registry: Register called",
 		zap.String("service_name", reg.ServiceName),
 		zap.String("pod_name", reg.PodName),
 		zap.Int("subscriptions_count", len(reg.Subscriptions)),
 	)
 
+	if err := r.CheckSubscriptions(reg.Subscriptions); err != nil {
+		logger.Warn("Registry: Registration rejected",
+			zap.String("service_key", models.ServiceKey(reg.QualifiedName(), reg.PodName)),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	if err := r.CheckQuota(ctx, reg, 0); err != nil {
+		logger.Warn("Registry: Registration rejected",
+			zap.String("service_key", models.ServiceKey(reg.QualifiedName(), reg.PodName)),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
 	serviceInfo := &models.ServiceInfo{
-		ServiceName:     reg.ServiceName,
-		PodName:         reg.PodName,
-		Providers:       reg.Providers,
-		HealthCheckURL:  reg.HealthCheckURL,
-		NotificationURL: reg.NotificationURL,
-		Subscriptions:   reg.Subscriptions,
-		Status:          models.StatusUnknown, // Initial status is unknown
-		RegisteredAt:    time.Now(),
-		LastHealthCheck: time.Time{},
+		Namespace:          reg.Namespace,
+		ServiceName:        reg.ServiceName,
+		PodName:            reg.PodName,
+		Providers:          reg.Providers,
+		HealthCheckURL:     reg.HealthCheckURL,
+		HealthCheckMethod:  reg.HealthCheckMethod,
+		HealthCheckHeaders: reg.HealthCheckHeaders,
+		HealthCheckMatch:   reg.HealthCheckMatch,
+		HealthChecks:       reg.HealthChecks,
+		HealthCheckMode:    reg.HealthCheckMode,
+		UDPProbe:           reg.UDPProbe,
+		NotificationURL:    reg.NotificationURL,
+		NotificationURLs:   reg.NotificationURLs,
+		Subscriptions:      reg.Subscriptions,
+		NotificationFormat: reg.NotificationFormat,
+		Labels:             reg.Labels,
+		TTLSeconds:         reg.TTLSeconds,
+		Status:             models.StatusUnknown, // Initial status is unknown
+		RegisteredAt:       r.clock.Now(),
+		LastHealthCheck:    time.Time{},
+	}
+	if reg.TTLSeconds > 0 {
+		serviceInfo.ExpiresAt = serviceInfo.RegisteredAt.Add(time.Duration(reg.TTLSeconds) * time.Second)
 	}
 
 	key := serviceInfo.GetKey()
 
 	// Remove old subscriptions if service already exists
-	if oldService, err := r.store.GetService(r.ctx, key); err == nil {
+	if oldService, err := r.store.GetService(ctx, key); err == nil {
 		logger.Debug("Registry: Service already exists, removing old subscriptions",
 			zap.String("service_key", key),
 			zap.Int("old_subscriptions_count", len(oldService.Subscriptions)),
 		)
-		r.removeSubscriptions(key, oldService.Subscriptions)
+		r.removeSubscriptions(ctx, key, oldService.SubscriptionGroups())
 	} else {
 		logger.Debug("Registry: New service registration",
 			zap.String("service_key", key),
@@ -61,12 +210,12 @@ func (r *Registry) Register(reg *models.ServiceRegistration) *models.ServiceInfo
 	}
 
 	// Save service to storage
-	if err := r.store.SaveService(r.ctx, serviceInfo); err != nil {
+	if err := r.save(ctx, serviceInfo); err != nil {
 		logger.Error("Registry: Failed to save service to storage",
 			zap.String("service_key", key),
 			zap.Error(err),
 		)
-		return serviceInfo
+		return nil, err
 	}
 
 	logger.Debug("Registry: Service saved to storage",
@@ -79,7 +228,7 @@ func (r *Registry) Register(reg *models.ServiceRegistration) *models.ServiceInfo
 			zap.String("service_key", key),
 			zap.Strings("subscriptions", reg.Subscriptions),
 		)
-		r.addSubscriptions(key, reg.Subscriptions)
+		r.addSubscriptions(ctx, key, serviceInfo.SubscriptionGroups())
 	}
 
 	logger.Info("Registry: Service registered successfully",
@@ -87,18 +236,183 @@ func (r *Registry) Register(reg *models.ServiceRegistration) *models.ServiceInfo
 		zap.Int("subscriptions_count", len(reg.Subscriptions)),
 	)
 
-	return serviceInfo
+	return serviceInfo, nil
 }
 
-// Unregister removes a service from the registry
-func (r *Registry) Unregister(serviceName, podName string) *models.ServiceInfo {
-	key := serviceName + ":" + podName
+// Refresh re-registers a pod whose registration is identical to the stored one without
+// rebuilding its subscriptions: only RegisteredAt and, with a TTL, ExpiresAt are renewed,
+// while the health status is kept.
+// Returns the refreshed service, or nil and no error if the pod is not registered or reg
+// differs from its registration, in which case it needs a full Register.
+func (r *Registry) Refresh(ctx context.Context, reg *models.ServiceRegistration) (*models.ServiceInfo, error) {
+	defer r.Invalidate()
+
+	key := models.ServiceKey(reg.QualifiedName(), reg.PodName)
+	service, exists := r.Get(ctx, key)
+	if !exists || !service.Registration().Equal(reg) {
+		return nil, nil
+	}
+
+	service.RegisteredAt = r.clock.Now()
+	if service.TTLSeconds > 0 {
+		service.ExpiresAt = service.RegisteredAt.Add(time.Duration(service.TTLSeconds) * time.Second)
+	}
+	if err := r.save(ctx, service); err != nil {
+		logger.Error("Registry: Failed to save refreshed service to storage",
+			zap.String("service_key", key),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	logger.Info("Registry: Service re-registered with identical data, refreshed",
+		zap.String("service_key", key),
+	)
+	return service, nil
+}
+
+// Update applies a partial update to a registered pod in place.
+// Unlike Register it keeps RegisteredAt, the health status and subscriptions.
+// Returns the updated service, or nil if the pod is not registered.
+func (r *Registry) Update(ctx context.Context, serviceName, podName string, update *models.ServiceUpdate) *models.ServiceInfo {
+	defer r.Invalidate()
+
+	key := models.ServiceKey(serviceName, podName)
+
+	logger.Debug("Registry: Update called",
+		zap.String("service_key", key),
+	)
+
+	service, exists := r.Get(ctx, key)
+	if !exists {
+		logger.Warn("Registry: Service not found for update",
+			zap.String("service_key", key),
+		)
+		return nil
+	}
+
+	update.Apply(service)
+
+	if err := r.save(ctx, service); err != nil {
+		logger.Error("Registry: Failed to save updated service to storage",
+			zap.String("service_key", key),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	logger.Info("Registry: Service updated successfully",
+		zap.String("service_key", key),
+	)
+
+	return service
+}
+
+// RemoveAllSubscriptions detaches a pod from every service group it subscribed to, keeping
+// the pod itself registered. Its subscriptions are cleared so they don't come back from the
+// database after a restart.
+// Returns the groups it was detached from, or nil and no error if the pod is not registered.
+func (r *Registry) RemoveAllSubscriptions(ctx context.Context, key string) ([]string, error) {
+	defer r.Invalidate()
+
+	service, exists := r.Get(ctx, key)
+	if !exists {
+		logger.Warn("Registry: Service not found for subscription removal",
+			zap.String("service_key", key),
+		)
+		return nil, nil
+	}
+
+	groups := service.Subscriptions
+	if err := r.store.RemoveAllSubscriptions(ctx, key); err != nil {
+		logger.Error("Registry: Failed to remove subscriptions from storage",
+			zap.String("service_key", key),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	service.Subscriptions = nil
+	if err := r.save(ctx, service); err != nil {
+		logger.Error("Registry: Failed to save service without subscriptions to storage",
+			zap.String("service_key", key),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	logger.Info("Registry: Subscriptions removed",
+		zap.String("service_key", key),
+		zap.Strings("service_groups", groups),
+	)
+
+	if groups == nil {
+		groups = []string{}
+	}
+	return groups, nil
+}
+
+// Renew pushes back the expiry of a pod registered with a TTL by one TTL from now.
+// Pods without a TTL are returned unchanged. Returns nil if the pod is not registered.
+func (r *Registry) Renew(ctx context.Context, serviceName, podName string) *models.ServiceInfo {
+	defer r.Invalidate()
+
+	key := models.ServiceKey(serviceName, podName)
+
+	service, exists := r.Get(ctx, key)
+	if !exists {
+		logger.Warn("Registry: Service not found for heartbeat",
+			zap.String("service_key", key),
+		)
+		return nil
+	}
+	if service.TTLSeconds <= 0 {
+		return service
+	}
+
+	service.ExpiresAt = r.clock.Now().Add(time.Duration(service.TTLSeconds) * time.Second)
+	if err := r.save(ctx, service); err != nil {
+		logger.Error("Registry: Failed to save renewed service to storage",
+			zap.String("service_key", key),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	logger.Debug("Registry: Service TTL renewed",
+		zap.String("service_key", key),
+		zap.Time("expires_at", service.ExpiresAt),
+	)
+
+	return service
+}
+
+// GetExpired returns the pods whose TTL lapsed without a heartbeat
+func (r *Registry) GetExpired(ctx context.Context, now time.Time) []*models.ServiceInfo {
+	result := make([]*models.ServiceInfo, 0)
+	for _, service := range r.GetAllServices(ctx) {
+		if service.IsExpired(now) {
+			result = append(result, service)
+		}
+	}
+	return result
+}
+
+// Unregister removes a service from the registry, or turns it into a tombstone if
+// soft-delete is enabled. Returns the service as it is after unregistering, nil if not found.
+func (r *Registry) Unregister(ctx context.Context, serviceName, podName string) *models.ServiceInfo {
+	defer r.Invalidate()
+
+	key := models.ServiceKey(serviceName, podName)
 
 	logger.Debug("Registry: Unregister called",
 		zap.String("service_key", key),
 	)
 
-	service, err := r.store.GetService(r.ctx, key)
+	service, err := r.store.GetService(ctx, key)
+	if err == nil && service.IsTombstone() {
+		err = fmt.Errorf("service already unregistered: %s", key)
+	}
 	if err != nil {
 		logger.Warn("Registry: Service not found for unregistration",
 			zap.String("service_key", key),
@@ -113,16 +427,35 @@ func (r *Registry) Unregister(serviceName, podName string) *models.ServiceInfo {
 			zap.String("service_key", key),
 			zap.Int("subscriptions_count", len(service.Subscriptions)),
 		)
-		r.removeSubscriptions(key, service.Subscriptions)
+		r.removeSubscriptions(ctx, key, service.SubscriptionGroups())
+	}
+
+	// Keep a tombstone until the grace period ends
+	if r.tombstoneGracePeriod > 0 {
+		service.Status = models.StatusTombstone
+		service.DeletedAt = r.clock.Now()
+		if err := r.save(ctx, service); err != nil {
+			logger.Error("Registry: Failed to save tombstone to storage",
+				zap.String("service_key", key),
+				zap.Error(err),
+			)
+		} else {
+			logger.Info("Registry: Service unregistered, tombstone kept",
+				zap.String("service_key", key),
+				zap.Duration("grace_period", r.tombstoneGracePeriod),
+			)
+		}
+		return service
 	}
 
 	// Remove from storage
-	if err := r.store.DeleteService(r.ctx, key); err != nil {
+	if err := r.store.DeleteService(ctx, key); err != nil {
 		logger.Error("Registry: Failed to delete service from storage",
 			zap.String("service_key", key),
 			zap.Error(err),
 		)
 	} else {
+		r.history.forget(key)
 		logger.Debug("Registry: Service deleted from storage",
 			zap.String("service_key", key),
 		)
@@ -136,40 +469,179 @@ func (r *Registry) Unregister(serviceName, podName string) *models.ServiceInfo {
 }
 
 // Get retrieves a service by key
-func (r *Registry) Get(key string) (*models.ServiceInfo, bool) {
-	service, err := r.store.GetService(r.ctx, key)
-	if err != nil {
+func (r *Registry) Get(ctx context.Context, key string) (*models.ServiceInfo, bool) {
+	service, err := r.store.GetService(ctx, key)
+	if err != nil || service.IsTombstone() {
 		return nil, false
 	}
 	return service, true
 }
 
 // GetByServiceName returns all pods of a service
-func (r *Registry) GetByServiceName(serviceName string) []*models.ServiceInfo {
-	result, err := r.store.GetServicesByName(r.ctx, serviceName)
+func (r *Registry) GetByServiceName(ctx context.Context, serviceName string) []*models.ServiceInfo {
+	result, err := r.store.GetServicesByName(ctx, serviceName)
 	if err != nil {
 		return []*models.ServiceInfo{}
 	}
-	return result
+	return withoutTombstones(result)
 }
 
 // GetAllServices returns all registered services
-func (r *Registry) GetAllServices() []*models.ServiceInfo {
-	result, err := r.store.GetAllServices(r.ctx)
+func (r *Registry) GetAllServices(ctx context.Context) []*models.ServiceInfo {
+	result, err := r.store.GetAllServices(ctx)
+	if err != nil {
+		return []*models.ServiceInfo{}
+	}
+	return withoutTombstones(result)
+}
+
+// GetByStatus returns all pods with the given health status.
+// Unregistered pods kept as tombstones only match StatusTombstone.
+func (r *Registry) GetByStatus(ctx context.Context, status models.ServiceStatus) []*models.ServiceInfo {
+	result, err := r.store.GetServicesByStatus(ctx, status)
+	if err != nil {
+		return []*models.ServiceInfo{}
+	}
+	return result
+}
+
+// GetTombstones returns the unregistered pods still kept for the soft-delete grace period
+func (r *Registry) GetTombstones(ctx context.Context) []*models.ServiceInfo {
+	all, err := r.store.GetAllServices(ctx)
 	if err != nil {
 		return []*models.ServiceInfo{}
 	}
+
+	result := make([]*models.ServiceInfo, 0)
+	for _, service := range all {
+		if service.IsTombstone() {
+			result = append(result, service)
+		}
+	}
+	return result
+}
+
+// PurgeTombstones deletes tombstones whose grace period has passed and returns how many
+func (r *Registry) PurgeTombstones(ctx context.Context) int {
+	defer r.Invalidate()
+
+	purged := 0
+	for _, service := range r.GetTombstones(ctx) {
+		if r.clock.Now().Sub(service.DeletedAt) < r.tombstoneGracePeriod {
+			continue
+		}
+
+		key := service.GetKey()
+		if err := r.store.DeleteService(ctx, key); err != nil {
+			logger.Error("Registry: Failed to purge tombstone",
+				zap.String("service_key", key),
+				zap.Error(err),
+			)
+			continue
+		}
+		r.history.forget(key)
+		purged++
+
+		logger.Debug("Registry: Tombstone purged",
+			zap.String("service_key", key),
+		)
+	}
+	return purged
+}
+
+// Snapshot returns a snapshot of every service in the registry, tombstones included, sorted by key
+func (r *Registry) Snapshot(ctx context.Context) (*models.Snapshot, error) {
+	services, err := r.store.GetAllServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(services, func(a, b *models.ServiceInfo) int {
+		return strings.Compare(a.GetKey(), b.GetKey())
+	})
+	return &models.Snapshot{
+		Version:    models.SnapshotVersion,
+		ExportedAt: r.clock.Now(),
+		Services:   services,
+	}, nil
+}
+
+// Restore replaces the contents of the registry with services, e.g. from an imported snapshot.
+// Services not in the list are deleted and returned, the others are saved as given with their
+// subscriptions. Tombstones are restored without subscriptions, like after Unregister.
+// Stops at the first storage error, leaving the registry partially restored.
+func (r *Registry) Restore(ctx context.Context, services []*models.ServiceInfo) ([]*models.ServiceInfo, error) {
+	defer r.Invalidate()
+
+	existing, err := r.store.GetAllServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := make(map[string]bool, len(services))
+	for _, service := range services {
+		restored[service.GetKey()] = true
+	}
+
+	// Drop everything currently registered, keeping the removed services for the caller
+	removed := make([]*models.ServiceInfo, 0)
+	for _, service := range existing {
+		key := service.GetKey()
+		if !service.IsTombstone() {
+			r.removeSubscriptions(ctx, key, service.SubscriptionGroups())
+		}
+		if restored[key] {
+			continue
+		}
+		if err := r.store.DeleteService(ctx, key); err != nil {
+			return removed, fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+		r.history.forget(key)
+		removed = append(removed, service)
+	}
+
+	for _, service := range services {
+		key := service.GetKey()
+		if service.Status == "" {
+			service.Status = models.StatusUnknown
+		}
+		if err := r.save(ctx, service); err != nil {
+			return removed, fmt.Errorf("failed to save %s: %w", key, err)
+		}
+		if !service.IsTombstone() {
+			r.addSubscriptions(ctx, key, service.SubscriptionGroups())
+		}
+	}
+
+	logger.Info("Registry: Restored services",
+		zap.Int("restored", len(services)),
+		zap.Int("removed", len(removed)),
+	)
+
+	return removed, nil
+}
+
+// withoutTombstones filters out services that are only kept as tombstones
+func withoutTombstones(services []*models.ServiceInfo) []*models.ServiceInfo {
+	result := make([]*models.ServiceInfo, 0, len(services))
+	for _, service := range services {
+		if !service.IsTombstone() {
+			result = append(result, service)
+		}
+	}
 	return result
 }
 
-// UpdateHealthStatus updates the health status of a service
-func (r *Registry) UpdateHealthStatus(key string, status models.ServiceStatus) bool {
+// UpdateHealthStatus updates the health status of a service and records a change in its
+// status history
+func (r *Registry) UpdateHealthStatus(ctx context.Context, key string, status models.ServiceStatus) bool {
+	defer r.Invalidate()
+
 	logger.Debug("Registry: UpdateHealthStatus called",
 		zap.String("service_key", key),
 		zap.String("new_status", string(status)),
 	)
 
-	service, err := r.store.GetService(r.ctx, key)
+	service, err := r.store.GetService(ctx, key)
 	if err != nil {
 		logger.Warn("Registry: Service not found for health status update",
 			zap.String("service_key", key),
@@ -179,10 +651,19 @@ func (r *Registry) UpdateHealthStatus(key string, status models.ServiceStatus) b
 	}
 
 	oldStatus := service.Status
-	timestamp := time.Now()
+	timestamp := r.clock.Now()
+	statusChanged := oldStatus != status
 
-	// Update in storage
-	if err := r.store.UpdateHealthStatus(r.ctx, key, status, timestamp); err != nil {
+	// A changed status is a new revision of the service, an unchanged one only moves the
+	// health check time
+	if statusChanged {
+		service.Status = status
+		service.LastHealthCheck = timestamp
+		err = r.save(ctx, service)
+	} else {
+		err = r.store.UpdateHealthStatus(ctx, key, status, timestamp)
+	}
+	if err != nil {
 		logger.Error("Registry: Failed to update health status in storage",
 			zap.String("service_key", key),
 			zap.String("old_status", string(oldStatus)),
@@ -192,8 +673,8 @@ func (r *Registry) UpdateHealthStatus(key string, status models.ServiceStatus) b
 		return false
 	}
 
-	statusChanged := oldStatus != status
 	if statusChanged {
+		r.history.add(key, models.StatusTransition{Timestamp: timestamp, OldStatus: oldStatus, NewStatus: status})
 		logger.Info("Registry: Health status updated",
 			zap.String("service_key", key),
 			zap.String("old_status", string(oldStatus)),
@@ -209,9 +690,15 @@ func (r *Registry) UpdateHealthStatus(key string, status models.ServiceStatus) b
 	return statusChanged
 }
 
+// GetStatusHistory returns the recent health status transitions of a pod, newest first.
+// Pods keep their history while they are tombstones; it is dropped once they are deleted.
+func (r *Registry) GetStatusHistory(key string) []models.StatusTransition {
+	return r.history.get(key)
+}
+
 // GetSubscribers returns all subscriber keys for a given service name
-func (r *Registry) GetSubscribers(serviceName string) []string {
-	subscribers, err := r.store.GetSubscribers(r.ctx, serviceName)
+func (r *Registry) GetSubscribers(ctx context.Context, serviceName string) []string {
+	subscribers, err := r.store.GetSubscribers(ctx, serviceName)
 	if err != nil {
 		return []string{}
 	}
@@ -219,18 +706,86 @@ func (r *Registry) GetSubscribers(serviceName string) []string {
 }
 
 // GetSubscriberServices returns all ServiceInfo of subscribers for a given service name
-func (r *Registry) GetSubscriberServices(serviceName string) []*models.ServiceInfo {
-	result, err := r.store.GetSubscriberServices(r.ctx, serviceName)
+func (r *Registry) GetSubscriberServices(ctx context.Context, serviceName string) []*models.ServiceInfo {
+	result, err := r.store.GetSubscriberServices(ctx, serviceName)
 	if err != nil {
 		return []*models.ServiceInfo{}
 	}
 	return result
 }
 
+// CheckSubscriptions returns a *models.ValidationError if a pod may not subscribe to that many
+// service groups, see WithMaxSubscriptions
+func (r *Registry) CheckSubscriptions(subscriptions []string) error {
+	if r.maxSubscriptions > 0 && len(subscriptions) > r.maxSubscriptions {
+		return &models.ValidationError{Message: fmt.Sprintf(
+			"too many subscriptions: %d, at most %d allowed", len(subscriptions), r.maxSubscriptions)}
+	}
+	return nil
+}
+
+// Quota returns the most live pods namespace may have, 0 if unlimited, see WithNamespaceQuotas
+func (r *Registry) Quota(namespace string) int {
+	if quota, ok := r.namespaceQuotas[namespace]; ok {
+		return quota
+	}
+	return r.defaultNamespaceQuota
+}
+
+// CheckQuota returns an error wrapping ErrQuotaExceeded if reg is a new pod and its namespace
+// already has as many live pods as its quota allows, counting pending new pods of the
+// namespace that are not registered yet (e.g. earlier items of a batch). Re-registering a
+// live pod never counts against the quota.
+func (r *Registry) CheckQuota(ctx context.Context, reg *models.ServiceRegistration, pending int) error {
+	quota := r.Quota(reg.Namespace)
+	if quota <= 0 {
+		return nil
+	}
+	if _, exists := r.Get(ctx, models.ServiceKey(reg.QualifiedName(), reg.PodName)); exists {
+		return nil
+	}
+
+	used := pending
+	for _, service := range r.GetAllServices(ctx) {
+		if service.Namespace == reg.Namespace {
+			used++
+		}
+	}
+	if used >= quota {
+		return fmt.Errorf("%w: namespace %q has %d of %d pods", ErrQuotaExceeded, reg.Namespace, used, quota)
+	}
+	return nil
+}
+
+// GetNamespaceUsage returns the live pods of every namespace that has pods or a quota,
+// sorted by namespace
+func (r *Registry) GetNamespaceUsage(ctx context.Context) []models.NamespaceUsage {
+	used := make(map[string]int)
+	for namespace := range r.namespaceQuotas {
+		used[namespace] = 0
+	}
+	for _, service := range r.GetAllServices(ctx) {
+		used[service.Namespace]++
+	}
+
+	result := make([]models.NamespaceUsage, 0, len(used))
+	for namespace, count := range used {
+		result = append(result, models.NamespaceUsage{
+			Namespace: namespace,
+			Pods:      count,
+			Quota:     r.Quota(namespace),
+		})
+	}
+	slices.SortFunc(result, func(a, b models.NamespaceUsage) int {
+		return strings.Compare(a.Namespace, b.Namespace)
+	})
+	return result
+}
+
 // addSubscriptions adds subscriptions for a service
-func (r *Registry) addSubscriptions(subscriberKey string, subscriptions []string) {
+func (r *Registry) addSubscriptions(ctx context.Context, subscriberKey string, subscriptions []string) {
 	for _, serviceName := range subscriptions {
-		if err := r.store.AddSubscription(r.ctx, subscriberKey, serviceName); err != nil {
+		if err := r.store.AddSubscription(ctx, subscriberKey, serviceName); err != nil {
 			logger.Error("Registry: Failed to add subscription",
 				zap.String("subscriber_key", subscriberKey),
 				zap.String("service_name", serviceName),
@@ -246,9 +801,9 @@ func (r *Registry) addSubscriptions(subscriberKey string, subscriptions []string
 }
 
 // removeSubscriptions removes subscriptions for a service
-func (r *Registry) removeSubscriptions(subscriberKey string, subscriptions []string) {
+func (r *Registry) removeSubscriptions(ctx context.Context, subscriberKey string, subscriptions []string) {
 	for _, serviceName := range subscriptions {
-		if err := r.store.RemoveSubscription(r.ctx, subscriberKey, serviceName); err != nil {
+		if err := r.store.RemoveSubscription(ctx, subscriberKey, serviceName); err != nil {
 			logger.Error("Registry: Failed to remove subscription",
 				zap.String("subscriber_key", subscriberKey),
 				zap.String("service_name", serviceName),
@@ -1,6 +1,9 @@
 package registry
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -34,7 +37,10 @@ func TestRegister(t *testing.T) {
 		Subscriptions:   []string{"other-service"},
 	}
 
-	serviceInfo := reg.Register(registration)
+	serviceInfo, err := reg.Register(context.Background(), registration)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
 
 	// Verify service info
 	if serviceInfo.ServiceName != "test-service" {
@@ -49,7 +55,7 @@ func TestRegister(t *testing.T) {
 
 	// Verify service is in registry
 	key := "test-service:test-pod-1"
-	retrieved, exists := reg.Get(key)
+	retrieved, exists := reg.Get(context.Background(), key)
 	if !exists {
 		t.Error("Service not found in registry")
 	}
@@ -58,7 +64,7 @@ func TestRegister(t *testing.T) {
 	}
 
 	// Verify subscriptions are registered
-	subscribers := reg.GetSubscribers("other-service")
+	subscribers := reg.GetSubscribers(context.Background(), "other-service")
 	if len(subscribers) != 1 {
 		t.Errorf("Expected 1 subscriber, got %d", len(subscribers))
 	}
@@ -80,7 +86,7 @@ func TestRegisterUpdate(t *testing.T) {
 		NotificationURL: "http://192.168.1.10:8080/notify",
 		Subscriptions:   []string{"service-a"},
 	}
-	reg.Register(reg1)
+	reg.Register(context.Background(), reg1)
 
 	// Update registration with different subscriptions
 	reg2 := &models.ServiceRegistration{
@@ -91,7 +97,10 @@ func TestRegisterUpdate(t *testing.T) {
 		NotificationURL: "http://192.168.1.10:9090/notify",
 		Subscriptions:   []string{"service-b"},
 	}
-	serviceInfo := reg.Register(reg2)
+	serviceInfo, err := reg.Register(context.Background(), reg2)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
 
 	// Verify update
 	if serviceInfo.Providers[0].Port != 9090 {
@@ -99,13 +108,13 @@ func TestRegisterUpdate(t *testing.T) {
 	}
 
 	// Verify old subscription removed
-	subscribersA := reg.GetSubscribers("service-a")
+	subscribersA := reg.GetSubscribers(context.Background(), "service-a")
 	if len(subscribersA) != 0 {
 		t.Error("Old subscription should be removed")
 	}
 
 	// Verify new subscription added
-	subscribersB := reg.GetSubscribers("service-b")
+	subscribersB := reg.GetSubscribers(context.Background(), "service-b")
 	if len(subscribersB) != 1 {
 		t.Errorf("Expected 1 subscriber for service-b, got %d", len(subscribersB))
 	}
@@ -124,10 +133,10 @@ func TestUnregister(t *testing.T) {
 		NotificationURL: "http://192.168.1.10:8080/notify",
 		Subscriptions:   []string{"other-service"},
 	}
-	reg.Register(registration)
+	reg.Register(context.Background(), registration)
 
 	// Unregister
-	serviceInfo := reg.Unregister("test-service", "test-pod-1")
+	serviceInfo := reg.Unregister(context.Background(), "test-service", "test-pod-1")
 	if serviceInfo == nil {
 		t.Fatal("Unregister returned nil")
 	}
@@ -137,13 +146,13 @@ func TestUnregister(t *testing.T) {
 
 	// Verify service removed from registry
 	key := "test-service:test-pod-1"
-	_, exists := reg.Get(key)
+	_, exists := reg.Get(context.Background(), key)
 	if exists {
 		t.Error("Service should be removed from registry")
 	}
 
 	// Verify subscriptions removed
-	subscribers := reg.GetSubscribers("other-service")
+	subscribers := reg.GetSubscribers(context.Background(), "other-service")
 	if len(subscribers) != 0 {
 		t.Error("Subscriptions should be removed")
 	}
@@ -153,12 +162,253 @@ func TestUnregisterNonExistent(t *testing.T) {
 	dualStore := storage.NewDualStore(nil)
 	reg := NewRegistry(dualStore)
 
-	serviceInfo := reg.Unregister("non-existent", "pod-1")
+	serviceInfo := reg.Unregister(context.Background(), "non-existent", "pod-1")
 	if serviceInfo != nil {
 		t.Error("Unregister should return nil for non-existent service")
 	}
 }
 
+func TestUnregisterSoftDelete(t *testing.T) {
+	dualStore := storage.NewDualStore(nil)
+	reg := NewRegistry(dualStore, WithTombstoneGracePeriod(time.Minute))
+
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "test-service",
+		PodName:         "test-pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		Subscriptions:   []string{"other-service"},
+	})
+
+	serviceInfo := reg.Unregister(context.Background(), "test-service", "test-pod-1")
+	if serviceInfo == nil {
+		t.Fatal("Unregister returned nil")
+	}
+	if serviceInfo.Status != models.StatusTombstone || !serviceInfo.IsTombstone() {
+		t.Errorf("Expected tombstone, got status %s deleted at %v", serviceInfo.Status, serviceInfo.DeletedAt)
+	}
+
+	// Hidden from normal lookups
+	if _, exists := reg.Get(context.Background(), "test-service:test-pod-1"); exists {
+		t.Error("Tombstone should not be returned by Get")
+	}
+	if len(reg.GetByServiceName(context.Background(), "test-service")) != 0 || len(reg.GetAllServices(context.Background())) != 0 {
+		t.Error("Tombstone should not be listed with live services")
+	}
+	if len(reg.GetSubscribers(context.Background(), "other-service")) != 0 {
+		t.Error("Subscriptions should be removed")
+	}
+
+	// But still queryable as a tombstone
+	tombstones := reg.GetTombstones(context.Background())
+	if len(tombstones) != 1 || tombstones[0].GetKey() != "test-service:test-pod-1" {
+		t.Fatalf("Expected the pod as tombstone, got %v", tombstones)
+	}
+
+	// Unregistering again finds nothing
+	if reg.Unregister(context.Background(), "test-service", "test-pod-1") != nil {
+		t.Error("Unregister of a tombstone should return nil")
+	}
+
+	// Within the grace period nothing is purged
+	if purged := reg.PurgeTombstones(context.Background()); purged != 0 {
+		t.Errorf("Expected no tombstones purged, got %d", purged)
+	}
+}
+
+func TestPurgeTombstones(t *testing.T) {
+	dualStore := storage.NewDualStore(nil)
+	reg := NewRegistry(dualStore, WithTombstoneGracePeriod(time.Minute))
+
+	for _, pod := range []string{"pod-1", "pod-2"} {
+		dualStore.SaveService(context.Background(), &models.ServiceInfo{
+			ServiceName: "test-service",
+			PodName:     pod,
+			Status:      models.StatusTombstone,
+			DeletedAt:   time.Now(),
+		})
+	}
+	expired, _ := dualStore.GetService(context.Background(), "test-service:pod-1")
+	expired.DeletedAt = time.Now().Add(-2 * time.Minute)
+	dualStore.SaveService(context.Background(), expired)
+
+	if purged := reg.PurgeTombstones(context.Background()); purged != 1 {
+		t.Fatalf("Expected 1 tombstone purged, got %d", purged)
+	}
+
+	tombstones := reg.GetTombstones(context.Background())
+	if len(tombstones) != 1 || tombstones[0].PodName != "pod-2" {
+		t.Errorf("Expected only pod-2 left, got %v", tombstones)
+	}
+}
+
+func TestRegisterReplacesTombstone(t *testing.T) {
+	dualStore := storage.NewDualStore(nil)
+	reg := NewRegistry(dualStore, WithTombstoneGracePeriod(time.Minute))
+
+	registration := &models.ServiceRegistration{
+		ServiceName:     "test-service",
+		PodName:         "test-pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	}
+	reg.Register(context.Background(), registration)
+	reg.Unregister(context.Background(), "test-service", "test-pod-1")
+	reg.Register(context.Background(), registration)
+
+	service, exists := reg.Get(context.Background(), "test-service:test-pod-1")
+	if !exists || service.IsTombstone() {
+		t.Error("Re-registering should replace the tombstone")
+	}
+	if len(reg.GetTombstones(context.Background())) != 0 {
+		t.Error("Expected no tombstones left")
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	dualStore := storage.NewDualStore(nil)
+	reg := NewRegistry(dualStore)
+	ctx := context.Background()
+
+	registration := &models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		Subscriptions:   []string{"order-service"},
+		TTLSeconds:      30,
+	}
+
+	// Not registered yet
+	if service, err := reg.Refresh(ctx, registration); service != nil || err != nil {
+		t.Fatalf("Expected nothing to refresh, got %v, %v", service, err)
+	}
+
+	original, _ := reg.Register(ctx, registration)
+	reg.UpdateHealthStatus(ctx, original.GetKey(), models.StatusHealthy)
+	time.Sleep(10 * time.Millisecond)
+
+	identical := *registration
+	identical.Labels = map[string]string{} // Empty counts as unset
+	refreshed, err := reg.Refresh(ctx, &identical)
+	if err != nil || refreshed == nil {
+		t.Fatalf("Expected an identical registration to be refreshed, got %v, %v", refreshed, err)
+	}
+	if !refreshed.RegisteredAt.After(original.RegisteredAt) || !refreshed.ExpiresAt.After(original.ExpiresAt) {
+		t.Error("Expected RegisteredAt and ExpiresAt to be renewed")
+	}
+	if refreshed.Status != models.StatusHealthy {
+		t.Errorf("Expected the health status to be kept, got %s", refreshed.Status)
+	}
+	if subscribers := reg.GetSubscriberServices(ctx, "order-service"); len(subscribers) != 1 {
+		t.Errorf("Expected the subscription to be kept, got %d subscribers", len(subscribers))
+	}
+
+	changed := *registration
+	changed.Subscriptions = []string{"payment-service"}
+	if service, err := reg.Refresh(ctx, &changed); service != nil || err != nil {
+		t.Errorf("Expected a changed registration not to be refreshed, got %v, %v", service, err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	dualStore := storage.NewDualStore(nil)
+	reg := NewRegistry(dualStore)
+
+	original, _ := reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "test-service",
+		PodName:         "test-pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		Subscriptions:   []string{"other-service"},
+		Labels:          map[string]string{"version": "stable"},
+	})
+	reg.UpdateHealthStatus(context.Background(), "test-service:test-pod-1", models.StatusHealthy)
+
+	updated := reg.Update(context.Background(), "test-service", "test-pod-1", &models.ServiceUpdate{
+		Providers: []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 9090}},
+	})
+	if updated == nil {
+		t.Fatal("Update returned nil")
+	}
+
+	// Updated field changed, everything else kept
+	if updated.Providers[0].Port != 9090 {
+		t.Errorf("Expected provider port 9090, got %d", updated.Providers[0].Port)
+	}
+	if updated.HealthCheckURL != "http://192.168.1.10:8080/health" || updated.Labels["version"] != "stable" {
+		t.Error("Fields absent from the update should be unchanged")
+	}
+	if !updated.RegisteredAt.Equal(original.RegisteredAt) {
+		t.Error("RegisteredAt should be preserved")
+	}
+	if updated.Status != models.StatusHealthy {
+		t.Errorf("Expected status to stay healthy, got %s", updated.Status)
+	}
+	if subscribers := reg.GetSubscribers(context.Background(), "other-service"); len(subscribers) != 1 {
+		t.Errorf("Expected subscription to be preserved, got %v", subscribers)
+	}
+
+	stored, _ := reg.Get(context.Background(), "test-service:test-pod-1")
+	if stored.Providers[0].Port != 9090 {
+		t.Error("Update should be saved to storage")
+	}
+}
+
+func TestUpdateNonExistent(t *testing.T) {
+	dualStore := storage.NewDualStore(nil)
+	reg := NewRegistry(dualStore)
+
+	if reg.Update(context.Background(), "non-existent", "pod-1", &models.ServiceUpdate{}) != nil {
+		t.Error("Update should return nil for non-existent service")
+	}
+}
+
+func TestRemoveAllSubscriptions(t *testing.T) {
+	dualStore := storage.NewDualStore(nil)
+	reg := NewRegistry(dualStore)
+
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "test-service",
+		PodName:         "test-pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		Subscriptions:   []string{"user-service", "order-service"},
+	})
+
+	groups, err := reg.RemoveAllSubscriptions(context.Background(), "test-service:test-pod-1")
+	if err != nil {
+		t.Fatalf("RemoveAllSubscriptions failed: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Errorf("Expected to be detached from 2 groups, got %v", groups)
+	}
+
+	for _, group := range []string{"user-service", "order-service"} {
+		if subscribers := reg.GetSubscribers(context.Background(), group); len(subscribers) != 0 {
+			t.Errorf("Expected no subscribers of %s, got %v", group, subscribers)
+		}
+	}
+
+	// The pod itself stays registered, without subscriptions
+	stored, exists := reg.Get(context.Background(), "test-service:test-pod-1")
+	if !exists {
+		t.Fatal("Service should still be registered")
+	}
+	if len(stored.Subscriptions) != 0 {
+		t.Errorf("Expected subscriptions to be cleared, got %v", stored.Subscriptions)
+	}
+
+	if groups, err := reg.RemoveAllSubscriptions(context.Background(), "test-service:test-pod-2"); groups != nil || err != nil {
+		t.Errorf("Expected nil for an unregistered pod, got %v, %v", groups, err)
+	}
+}
+
 func TestGetByServiceName(t *testing.T) {
 	dualStore := storage.NewDualStore(nil)
 	reg := NewRegistry(dualStore)
@@ -173,11 +423,11 @@ func TestGetByServiceName(t *testing.T) {
 			NotificationURL: "http://192.168.1.10:8080/notify",
 			Subscriptions:   []string{},
 		}
-		reg.Register(registration)
+		reg.Register(context.Background(), registration)
 	}
 
 	// Get all pods
-	pods := reg.GetByServiceName("test-service")
+	pods := reg.GetByServiceName(context.Background(), "test-service")
 	if len(pods) != 3 {
 		t.Errorf("Expected 3 pods, got %d", len(pods))
 	}
@@ -197,10 +447,10 @@ func TestGetAllServices(t *testing.T) {
 			NotificationURL: "http://192.168.1.10:8080/notify",
 			Subscriptions:   []string{},
 		}
-		reg.Register(registration)
+		reg.Register(context.Background(), registration)
 	}
 
-	services := reg.GetAllServices()
+	services := reg.GetAllServices(context.Background())
 	if len(services) != 5 {
 		t.Errorf("Expected 5 services, got %d", len(services))
 	}
@@ -219,29 +469,29 @@ func TestUpdateHealthStatus(t *testing.T) {
 		NotificationURL: "http://192.168.1.10:8080/notify",
 		Subscriptions:   []string{},
 	}
-	reg.Register(registration)
+	reg.Register(context.Background(), registration)
 
 	key := "test-service:test-pod-1"
 
 	// Update to healthy
-	changed := reg.UpdateHealthStatus(key, models.StatusHealthy)
+	changed := reg.UpdateHealthStatus(context.Background(), key, models.StatusHealthy)
 	if !changed {
 		t.Error("Status should have changed from unknown to healthy")
 	}
 
-	service, _ := reg.Get(key)
+	service, _ := reg.Get(context.Background(), key)
 	if service.Status != models.StatusHealthy {
 		t.Errorf("Expected status 'healthy', got '%s'", service.Status)
 	}
 
 	// Update to same status
-	changed = reg.UpdateHealthStatus(key, models.StatusHealthy)
+	changed = reg.UpdateHealthStatus(context.Background(), key, models.StatusHealthy)
 	if changed {
 		t.Error("Status should not have changed when updating to same status")
 	}
 
 	// Update to unhealthy
-	changed = reg.UpdateHealthStatus(key, models.StatusUnhealthy)
+	changed = reg.UpdateHealthStatus(context.Background(), key, models.StatusUnhealthy)
 	if !changed {
 		t.Error("Status should have changed from healthy to unhealthy")
 	}
@@ -251,12 +501,112 @@ func TestUpdateHealthStatusNonExistent(t *testing.T) {
 	dualStore := storage.NewDualStore(nil)
 	reg := NewRegistry(dualStore)
 
-	changed := reg.UpdateHealthStatus("non-existent:pod-1", models.StatusHealthy)
+	changed := reg.UpdateHealthStatus(context.Background(), "non-existent:pod-1", models.StatusHealthy)
 	if changed {
 		t.Error("Should return false for non-existent service")
 	}
 }
 
+func TestStatusHistory(t *testing.T) {
+	dualStore := storage.NewDualStore(nil)
+	reg := NewRegistry(dualStore, WithStatusHistorySize(3))
+	ctx := context.Background()
+
+	service, _ := reg.Register(ctx, &models.ServiceRegistration{
+		ServiceName:     "test-service",
+		PodName:         "test-pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	})
+	key := service.GetKey()
+
+	// Unchanged statuses are not transitions, and the oldest transition is overwritten
+	for _, status := range []models.ServiceStatus{
+		models.StatusHealthy, models.StatusHealthy, models.StatusUnhealthy, models.StatusHealthy, models.StatusUnhealthy,
+	} {
+		reg.UpdateHealthStatus(ctx, key, status)
+	}
+
+	history := reg.GetStatusHistory(key)
+	want := []models.StatusTransition{
+		{OldStatus: models.StatusHealthy, NewStatus: models.StatusUnhealthy},
+		{OldStatus: models.StatusUnhealthy, NewStatus: models.StatusHealthy},
+		{OldStatus: models.StatusHealthy, NewStatus: models.StatusUnhealthy},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("Expected %d transitions, got %+v", len(want), history)
+	}
+	for i, transition := range history {
+		if transition.OldStatus != want[i].OldStatus || transition.NewStatus != want[i].NewStatus || transition.Timestamp.IsZero() {
+			t.Errorf("Transition %d: expected %s -> %s, got %+v", i, want[i].OldStatus, want[i].NewStatus, transition)
+		}
+	}
+
+	reg.Unregister(ctx, "test-service", "test-pod-1")
+	if history := reg.GetStatusHistory(key); len(history) != 0 {
+		t.Errorf("Expected the history to be dropped with the pod, got %+v", history)
+	}
+}
+
+func TestRevision(t *testing.T) {
+	dualStore := storage.NewDualStore(nil)
+	reg := NewRegistry(dualStore)
+	ctx := context.Background()
+
+	register := func(pod string) *models.ServiceInfo {
+		service, err := reg.Register(ctx, &models.ServiceRegistration{
+			ServiceName:     "test-service",
+			PodName:         pod,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+		if err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		return service
+	}
+
+	start := reg.Revision()
+	first := register("pod-1")
+	if first.Revision <= start || reg.Revision() < first.Revision {
+		t.Fatalf("Expected the write to stamp a revision after %d and no later than %d, got %d", start, reg.Revision(), first.Revision)
+	}
+	second := register("pod-2")
+	if second.Revision <= first.Revision {
+		t.Errorf("Expected increasing revisions, got %d after %d", second.Revision, first.Revision)
+	}
+
+	revision := func() int64 {
+		service, _ := reg.Get(ctx, first.GetKey())
+		return service.Revision
+	}
+	before := revision()
+	if !reg.UpdateHealthStatus(ctx, first.GetKey(), models.StatusHealthy) {
+		t.Fatal("Expected the status to change")
+	}
+	changed := revision()
+	if changed <= second.Revision {
+		t.Errorf("Expected a status change to stamp a new revision, got %d (was %d)", changed, before)
+	}
+	reg.UpdateHealthStatus(ctx, first.GetKey(), models.StatusHealthy)
+	if got := revision(); got != changed {
+		t.Errorf("Expected an unchanged status to keep revision %d, got %d", changed, got)
+	}
+
+	// Reads don't change the revision
+	current := reg.Revision()
+	reg.GetAllServices(ctx)
+	if reg.Revision() != current {
+		t.Errorf("Expected reads to keep revision %d, got %d", current, reg.Revision())
+	}
+	reg.Unregister(ctx, "test-service", "pod-2")
+	if reg.Revision() <= current {
+		t.Errorf("Expected Unregister to advance the revision past %d, got %d", current, reg.Revision())
+	}
+}
+
 func TestGetSubscriberServices(t *testing.T) {
 	dualStore := storage.NewDualStore(nil)
 	reg := NewRegistry(dualStore)
@@ -271,10 +621,10 @@ func TestGetSubscriberServices(t *testing.T) {
 			NotificationURL: "http://192.168.1.10:8080/notify",
 			Subscriptions:   []string{"target-service"},
 		}
-		reg.Register(registration)
+		reg.Register(context.Background(), registration)
 	}
 
-	subscribers := reg.GetSubscriberServices("target-service")
+	subscribers := reg.GetSubscriberServices(context.Background(), "target-service")
 	if len(subscribers) != 3 {
 		t.Errorf("Expected 3 subscriber services, got %d", len(subscribers))
 	}
@@ -293,13 +643,13 @@ func TestMultipleSubscriptions(t *testing.T) {
 		NotificationURL: "http://192.168.1.10:8080/notify",
 		Subscriptions:   []string{"service-a", "service-b", "service-c"},
 	}
-	reg.Register(registration)
+	reg.Register(context.Background(), registration)
 
 	key := "subscriber-service:pod-1"
 
 	// Verify subscriptions
 	for _, serviceName := range []string{"service-a", "service-b", "service-c"} {
-		subscribers := reg.GetSubscribers(serviceName)
+		subscribers := reg.GetSubscribers(context.Background(), serviceName)
 		if len(subscribers) != 1 {
 			t.Errorf("Expected 1 subscriber for %s, got %d", serviceName, len(subscribers))
 		}
@@ -309,6 +659,186 @@ func TestMultipleSubscriptions(t *testing.T) {
 	}
 }
 
+func TestMaxSubscriptions(t *testing.T) {
+	reg := NewRegistry(storage.NewDualStore(nil), WithMaxSubscriptions(2))
+	ctx := context.Background()
+
+	registration := &models.ServiceRegistration{
+		ServiceName:     "subscriber-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		Subscriptions:   []string{"service-a", "service-b"},
+	}
+	if _, err := reg.Register(ctx, registration); err != nil {
+		t.Fatalf("Expected registration at the limit to succeed, got %v", err)
+	}
+
+	// Over the limit, the previous registration and its subscriptions stay
+	tooMany := *registration
+	tooMany.Subscriptions = []string{"service-a", "service-b", "service-c"}
+	_, err := reg.Register(ctx, &tooMany)
+	var validationErr *models.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	if subscribers := reg.GetSubscribers(ctx, "service-c"); len(subscribers) != 0 {
+		t.Errorf("Expected no subscribers for service-c, got %v", subscribers)
+	}
+	if subscribers := reg.GetSubscribers(ctx, "service-a"); len(subscribers) != 1 {
+		t.Errorf("Expected the previous subscription to service-a to stay, got %v", subscribers)
+	}
+}
+
+func TestWildcardSubscriptions(t *testing.T) {
+	dualStore := storage.NewDualStore(nil)
+	reg := NewRegistry(dualStore)
+
+	subscribe := func(serviceName string, subscriptions ...string) {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     serviceName,
+			PodName:         "pod-1",
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+			Subscriptions:   subscriptions,
+		})
+	}
+	subscribe("monitor", "edge-*")
+	subscribe("router", "edge-gateway")
+	subscribe("auditor", "edge-gateway", "edge-*", "*-gateway") // Overlapping subscriptions
+
+	// Exact subscribers first, each subscriber once
+	subscribers := reg.GetSubscribers(context.Background(), "edge-gateway")
+	expected := []string{"router:pod-1", "auditor:pod-1", "monitor:pod-1"}
+	if !slices.Equal(subscribers, expected) {
+		t.Errorf("Expected subscribers %v, got %v", expected, subscribers)
+	}
+
+	// Service groups created later match too
+	subscribers = reg.GetSubscribers(context.Background(), "edge-cache")
+	expected = []string{"monitor:pod-1", "auditor:pod-1"}
+	if !slices.Equal(subscribers, expected) {
+		t.Errorf("Expected subscribers %v, got %v", expected, subscribers)
+	}
+
+	if subscribers := reg.GetSubscribers(context.Background(), "core-cache"); len(subscribers) != 0 {
+		t.Errorf("Expected no subscribers for a non-matching group, got %v", subscribers)
+	}
+
+	// Unsubscribing drops the pattern subscription as well
+	reg.Unregister(context.Background(), "monitor", "pod-1")
+	subscribers = reg.GetSubscribers(context.Background(), "edge-cache")
+	if !slices.Equal(subscribers, []string{"auditor:pod-1"}) {
+		t.Errorf("Expected only auditor after monitor unregistered, got %v", subscribers)
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	reg := NewRegistry(storage.NewDualStore(nil))
+	ctx := context.Background()
+
+	register := func(namespace, serviceName string, subscriptions ...string) {
+		if _, err := reg.Register(ctx, &models.ServiceRegistration{
+			Namespace:       namespace,
+			ServiceName:     serviceName,
+			PodName:         "pod-1",
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+			Subscriptions:   subscriptions,
+		}); err != nil {
+			t.Fatalf("Register(%q, %q) failed: %v", namespace, serviceName, err)
+		}
+	}
+	register("", "gateway")
+	register("team-a", "gateway")
+	register("team-a", "router", "gateway")
+	register("team-b", "router", "*")
+
+	// Equally named services of different namespaces are separate groups
+	if pods := reg.GetByServiceName(ctx, "gateway"); len(pods) != 1 || pods[0].Namespace != "" {
+		t.Errorf("Expected only the default namespace gateway, got %v", pods)
+	}
+	pods := reg.GetByServiceName(ctx, "team-a/gateway")
+	if len(pods) != 1 || pods[0].Namespace != "team-a" || pods[0].ServiceName != "gateway" {
+		t.Errorf("Expected only the team-a gateway, got %v", pods)
+	}
+	if _, exists := reg.Get(ctx, "team-a/gateway:pod-1"); !exists {
+		t.Error("Expected the team-a gateway under its qualified key")
+	}
+
+	// Subscriptions resolve within the subscriber's namespace
+	if subscribers := reg.GetSubscribers(ctx, "team-a/gateway"); !slices.Equal(subscribers, []string{"team-a/router:pod-1"}) {
+		t.Errorf("Expected only the team-a router subscribed to team-a/gateway, got %v", subscribers)
+	}
+	if subscribers := reg.GetSubscribers(ctx, "gateway"); len(subscribers) != 0 {
+		t.Errorf("Expected no subscribers for the default namespace gateway, got %v", subscribers)
+	}
+	if subscribers := reg.GetSubscribers(ctx, "team-b/router"); !slices.Equal(subscribers, []string{"team-b/router:pod-1"}) {
+		t.Errorf("Expected the team-b wildcard to match its own namespace, got %v", subscribers)
+	}
+}
+
+func TestNamespaceQuotas(t *testing.T) {
+	reg := NewRegistry(storage.NewDualStore(nil), WithNamespaceQuotas(map[string]int{"team-a": 2, "team-b": 0}, 1))
+	ctx := context.Background()
+
+	register := func(namespace, podName string) error {
+		_, err := reg.Register(ctx, &models.ServiceRegistration{
+			Namespace:       namespace,
+			ServiceName:     "gateway",
+			PodName:         podName,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+		return err
+	}
+
+	for _, podName := range []string{"pod-1", "pod-2"} {
+		if err := register("team-a", podName); err != nil {
+			t.Fatalf("Expected team-a %s within its quota, got %v", podName, err)
+		}
+	}
+	if err := register("team-a", "pod-3"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected team-a pod-3 over the quota, got %v", err)
+	}
+	// Re-registering an existing pod doesn't count
+	if err := register("team-a", "pod-1"); err != nil {
+		t.Errorf("Expected re-registration of team-a pod-1 to succeed, got %v", err)
+	}
+
+	// Unlisted namespaces get the default quota, 0 is unlimited
+	if err := register("team-c", "pod-1"); err != nil {
+		t.Fatalf("Expected team-c pod-1 within the default quota, got %v", err)
+	}
+	if err := register("team-c", "pod-2"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected team-c pod-2 over the default quota, got %v", err)
+	}
+	for _, podName := range []string{"pod-1", "pod-2", "pod-3"} {
+		if err := register("team-b", podName); err != nil {
+			t.Errorf("Expected team-b to be unlimited, got %v", err)
+		}
+	}
+
+	// Unregistered pods free their slot
+	reg.Unregister(ctx, "team-a/gateway", "pod-2")
+	if err := register("team-a", "pod-3"); err != nil {
+		t.Errorf("Expected team-a pod-3 after pod-2 left, got %v", err)
+	}
+
+	expected := []models.NamespaceUsage{
+		{Namespace: "team-a", Pods: 2, Quota: 2},
+		{Namespace: "team-b", Pods: 3, Quota: 0},
+		{Namespace: "team-c", Pods: 1, Quota: 1},
+	}
+	if usage := reg.GetNamespaceUsage(ctx); !slices.Equal(usage, expected) {
+		t.Errorf("Expected usage %v, got %v", expected, usage)
+	}
+}
+
 func TestServiceInfoGetKey(t *testing.T) {
 	service := &models.ServiceInfo{
 		ServiceName: "test-service",
@@ -334,10 +864,81 @@ func TestRegisteredAtTimestamp(t *testing.T) {
 		NotificationURL: "http://192.168.1.10:8080/notify",
 		Subscriptions:   []string{},
 	}
-	serviceInfo := reg.Register(registration)
+	serviceInfo, err := reg.Register(context.Background(), registration)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
 	after := time.Now()
 
 	if serviceInfo.RegisteredAt.Before(before) || serviceInfo.RegisteredAt.After(after) {
 		t.Error("RegisteredAt timestamp is not within expected range")
 	}
 }
+
+func TestSnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	register := func(reg *Registry, serviceName, podName string, subscriptions ...string) {
+		_, err := reg.Register(ctx, &models.ServiceRegistration{
+			ServiceName:     serviceName,
+			PodName:         podName,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			NotificationURL: "http://192.168.1.10:8080/notify",
+			Subscriptions:   subscriptions,
+		})
+		if err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	source := NewRegistry(storage.NewDualStore(nil), WithTombstoneGracePeriod(time.Minute))
+	register(source, "user-service", "pod-2", "order-service")
+	register(source, "user-service", "pod-1")
+	register(source, "order-service", "pod-1")
+	source.UpdateHealthStatus(ctx, "user-service:pod-1", models.StatusHealthy)
+	source.Unregister(ctx, "order-service", "pod-1")
+
+	snapshot, err := source.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if snapshot.Version != models.SnapshotVersion {
+		t.Errorf("Expected version %d, got %d", models.SnapshotVersion, snapshot.Version)
+	}
+	keys := make([]string, 0, len(snapshot.Services))
+	for _, service := range snapshot.Services {
+		keys = append(keys, service.GetKey())
+	}
+	if want := []string{"order-service:pod-1", "user-service:pod-1", "user-service:pod-2"}; !slices.Equal(keys, want) {
+		t.Fatalf("Expected snapshot of %v including the tombstone, got %v", want, keys)
+	}
+
+	target := NewRegistry(storage.NewDualStore(nil), WithTombstoneGracePeriod(time.Minute))
+	register(target, "payment-service", "pod-1", "user-service")
+	register(target, "user-service", "pod-1", "payment-service")
+
+	removed, err := target.Restore(ctx, snapshot.Services)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0].GetKey() != "payment-service:pod-1" {
+		t.Errorf("Expected payment-service:pod-1 to be removed, got %v", removed)
+	}
+
+	if len(target.GetAllServices(ctx)) != 2 || len(target.GetTombstones(ctx)) != 1 {
+		t.Errorf("Expected 2 services and 1 tombstone after restore")
+	}
+	if service, _ := target.Get(ctx, "user-service:pod-1"); service.Status != models.StatusHealthy {
+		t.Errorf("Expected restored status healthy, got %s", service.Status)
+	}
+
+	// Subscriptions follow the snapshot, not what was registered before
+	if subscribers := target.GetSubscribers(ctx, "order-service"); !slices.Equal(subscribers, []string{"user-service:pod-2"}) {
+		t.Errorf("Expected order-service subscribers [user-service:pod-2], got %v", subscribers)
+	}
+	if subscribers := target.GetSubscribers(ctx, "payment-service"); len(subscribers) != 0 {
+		t.Errorf("Expected no payment-service subscribers, got %v", subscribers)
+	}
+	if subscribers := target.GetSubscribers(ctx, "user-service"); len(subscribers) != 0 {
+		t.Errorf("Expected no user-service subscribers, got %v", subscribers)
+	}
+}
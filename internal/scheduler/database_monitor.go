@@ -0,0 +1,193 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chronnie/governance/internal/clock"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"github.com/chronnie/governance/storage"
+	"go.uber.org/zap"
+)
+
+// maxReconnectBackoff caps the delay between reconnect attempts
+const maxReconnectBackoff = 30 * time.Second
+
+// DatabaseMonitor periodically pings the database. After threshold pings in a row have
+// failed it replaces the store's connection pool, if the store supports it, retrying with
+// exponential backoff until the database answers again. A pool whose connections all went
+// stale (e.g. after a database failover) otherwise never recovers on its own.
+type DatabaseMonitor struct {
+	db        storage.DatabaseStore
+	interval  time.Duration
+	threshold int // Consecutive ping failures before reconnecting
+	clock     clock.Clock
+	ctx       context.Context
+	cancel    context.CancelFunc
+
+	mu            sync.Mutex
+	stats         models.DatabaseConnectionStats
+	backoff       time.Duration // Wait before the next reconnect attempt
+	nextReconnect time.Time     // No reconnect attempt before this
+}
+
+// NewDatabaseMonitor creates a new database monitor pinging every interval and reconnecting
+// after threshold consecutive failures (<= 0 = 3)
+func NewDatabaseMonitor(db storage.DatabaseStore, interval time.Duration, threshold int, opts ...Option) *DatabaseMonitor {
+	if threshold <= 0 {
+		threshold = 3
+	}
+	o := newOptions(opts)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DatabaseMonitor{
+		db:        db,
+		interval:  interval,
+		threshold: threshold,
+		clock:     o.clock,
+		ctx:       ctx,
+		cancel:    cancel,
+		stats: models.DatabaseConnectionStats{
+			State: models.ConnectionConnected, // The store pinged the database when it was created
+			Since: o.clock.Now(),
+		},
+		backoff: storage.DefaultConnectBackoff,
+	}
+}
+
+// Start begins monitoring the database
+func (s *DatabaseMonitor) Start() {
+	logger.Info("DatabaseMonitor: Starting database monitor",
+		zap.Duration("interval", s.interval),
+		zap.Int("reconnect_threshold", s.threshold),
+	)
+
+	ticker := s.clock.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			s.check()
+		case <-s.ctx.Done():
+			logger.Info("DatabaseMonitor: Stopping database monitor")
+			return
+		}
+	}
+}
+
+// Stop stops the database monitor and aborts a reconnect in progress
+func (s *DatabaseMonitor) Stop() {
+	logger.Debug("DatabaseMonitor: Stop signal sent")
+	s.cancel()
+}
+
+// Stats returns the current connection state
+func (s *DatabaseMonitor) Stats() models.DatabaseConnectionStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// check pings the database and reconnects if it has been unreachable for too long
+func (s *DatabaseMonitor) check() {
+	// Don't let a hanging ping hold up the monitor past the next tick
+	ctx, cancel := context.WithTimeout(s.ctx, s.interval)
+	err := s.db.Ping(ctx)
+	cancel()
+	if s.ctx.Err() != nil {
+		return
+	}
+
+	if err == nil {
+		s.connected()
+		return
+	}
+
+	s.mu.Lock()
+	s.stats.ConsecutiveFailures++
+	s.stats.LastError = err.Error()
+	s.setState(models.ConnectionDisconnected)
+	failures := s.stats.ConsecutiveFailures
+	s.mu.Unlock()
+
+	logger.Warn("DatabaseMonitor: Database ping failed",
+		zap.Int("consecutive_failures", failures),
+		zap.Error(err),
+	)
+
+	if failures >= s.threshold {
+		s.reconnect()
+	}
+}
+
+// reconnect replaces the store's connection pool, unless the last attempt is too recent
+func (s *DatabaseMonitor) reconnect() {
+	reconnecting, ok := s.db.(storage.ReconnectingDatabaseStore)
+	if !ok {
+		return // The driver reconnects on its own, if at all
+	}
+
+	s.mu.Lock()
+	if s.clock.Now().Before(s.nextReconnect) {
+		s.mu.Unlock()
+		return
+	}
+	s.setState(models.ConnectionReconnecting)
+	s.mu.Unlock()
+
+	logger.Info("DatabaseMonitor: Reconnecting to database")
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.interval)
+	err := reconnecting.Reconnect(ctx)
+	cancel()
+
+	if err == nil {
+		s.mu.Lock()
+		s.stats.Reconnects++
+		s.mu.Unlock()
+
+		logger.Info("DatabaseMonitor: Reconnected to database")
+		s.connected()
+		return
+	}
+
+	s.mu.Lock()
+	s.stats.FailedReconnects++
+	s.stats.LastError = err.Error()
+	s.setState(models.ConnectionDisconnected)
+	retryIn := s.backoff
+	s.nextReconnect = s.clock.Now().Add(retryIn)
+	s.backoff = min(s.backoff*2, maxReconnectBackoff)
+	s.mu.Unlock()
+
+	logger.Warn("DatabaseMonitor: Failed to reconnect to database",
+		zap.Duration("retry_in", retryIn),
+		zap.Error(err),
+	)
+}
+
+// connected records that the database answered
+func (s *DatabaseMonitor) connected() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stats.State != models.ConnectionConnected {
+		logger.Info("DatabaseMonitor: Database reachable again",
+			zap.Int("failed_pings", s.stats.ConsecutiveFailures),
+		)
+	}
+	s.stats.ConsecutiveFailures = 0
+	s.setState(models.ConnectionConnected)
+	s.backoff = storage.DefaultConnectBackoff
+	s.nextReconnect = time.Time{}
+}
+
+// setState changes the connection state. Called with mu held.
+func (s *DatabaseMonitor) setState(state models.ConnectionState) {
+	if s.stats.State != state {
+		s.stats.State = state
+		s.stats.Since = s.clock.Now()
+	}
+}
@@ -0,0 +1,184 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/clock"
+	"github.com/chronnie/governance/pkg/logger"
+	"github.com/chronnie/governance/storage"
+	"go.uber.org/zap"
+)
+
+// databaseWatchRetryDelay is how long DatabaseWatcher waits before reopening a broken watch
+const databaseWatchRetryDelay = 5 * time.Second
+
+// DatabaseWatcher follows a database's stream of service changes and periodically schedules a
+// database sync event reloading only the services that changed. Each time the watch opens it
+// schedules a full reload, to catch up on changes made while it was down.
+// Watching reports false while the watch is down or a full reload is still to be scheduled,
+// so reconcile falls back to reloading the whole database; if the database cannot stream
+// changes at all the watcher gives up for good.
+type DatabaseWatcher struct {
+	db         storage.WatchingDatabaseStore
+	eventQueue eventqueue.IEventQueue
+	interval   time.Duration // How often changed keys are scheduled
+	watching   atomic.Bool   // The watch is open
+	resync     atomic.Bool   // A full reload has to be scheduled before changed keys
+	mu         sync.Mutex
+	changed    map[string]struct{} // Keys changed since the last scheduled sync
+	ctx        context.Context
+	cancel     context.CancelFunc
+	clock      clock.Clock
+}
+
+// NewDatabaseWatcher creates a new database watcher scheduling changed services every interval
+func NewDatabaseWatcher(db storage.WatchingDatabaseStore, eventQueue eventqueue.IEventQueue, interval time.Duration, opts ...Option) *DatabaseWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DatabaseWatcher{
+		db:         db,
+		eventQueue: eventQueue,
+		interval:   interval,
+		clock:      newOptions(opts).clock,
+		changed:    make(map[string]struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start follows the database's changes until Stop is called
+func (s *DatabaseWatcher) Start() {
+	logger.Info("DatabaseWatcher: Starting database watcher",
+		zap.Duration("interval", s.interval),
+	)
+
+	go s.watch()
+
+	ticker := s.clock.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			s.scheduleChanged()
+		case <-s.ctx.Done():
+			logger.Info("DatabaseWatcher: Stopping database watcher")
+			return
+		}
+	}
+}
+
+// Stop stops the database watcher and closes the watch
+func (s *DatabaseWatcher) Stop() {
+	logger.Debug("DatabaseWatcher: Stop signal sent")
+	s.cancel()
+}
+
+// Watching reports whether the database's changes are currently being followed and the
+// cache is caught up with those made before
+func (s *DatabaseWatcher) Watching() bool {
+	return s.watching.Load() && !s.resync.Load()
+}
+
+// watch keeps a watch open, reopening it after it breaks
+func (s *DatabaseWatcher) watch() {
+	for {
+		stream, err := s.db.WatchServices(s.ctx)
+		if errors.Is(err, storage.ErrWatchUnsupported) {
+			logger.Info("DatabaseWatcher: Database cannot stream changes, reconcile keeps reloading the whole database",
+				zap.Error(err),
+			)
+			return
+		}
+
+		if err == nil {
+			logger.Info("DatabaseWatcher: Watching database changes")
+			s.resync.Store(true)
+			s.watching.Store(true)
+
+			err = s.follow(stream)
+
+			s.watching.Store(false)
+			stream.Close(context.Background())
+		}
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		logger.Warn("DatabaseWatcher: Database watch failed, reconcile reloads the whole database until it reopens",
+			zap.Duration("retry_in", databaseWatchRetryDelay),
+			zap.Error(err),
+		)
+		select {
+		case <-time.After(databaseWatchRetryDelay):
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// follow collects changed keys until the watch breaks
+func (s *DatabaseWatcher) follow(stream storage.ServiceWatch) error {
+	for {
+		key, err := stream.Next(s.ctx)
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.changed[key] = struct{}{}
+		s.mu.Unlock()
+	}
+}
+
+// scheduleChanged schedules a sync of the keys changed since the last one, if any, or a full
+// reload if one is due. Keys that could not be scheduled are retried on the next tick.
+func (s *DatabaseWatcher) scheduleChanged() {
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.changed))
+	for key := range s.changed {
+		keys = append(keys, key)
+	}
+	s.changed = make(map[string]struct{})
+	s.mu.Unlock()
+
+	if s.resync.Load() {
+		if s.scheduleSync(nil) {
+			s.resync.Store(false) // Also reloads the changed keys
+			return
+		}
+	} else if len(keys) == 0 || s.scheduleSync(keys) {
+		return
+	}
+
+	s.mu.Lock()
+	for _, key := range keys {
+		s.changed[key] = struct{}{}
+	}
+	s.mu.Unlock()
+}
+
+// scheduleSync creates a database sync event for keys, nil syncing the whole database.
+// Returns false if the event could not be enqueued.
+func (s *DatabaseWatcher) scheduleSync(keys []string) bool {
+	logger.Debug("DatabaseWatcher: Enqueuing database sync event",
+		zap.Int("keys", len(keys)),
+	)
+
+	// Create event (without deadline, like reconcile)
+	event := eventqueue.NewEvent(string(events.EventDatabaseSync), events.NewDatabaseSyncContext(keys))
+
+	if err := s.eventQueue.Enqueue(event); err != nil {
+		logger.Warn("DatabaseWatcher: Failed to enqueue database sync event, retrying next interval",
+			zap.Int("keys", len(keys)),
+			zap.Error(err),
+		)
+		return false
+	}
+	return true
+}
@@ -1,30 +1,78 @@
 package scheduler
 
 import (
+	"context"
+	"math/rand/v2"
+	"sync"
 	"time"
 
 	eventqueue "github.com/chronnie/go-event-queue"
 	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/clock"
 	"github.com/chronnie/governance/internal/registry"
 	"github.com/chronnie/governance/pkg/logger"
 	"go.uber.org/zap"
 )
 
+// Option configures optional settings of the schedulers and monitors in this package
+type Option func(*options)
+
+type options struct {
+	clock clock.Clock
+}
+
+// WithClock makes a scheduler take its ticks and times from c instead of the system clock
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{clock: clock.Real}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // HealthCheckScheduler periodically schedules health check events for all services
 type HealthCheckScheduler struct {
 	registry   *registry.Registry
 	eventQueue eventqueue.IEventQueue
 	interval   time.Duration
+	jitter     float64 // Fraction of the interval over which checks are spread (0 = all at once)
+	clock      clock.Clock
+	ctx        context.Context    // Parent of every health check event
+	cancel     context.CancelFunc // Aborts scheduled health checks
 	stopChan   chan struct{}
+
+	mu           sync.Mutex
+	paused       bool
+	checks       context.Context    // Parent of checks scheduled since the last resume, child of ctx
+	cancelChecks context.CancelFunc // Aborts them when pausing
 }
 
-// NewHealthCheckScheduler creates a new health check scheduler
-func NewHealthCheckScheduler(reg *registry.Registry, eventQueue eventqueue.IEventQueue, interval time.Duration) *HealthCheckScheduler {
+// NewHealthCheckScheduler creates a new health check scheduler.
+// jitter is the fraction of the interval (0 to 1) over which each tick's checks are randomly
+// spread, so targets aren't all hit at the same instant.
+// Health check events carry a context derived from ctx (usually the event queue's), which is
+// cancelled by Stop, so checks still queued or backing off between retries end at once.
+func NewHealthCheckScheduler(ctx context.Context, reg *registry.Registry, eventQueue eventqueue.IEventQueue, interval time.Duration, jitter float64, opts ...Option) *HealthCheckScheduler {
+	ctx, cancel := context.WithCancel(ctx)
+	checks, cancelChecks := context.WithCancel(ctx)
 	return &HealthCheckScheduler{
-		registry:   reg,
-		eventQueue: eventQueue,
-		interval:   interval,
-		stopChan:   make(chan struct{}),
+		registry:     reg,
+		eventQueue:   eventQueue,
+		interval:     interval,
+		jitter:       min(max(jitter, 0), 1),
+		clock:        newOptions(opts).clock,
+		ctx:          ctx,
+		cancel:       cancel,
+		stopChan:     make(chan struct{}),
+		checks:       checks,
+		cancelChecks: cancelChecks,
 	}
 }
 
@@ -34,12 +82,12 @@ func (s *HealthCheckScheduler) Start() {
 		zap.Duration("interval", s.interval),
 	)
 
-	ticker := time.NewTicker(s.interval)
+	ticker := s.clock.NewTicker(s.interval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			logger.Debug("HealthCheckScheduler: Ticker fired, scheduling health checks")
 			s.scheduleHealthChecks()
 		case <-s.stopChan:
@@ -49,54 +97,128 @@ func (s *HealthCheckScheduler) Start() {
 	}
 }
 
-// Stop stops the health check scheduler
+// Stop stops the health check scheduler and cancels health checks already scheduled
 func (s *HealthCheckScheduler) Stop() {
 	logger.Debug("HealthCheckScheduler: Stop signal sent")
 	close(s.stopChan)
+	s.cancel()
+}
+
+// SetPaused stops or resumes scheduling health checks, e.g. during planned maintenance of
+// the checked targets. Pausing also aborts checks already scheduled, leaving statuses as
+// they are; after resuming, checks run again from the next tick.
+func (s *HealthCheckScheduler) SetPaused(paused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.paused == paused {
+		return
+	}
+	s.paused = paused
+	if paused {
+		s.cancelChecks()
+		logger.Info("HealthCheckScheduler: Health checks paused")
+		return
+	}
+	s.checks, s.cancelChecks = context.WithCancel(s.ctx)
+	logger.Info("HealthCheckScheduler: Health checks resumed")
+}
+
+// Paused reports whether scheduling is paused
+func (s *HealthCheckScheduler) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
 }
 
 // scheduleHealthChecks creates health check events for all registered services
 func (s *HealthCheckScheduler) scheduleHealthChecks() {
-	services := s.registry.GetAllServices()
+	if s.Paused() {
+		logger.Debug("HealthCheckScheduler: Paused, skipping health checks")
+		return
+	}
+
+	// Don't let a slow store hold up the scheduler past the next tick
+	ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+	defer cancel()
+
+	services := s.registry.GetAllServices(ctx)
 
 	logger.Debug("HealthCheckScheduler: Scheduling health checks for all services",
 		zap.Int("service_count", len(services)),
 	)
 
+	window := time.Duration(float64(s.interval) * s.jitter)
+
 	for _, service := range services {
-		logger.Debug("HealthCheckScheduler: Enqueuing health check event",
-			zap.String("service_key", service.GetKey()),
-			zap.String("service_name", service.ServiceName),
-			zap.String("pod_name", service.PodName),
-		)
+		if window <= 0 {
+			s.enqueueHealthCheck(service.GetKey())
+			continue
+		}
 
-		// Create context with event data
-		ctx := events.NewHealthCheckContext(service.GetKey())
+		// Randomly offset each check within the jitter window
+		s.enqueueAfter(rand.N(window), service.GetKey())
+	}
 
-		// Create event (without deadline for health checks)
-		event := eventqueue.NewEvent(string(events.EventHealthCheck), ctx)
+	logger.Info("HealthCheckScheduler: Scheduled health checks",
+		zap.Int("events_scheduled", len(services)),
+		zap.Duration("jitter_window", window),
+	)
+}
 
-		// Enqueue event
-		s.eventQueue.Enqueue(event)
+// enqueueAfter enqueues a health check after delay, unless the scheduler stops first
+func (s *HealthCheckScheduler) enqueueAfter(delay time.Duration, serviceKey string) {
+	s.clock.AfterFunc(delay, func() {
+		select {
+		case <-s.stopChan:
+		default:
+			s.enqueueHealthCheck(serviceKey)
+		}
+	})
+}
+
+// enqueueHealthCheck creates and enqueues a health check event for a service
+func (s *HealthCheckScheduler) enqueueHealthCheck(serviceKey string) {
+	s.mu.Lock()
+	paused, checks := s.paused, s.checks
+	s.mu.Unlock()
+	if paused {
+		return // Paused while waiting out the jitter
 	}
 
-	logger.Info("HealthCheckScheduler: Scheduled health checks",
-		zap.Int("events_enqueued", len(services)),
+	logger.Debug("HealthCheckScheduler: Enqueuing health check event",
+		zap.String("service_key", serviceKey),
 	)
+
+	// Create context with event data
+	ctx := events.NewHealthCheckContext(checks, serviceKey)
+
+	// Create event (without deadline for health checks)
+	event := eventqueue.NewEvent(string(events.EventHealthCheck), ctx)
+
+	// Enqueue event; a check lost to a full queue is simply done next interval
+	if err := s.eventQueue.Enqueue(event); err != nil {
+		logger.Warn("HealthCheckScheduler: Failed to enqueue health check event, skipping until next interval",
+			zap.String("service_key", serviceKey),
+			zap.Error(err),
+		)
+	}
 }
 
 // ReconcileScheduler periodically schedules reconcile events
 type ReconcileScheduler struct {
 	eventQueue eventqueue.IEventQueue
 	interval   time.Duration
+	clock      clock.Clock
 	stopChan   chan struct{}
 }
 
 // NewReconcileScheduler creates a new reconcile scheduler
-func NewReconcileScheduler(eventQueue eventqueue.IEventQueue, interval time.Duration) *ReconcileScheduler {
+func NewReconcileScheduler(eventQueue eventqueue.IEventQueue, interval time.Duration, opts ...Option) *ReconcileScheduler {
 	return &ReconcileScheduler{
 		eventQueue: eventQueue,
 		interval:   interval,
+		clock:      newOptions(opts).clock,
 		stopChan:   make(chan struct{}),
 	}
 }
@@ -107,12 +229,12 @@ func (s *ReconcileScheduler) Start() {
 		zap.Duration("interval", s.interval),
 	)
 
-	ticker := time.NewTicker(s.interval)
+	ticker := s.clock.NewTicker(s.interval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			logger.Debug("ReconcileScheduler: Ticker fired, scheduling reconcile")
 			s.scheduleReconcile()
 		case <-s.stopChan:
@@ -139,7 +261,78 @@ func (s *ReconcileScheduler) scheduleReconcile() {
 	event := eventqueue.NewEvent(string(events.EventReconcile), ctx)
 
 	// Enqueue event
-	s.eventQueue.Enqueue(event)
+	if err := s.eventQueue.Enqueue(event); err != nil {
+		logger.Warn("ReconcileScheduler: Failed to enqueue reconcile event, skipping until next interval",
+			zap.Error(err),
+		)
+		return
+	}
 
 	logger.Debug("ReconcileScheduler: Reconcile event enqueued")
 }
+
+// RepairScheduler periodically schedules repair events while database writes are waiting
+// to be retried
+type RepairScheduler struct {
+	eventQueue eventqueue.IEventQueue
+	interval   time.Duration
+	clock      clock.Clock
+	pending    func() int // Number of pending repairs
+	stopChan   chan struct{}
+}
+
+// NewRepairScheduler creates a new repair scheduler. pending reports how many repairs are
+// waiting; ticks without any are skipped.
+func NewRepairScheduler(eventQueue eventqueue.IEventQueue, interval time.Duration, pending func() int, opts ...Option) *RepairScheduler {
+	return &RepairScheduler{
+		eventQueue: eventQueue,
+		interval:   interval,
+		clock:      newOptions(opts).clock,
+		pending:    pending,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Start begins the repair scheduling
+func (s *RepairScheduler) Start() {
+	logger.Info("RepairScheduler: Starting repair scheduler",
+		zap.Duration("interval", s.interval),
+	)
+
+	ticker := s.clock.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			if s.pending() > 0 {
+				s.scheduleRepair()
+			}
+		case <-s.stopChan:
+			logger.Info("RepairScheduler: Stopping repair scheduler")
+			return
+		}
+	}
+}
+
+// Stop stops the repair scheduler
+func (s *RepairScheduler) Stop() {
+	logger.Debug("RepairScheduler: Stop signal sent")
+	close(s.stopChan)
+}
+
+// scheduleRepair creates a repair event
+func (s *RepairScheduler) scheduleRepair() {
+	logger.Debug("RepairScheduler: Enqueuing repair event",
+		zap.Int("pending", s.pending()),
+	)
+
+	// Create event (without deadline, like reconcile)
+	event := eventqueue.NewEvent(string(events.EventRepair), events.NewRepairContext())
+
+	if err := s.eventQueue.Enqueue(event); err != nil {
+		logger.Warn("RepairScheduler: Failed to enqueue repair event, skipping until next interval",
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,331 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/storage"
+)
+
+// countingQueue records how many events were enqueued
+type countingQueue struct {
+	enqueued atomic.Int32
+}
+
+func (q *countingQueue) Enqueue(event eventqueue.IEvent) error {
+	q.enqueued.Add(1)
+	return nil
+}
+func (q *countingQueue) Start(ctx context.Context) error                                    { return nil }
+func (q *countingQueue) Stop() error                                                        { return nil }
+func (q *countingQueue) RegisterHandler(eventType string, handler eventqueue.IEventHandler) {}
+func (q *countingQueue) GetQueueSize() int                                                  { return 0 }
+
+func setupRegistry(count int) *registry.Registry {
+	reg := registry.NewRegistry(storage.NewDualStore(nil))
+	for i := 0; i < count; i++ {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName: "user-service",
+			PodName:     "pod-" + string(rune('a'+i)),
+		})
+	}
+	return reg
+}
+
+func TestScheduleHealthChecksWithoutJitter(t *testing.T) {
+	queue := &countingQueue{}
+	s := NewHealthCheckScheduler(context.Background(), setupRegistry(5), queue, time.Second, 0)
+
+	s.scheduleHealthChecks()
+
+	if queue.enqueued.Load() != 5 {
+		t.Errorf("Expected 5 events enqueued immediately, got %d", queue.enqueued.Load())
+	}
+}
+
+func TestScheduleHealthChecksWithJitter(t *testing.T) {
+	queue := &countingQueue{}
+	s := NewHealthCheckScheduler(context.Background(), setupRegistry(5), queue, 100*time.Millisecond, 0.5)
+
+	s.scheduleHealthChecks()
+
+	// All checks land within the 50ms jitter window
+	time.Sleep(100 * time.Millisecond)
+	if queue.enqueued.Load() != 5 {
+		t.Errorf("Expected 5 events enqueued within the jitter window, got %d", queue.enqueued.Load())
+	}
+}
+
+func TestScheduleHealthChecksJitterCancelledOnStop(t *testing.T) {
+	queue := &countingQueue{}
+	s := NewHealthCheckScheduler(context.Background(), setupRegistry(5), queue, time.Hour, 1)
+
+	s.scheduleHealthChecks()
+	s.Stop()
+
+	time.Sleep(20 * time.Millisecond)
+	if queue.enqueued.Load() != 0 {
+		t.Errorf("Expected pending checks to be dropped on stop, got %d enqueued", queue.enqueued.Load())
+	}
+}
+
+// eventsQueue keeps the events enqueued
+type eventsQueue struct {
+	countingQueue
+	mu     sync.Mutex
+	events []eventqueue.IEvent
+}
+
+func (q *eventsQueue) Enqueue(event eventqueue.IEvent) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.events = append(q.events, event)
+	return nil
+}
+
+func TestHealthCheckSchedulerStopCancelsChecks(t *testing.T) {
+	queue := &eventsQueue{}
+	s := NewHealthCheckScheduler(context.Background(), setupRegistry(2), queue, time.Second, 0)
+
+	s.scheduleHealthChecks()
+	if len(queue.events) != 2 {
+		t.Fatalf("Expected 2 events enqueued, got %d", len(queue.events))
+	}
+	for _, event := range queue.events {
+		if event.GetContext().Err() != nil {
+			t.Fatal("Expected health check context to be live before stop")
+		}
+	}
+
+	s.Stop()
+	for _, event := range queue.events {
+		if event.GetContext().Err() == nil {
+			t.Error("Expected health check context to be cancelled by stop")
+		}
+		if _, ok := events.GetEventData(event.GetContext()).(*events.HealthCheckEvent); !ok {
+			t.Error("Expected health check event data to be kept")
+		}
+	}
+}
+
+func TestHealthCheckSchedulerPause(t *testing.T) {
+	queue := &eventsQueue{}
+	s := NewHealthCheckScheduler(context.Background(), setupRegistry(2), queue, time.Second, 0)
+
+	s.scheduleHealthChecks()
+	s.SetPaused(true)
+	for _, event := range queue.events {
+		if event.GetContext().Err() == nil {
+			t.Error("Expected pausing to cancel checks already scheduled")
+		}
+	}
+
+	s.scheduleHealthChecks()
+	if len(queue.events) != 2 {
+		t.Fatalf("Expected no checks while paused, got %d events", len(queue.events)-2)
+	}
+
+	s.SetPaused(false)
+	s.scheduleHealthChecks()
+	if len(queue.events) != 4 {
+		t.Fatalf("Expected checks to resume, got %d events", len(queue.events)-2)
+	}
+	for _, event := range queue.events[2:] {
+		if event.GetContext().Err() != nil {
+			t.Error("Expected checks scheduled after resuming to be live")
+		}
+	}
+}
+
+func TestRepairSchedulerOnlyWhenPending(t *testing.T) {
+	queue := &countingQueue{}
+	var pending atomic.Int32
+	s := NewRepairScheduler(queue, 10*time.Millisecond, func() int { return int(pending.Load()) })
+	go s.Start()
+	defer s.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+	if queue.enqueued.Load() != 0 {
+		t.Fatalf("Expected no repair events without pending repairs, got %d", queue.enqueued.Load())
+	}
+
+	pending.Store(1)
+	time.Sleep(50 * time.Millisecond)
+	if queue.enqueued.Load() == 0 {
+		t.Error("Expected repair events while repairs are pending")
+	}
+}
+
+// recordingQueue keeps the database sync events enqueued
+type recordingQueue struct {
+	countingQueue
+	mu   sync.Mutex
+	keys [][]string
+}
+
+func (q *recordingQueue) Enqueue(event eventqueue.IEvent) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.keys = append(q.keys, events.GetEventData(event.GetContext()).(*events.DatabaseSyncEvent).Keys)
+	return nil
+}
+
+func (q *recordingQueue) synced() [][]string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.Clone(q.keys)
+}
+
+// channelWatchStore streams the keys sent on changes
+type channelWatchStore struct {
+	changes     chan string
+	unsupported bool
+}
+
+func (s *channelWatchStore) WatchServices(ctx context.Context) (storage.ServiceWatch, error) {
+	if s.unsupported {
+		return nil, storage.ErrWatchUnsupported
+	}
+	return s, nil
+}
+
+func (s *channelWatchStore) Next(ctx context.Context) (string, error) {
+	select {
+	case key := <-s.changes:
+		return key, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (s *channelWatchStore) Close(ctx context.Context) error { return nil }
+
+func TestDatabaseWatcherSchedulesChangedKeys(t *testing.T) {
+	queue := &recordingQueue{}
+	db := &channelWatchStore{changes: make(chan string)}
+	s := NewDatabaseWatcher(db, queue, 10*time.Millisecond)
+	go s.Start()
+	defer s.Stop()
+
+	// The first sync after the watch opens reloads everything
+	time.Sleep(50 * time.Millisecond)
+	if synced := queue.synced(); len(synced) != 1 || synced[0] != nil {
+		t.Fatalf("Expected one full sync after the watch opened, got %v", synced)
+	}
+	if !s.Watching() {
+		t.Error("Expected the watcher to report watching")
+	}
+
+	db.changes <- "user-service:pod-1"
+	db.changes <- "user-service:pod-1"
+	time.Sleep(50 * time.Millisecond)
+	if synced := queue.synced(); len(synced) != 2 || !slices.Equal(synced[1], []string{"user-service:pod-1"}) {
+		t.Errorf("Expected a sync of the changed key, got %v", synced)
+	}
+}
+
+func TestDatabaseWatcherUnsupported(t *testing.T) {
+	queue := &recordingQueue{}
+	s := NewDatabaseWatcher(&channelWatchStore{unsupported: true}, queue, 10*time.Millisecond)
+	go s.Start()
+	defer s.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+	if s.Watching() || len(queue.synced()) != 0 {
+		t.Error("Expected no watch and no syncs when the database cannot stream changes")
+	}
+}
+
+// pingStore fails pings while down
+type pingStore struct {
+	storage.DatabaseStore
+	down atomic.Bool
+}
+
+func (s *pingStore) Ping(ctx context.Context) error {
+	if s.down.Load() {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// reconnectingStore is a pingStore whose reconnects are counted and bring it back up
+type reconnectingStore struct {
+	pingStore
+	reconnects atomic.Int32
+	stayDown   bool // Reconnects fail too
+}
+
+func (s *reconnectingStore) Reconnect(ctx context.Context) error {
+	s.reconnects.Add(1)
+	if s.stayDown {
+		return errors.New("connection refused")
+	}
+	s.down.Store(false)
+	return nil
+}
+
+func TestDatabaseMonitorReconnectsAfterThreshold(t *testing.T) {
+	db := &reconnectingStore{}
+	s := NewDatabaseMonitor(db, time.Second, 2)
+
+	db.down.Store(true)
+	s.check()
+	if stats := s.Stats(); stats.State != models.ConnectionDisconnected || stats.ConsecutiveFailures != 1 {
+		t.Fatalf("Expected disconnected after one failed ping, got %+v", stats)
+	}
+	if db.reconnects.Load() != 0 {
+		t.Fatal("Expected no reconnect below the threshold")
+	}
+
+	s.check()
+	if db.reconnects.Load() != 1 {
+		t.Fatalf("Expected a reconnect at the threshold, got %d", db.reconnects.Load())
+	}
+	stats := s.Stats()
+	if stats.State != models.ConnectionConnected || stats.ConsecutiveFailures != 0 || stats.Reconnects != 1 {
+		t.Errorf("Expected connected after reconnecting, got %+v", stats)
+	}
+}
+
+func TestDatabaseMonitorBacksOffFailedReconnects(t *testing.T) {
+	db := &reconnectingStore{stayDown: true}
+	s := NewDatabaseMonitor(db, time.Second, 1)
+
+	db.down.Store(true)
+	s.check()
+	s.check() // Within the backoff
+	if db.reconnects.Load() != 1 {
+		t.Fatalf("Expected one reconnect attempt within the backoff, got %d", db.reconnects.Load())
+	}
+	if stats := s.Stats(); stats.State != models.ConnectionDisconnected || stats.FailedReconnects != 1 || stats.LastError == "" {
+		t.Errorf("Expected a failed reconnect to be reported, got %+v", stats)
+	}
+
+	db.down.Store(false)
+	s.check()
+	if stats := s.Stats(); stats.State != models.ConnectionConnected {
+		t.Errorf("Expected connected once the database answers, got %+v", stats)
+	}
+}
+
+func TestDatabaseMonitorWithoutReconnect(t *testing.T) {
+	db := &pingStore{}
+	s := NewDatabaseMonitor(db, time.Second, 1)
+
+	db.down.Store(true)
+	s.check()
+	s.check()
+	if stats := s.Stats(); stats.State != models.ConnectionDisconnected || stats.ConsecutiveFailures != 2 {
+		t.Errorf("Expected failures to be counted without reconnecting, got %+v", stats)
+	}
+}
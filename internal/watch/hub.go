@@ -0,0 +1,123 @@
+package watch
+
+import (
+	"sync"
+
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultBufferSize is the number of changes buffered per watcher before it is
+// considered too slow and disconnected
+const DefaultBufferSize = 64
+
+// Hub fans out registry changes to connected watchers
+type Hub struct {
+	mu         sync.Mutex
+	watchers   map[*Watcher]struct{}
+	bufferSize int
+	closed     bool
+}
+
+// Watcher receives the changes published to a Hub, optionally limited to one service
+type Watcher struct {
+	serviceName string // Empty means all services
+	changes     chan *models.ChangeRecord
+}
+
+// NewHub creates a new watch hub
+func NewHub() *Hub {
+	return &Hub{
+		watchers:   make(map[*Watcher]struct{}),
+		bufferSize: DefaultBufferSize,
+	}
+}
+
+// Subscribe registers a watcher for changes to serviceName (empty for all services).
+// The caller must call Unsubscribe when done.
+func (h *Hub) Subscribe(serviceName string) *Watcher {
+	watcher := &Watcher{
+		serviceName: serviceName,
+		changes:     make(chan *models.ChangeRecord, h.bufferSize),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(watcher.changes)
+		return watcher
+	}
+	h.watchers[watcher] = struct{}{}
+
+	logger.Debug("WatchHub: Watcher subscribed",
+		zap.String("service_name", serviceName),
+		zap.Int("watcher_count", len(h.watchers)),
+	)
+	return watcher
+}
+
+// Unsubscribe removes a watcher and closes its channel. Safe to call more than once.
+func (h *Hub) Unsubscribe(watcher *Watcher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(watcher)
+}
+
+// Publish sends a change to every matching watcher without blocking.
+// A watcher whose buffer is full is disconnected; it can catch up from the change feed.
+func (h *Hub) Publish(change *models.ChangeRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for watcher := range h.watchers {
+		if watcher.serviceName != "" && watcher.serviceName != change.ServiceName {
+			continue
+		}
+
+		select {
+		case watcher.changes <- change:
+		default:
+			logger.Warn("WatchHub: Watcher too slow, disconnecting",
+				zap.String("service_name", watcher.serviceName),
+			)
+			h.remove(watcher)
+		}
+	}
+}
+
+// Close disconnects all watchers and refuses new ones
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for watcher := range h.watchers {
+		h.remove(watcher)
+	}
+}
+
+// Count returns the number of connected watchers
+func (h *Hub) Count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.watchers)
+}
+
+// remove deletes and closes a watcher if it is still registered. Caller must hold h.mu.
+func (h *Hub) remove(watcher *Watcher) {
+	if _, ok := h.watchers[watcher]; !ok {
+		return
+	}
+	delete(h.watchers, watcher)
+	close(watcher.changes)
+}
+
+// Changes returns the channel changes are delivered on.
+// It is closed when the watcher is unsubscribed or disconnected.
+func (w *Watcher) Changes() <-chan *models.ChangeRecord {
+	return w.changes
+}
@@ -0,0 +1,71 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/chronnie/governance/models"
+)
+
+func TestHubPublishFiltersByService(t *testing.T) {
+	hub := NewHub()
+	all := hub.Subscribe("")
+	users := hub.Subscribe("user-service")
+	defer hub.Unsubscribe(all)
+	defer hub.Unsubscribe(users)
+
+	hub.Publish(&models.ChangeRecord{ServiceName: "order-service", EventType: models.EventTypeRegister})
+	hub.Publish(&models.ChangeRecord{ServiceName: "user-service", EventType: models.EventTypeRegister})
+
+	if got := len(all.Changes()); got != 2 {
+		t.Errorf("Expected 2 changes for unfiltered watcher, got %d", got)
+	}
+	if got := len(users.Changes()); got != 1 {
+		t.Fatalf("Expected 1 change for filtered watcher, got %d", got)
+	}
+	if change := <-users.Changes(); change.ServiceName != "user-service" {
+		t.Errorf("Expected user-service change, got %s", change.ServiceName)
+	}
+}
+
+func TestHubDisconnectsSlowWatcher(t *testing.T) {
+	hub := NewHub()
+	watcher := hub.Subscribe("")
+
+	for i := 0; i <= DefaultBufferSize; i++ {
+		hub.Publish(&models.ChangeRecord{ServiceName: "user-service"})
+	}
+
+	if hub.Count() != 0 {
+		t.Errorf("Expected slow watcher to be removed, %d watchers left", hub.Count())
+	}
+
+	// Buffered changes are still readable, then the channel is closed
+	for range DefaultBufferSize {
+		<-watcher.Changes()
+	}
+	if _, ok := <-watcher.Changes(); ok {
+		t.Error("Expected channel to be closed")
+	}
+
+	// Unsubscribing a disconnected watcher is a no-op
+	hub.Unsubscribe(watcher)
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub()
+	watcher := hub.Subscribe("")
+
+	hub.Close()
+	if _, ok := <-watcher.Changes(); ok {
+		t.Error("Expected channel to be closed after Close")
+	}
+
+	// New watchers are refused
+	late := hub.Subscribe("")
+	if _, ok := <-late.Changes(); ok {
+		t.Error("Expected channel to be closed for watcher subscribed after Close")
+	}
+	if hub.Count() != 0 {
+		t.Errorf("Expected no watchers, got %d", hub.Count())
+	}
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// AuditSink receives an audit record for every registry mutation. Audit is called on the
+// event handling path and should return quickly.
+type AuditSink interface {
+	Audit(record models.AuditRecord)
+}
+
+// WithAuditSink records every registry mutation, with the caller that requested it, to sink
+func WithAuditSink(sink AuditSink) WorkerOption {
+	return func(w *EventWorker) {
+		w.auditSink = sink
+	}
+}
+
+// JSONAuditSink writes audit records as JSON lines, one record per line.
+// It writes directly to its writer, independent of the application logger and its level.
+type JSONAuditSink struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+var _ AuditSink = (*JSONAuditSink)(nil)
+
+// NewJSONAuditSink creates a sink writing JSON lines to writer, e.g. an append-only file
+func NewJSONAuditSink(writer io.Writer) *JSONAuditSink {
+	return &JSONAuditSink{writer: writer}
+}
+
+// Audit writes the record as a single line
+func (s *JSONAuditSink) Audit(record models.AuditRecord) {
+	line, _ := json.Marshal(record) // AuditRecord has only plain fields, marshalling can't fail
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.writer.Write(line); err != nil {
+		logger.Error("Failed to write audit record",
+			zap.String("service_key", record.ServiceKey),
+			zap.String("event_type", string(record.EventType)),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/storage"
+)
+
+func TestRecordChangeAudits(t *testing.T) {
+	var buf bytes.Buffer
+	dualStore := storage.NewDualStore(nil)
+	w := NewEventWorker(registry.NewRegistry(dualStore), nil, nil, dualStore, WithAuditSink(NewJSONAuditSink(&buf)))
+
+	service := &models.ServiceInfo{ServiceName: "user-service", PodName: "pod-1", Status: models.StatusUnknown}
+	caller := models.Caller{Principal: "token:0123456789ab", RemoteAddr: "10.0.0.1:5000"}
+
+	w.recordChange(events.WithCaller(context.Background(), caller), models.EventTypeRegister, service)
+	service.Status = models.StatusHealthy
+	w.recordChange(context.Background(), models.EventTypeUpdate, service)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 audit lines, got %d: %q", len(lines), buf.String())
+	}
+
+	var register, update models.AuditRecord
+	if err := json.Unmarshal([]byte(lines[0]), &register); err != nil {
+		t.Fatalf("Failed to decode audit line: %v", err)
+	}
+	json.Unmarshal([]byte(lines[1]), &update)
+
+	if register.EventType != models.EventTypeRegister || register.ServiceKey != "user-service:pod-1" || register.Caller != caller {
+		t.Errorf("Unexpected register record: %+v", register)
+	}
+	if register.Timestamp.IsZero() {
+		t.Error("Expected timestamp to be set")
+	}
+
+	// Changes without a caller are made by the manager itself
+	if update.Caller.Principal != models.PrincipalSystem || update.Status != models.StatusHealthy {
+		t.Errorf("Unexpected update record: %+v", update)
+	}
+}
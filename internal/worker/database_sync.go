@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// WithDatabaseWatch skips the full database sync during reconcile while watching reports
+// true, i.e. while changes to the database are streamed and reloaded by database sync events
+func WithDatabaseWatch(watching func() bool) WorkerOption {
+	return func(w *EventWorker) {
+		w.databaseWatching = watching
+	}
+}
+
+// handleDatabaseSync reloads services the database reported changed, or the whole database
+// when the event has no keys, e.g. after the database watch (re)connected
+func (w *EventWorker) handleDatabaseSync(ctx context.Context, event eventqueue.IEvent) error {
+	eventData := events.GetEventData(ctx)
+	syncEvent, ok := eventData.(*events.DatabaseSyncEvent)
+	if !ok {
+		logger.Warn("Invalid event data type for database sync event")
+		return nil
+	}
+
+	if syncEvent.Keys == nil {
+		w.syncFromDatabase(ctx)
+		return nil
+	}
+
+	synced, err := w.dualStore.SyncServicesFromDatabase(ctx, syncEvent.Keys)
+	w.registry.Invalidate()
+	if err != nil {
+		logger.Error("Failed to reload changed services from database",
+			zap.Int("changed", len(syncEvent.Keys)),
+			zap.Int("services_synced", synced),
+			zap.Error(err),
+		)
+		return nil
+	}
+	logger.Debug("Reloaded changed services from database",
+		zap.Int("changed", len(syncEvent.Keys)),
+		zap.Int("services_synced", synced),
+	)
+	return nil
+}
+
+// syncFromDatabase loads the whole database into the cache
+func (w *EventWorker) syncFromDatabase(ctx context.Context) {
+	logger.Info("Database persistence enabled - syncing from database to cache")
+	servicesSynced, subsSynced, err := w.dualStore.SyncFromDatabase(ctx)
+	w.registry.Invalidate()
+	if err != nil {
+		logger.Error("Failed to sync from database", zap.Error(err))
+		return
+	}
+	logger.Info("Database sync completed successfully",
+		zap.Int("services_synced", servicesSynced),
+		zap.Int("subscriptions_synced", subsSynced),
+	)
+}
@@ -0,0 +1,28 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/chronnie/governance/internal/notifier"
+	"github.com/chronnie/governance/models"
+)
+
+// notifySubscribers sends payload to the subscribers receiving the full format, and a delta
+// payload holding only the changed pods to those that opted into the delta format
+func (w *EventWorker) notifySubscribers(ctx context.Context, subscribers []*models.ServiceInfo, payload *models.NotificationPayload, changed ...*models.ServiceInfo) {
+	var full, delta []*models.ServiceInfo
+	for _, subscriber := range subscribers {
+		if subscriber.NotificationFormat == models.NotificationFormatDelta {
+			delta = append(delta, subscriber)
+		} else {
+			full = append(full, subscriber)
+		}
+	}
+
+	if len(full) > 0 {
+		w.notifier.NotifySubscribers(ctx, full, payload)
+	}
+	if len(delta) > 0 {
+		w.notifier.NotifySubscribers(ctx, delta, notifier.BuildDeltaPayload(payload, changed))
+	}
+}
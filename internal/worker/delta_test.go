@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/notifier"
+	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/storage"
+)
+
+func TestDeltaNotificationFormat(t *testing.T) {
+	type notification struct {
+		path    string
+		payload models.NotificationPayload
+	}
+	received := make(chan notification, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := notification{path: r.URL.Path}
+		json.NewDecoder(r.Body).Decode(&n.payload)
+		received <- n
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore)
+	w := NewEventWorker(reg, notifier.NewNotifier(time.Second), nil, dualStore)
+
+	for _, pod := range []string{"pod-1", "pod-2", "pod-3"} {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     "user-service",
+			PodName:         pod,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+	}
+	for _, format := range []models.NotificationFormat{models.NotificationFormatFull, models.NotificationFormatDelta} {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:        "order-service",
+			PodName:            string(format),
+			Providers:          []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.20", Port: 8080}},
+			HealthCheckURL:     "http://192.168.1.20:8080/health",
+			NotificationURL:    server.URL + "/" + string(format),
+			Subscriptions:      []string{"user-service"},
+			NotificationFormat: format,
+		})
+	}
+
+	update := &models.ServiceUpdate{Labels: map[string]string{"version": "v2"}}
+	ctx := events.NewUpdateContext("user-service", "pod-2", update)
+	if err := w.handleUpdate(ctx, eventqueue.NewEvent(string(events.EventUpdate), ctx)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for range 2 {
+		var n notification
+		select {
+		case n = <-received:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected a notification per subscriber")
+		}
+
+		payload := n.payload
+		switch n.path {
+		case "/full":
+			if len(payload.Pods) != 3 || payload.Format != "" {
+				t.Errorf("Expected a full payload with every pod, got %+v", payload)
+			}
+		case "/delta":
+			if len(payload.Pods) != 1 || payload.Pods[0].PodName != "pod-2" || payload.Format != models.NotificationFormatDelta {
+				t.Errorf("Expected a delta payload with only the changed pod, got %+v", payload)
+			}
+			if payload.EventType != models.EventTypeUpdate {
+				t.Errorf("Expected event type %s, got %s", models.EventTypeUpdate, payload.EventType)
+			}
+		default:
+			t.Errorf("Unexpected notification path %s", n.path)
+		}
+	}
+}
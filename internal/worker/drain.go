@@ -0,0 +1,30 @@
+package worker
+
+import "github.com/chronnie/governance/pkg/logger"
+
+// SetDrainMode turns drain mode on or off. While draining, health status changes (e.g. from
+// an on-demand check) are still applied and recorded, but subscribers are not notified;
+// they catch up on the next reconcile. Entering drain mode drops notifications waiting out
+// the dwell time; leaving it forgets consecutive check results gathered before, so checks
+// start over.
+func (w *EventWorker) SetDrainMode(enabled bool) {
+	if w.drainMode.Swap(enabled) == enabled {
+		return
+	}
+
+	if enabled {
+		logger.Info("Drain mode enabled, health status notifications suppressed")
+		w.DiscardPendingNotifications()
+		return
+	}
+
+	logger.Info("Drain mode disabled, health status notifications resumed")
+	w.streaksMu.Lock()
+	clear(w.healthStreaks)
+	w.streaksMu.Unlock()
+}
+
+// DrainMode reports whether drain mode is on
+func (w *EventWorker) DrainMode() bool {
+	return w.drainMode.Load()
+}
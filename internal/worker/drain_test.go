@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/notifier"
+	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/storage"
+)
+
+func TestDrainModeSuppressesNotifications(t *testing.T) {
+	var healthStatus atomic.Int32
+	healthStatus.Store(http.StatusOK)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(healthStatus.Load()))
+	}))
+	defer target.Close()
+
+	received := make(chan *models.NotificationPayload, 10)
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload models.NotificationPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- &payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer subscriber.Close()
+
+	ctx := context.Background()
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore)
+	w := NewEventWorker(reg, notifier.NewNotifier(time.Second), notifier.NewHealthChecker(time.Second, 0), dualStore)
+
+	pod, _ := reg.Register(ctx, &models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "127.0.0.1", Port: 8080}},
+		HealthCheckURL:  target.URL,
+		NotificationURL: "http://127.0.0.1:8080/notify",
+	})
+	reg.Register(ctx, &models.ServiceRegistration{
+		ServiceName:     "order-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "127.0.0.1", Port: 8081}},
+		HealthCheckURL:  "http://127.0.0.1:8081/health",
+		NotificationURL: subscriber.URL,
+		Subscriptions:   []string{"user-service"},
+	})
+
+	check := func() {
+		eventCtx := events.NewHealthCheckContext(ctx, pod.GetKey())
+		if err := w.handleHealthCheck(eventCtx, eventqueue.NewEvent(string(events.EventHealthCheck), eventCtx)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// While draining the status still changes, but subscribers don't hear about it
+	w.SetDrainMode(true)
+	check()
+	if service, _ := reg.Get(ctx, pod.GetKey()); service.Status != models.StatusHealthy {
+		t.Errorf("Expected the status to change while draining, got %s", service.Status)
+	}
+	select {
+	case payload := <-received:
+		t.Fatalf("Expected no notification while draining, got %+v", payload.Pods)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Notifications resume with drain mode off
+	w.SetDrainMode(false)
+	healthStatus.Store(http.StatusServiceUnavailable)
+	check()
+	select {
+	case payload := <-received:
+		if len(payload.Pods) != 1 || payload.Pods[0].Status != models.StatusUnhealthy {
+			t.Errorf("Expected an unhealthy pod in the notification, got %+v", payload.Pods)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a notification after drain mode was turned off")
+	}
+}
@@ -0,0 +1,145 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/clock"
+	"github.com/chronnie/governance/internal/notifier"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// pendingStatusChange is a health status change waiting out the dwell time before
+// subscribers are notified
+type pendingStatusChange struct {
+	notified models.ServiceStatus // Status subscribers last heard about
+	timer    clock.Timer
+}
+
+// WithNotificationDwell delays subscriber notifications for health status changes until the
+// new status has held for dwell. A pod that flaps back to the status subscribers last heard
+// about within the dwell time causes no notification at all. 0 notifies immediately.
+// The registry, change feed and watchers still see every change right away.
+func WithNotificationDwell(dwell time.Duration) WorkerOption {
+	return func(w *EventWorker) {
+		w.notificationDwell = dwell
+	}
+}
+
+// notifyHealthChange notifies subscribers that a pod's status changed from previous to
+// service.Status, after the dwell time if one is configured
+func (w *EventWorker) notifyHealthChange(ctx context.Context, service *models.ServiceInfo, previous models.ServiceStatus, eventID uint64) {
+	if w.notificationDwell <= 0 {
+		w.notifyServiceUpdate(ctx, service, eventID)
+		return
+	}
+
+	key := service.GetKey()
+
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+
+	if pending, exists := w.pendingChanges[key]; exists {
+		pending.timer.Stop()
+		delete(w.pendingChanges, key)
+
+		// Flapped back before the dwell time passed, subscribers never need to know
+		if service.Status == pending.notified {
+			logger.Info("Health status reverted within dwell time, notification suppressed",
+				zap.String("service_key", key),
+				zap.String("status", string(service.Status)),
+			)
+			return
+		}
+		previous = pending.notified
+	}
+
+	pending := &pendingStatusChange{notified: previous}
+	pending.timer = w.clock.AfterFunc(w.notificationDwell, func() {
+		w.firePendingStatusChange(key, pending, eventID)
+	})
+	w.pendingChanges[key] = pending
+
+	logger.Debug("Health status change pending until dwell time passes",
+		zap.String("service_key", key),
+		zap.String("new_status", string(service.Status)),
+		zap.Duration("dwell", w.notificationDwell),
+	)
+}
+
+// firePendingStatusChange notifies subscribers once a pending change has held for the dwell time
+func (w *EventWorker) firePendingStatusChange(key string, pending *pendingStatusChange, eventID uint64) {
+	w.pendingMu.Lock()
+	if w.pendingChanges[key] != pending {
+		w.pendingMu.Unlock()
+		return // Superseded or cancelled
+	}
+	delete(w.pendingChanges, key)
+	w.pendingMu.Unlock()
+
+	ctx := context.Background()
+	service, exists := w.registry.Get(ctx, key)
+	if !exists || service.Status == pending.notified || w.drainMode.Load() {
+		return
+	}
+
+	w.notifyServiceUpdate(ctx, service, eventID)
+}
+
+// cancelPendingStatusChange drops a pod's pending notification, e.g. when it unregisters
+func (w *EventWorker) cancelPendingStatusChange(key string) {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+
+	if pending, exists := w.pendingChanges[key]; exists {
+		pending.timer.Stop()
+		delete(w.pendingChanges, key)
+	}
+}
+
+// DiscardPendingNotifications drops health status notifications still waiting out the dwell
+// time, so none are sent after shutdown. Subscribers catch up on the next reconcile.
+func (w *EventWorker) DiscardPendingNotifications() {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+
+	if len(w.pendingChanges) > 0 {
+		logger.Info("Discarding pending health status notifications",
+			zap.Int("count", len(w.pendingChanges)),
+		)
+	}
+	for key, pending := range w.pendingChanges {
+		pending.timer.Stop()
+		delete(w.pendingChanges, key)
+	}
+}
+
+// notifyServiceUpdate sends subscribers of a pod's service an update notification with its
+// current pods, or only the changed pod in the delta format
+func (w *EventWorker) notifyServiceUpdate(ctx context.Context, changed *models.ServiceInfo, eventID uint64) {
+	serviceName := changed.QualifiedName()
+	// Get all pods of this service
+	servicePods := w.registry.GetByServiceName(ctx, serviceName)
+
+	// Build notification payload
+	payload := notifier.BuildNotificationPayload(
+		serviceName,
+		models.EventTypeUpdate,
+		servicePods,
+	)
+	payload.EventID = eventID
+	payload.RequestID = events.GetRequestID(ctx)
+	payload.Revision = w.registry.Revision()
+
+	// Notify all subscribers
+	subscribers := w.registry.GetSubscriberServices(ctx, serviceName)
+	logger.Info("Notifying subscribers of health status change",
+		zap.String("service_name", serviceName),
+		zap.Int("subscriber_count", len(subscribers)),
+		events.RequestIDField(ctx),
+	)
+	w.notifySubscribers(ctx, subscribers, payload, changed)
+}
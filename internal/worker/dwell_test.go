@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chronnie/governance/internal/notifier"
+	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/storage"
+)
+
+func TestNotificationDwell(t *testing.T) {
+	received := make(chan *models.NotificationPayload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload models.NotificationPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- &payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore)
+	w := NewEventWorker(reg, notifier.NewNotifier(time.Second), nil, dualStore, WithNotificationDwell(100*time.Millisecond))
+
+	pod, _ := reg.Register(ctx, &models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	})
+	reg.Register(ctx, &models.ServiceRegistration{
+		ServiceName:     "order-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.20", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.20:8080/health",
+		NotificationURL: server.URL,
+		Subscriptions:   []string{"user-service"},
+	})
+
+	setStatus := func(status models.ServiceStatus) {
+		previous := pod.Status
+		reg.UpdateHealthStatus(ctx, pod.GetKey(), status)
+		pod.Status = status
+		w.notifyHealthChange(ctx, pod, previous, 1)
+	}
+
+	// unknown -> healthy -> unknown within the dwell time: nothing sent
+	setStatus(models.StatusHealthy)
+	setStatus(models.StatusUnknown)
+	select {
+	case payload := <-received:
+		t.Fatalf("Expected flap to be suppressed, got notification with %+v", payload.Pods)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// A change that holds is sent once the dwell time passes
+	setStatus(models.StatusHealthy)
+	select {
+	case payload := <-received:
+		if len(payload.Pods) != 1 || payload.Pods[0].Status != models.StatusHealthy {
+			t.Errorf("Expected healthy pod in notification, got %+v", payload.Pods)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a notification after the dwell time")
+	}
+
+	// Pending changes are dropped on unregister
+	setStatus(models.StatusUnhealthy)
+	w.cancelPendingStatusChange(pod.GetKey())
+	select {
+	case <-received:
+		t.Error("Expected cancelled change not to notify")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
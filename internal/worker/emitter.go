@@ -0,0 +1,183 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// EventEmitter receives every registry change the worker records, e.g. to stream it to an
+// external system. Emit is called on the event handling path and must never block.
+type EventEmitter interface {
+	Emit(change *models.ChangeRecord)
+}
+
+// WithEventEmitter sends every recorded change to the emitter
+func WithEventEmitter(emitter EventEmitter) WorkerOption {
+	return func(w *EventWorker) {
+		w.emitter = emitter
+	}
+}
+
+// DefaultKafkaBufferSize is used when NewKafkaEmitter is given a buffer size <= 0
+const DefaultKafkaBufferSize = 1000
+
+// kafkaBatchSize is the maximum number of records written to Kafka in one request
+const kafkaBatchSize = 100
+
+// messageWriter is the subset of *kafka.Writer used by KafkaEmitter
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaEmitter publishes change records as JSON to a Kafka topic, keyed by service key so
+// the changes of one pod stay ordered within a partition.
+// Records are buffered in memory and written by a background goroutine. When the buffer is
+// full (Kafka is slow or unavailable) new records are dropped and counted, so the registry
+// never waits on Kafka.
+type KafkaEmitter struct {
+	writer  messageWriter
+	records chan *models.ChangeRecord
+	done    chan struct{} // Closed when the background writer has exited
+	ctx     context.Context
+	cancel  context.CancelFunc // Aborts in-flight writes when Close times out
+
+	mu      sync.RWMutex // Guards closed against Emit racing with Close
+	closed  bool
+	dropped atomic.Uint64
+}
+
+var _ EventEmitter = (*KafkaEmitter)(nil)
+
+// NewKafkaEmitter creates an emitter writing to topic on the given brokers
+func NewKafkaEmitter(brokers []string, topic string, bufferSize int) *KafkaEmitter {
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		MaxAttempts:  3,
+		BatchSize:    kafkaBatchSize,
+		BatchTimeout: 10 * time.Millisecond,
+		WriteTimeout: 5 * time.Second,
+	}
+	return newKafkaEmitter(writer, bufferSize)
+}
+
+func newKafkaEmitter(writer messageWriter, bufferSize int) *KafkaEmitter {
+	if bufferSize <= 0 {
+		bufferSize = DefaultKafkaBufferSize
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &KafkaEmitter{
+		writer:  writer,
+		records: make(chan *models.ChangeRecord, bufferSize),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	go e.run()
+	return e
+}
+
+// Emit queues the change for publishing, dropping it if the buffer is full
+func (e *KafkaEmitter) Emit(change *models.ChangeRecord) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closed {
+		return
+	}
+
+	select {
+	case e.records <- change:
+	default:
+		// Log the first drop and then every 100th, Kafka being down would flood the log otherwise
+		if dropped := e.dropped.Add(1); dropped%100 == 1 {
+			logger.Warn("Kafka: Event buffer full, dropping change records",
+				zap.String("service_key", change.ServiceKey),
+				zap.Uint64("dropped_total", dropped),
+			)
+		}
+	}
+}
+
+// Dropped returns the number of change records dropped because the buffer was full
+func (e *KafkaEmitter) Dropped() uint64 {
+	return e.dropped.Load()
+}
+
+// Close stops accepting records and waits until buffered ones are written or ctx is done.
+// Records still buffered when ctx is done are lost.
+func (e *KafkaEmitter) Close(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.records)
+	}
+	e.mu.Unlock()
+	defer e.cancel()
+
+	var drainErr error
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		drainErr = errors.Join(errors.New("timed out writing buffered change records to kafka"), ctx.Err())
+		e.cancel()
+		<-e.done
+	}
+
+	return errors.Join(drainErr, e.writer.Close())
+}
+
+// run writes buffered records in batches until the buffer is closed and drained
+func (e *KafkaEmitter) run() {
+	defer close(e.done)
+
+	batch := make([]kafka.Message, 0, kafkaBatchSize)
+	for change := range e.records {
+		batch = append(batch[:0], e.message(change))
+
+		// Take whatever else is already buffered, up to a full batch
+	fill:
+		for len(batch) < kafkaBatchSize {
+			select {
+			case next, ok := <-e.records:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, e.message(next))
+			default:
+				break fill
+			}
+		}
+
+		if err := e.writer.WriteMessages(e.ctx, batch...); err != nil {
+			logger.Error("Kafka: Failed to write change records, dropping them",
+				zap.Int("count", len(batch)),
+				zap.Error(err),
+			)
+			e.dropped.Add(uint64(len(batch)))
+		}
+	}
+}
+
+// message encodes a change record as a Kafka message keyed by service key
+func (e *KafkaEmitter) message(change *models.ChangeRecord) kafka.Message {
+	value, _ := json.Marshal(change) // ChangeRecord has only plain fields, marshalling can't fail
+	return kafka.Message{
+		Key:   []byte(change.ServiceKey),
+		Value: value,
+		Time:  change.Timestamp,
+	}
+}
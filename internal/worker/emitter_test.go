@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/storage"
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeWriter records written messages; writes block until release is closed
+type fakeWriter struct {
+	messages chan kafka.Message
+	release  chan struct{}
+	closed   bool
+}
+
+func newFakeWriter() *fakeWriter {
+	release := make(chan struct{})
+	close(release)
+	return &fakeWriter{messages: make(chan kafka.Message, 100), release: release}
+}
+
+func (f *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	select {
+	case <-f.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	for _, msg := range msgs {
+		f.messages <- msg
+	}
+	return nil
+}
+
+func (f *fakeWriter) Close() error {
+	f.closed = true
+	return nil
+}
+
+// recordingEmitter collects emitted changes
+type recordingEmitter struct {
+	changes []*models.ChangeRecord
+}
+
+func (r *recordingEmitter) Emit(change *models.ChangeRecord) {
+	r.changes = append(r.changes, change)
+}
+
+func TestKafkaEmitterWritesRecords(t *testing.T) {
+	writer := newFakeWriter()
+	emitter := newKafkaEmitter(writer, 10)
+
+	emitter.Emit(&models.ChangeRecord{
+		Timestamp:  time.Now(),
+		EventType:  models.EventTypeRegister,
+		ServiceKey: "user-service:pod-1",
+		Status:     models.StatusUnknown,
+	})
+
+	select {
+	case msg := <-writer.messages:
+		if string(msg.Key) != "user-service:pod-1" {
+			t.Errorf("Expected message keyed by service key, got %s", msg.Key)
+		}
+		var change models.ChangeRecord
+		if err := json.Unmarshal(msg.Value, &change); err != nil {
+			t.Fatalf("Failed to decode message: %v", err)
+		}
+		if change.EventType != models.EventTypeRegister || change.Status != models.StatusUnknown {
+			t.Errorf("Unexpected record: %+v", change)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected record to be written")
+	}
+
+	if err := emitter.Close(context.Background()); err != nil {
+		t.Errorf("Unexpected close error: %v", err)
+	}
+	if !writer.closed {
+		t.Error("Expected writer to be closed")
+	}
+}
+
+func TestKafkaEmitterDropsWhenUnavailable(t *testing.T) {
+	writer := newFakeWriter()
+	writer.release = make(chan struct{}) // Kafka hangs
+	emitter := newKafkaEmitter(writer, 2)
+
+	done := make(chan struct{})
+	go func() {
+		for range 10 {
+			emitter.Emit(&models.ChangeRecord{ServiceKey: "user-service:pod-1"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Emit blocked while Kafka was unavailable")
+	}
+
+	// One record may be held by the blocked write, two buffered, the rest dropped
+	if dropped := emitter.Dropped(); dropped < 7 {
+		t.Errorf("Expected at least 7 dropped records, got %d", dropped)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := emitter.Close(ctx); err == nil {
+		t.Error("Expected close to time out while Kafka is unavailable")
+	}
+
+	// Emitting after close is a no-op
+	emitter.Emit(&models.ChangeRecord{ServiceKey: "user-service:pod-1"})
+}
+
+func TestRecordChangeEmits(t *testing.T) {
+	dualStore := storage.NewDualStore(nil)
+	emitter := &recordingEmitter{}
+	w := NewEventWorker(registry.NewRegistry(dualStore), nil, nil, dualStore, WithEventEmitter(emitter))
+
+	w.recordChange(context.Background(), models.EventTypeUpdate, &models.ServiceInfo{
+		ServiceName: "user-service",
+		PodName:     "pod-1",
+		Status:      models.StatusUnhealthy,
+	})
+
+	if len(emitter.changes) != 1 {
+		t.Fatalf("Expected 1 emitted change, got %d", len(emitter.changes))
+	}
+	change := emitter.changes[0]
+	if change.ServiceKey != "user-service:pod-1" || change.EventType != models.EventTypeUpdate || change.Status != models.StatusUnhealthy {
+		t.Errorf("Unexpected change: %+v", change)
+	}
+	if change.Timestamp.IsZero() {
+		t.Error("Expected timestamp to be set")
+	}
+}
@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"slices"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/notifier"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// handleImport replaces the registry with an imported snapshot. Every restored pod is
+// recorded as registered and every dropped pod as unregistered, then subscribers of each
+// service affected get a reconcile notification with its pods after the import.
+func (w *EventWorker) handleImport(ctx context.Context, event eventqueue.IEvent) error {
+	eventData := events.GetEventData(ctx)
+	importEvent, ok := eventData.(*events.ImportEvent)
+	if !ok {
+		logger.Warn("Invalid event data type for import event")
+		return nil
+	}
+
+	services := importEvent.Snapshot.Services
+	logger.Info("Processing import event",
+		zap.Int("services", len(services)),
+		events.RequestIDField(ctx),
+	)
+
+	// Services whose subscribers must hear about the import, before it runs
+	affected := make(map[string]bool)
+	for _, service := range w.registry.GetAllServices(ctx) {
+		affected[service.QualifiedName()] = true
+	}
+
+	removed, err := w.registry.Restore(ctx, services)
+	for _, service := range removed {
+		w.cancelPendingStatusChange(service.GetKey())
+		if !service.IsTombstone() {
+			w.recordChange(ctx, models.EventTypeUnregister, service)
+		}
+	}
+	if err != nil {
+		logger.Error("Failed to import snapshot, registry partially restored",
+			zap.Error(err),
+		)
+		return err // Reported back to the caller
+	}
+
+	for _, service := range services {
+		w.cancelPendingStatusChange(service.GetKey())
+		if !service.IsTombstone() {
+			affected[service.QualifiedName()] = true
+			w.recordChange(ctx, models.EventTypeRegister, service)
+		}
+	}
+
+	names := make([]string, 0, len(affected))
+	for serviceName := range affected {
+		names = append(names, serviceName)
+	}
+	slices.Sort(names)
+
+	totalNotifications := 0
+	for _, serviceName := range names {
+		payload := notifier.BuildNotificationPayload(
+			serviceName,
+			models.EventTypeReconcile,
+			w.registry.GetByServiceName(ctx, serviceName),
+		)
+		payload.EventID = event.GetID()
+		payload.RequestID = events.GetRequestID(ctx)
+		payload.Revision = w.registry.Revision()
+
+		subscribers := w.registry.GetSubscriberServices(ctx, serviceName)
+		w.notifier.NotifySubscribers(ctx, subscribers, payload)
+		totalNotifications += len(subscribers)
+	}
+
+	logger.Info("Snapshot imported",
+		zap.Int("restored", len(services)),
+		zap.Int("removed", len(removed)),
+		zap.Int("total_notifications_sent", totalNotifications),
+	)
+
+	return nil
+}
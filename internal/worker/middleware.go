@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// tracerName identifies the spans of the event handlers
+const tracerName = "github.com/chronnie/governance/internal/worker"
+
+// TracingMiddleware runs each handler in an OpenTelemetry span of the global tracer provider.
+// The span is a child of the span carried by the event context, e.g. the API request that
+// queued the event, and is passed on to the notifications the handler sends.
+func TracingMiddleware() events.Middleware {
+	return func(next eventqueue.EventHandlerFunc) eventqueue.EventHandlerFunc {
+		return func(ctx context.Context, event eventqueue.IEvent) error {
+			attrs := []attribute.KeyValue{
+				attribute.String("event.type", event.GetType()),
+				attribute.Int64("event.id", int64(event.GetID())),
+			}
+			if requestID := events.GetRequestID(ctx); requestID != "" {
+				attrs = append(attrs, attribute.String("request.id", requestID))
+			}
+			ctx, span := otel.Tracer(tracerName).Start(ctx, "process "+event.GetType(), trace.WithAttributes(attrs...))
+			defer span.End()
+
+			err := next(ctx, event)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
+
+// RecoveryMiddleware converts a panic in a handler into an error.
+// The event queue doesn't recover panics itself, so without this a single bad
+// event would crash the manager.
+func RecoveryMiddleware() events.Middleware {
+	return func(next eventqueue.EventHandlerFunc) eventqueue.EventHandlerFunc {
+		return func(ctx context.Context, event eventqueue.IEvent) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("Worker: Recovered from panic in event handler",
+						zap.String("event_type", event.GetType()),
+						zap.Uint64("event_id", event.GetID()),
+						zap.Any("panic", r),
+						zap.String("stack", string(debug.Stack())),
+						events.RequestIDField(ctx),
+					)
+					err = fmt.Errorf("panic in %s handler: %v", event.GetType(), r)
+				}
+			}()
+			return next(ctx, event)
+		}
+	}
+}
+
+// TimingMiddleware logs how long each handler took to process its event
+func TimingMiddleware() events.Middleware {
+	return func(next eventqueue.EventHandlerFunc) eventqueue.EventHandlerFunc {
+		return func(ctx context.Context, event eventqueue.IEvent) error {
+			start := time.Now()
+			err := next(ctx, event)
+			logger.Debug("Worker: Event handler finished",
+				zap.String("event_type", event.GetType()),
+				zap.Uint64("event_id", event.GetID()),
+				zap.Duration("duration", time.Since(start)),
+				zap.Bool("success", err == nil),
+				events.RequestIDField(ctx),
+			)
+			return err
+		}
+	}
+}
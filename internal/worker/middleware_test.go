@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	handler := events.Chain(func(ctx context.Context, event eventqueue.IEvent) error {
+		panic("boom")
+	}, RecoveryMiddleware())
+
+	event := eventqueue.NewEvent(string(events.EventRegister), context.Background())
+	err := handler(context.Background(), event)
+	if err == nil {
+		t.Fatal("Expected error from recovered panic, got nil")
+	}
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	// Events queued by the API carry the request's span
+	_, request := provider.Tracer("test").Start(context.Background(), "POST /register")
+	request.End()
+	ctx := trace.ContextWithSpanContext(context.Background(), request.SpanContext())
+
+	var handlerSpan trace.SpanContext
+	expectedErr := errors.New("handler error")
+	handler := events.Chain(func(ctx context.Context, event eventqueue.IEvent) error {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return expectedErr
+	}, TracingMiddleware())
+
+	event := eventqueue.NewEvent(string(events.EventRegister), ctx)
+	if err := handler(ctx, event); err != expectedErr {
+		t.Errorf("Expected handler error to propagate, got %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected request and event spans, got %d spans", len(spans))
+	}
+	span := spans[1]
+	if span.Name() != "process register" {
+		t.Errorf("Expected span name 'process register', got %q", span.Name())
+	}
+	if span.Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Error("Expected the event span to be a child of the request span")
+	}
+	if span.SpanContext().SpanID() != handlerSpan.SpanID() {
+		t.Error("Expected the handler to run in the event span")
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("Expected error status, got %v", span.Status().Code)
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) events.Middleware {
+		return func(next eventqueue.EventHandlerFunc) eventqueue.EventHandlerFunc {
+			return func(ctx context.Context, event eventqueue.IEvent) error {
+				calls = append(calls, name)
+				return next(ctx, event)
+			}
+		}
+	}
+
+	expectedErr := errors.New("handler error")
+	handler := events.Chain(func(ctx context.Context, event eventqueue.IEvent) error {
+		calls = append(calls, "handler")
+		return expectedErr
+	}, record("first"), record("second"), TimingMiddleware())
+
+	event := eventqueue.NewEvent(string(events.EventReconcile), context.Background())
+	if err := handler(context.Background(), event); err != expectedErr {
+		t.Errorf("Expected handler error to propagate, got %v", err)
+	}
+
+	expected := []string{"first", "second", "handler"}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("Expected calls %v, got %v", expected, calls)
+			break
+		}
+	}
+}
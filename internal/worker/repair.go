@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// handleRepair retries database writes that failed, so a short database outage heals
+// without waiting for the next reconcile
+func (w *EventWorker) handleRepair(ctx context.Context, event eventqueue.IEvent) error {
+	w.retryFailedWrites(ctx)
+	return nil
+}
+
+// retryFailedWrites writes the cached state of services with a failed database write
+func (w *EventWorker) retryFailedWrites(ctx context.Context) {
+	if w.dualStore.PendingRepairs() == 0 {
+		return
+	}
+
+	repaired, err := w.dualStore.RetryFailedWrites(ctx)
+	if err != nil {
+		logger.Warn("Database repair incomplete, retrying later",
+			zap.Int("repaired", repaired),
+			zap.Int("pending", w.dualStore.PendingRepairs()),
+			zap.Error(err),
+		)
+		return
+	}
+	logger.Info("Repaired failed database writes",
+		zap.Int("repaired", repaired),
+	)
+}
@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// handleEvictSubscriber detaches a subscriber from every service group it subscribed to.
+// The subscriber stays registered, so this is not recorded as a change and nobody is notified.
+// Storage errors are returned so the caller doesn't confirm an eviction that didn't happen.
+func (w *EventWorker) handleEvictSubscriber(ctx context.Context, event eventqueue.IEvent) error {
+	eventData := events.GetEventData(ctx)
+	evictEvent, ok := eventData.(*events.EvictSubscriberEvent)
+	if !ok {
+		logger.Warn("Invalid event data type for evict subscriber event")
+		return nil
+	}
+
+	// The subscriber may have unregistered since the request was accepted
+	groups, err := w.registry.RemoveAllSubscriptions(ctx, evictEvent.SubscriberKey)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Subscriber evicted from its service groups",
+		zap.String("subscriber_key", evictEvent.SubscriberKey),
+		zap.Strings("service_groups", groups),
+		zap.String("principal", events.GetCaller(ctx).Principal),
+		events.RequestIDField(ctx),
+	)
+	return nil
+}
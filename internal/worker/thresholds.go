@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// healthStreak counts consecutive health check results that disagree with a service's current status
+type healthStreak struct {
+	status models.ServiceStatus
+	count  int
+}
+
+// WithHealthThresholds sets how many consecutive failed checks mark a healthy service unhealthy,
+// and how many consecutive successful checks mark an unhealthy service healthy.
+// Values below 1 are treated as 1 (flip on the first check, the default).
+func WithHealthThresholds(unhealthy, healthy int) WorkerOption {
+	return func(w *EventWorker) {
+		w.unhealthyThreshold = max(unhealthy, 1)
+		w.healthyThreshold = max(healthy, 1)
+	}
+}
+
+// applyHealthThreshold returns the status the service should have after observing a check result.
+// The status only flips once the observed result has been seen threshold times in a row, where
+// unhealthy and unknown results count towards the same streak of a healthy service (the status
+// becomes the latest of them). Services that were never checked take the first result immediately.
+func (w *EventWorker) applyHealthThreshold(service *models.ServiceInfo, observed models.ServiceStatus) models.ServiceStatus {
+	key := service.GetKey()
+
+	w.streaksMu.Lock()
+	defer w.streaksMu.Unlock()
+
+	if observed == service.Status || (service.Status == models.StatusUnknown && service.LastHealthCheck.IsZero()) {
+		delete(w.healthStreaks, key)
+		return observed
+	}
+
+	threshold := w.healthyThreshold
+	if observed != models.StatusHealthy {
+		threshold = w.unhealthyThreshold
+	}
+
+	streak, exists := w.healthStreaks[key]
+	if !exists || (streak.status == models.StatusHealthy) != (observed == models.StatusHealthy) {
+		streak = &healthStreak{}
+		w.healthStreaks[key] = streak
+	}
+	streak.status = observed
+	streak.count++
+
+	if streak.count < threshold {
+		logger.Debug("Health check result below threshold, keeping current status",
+			zap.String("service_key", key),
+			zap.String("current_status", string(service.Status)),
+			zap.String("observed_status", string(observed)),
+			zap.Int("consecutive", streak.count),
+			zap.Int("threshold", threshold),
+		)
+		return service.Status
+	}
+
+	delete(w.healthStreaks, key)
+	return observed
+}
+
+// clearHealthStreak forgets the streak of a service, e.g. when it unregisters
+func (w *EventWorker) clearHealthStreak(key string) {
+	w.streaksMu.Lock()
+	defer w.streaksMu.Unlock()
+
+	delete(w.healthStreaks, key)
+}
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chronnie/governance/models"
+)
+
+func TestApplyHealthThreshold(t *testing.T) {
+	w := NewEventWorker(nil, nil, nil, nil, WithHealthThresholds(3, 2))
+	service := &models.ServiceInfo{ServiceName: "user-service", PodName: "pod-1", Status: models.StatusUnknown}
+
+	// Unknown status takes the first result immediately
+	service.Status = w.applyHealthThreshold(service, models.StatusHealthy)
+	if service.Status != models.StatusHealthy {
+		t.Fatalf("Expected healthy, got %s", service.Status)
+	}
+
+	// Two failures and a success reset the streak
+	for _, observed := range []models.ServiceStatus{models.StatusUnhealthy, models.StatusUnhealthy, models.StatusHealthy} {
+		service.Status = w.applyHealthThreshold(service, observed)
+		if service.Status != models.StatusHealthy {
+			t.Fatalf("Expected status to stay healthy, got %s", service.Status)
+		}
+	}
+
+	// Three consecutive failures flip to unhealthy
+	for i := 1; i <= 3; i++ {
+		service.Status = w.applyHealthThreshold(service, models.StatusUnhealthy)
+	}
+	if service.Status != models.StatusUnhealthy {
+		t.Fatalf("Expected unhealthy after 3 failures, got %s", service.Status)
+	}
+
+	// Two consecutive successes flip back to healthy
+	service.Status = w.applyHealthThreshold(service, models.StatusHealthy)
+	if service.Status != models.StatusUnhealthy {
+		t.Fatalf("Expected status to stay unhealthy after 1 success, got %s", service.Status)
+	}
+	service.Status = w.applyHealthThreshold(service, models.StatusHealthy)
+	if service.Status != models.StatusHealthy {
+		t.Fatalf("Expected healthy after 2 successes, got %s", service.Status)
+	}
+}
+
+func TestApplyHealthThresholdUnknown(t *testing.T) {
+	w := NewEventWorker(nil, nil, nil, nil, WithHealthThresholds(3, 2))
+	service := &models.ServiceInfo{ServiceName: "user-service", PodName: "pod-1", Status: models.StatusHealthy, LastHealthCheck: time.Now()}
+
+	// Unknown and unhealthy results make up one streak, the latest decides the status
+	for _, observed := range []models.ServiceStatus{models.StatusUnhealthy, models.StatusUnknown, models.StatusUnknown} {
+		service.Status = w.applyHealthThreshold(service, observed)
+	}
+	if service.Status != models.StatusUnknown {
+		t.Fatalf("Expected unknown after 3 failed checks, got %s", service.Status)
+	}
+
+	// A service that became unknown still needs the healthy threshold to recover
+	service.Status = w.applyHealthThreshold(service, models.StatusHealthy)
+	if service.Status != models.StatusUnknown {
+		t.Fatalf("Expected status to stay unknown after 1 success, got %s", service.Status)
+	}
+	service.Status = w.applyHealthThreshold(service, models.StatusHealthy)
+	if service.Status != models.StatusHealthy {
+		t.Fatalf("Expected healthy after 2 successes, got %s", service.Status)
+	}
+}
+
+func TestApplyHealthThresholdDefault(t *testing.T) {
+	w := NewEventWorker(nil, nil, nil, nil)
+	service := &models.ServiceInfo{ServiceName: "user-service", PodName: "pod-1", Status: models.StatusHealthy}
+
+	if status := w.applyHealthThreshold(service, models.StatusUnhealthy); status != models.StatusUnhealthy {
+		t.Errorf("Expected unhealthy on first failure by default, got %s", status)
+	}
+}
@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"context"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// handleHeartbeat renews the TTL of a pod. Heartbeats are not recorded as changes and
+// subscribers are not notified, the pod itself is unchanged.
+func (w *EventWorker) handleHeartbeat(ctx context.Context, event eventqueue.IEvent) error {
+	eventData := events.GetEventData(ctx)
+	heartbeatEvent, ok := eventData.(*events.HeartbeatEvent)
+	if !ok {
+		logger.Warn("Invalid event data type for heartbeat event")
+		return nil
+	}
+
+	// The pod may have expired or unregistered since the request was accepted
+	w.registry.Renew(ctx, heartbeatEvent.ServiceName, heartbeatEvent.PodName)
+	return nil
+}
+
+// expireServices evicts pods whose TTL lapsed without a heartbeat, e.g. because the pod
+// died without unregistering. Subscribers get an evict notification.
+func (w *EventWorker) expireServices(ctx context.Context, eventID uint64) int {
+	expired := w.registry.GetExpired(ctx, w.clock.Now())
+	for _, service := range expired {
+		logger.Info("Service TTL expired without heartbeat, evicting",
+			zap.String("service_key", service.GetKey()),
+			zap.Time("expires_at", service.ExpiresAt),
+		)
+		w.evictPod(ctx, service.QualifiedName(), service.PodName, models.EvictionReasonTTLExpired, eventID)
+	}
+	return len(expired)
+}
@@ -0,0 +1,146 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/clock"
+	"github.com/chronnie/governance/internal/notifier"
+	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/storage"
+)
+
+func TestExpireServices(t *testing.T) {
+	received := make(chan *models.NotificationPayload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload models.NotificationPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- &payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore)
+	w := NewEventWorker(reg, notifier.NewNotifier(time.Second), nil, dualStore)
+
+	for _, pod := range []string{"pod-1", "pod-2", "pod-3"} {
+		ttl := 60
+		if pod == "pod-3" {
+			ttl = 0 // Never expires
+		}
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     "user-service",
+			PodName:         pod,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+			TTLSeconds:      ttl,
+		})
+	}
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "order-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.20", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.20:8080/health",
+		NotificationURL: server.URL,
+		Subscriptions:   []string{"user-service"},
+	})
+
+	// pod-1 missed its heartbeat
+	service, _ := reg.Get(context.Background(), "user-service:pod-1")
+	service.ExpiresAt = time.Now().Add(-time.Second)
+	dualStore.SaveService(context.Background(), service)
+
+	if expired := w.expireServices(context.Background(), 1); expired != 1 {
+		t.Fatalf("Expected 1 expired service, got %d", expired)
+	}
+
+	if _, exists := reg.Get(context.Background(), "user-service:pod-1"); exists {
+		t.Error("Expected expired pod to be unregistered")
+	}
+	if pods := reg.GetByServiceName(context.Background(), "user-service"); len(pods) != 2 {
+		t.Errorf("Expected 2 pods left, got %d", len(pods))
+	}
+
+	select {
+	case payload := <-received:
+		if payload.EventType != models.EventTypeEvict || len(payload.Pods) != 2 {
+			t.Errorf("Expected evict notification with 2 pods, got %s with %d pods", payload.EventType, len(payload.Pods))
+		}
+		if payload.PodName != "pod-1" || payload.Reason != models.EvictionReasonTTLExpired {
+			t.Errorf("Expected pod-1 evicted for %s, got %q for %q", models.EvictionReasonTTLExpired, payload.PodName, payload.Reason)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a notification")
+	}
+}
+
+func TestExpireServicesAfterTTL(t *testing.T) {
+	ctx := context.Background()
+	fakeClock := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore, registry.WithClock(fakeClock))
+	w := NewEventWorker(reg, nil, nil, dualStore, WithClock(fakeClock))
+
+	reg.Register(ctx, &models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		TTLSeconds:      30,
+	})
+
+	fakeClock.Advance(20 * time.Second)
+	reg.Renew(ctx, "user-service", "pod-1")
+
+	// 40s after registering, but only 20s after the heartbeat
+	fakeClock.Advance(20 * time.Second)
+	if expired := w.expireServices(ctx, 1); expired != 0 {
+		t.Fatalf("Expected the renewed pod to be kept, got %d expired", expired)
+	}
+
+	fakeClock.Advance(11 * time.Second)
+	if expired := w.expireServices(ctx, 2); expired != 1 {
+		t.Fatalf("Expected the pod to expire once 30s passed since the heartbeat, got %d expired", expired)
+	}
+}
+
+func TestHandleHeartbeat(t *testing.T) {
+	fakeClock := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore, registry.WithClock(fakeClock))
+	w := NewEventWorker(reg, nil, nil, dualStore)
+
+	registered, _ := reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+		TTLSeconds:      30,
+	})
+	if registered.ExpiresAt.IsZero() {
+		t.Fatal("Expected expiry to be set on registration")
+	}
+
+	fakeClock.Advance(10 * time.Second)
+
+	ctx := events.NewHeartbeatContext("user-service", "pod-1")
+	if err := w.handleHeartbeat(ctx, eventqueue.NewEvent(string(events.EventHeartbeat), ctx)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	service, _ := reg.Get(context.Background(), "user-service:pod-1")
+	if !service.ExpiresAt.Equal(registered.ExpiresAt.Add(10 * time.Second)) {
+		t.Errorf("Expected heartbeat to push back expiry from %v, got %v", registered.ExpiresAt, service.ExpiresAt)
+	}
+}
@@ -2,11 +2,16 @@ package worker
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	eventqueue "github.com/chronnie/go-event-queue"
 	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/clock"
 	"github.com/chronnie/governance/internal/notifier"
 	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/internal/watch"
 	"github.com/chronnie/governance/models"
 	"github.com/chronnie/governance/pkg/logger"
 	"github.com/chronnie/governance/storage"
@@ -18,7 +23,66 @@ type EventWorker struct {
 	registry      *registry.Registry
 	notifier      *notifier.Notifier
 	healthChecker *notifier.HealthChecker
-	dualStore     *storage.DualStore // For database sync during reconciliation
+	dualStore     *storage.DualStore  // For database sync during reconciliation
+	middlewares   []events.Middleware // Applied to every handler, outermost first
+	watchHub      *watch.Hub          // Optional, receives every recorded change
+	emitter       EventEmitter        // Optional, receives every recorded change
+	auditSink     AuditSink           // Optional, receives an audit record for every recorded change
+	clock         clock.Clock
+
+	// Consecutive-result thresholds for health status changes, see WithHealthThresholds
+	unhealthyThreshold int
+	healthyThreshold   int
+	healthStreaks      map[string]*healthStreak // Key: service key
+	streaksMu          sync.Mutex               // Handlers may run concurrently in parallel mode
+
+	// Health status notifications waiting out the dwell time, see WithNotificationDwell
+	notificationDwell time.Duration
+	pendingChanges    map[string]*pendingStatusChange // Key: service key
+	pendingMu         sync.Mutex                      // Also taken by dwell timers
+
+	lastReconcile atomic.Int64 // Unix nanoseconds of the last completed reconcile, 0 if none yet
+	drainMode     atomic.Bool  // Suppresses health status notifications, see SetDrainMode
+
+	reconcileNotifications bool // Whether reconciles notify subscribers, see WithReconcileNotifications
+
+	databaseWatching func() bool // Optional, reports whether database changes are being streamed
+}
+
+// WorkerOption configures optional EventWorker settings
+type WorkerOption func(*EventWorker)
+
+// WithMiddleware appends middlewares to the handler chain.
+// They run inside the built-in tracing, recovery and timing middlewares, in the order given.
+func WithMiddleware(middlewares ...events.Middleware) WorkerOption {
+	return func(w *EventWorker) {
+		w.middlewares = append(w.middlewares, middlewares...)
+	}
+}
+
+// WithWatchHub publishes every registry change to the hub's watchers
+func WithWatchHub(hub *watch.Hub) WorkerOption {
+	return func(w *EventWorker) {
+		w.watchHub = hub
+	}
+}
+
+// WithClock makes the worker take TTL expiry, dwell time and change record times from c
+// instead of the system clock
+func WithClock(c clock.Clock) WorkerOption {
+	return func(w *EventWorker) {
+		w.clock = c
+	}
+}
+
+// WithReconcileNotifications controls whether reconciles send every subscriber the full state
+// of the services it subscribes to (enabled by default). When disabled, reconciles still sync
+// the cache from the database, purge tombstones and evict expired pods, and subscribers only
+// hear about actual changes.
+func WithReconcileNotifications(enabled bool) WorkerOption {
+	return func(w *EventWorker) {
+		w.reconcileNotifications = enabled
+	}
 }
 
 // NewEventWorker creates a new event worker
@@ -27,22 +91,48 @@ func NewEventWorker(
 	notif *notifier.Notifier,
 	healthCheck *notifier.HealthChecker,
 	dualStore *storage.DualStore,
+	opts ...WorkerOption,
 ) *EventWorker {
-	return &EventWorker{
+	w := &EventWorker{
 		registry:      reg,
 		notifier:      notif,
 		healthChecker: healthCheck,
 		dualStore:     dualStore,
+		middlewares:   []events.Middleware{TracingMiddleware(), RecoveryMiddleware(), TimingMiddleware()},
+		clock:         clock.Real,
+
+		unhealthyThreshold: 1,
+		healthyThreshold:   1,
+		healthStreaks:      make(map[string]*healthStreak),
+		pendingChanges:     make(map[string]*pendingStatusChange),
+
+		reconcileNotifications: true,
+	}
+	for _, opt := range opts {
+		opt(w)
 	}
+	return w
 }
 
-// RegisterHandlers registers all event handlers to the queue
+// RegisterHandlers registers all event handlers to the queue, wrapped in the middleware chain
 func (w *EventWorker) RegisterHandlers(queue eventqueue.IEventQueue) {
 	// Register handler for each event type
-	queue.RegisterHandler(string(events.EventRegister), eventqueue.EventHandlerFunc(w.handleRegister))
-	queue.RegisterHandler(string(events.EventUnregister), eventqueue.EventHandlerFunc(w.handleUnregister))
-	queue.RegisterHandler(string(events.EventHealthCheck), eventqueue.EventHandlerFunc(w.handleHealthCheck))
-	queue.RegisterHandler(string(events.EventReconcile), eventqueue.EventHandlerFunc(w.handleReconcile))
+	queue.RegisterHandler(string(events.EventRegister), w.wrap(w.handleRegister))
+	queue.RegisterHandler(string(events.EventUnregister), w.wrap(w.handleUnregister))
+	queue.RegisterHandler(string(events.EventUnregisterService), w.wrap(w.handleUnregisterService))
+	queue.RegisterHandler(string(events.EventUpdate), w.wrap(w.handleUpdate))
+	queue.RegisterHandler(string(events.EventHeartbeat), w.wrap(w.handleHeartbeat))
+	queue.RegisterHandler(string(events.EventHealthCheck), w.wrap(w.handleHealthCheck))
+	queue.RegisterHandler(string(events.EventReconcile), w.wrap(w.handleReconcile))
+	queue.RegisterHandler(string(events.EventRepair), w.wrap(w.handleRepair))
+	queue.RegisterHandler(string(events.EventImport), w.wrap(w.handleImport))
+	queue.RegisterHandler(string(events.EventDatabaseSync), w.wrap(w.handleDatabaseSync))
+	queue.RegisterHandler(string(events.EventEvictSubscriber), w.wrap(w.handleEvictSubscriber))
+}
+
+// wrap applies the worker's middleware chain to a handler
+func (w *EventWorker) wrap(handler eventqueue.EventHandlerFunc) eventqueue.EventHandlerFunc {
+	return events.Chain(handler, w.middlewares...)
 }
 
 // handleRegister processes service registration
@@ -58,18 +148,47 @@ func (w *EventWorker) handleRegister(ctx context.Context, event eventqueue.IEven
 		zap.String("service_name", registerEvent.Registration.ServiceName),
 		zap.String("pod_name", registerEvent.Registration.PodName),
 		zap.String("health_check_url", registerEvent.Registration.HealthCheckURL),
+		events.RequestIDField(ctx),
 	)
 
+	// A pod re-registering with identical data (e.g. after a restart) changes nothing
+	// subscribers need to know about
+	refreshed, err := w.registry.Refresh(ctx, registerEvent.Registration)
+	if err != nil {
+		logger.Error("Failed to refresh service",
+			zap.String("service_name", registerEvent.Registration.ServiceName),
+			zap.String("pod_name", registerEvent.Registration.PodName),
+			zap.Error(err),
+		)
+		return err
+	}
+	if refreshed != nil {
+		logger.Debug("Identical re-registration, subscribers not notified",
+			zap.String("service_key", refreshed.GetKey()),
+		)
+		return nil
+	}
+
 	// Register service in registry
-	serviceInfo := w.registry.Register(registerEvent.Registration)
+	serviceInfo, err := w.registry.Register(ctx, registerEvent.Registration)
+	if err != nil {
+		logger.Error("Failed to register service",
+			zap.String("service_name", registerEvent.Registration.ServiceName),
+			zap.String("pod_name", registerEvent.Registration.PodName),
+			zap.Error(err),
+		)
+		return err // Reported back to the API when database writes are synchronous
+	}
 	logger.Debug("Service registered in registry",
 		zap.String("service_key", serviceInfo.GetKey()),
 		zap.String("service_name", serviceInfo.ServiceName),
 		zap.String("pod_name", serviceInfo.PodName),
 	)
 
+	w.recordChange(ctx, models.EventTypeRegister, serviceInfo)
+
 	// Get all pods of this service
-	servicePods := w.registry.GetByServiceName(serviceInfo.ServiceName)
+	servicePods := w.registry.GetByServiceName(ctx, serviceInfo.QualifiedName())
 	logger.Debug("Retrieved service pods",
 		zap.String("service_name", serviceInfo.ServiceName),
 		zap.Int("pod_count", len(servicePods)),
@@ -77,18 +196,74 @@ func (w *EventWorker) handleRegister(ctx context.Context, event eventqueue.IEven
 
 	// Build notification payload
 	payload := notifier.BuildNotificationPayload(
-		serviceInfo.ServiceName,
+		serviceInfo.QualifiedName(),
 		models.EventTypeRegister,
 		servicePods,
 	)
+	payload.EventID = event.GetID()
+	payload.RequestID = events.GetRequestID(ctx)
+	payload.Revision = w.registry.Revision()
 
 	// Notify all subscribers of this service
-	subscribers := w.registry.GetSubscriberServices(serviceInfo.ServiceName)
+	subscribers := w.registry.GetSubscriberServices(ctx, serviceInfo.QualifiedName())
 	logger.Info("Notifying subscribers of service registration",
 		zap.String("service_name", serviceInfo.ServiceName),
 		zap.Int("subscriber_count", len(subscribers)),
+		events.RequestIDField(ctx),
+	)
+	w.notifySubscribers(ctx, subscribers, payload, serviceInfo)
+
+	return nil
+}
+
+// handleUpdate processes an in-place update of a registered pod
+func (w *EventWorker) handleUpdate(ctx context.Context, event eventqueue.IEvent) error {
+	eventData := events.GetEventData(ctx)
+	updateEvent, ok := eventData.(*events.UpdateEvent)
+	if !ok {
+		logger.Warn("Invalid event data type for update event")
+		return nil
+	}
+
+	logger.Info("Processing update event",
+		zap.String("service_name", updateEvent.ServiceName),
+		zap.String("pod_name", updateEvent.PodName),
+		events.RequestIDField(ctx),
+	)
+
+	// Update service in registry, it may have unregistered since the request was accepted
+	serviceInfo := w.registry.Update(ctx, updateEvent.ServiceName, updateEvent.PodName, updateEvent.Update)
+	if serviceInfo == nil {
+		logger.Warn("Service not found for update",
+			zap.String("service_name", updateEvent.ServiceName),
+			zap.String("pod_name", updateEvent.PodName),
+		)
+		return nil
+	}
+
+	w.recordChange(ctx, models.EventTypeUpdate, serviceInfo)
+
+	// Get all pods of this service
+	servicePods := w.registry.GetByServiceName(ctx, serviceInfo.QualifiedName())
+
+	// Build notification payload
+	payload := notifier.BuildNotificationPayload(
+		serviceInfo.QualifiedName(),
+		models.EventTypeUpdate,
+		servicePods,
+	)
+	payload.EventID = event.GetID()
+	payload.RequestID = events.GetRequestID(ctx)
+	payload.Revision = w.registry.Revision()
+
+	// Notify all subscribers of this service
+	subscribers := w.registry.GetSubscriberServices(ctx, serviceInfo.QualifiedName())
+	logger.Info("Notifying subscribers of service update",
+		zap.String("service_name", serviceInfo.ServiceName),
+		zap.Int("subscriber_count", len(subscribers)),
+		events.RequestIDField(ctx),
 	)
-	w.notifier.NotifySubscribers(subscribers, payload)
+	w.notifySubscribers(ctx, subscribers, payload, serviceInfo)
 
 	return nil
 }
@@ -105,16 +280,36 @@ func (w *EventWorker) handleUnregister(ctx context.Context, event eventqueue.IEv
 	logger.Info("Processing unregister event",
 		zap.String("service_name", unregisterEvent.ServiceName),
 		zap.String("pod_name", unregisterEvent.PodName),
+		events.RequestIDField(ctx),
 	)
 
+	w.unregisterPod(ctx, unregisterEvent.ServiceName, unregisterEvent.PodName, event.GetID())
+
+	return nil
+}
+
+// unregisterPod removes a pod from the registry and notifies subscribers of its service
+func (w *EventWorker) unregisterPod(ctx context.Context, serviceName, podName string, eventID uint64) {
+	w.removePod(ctx, serviceName, podName, models.EventTypeUnregister, "", eventID)
+}
+
+// evictPod removes a pod on the manager's initiative and notifies subscribers of its service
+// with an evict event carrying the reason, so they can tell it apart from a clean unregister
+func (w *EventWorker) evictPod(ctx context.Context, serviceName, podName, reason string, eventID uint64) {
+	w.removePod(ctx, serviceName, podName, models.EventTypeEvict, reason, eventID)
+}
+
+// removePod removes a pod from the registry, records the change as eventType and notifies
+// subscribers of its service
+func (w *EventWorker) removePod(ctx context.Context, serviceName, podName string, eventType models.EventType, reason string, eventID uint64) {
 	// Unregister service from registry
-	serviceInfo := w.registry.Unregister(unregisterEvent.ServiceName, unregisterEvent.PodName)
+	serviceInfo := w.registry.Unregister(ctx, serviceName, podName)
 	if serviceInfo == nil {
 		logger.Warn("Service not found for unregistration",
-			zap.String("service_name", unregisterEvent.ServiceName),
-			zap.String("pod_name", unregisterEvent.PodName),
+			zap.String("service_name", serviceName),
+			zap.String("pod_name", podName),
 		)
-		return nil
+		return
 	}
 
 	logger.Debug("Service unregistered from registry",
@@ -123,27 +318,100 @@ func (w *EventWorker) handleUnregister(ctx context.Context, event eventqueue.IEv
 		zap.String("pod_name", serviceInfo.PodName),
 	)
 
+	w.recordChange(ctx, eventType, serviceInfo)
+	w.clearHealthStreak(serviceInfo.GetKey())
+	w.cancelPendingStatusChange(serviceInfo.GetKey())
+
 	// Get remaining pods of this service (after unregistration)
-	servicePods := w.registry.GetByServiceName(unregisterEvent.ServiceName)
+	servicePods := w.registry.GetByServiceName(ctx, serviceName)
 	logger.Debug("Retrieved remaining service pods",
-		zap.String("service_name", unregisterEvent.ServiceName),
+		zap.String("service_name", serviceName),
 		zap.Int("remaining_pod_count", len(servicePods)),
 	)
 
 	// Build notification payload
+	payload := notifier.BuildNotificationPayload(
+		serviceName,
+		eventType,
+		servicePods,
+	)
+	payload.EventID = eventID
+	payload.RequestID = events.GetRequestID(ctx)
+	payload.Revision = w.registry.Revision()
+	if eventType == models.EventTypeEvict {
+		payload.PodName = podName
+		payload.Reason = reason
+	}
+
+	// Notify all subscribers of this service
+	subscribers := w.registry.GetSubscriberServices(ctx, serviceName)
+	logger.Info("Notifying subscribers of service unregistration",
+		zap.String("service_name", serviceName),
+		zap.String("event_type", string(eventType)),
+		zap.Int("subscriber_count", len(subscribers)),
+		events.RequestIDField(ctx),
+	)
+	w.notifySubscribers(ctx, subscribers, payload, serviceInfo)
+}
+
+// handleUnregisterService removes every pod of a service and sends subscribers a single
+// unregister notification with the (now empty) pod list
+func (w *EventWorker) handleUnregisterService(ctx context.Context, event eventqueue.IEvent) error {
+	eventData := events.GetEventData(ctx)
+	unregisterEvent, ok := eventData.(*events.UnregisterServiceEvent)
+	if !ok {
+		logger.Warn("Invalid event data type for unregister service event")
+		return nil
+	}
+
+	logger.Info("Processing unregister service event",
+		zap.String("service_name", unregisterEvent.ServiceName),
+		events.RequestIDField(ctx),
+	)
+
+	pods := w.registry.GetByServiceName(ctx, unregisterEvent.ServiceName)
+	if len(pods) == 0 {
+		logger.Warn("Service not found for unregistration",
+			zap.String("service_name", unregisterEvent.ServiceName),
+		)
+		return nil
+	}
+
+	removed := make([]*models.ServiceInfo, 0, len(pods))
+	for _, pod := range pods {
+		serviceInfo := w.registry.Unregister(ctx, pod.QualifiedName(), pod.PodName)
+		if serviceInfo == nil {
+			continue
+		}
+		removed = append(removed, serviceInfo)
+		w.recordChange(ctx, models.EventTypeUnregister, serviceInfo)
+		w.clearHealthStreak(serviceInfo.GetKey())
+		w.cancelPendingStatusChange(serviceInfo.GetKey())
+	}
+
+	logger.Debug("Service pods unregistered from registry",
+		zap.String("service_name", unregisterEvent.ServiceName),
+		zap.Int("pod_count", len(pods)),
+	)
+
+	// Build one notification for the whole service instead of one per pod
 	payload := notifier.BuildNotificationPayload(
 		unregisterEvent.ServiceName,
 		models.EventTypeUnregister,
-		servicePods,
+		w.registry.GetByServiceName(ctx, unregisterEvent.ServiceName),
 	)
+	payload.EventID = event.GetID()
+	payload.RequestID = events.GetRequestID(ctx)
+	payload.Revision = w.registry.Revision()
 
 	// Notify all subscribers of this service
-	subscribers := w.registry.GetSubscriberServices(unregisterEvent.ServiceName)
+	subscribers := w.registry.GetSubscriberServices(ctx, unregisterEvent.ServiceName)
 	logger.Info("Notifying subscribers of service unregistration",
 		zap.String("service_name", unregisterEvent.ServiceName),
 		zap.Int("subscriber_count", len(subscribers)),
+		events.RequestIDField(ctx),
 	)
-	w.notifier.NotifySubscribers(subscribers, payload)
+	w.notifySubscribers(ctx, subscribers, payload, removed...)
 
 	return nil
 }
@@ -159,10 +427,11 @@ func (w *EventWorker) handleHealthCheck(ctx context.Context, event eventqueue.IE
 
 	logger.Debug("Processing health check event",
 		zap.String("service_key", healthCheckEvent.ServiceKey),
+		events.RequestIDField(ctx),
 	)
 
 	// Get service from registry
-	serviceInfo, exists := w.registry.Get(healthCheckEvent.ServiceKey)
+	serviceInfo, exists := w.registry.Get(ctx, healthCheckEvent.ServiceKey)
 	if !exists {
 		logger.Warn("Service not found for health check",
 			zap.String("service_key", healthCheckEvent.ServiceKey),
@@ -177,16 +446,27 @@ func (w *EventWorker) handleHealthCheck(ctx context.Context, event eventqueue.IE
 		zap.String("current_status", string(serviceInfo.Status)),
 	)
 
-	// Perform health check with retries
-	newStatus := w.healthChecker.GetHealthStatus(serviceInfo.HealthCheckURL)
+	// Perform health check with retries (HTTP, or TCP connect for services without a URL)
+	observedStatus := w.healthChecker.GetServiceHealthStatus(ctx, serviceInfo)
+
+	// A check cut short by shutdown says nothing about the pod
+	if ctx.Err() != nil {
+		logger.Debug("Health check cancelled, status unchanged",
+			zap.String("service_key", healthCheckEvent.ServiceKey),
+		)
+		return nil
+	}
 
 	logger.Debug("Health check completed",
 		zap.String("service_key", healthCheckEvent.ServiceKey),
-		zap.String("new_status", string(newStatus)),
+		zap.String("observed_status", string(observedStatus)),
 	)
 
+	// Only flip status once enough consecutive checks agree
+	newStatus := w.applyHealthThreshold(serviceInfo, observedStatus)
+
 	// Update health status in registry
-	statusChanged := w.registry.UpdateHealthStatus(healthCheckEvent.ServiceKey, newStatus)
+	statusChanged := w.registry.UpdateHealthStatus(ctx, healthCheckEvent.ServiceKey, newStatus)
 
 	// If status changed, notify subscribers
 	if statusChanged {
@@ -196,23 +476,19 @@ func (w *EventWorker) handleHealthCheck(ctx context.Context, event eventqueue.IE
 			zap.String("new_status", string(newStatus)),
 		)
 
-		// Get all pods of this service
-		servicePods := w.registry.GetByServiceName(serviceInfo.ServiceName)
+		previousStatus := serviceInfo.Status
+		serviceInfo.Status = newStatus
+		w.recordChange(ctx, models.EventTypeUpdate, serviceInfo)
 
-		// Build notification payload
-		payload := notifier.BuildNotificationPayload(
-			serviceInfo.ServiceName,
-			models.EventTypeUpdate,
-			servicePods,
-		)
+		if w.drainMode.Load() {
+			logger.Info("Drain mode, health status notification suppressed",
+				zap.String("service_key", healthCheckEvent.ServiceKey),
+			)
+			return nil
+		}
 
-		// Notify all subscribers
-		subscribers := w.registry.GetSubscriberServices(serviceInfo.ServiceName)
-		logger.Info("Notifying subscribers of health status change",
-			zap.String("service_name", serviceInfo.ServiceName),
-			zap.Int("subscriber_count", len(subscribers)),
-		)
-		w.notifier.NotifySubscribers(subscribers, payload)
+		// Notify subscribers, possibly after the dwell time
+		w.notifyHealthChange(ctx, serviceInfo, previousStatus, event.GetID())
 	} else {
 		logger.Debug("Health status unchanged",
 			zap.String("service_key", healthCheckEvent.ServiceKey),
@@ -223,29 +499,48 @@ func (w *EventWorker) handleHealthCheck(ctx context.Context, event eventqueue.IE
 	return nil
 }
 
-// handleReconcile processes reconcile event (notify all subscribers with current state + sync database)
+// handleReconcile processes reconcile event (notify all subscribers with current state + sync database
+// + purge expired tombstones + unregister pods whose TTL lapsed)
 func (w *EventWorker) handleReconcile(ctx context.Context, event eventqueue.IEvent) error {
 	logger.Info("Processing reconcile event - starting full reconciliation")
 
 	// Sync from database to cache (if database is enabled)
 	// This ensures cache has the latest data from database
 	if w.dualStore.GetDatabase() != nil {
-		logger.Info("Database persistence enabled - syncing from database to cache")
-		servicesSynced, subsSynced, err := w.dualStore.SyncFromDatabase(ctx)
-		if err != nil {
-			logger.Error("Failed to sync from database", zap.Error(err))
+		// Push failed writes first so the sync doesn't bring back stale records
+		w.retryFailedWrites(ctx)
+
+		if w.databaseWatching != nil && w.databaseWatching() {
+			logger.Debug("Database changes are streamed - skipping full database sync")
 		} else {
-			logger.Info("Database sync completed successfully",
-				zap.Int("services_synced", servicesSynced),
-				zap.Int("subscriptions_synced", subsSynced),
-			)
+			w.syncFromDatabase(ctx)
 		}
 	} else {
 		logger.Debug("Database persistence disabled - using cache only")
 	}
 
+	// Purge tombstones whose soft-delete grace period has passed
+	if purged := w.registry.PurgeTombstones(ctx); purged > 0 {
+		logger.Info("Purged expired tombstones",
+			zap.Int("purged", purged),
+		)
+	}
+
+	// Evict pods that stopped sending heartbeats
+	if expired := w.expireServices(ctx, event.GetID()); expired > 0 {
+		logger.Info("Evicted services with expired TTL",
+			zap.Int("expired", expired),
+		)
+	}
+
+	if !w.reconcileNotifications {
+		w.lastReconcile.Store(w.clock.Now().UnixNano())
+		logger.Info("Reconciliation completed, subscriber notifications disabled")
+		return nil
+	}
+
 	// Get all services from cache
-	allServices := w.registry.GetAllServices()
+	allServices := w.registry.GetAllServices(ctx)
 	logger.Info("Retrieved all services from cache",
 		zap.Int("total_services", len(allServices)),
 	)
@@ -253,7 +548,7 @@ func (w *EventWorker) handleReconcile(ctx context.Context, event eventqueue.IEve
 	// Group services by service name
 	serviceGroups := make(map[string][]*models.ServiceInfo)
 	for _, service := range allServices {
-		serviceGroups[service.ServiceName] = append(serviceGroups[service.ServiceName], service)
+		serviceGroups[service.QualifiedName()] = append(serviceGroups[service.QualifiedName()], service)
 	}
 
 	logger.Info("Grouped services by service name",
@@ -266,6 +561,7 @@ func (w *EventWorker) handleReconcile(ctx context.Context, event eventqueue.IEve
 		logger.Debug("Processing service group for reconciliation",
 			zap.String("service_name", serviceName),
 			zap.Int("pod_count", len(pods)),
+			events.RequestIDField(ctx),
 		)
 
 		// Build notification payload
@@ -274,16 +570,20 @@ func (w *EventWorker) handleReconcile(ctx context.Context, event eventqueue.IEve
 			models.EventTypeReconcile,
 			pods,
 		)
+		payload.EventID = event.GetID()
+		payload.RequestID = events.GetRequestID(ctx)
+		payload.Revision = w.registry.Revision()
 
 		// Get subscribers
-		subscribers := w.registry.GetSubscriberServices(serviceName)
+		subscribers := w.registry.GetSubscriberServices(ctx, serviceName)
 		if len(subscribers) > 0 {
 			logger.Info("Notifying subscribers for service reconciliation",
 				zap.String("service_name", serviceName),
 				zap.Int("pod_count", len(pods)),
 				zap.Int("subscriber_count", len(subscribers)),
+				events.RequestIDField(ctx),
 			)
-			w.notifier.NotifySubscribers(subscribers, payload)
+			w.notifier.NotifySubscribers(ctx, subscribers, payload)
 			totalNotifications += len(subscribers)
 		} else {
 			logger.Debug("No subscribers for service",
@@ -292,6 +592,8 @@ func (w *EventWorker) handleReconcile(ctx context.Context, event eventqueue.IEve
 		}
 	}
 
+	w.lastReconcile.Store(w.clock.Now().UnixNano())
+
 	logger.Info("Reconciliation completed",
 		zap.Int("service_groups", len(serviceGroups)),
 		zap.Int("total_notifications_sent", totalNotifications),
@@ -299,3 +601,60 @@ func (w *EventWorker) handleReconcile(ctx context.Context, event eventqueue.IEve
 
 	return nil
 }
+
+// LastReconcile returns when the last reconcile completed, or the zero time if none has yet
+func (w *EventWorker) LastReconcile() time.Time {
+	nanos := w.lastReconcile.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// recordChange appends a registry mutation to the change feed and publishes it to watchers,
+// the event emitter and the audit sink.
+// Failures are logged but don't fail the event, the mutation itself already happened.
+func (w *EventWorker) recordChange(ctx context.Context, eventType models.EventType, service *models.ServiceInfo) {
+	change := &models.ChangeRecord{
+		Timestamp:   w.clock.Now(),
+		EventType:   eventType,
+		ServiceKey:  service.GetKey(),
+		ServiceName: service.QualifiedName(),
+		PodName:     service.PodName,
+		Status:      service.Status,
+	}
+
+	sequence, err := w.dualStore.AppendChange(ctx, change)
+	if err != nil {
+		logger.Error("Failed to append change to change feed",
+			zap.String("service_key", change.ServiceKey),
+			zap.String("event_type", string(eventType)),
+			zap.Error(err),
+		)
+	} else {
+		change.Sequence = sequence
+		logger.Debug("Change appended to change feed",
+			zap.String("service_key", change.ServiceKey),
+			zap.String("event_type", string(eventType)),
+			zap.Int64("sequence", sequence),
+		)
+	}
+
+	if w.watchHub != nil {
+		w.watchHub.Publish(change)
+	}
+	if w.emitter != nil {
+		w.emitter.Emit(change)
+	}
+	if w.auditSink != nil {
+		w.auditSink.Audit(models.AuditRecord{
+			Timestamp:   change.Timestamp,
+			EventType:   eventType,
+			ServiceKey:  change.ServiceKey,
+			ServiceName: change.ServiceName,
+			PodName:     change.PodName,
+			Status:      change.Status,
+			Caller:      events.GetCaller(ctx),
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
+	"github.com/chronnie/governance/internal/notifier"
+	"github.com/chronnie/governance/internal/registry"
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/storage"
+)
+
+func TestHandleUnregisterService(t *testing.T) {
+	received := make(chan *models.NotificationPayload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload models.NotificationPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- &payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore)
+	w := NewEventWorker(reg, notifier.NewNotifier(time.Second), nil, dualStore)
+
+	for _, pod := range []string{"pod-1", "pod-2", "pod-3"} {
+		reg.Register(context.Background(), &models.ServiceRegistration{
+			ServiceName:     "user-service",
+			PodName:         pod,
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+			HealthCheckURL:  "http://192.168.1.10:8080/health",
+			NotificationURL: "http://192.168.1.10:8080/notify",
+		})
+	}
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "order-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.20", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.20:8080/health",
+		NotificationURL: server.URL,
+		Subscriptions:   []string{"user-service"},
+	})
+
+	ctx := events.NewUnregisterServiceContext("user-service")
+	if err := w.handleUnregisterService(ctx, eventqueue.NewEvent(string(events.EventUnregisterService), ctx)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if pods := reg.GetByServiceName(context.Background(), "user-service"); len(pods) != 0 {
+		t.Errorf("Expected all pods removed, %d left", len(pods))
+	}
+
+	select {
+	case payload := <-received:
+		if payload.EventType != models.EventTypeUnregister || len(payload.Pods) != 0 {
+			t.Errorf("Expected unregister notification with no pods, got %s with %d pods", payload.EventType, len(payload.Pods))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a notification")
+	}
+
+	select {
+	case <-received:
+		t.Error("Expected a single coalesced notification")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestHandleUpdate(t *testing.T) {
+	received := make(chan *models.NotificationPayload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload models.NotificationPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- &payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore)
+	w := NewEventWorker(reg, notifier.NewNotifier(time.Second), nil, dualStore)
+
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	})
+	reg.Register(context.Background(), &models.ServiceRegistration{
+		ServiceName:     "order-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.20", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.20:8080/health",
+		NotificationURL: server.URL,
+		Subscriptions:   []string{"user-service"},
+	})
+
+	update := &models.ServiceUpdate{
+		Providers: []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "192.168.1.10", Port: 9090}},
+	}
+	ctx := events.NewUpdateContext("user-service", "pod-1", update)
+	if err := w.handleUpdate(ctx, eventqueue.NewEvent(string(events.EventUpdate), ctx)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case payload := <-received:
+		if payload.EventType != models.EventTypeUpdate || len(payload.Pods) != 1 || payload.Pods[0].Providers[0].Port != 9090 {
+			t.Errorf("Expected update notification with the new port, got %+v", payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a notification")
+	}
+}
+
+// statusStore is a database recording the health status written for each service
+type statusStore struct {
+	storage.DatabaseStore
+	mu       sync.Mutex
+	statuses map[string]models.ServiceStatus
+	checked  map[string]time.Time
+}
+
+func newStatusStore() *statusStore {
+	return &statusStore{statuses: make(map[string]models.ServiceStatus), checked: make(map[string]time.Time)}
+}
+
+func (s *statusStore) SaveService(ctx context.Context, service *models.ServiceInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[service.GetKey()] = service.Status
+	s.checked[service.GetKey()] = service.LastHealthCheck
+	return nil
+}
+
+func (s *statusStore) SaveSubscriptions(ctx context.Context, subscriberKey string, subscriptions []string) error {
+	return nil
+}
+
+func (s *statusStore) UpdateHealthStatus(ctx context.Context, key string, status models.ServiceStatus, timestamp time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[key] = status
+	s.checked[key] = timestamp
+	return nil
+}
+
+func (s *statusStore) AppendChange(ctx context.Context, change *models.ChangeRecord) (int64, error) {
+	return 0, nil
+}
+
+func (s *statusStore) status(key string) (models.ServiceStatus, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.statuses[key], s.checked[key]
+}
+
+func TestHealthCheckPersistsStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	for _, mode := range []models.WriteMode{models.WriteAsync, models.WriteSync} {
+		t.Run(string(mode), func(t *testing.T) {
+			db := newStatusStore()
+			var opts []storage.DualStoreOption
+			if mode == models.WriteSync {
+				opts = append(opts, storage.WithSyncWrites())
+			}
+			dualStore := storage.NewDualStore(db, opts...)
+			reg := registry.NewRegistry(dualStore)
+			w := NewEventWorker(reg, notifier.NewNotifier(time.Second), notifier.NewHealthChecker(time.Second, 0), dualStore)
+
+			service, err := reg.Register(context.Background(), &models.ServiceRegistration{
+				ServiceName:     "user-service",
+				PodName:         "pod-1",
+				Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "127.0.0.1", Port: 8080}},
+				HealthCheckURL:  server.URL,
+				NotificationURL: "http://127.0.0.1:8080/notify",
+			})
+			if err != nil {
+				t.Fatalf("Register failed: %v", err)
+			}
+
+			ctx := events.NewHealthCheckContext(context.Background(), service.GetKey())
+			if err := w.handleHealthCheck(ctx, eventqueue.NewEvent(string(events.EventHealthCheck), ctx)); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// Sync writes are done when the check returns, async ones shortly after
+			if mode == models.WriteSync {
+				if status, _ := db.status(service.GetKey()); status != models.StatusHealthy {
+					t.Fatalf("Expected the database to hold the new status right away, got %q", status)
+				}
+			}
+			flushCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			if err := dualStore.Flush(flushCtx); err != nil {
+				t.Fatalf("Flush failed: %v", err)
+			}
+
+			status, checked := db.status(service.GetKey())
+			if status != models.StatusHealthy || checked.IsZero() {
+				t.Errorf("Expected the database to record a healthy check, got %q at %v", status, checked)
+			}
+		})
+	}
+}
+
+func TestHealthCheckUnreachableIsUnknown(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	received := make(chan *models.NotificationPayload, 10)
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload models.NotificationPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- &payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer subscriber.Close()
+
+	ctx := context.Background()
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore)
+	w := NewEventWorker(reg, notifier.NewNotifier(time.Second), notifier.NewHealthChecker(time.Second, 0), dualStore)
+
+	pod, _ := reg.Register(ctx, &models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "127.0.0.1", Port: 8080}},
+		HealthCheckURL:  target.URL,
+		NotificationURL: "http://127.0.0.1:8080/notify",
+	})
+	reg.Register(ctx, &models.ServiceRegistration{
+		ServiceName:     "order-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "127.0.0.1", Port: 8081}},
+		HealthCheckURL:  "http://127.0.0.1:8081/health",
+		NotificationURL: subscriber.URL,
+		Subscriptions:   []string{"user-service"},
+	})
+
+	check := func() *models.NotificationPayload {
+		eventCtx := events.NewHealthCheckContext(ctx, pod.GetKey())
+		if err := w.handleHealthCheck(eventCtx, eventqueue.NewEvent(string(events.EventHealthCheck), eventCtx)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		select {
+		case payload := <-received:
+			return payload
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected a notification for the status change")
+			return nil
+		}
+	}
+
+	if payload := check(); payload.Pods[0].Status != models.StatusHealthy {
+		t.Fatalf("Expected a healthy pod in the notification, got %s", payload.Pods[0].Status)
+	}
+
+	// Nothing answers any more: the check can't tell whether the pod is healthy
+	target.Close()
+	if payload := check(); len(payload.Pods) != 1 || payload.Pods[0].Status != models.StatusUnknown {
+		t.Errorf("Expected an unknown pod in the notification, got %+v", payload.Pods)
+	}
+}
+
+func TestIdenticalReregistrationNotNotified(t *testing.T) {
+	received := make(chan *models.NotificationPayload, 10)
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload models.NotificationPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- &payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer subscriber.Close()
+
+	ctx := context.Background()
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore)
+	w := NewEventWorker(reg, notifier.NewNotifier(time.Second), nil, dualStore)
+
+	reg.Register(ctx, &models.ServiceRegistration{
+		ServiceName:     "order-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "127.0.0.1", Port: 8081}},
+		HealthCheckURL:  "http://127.0.0.1:8081/health",
+		NotificationURL: subscriber.URL,
+		Subscriptions:   []string{"user-service"},
+	})
+
+	register := func(port int) {
+		eventCtx := events.NewRegisterContext(&models.ServiceRegistration{
+			ServiceName:     "user-service",
+			PodName:         "pod-1",
+			Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "127.0.0.1", Port: port}},
+			HealthCheckURL:  "http://127.0.0.1:8080/health",
+			NotificationURL: "http://127.0.0.1:8080/notify",
+		})
+		if err := w.handleRegister(eventCtx, eventqueue.NewEvent(string(events.EventRegister), eventCtx)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	expectNotification := func(expected bool) {
+		select {
+		case payload := <-received:
+			if !expected {
+				t.Fatalf("Expected no notification, got %s", payload.EventType)
+			}
+		case <-time.After(300 * time.Millisecond):
+			if expected {
+				t.Fatal("Expected a notification")
+			}
+		}
+	}
+
+	register(8080)
+	expectNotification(true)
+
+	// Same data again, e.g. after a liveness restart
+	register(8080)
+	expectNotification(false)
+
+	// Changed data is a real re-registration
+	register(9090)
+	expectNotification(true)
+}
+
+func TestRequestIDPropagatedToNotifications(t *testing.T) {
+	type notification struct {
+		requestID string
+		payload   models.NotificationPayload
+	}
+	received := make(chan notification, 10)
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := notification{requestID: r.Header.Get("X-Request-ID")}
+		json.NewDecoder(r.Body).Decode(&n.payload)
+		received <- n
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer subscriber.Close()
+
+	ctx := context.Background()
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore)
+	w := NewEventWorker(reg, notifier.NewNotifier(time.Second), nil, dualStore)
+
+	reg.Register(ctx, &models.ServiceRegistration{
+		ServiceName:     "order-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "127.0.0.1", Port: 8081}},
+		HealthCheckURL:  "http://127.0.0.1:8081/health",
+		NotificationURL: subscriber.URL,
+		Subscriptions:   []string{"user-service"},
+	})
+
+	eventCtx := events.WithRequestID(events.NewRegisterContext(&models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "127.0.0.1", Port: 8080}},
+		HealthCheckURL:  "http://127.0.0.1:8080/health",
+		NotificationURL: "http://127.0.0.1:8080/notify",
+	}), "req-123")
+	if err := w.handleRegister(eventCtx, eventqueue.NewEvent(string(events.EventRegister), eventCtx)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case n := <-received:
+		if n.requestID != "req-123" {
+			t.Errorf("Expected X-Request-ID req-123, got %q", n.requestID)
+		}
+		if n.payload.RequestID != "req-123" {
+			t.Errorf("Expected request_id req-123 in the payload, got %q", n.payload.RequestID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a notification")
+	}
+}
+
+func TestReconcileWithoutNotifications(t *testing.T) {
+	received := make(chan struct{}, 10)
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer subscriber.Close()
+
+	ctx := context.Background()
+	dualStore := storage.NewDualStore(nil)
+	reg := registry.NewRegistry(dualStore)
+	w := NewEventWorker(reg, notifier.NewNotifier(time.Second), nil, dualStore, WithReconcileNotifications(false))
+
+	reg.Register(ctx, &models.ServiceRegistration{
+		ServiceName:     "order-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "127.0.0.1", Port: 8081}},
+		HealthCheckURL:  "http://127.0.0.1:8081/health",
+		NotificationURL: subscriber.URL,
+		Subscriptions:   []string{"order-service"},
+	})
+
+	eventCtx := events.NewReconcileContext()
+	if err := w.handleReconcile(eventCtx, eventqueue.NewEvent(string(events.EventReconcile), eventCtx)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if w.LastReconcile().IsZero() {
+		t.Error("Expected the reconcile to be recorded as completed")
+	}
+	select {
+	case <-received:
+		t.Error("Expected no reconcile notification")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
@@ -2,15 +2,24 @@ package manager
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"runtime"
+	"sync/atomic"
 	"time"
 
 	eventqueue "github.com/chronnie/go-event-queue"
+	"github.com/chronnie/governance/events"
 	"github.com/chronnie/governance/internal/api"
 	"github.com/chronnie/governance/internal/notifier"
+	"github.com/chronnie/governance/internal/queue"
 	"github.com/chronnie/governance/internal/registry"
 	"github.com/chronnie/governance/internal/scheduler"
+	"github.com/chronnie/governance/internal/watch"
 	"github.com/chronnie/governance/internal/worker"
 	"github.com/chronnie/governance/models"
 	"github.com/chronnie/governance/pkg/logger"
@@ -25,82 +34,305 @@ type Manager struct {
 	// Core components
 	dualStore     *storage.DualStore // Always uses in-memory cache + optional database
 	registry      *registry.Registry
-	eventQueue    eventqueue.IEventQueue
+	eventQueue    *queue.InstrumentedQueue
 	notifier      *notifier.Notifier
 	healthChecker *notifier.HealthChecker
 	eventWorker   *worker.EventWorker
+	kafkaEmitter  *worker.KafkaEmitter // Nil unless Kafka is configured
+	auditFile     *os.File             // Nil unless AuditLogFile is configured
 	queueContext  context.Context
 	queueCancel   context.CancelFunc
+	queueRunning  *atomic.Bool // Set once the queue starts, cleared when Stop begins
 
 	// Schedulers
 	healthCheckScheduler *scheduler.HealthCheckScheduler
 	reconcileScheduler   *scheduler.ReconcileScheduler
+	repairScheduler      *scheduler.RepairScheduler // nil without a database
+	databaseWatcher      *scheduler.DatabaseWatcher // nil unless the database can stream changes and DatabaseWatchInterval is set
+	databaseMonitor      *scheduler.DatabaseMonitor // nil unless there is a database and DatabaseHealthInterval is set
 
 	// HTTP server
 	httpServer *http.Server
+	listener   net.Listener // Set by Start
 
 	// Lifecycle
 	stopChan chan struct{}
 }
 
+// ManagerOption configures optional Manager settings
+type ManagerOption func(*managerOptions)
+
+// managerOptions holds settings collected from ManagerOptions before the manager is built
+type managerOptions struct {
+	eventMiddlewares []events.Middleware
+	deliveryHook     func(models.DeliveryResult)
+	deadLetters      notifier.DeadLetterStore
+	auditSink        worker.AuditSink
+	notifyClient     *http.Client
+	healthClient     *http.Client
+}
+
+// WithEventMiddleware adds middlewares around every event handler.
+// They run inside the built-in tracing, recovery and timing middlewares, in the order given.
+func WithEventMiddleware(middlewares ...events.Middleware) ManagerOption {
+	return func(o *managerOptions) {
+		o.eventMiddlewares = append(o.eventMiddlewares, middlewares...)
+	}
+}
+
+// WithDeliveryHook registers a function called with the final result of every notification
+// delivery, e.g. to alert on subscribers that keep failing. Sending stays non-blocking; the
+// hook runs on the delivery goroutine and should return quickly.
+func WithDeliveryHook(hook func(models.DeliveryResult)) ManagerOption {
+	return func(o *managerOptions) {
+		o.deliveryHook = hook
+	}
+}
+
+// WithDeadLetterStore keeps undelivered notifications in store instead of the default
+// in-memory ring buffer of ManagerConfig.NotificationDeadLetters entries. The store must
+// implement Add, List and Remove (see notifier.DeadLetterStore) and be safe for concurrent use.
+func WithDeadLetterStore(store notifier.DeadLetterStore) ManagerOption {
+	return func(o *managerOptions) {
+		o.deadLetters = store
+	}
+}
+
+// WithAuditSink sends an audit record of every registry mutation, including who requested
+// it, to sink instead of ManagerConfig.AuditLogFile. The sink must implement
+// Audit(models.AuditRecord) (see worker.AuditSink) and return quickly.
+func WithAuditSink(sink worker.AuditSink) ManagerOption {
+	return func(o *managerOptions) {
+		o.auditSink = sink
+	}
+}
+
+// WithNotificationHTTPClient delivers HTTP notifications with client instead of a default
+// client, e.g. one whose transport goes through a proxy or trusts a private CA.
+// NotificationTimeout still bounds every request.
+func WithNotificationHTTPClient(client *http.Client) ManagerOption {
+	return func(o *managerOptions) {
+		o.notifyClient = client
+	}
+}
+
+// WithHealthCheckHTTPClient performs HTTP health checks with client instead of a default
+// client, e.g. one with a custom TLS config or connection pool. HealthCheckTimeout still
+// bounds every attempt.
+func WithHealthCheckHTTPClient(client *http.Client) ManagerOption {
+	return func(o *managerOptions) {
+		o.healthClient = client
+	}
+}
+
 // NewManager creates a new governance manager with in-memory cache only (no database persistence)
-func NewManager(config *models.ManagerConfig) *Manager {
-	return NewManagerWithDatabase(config, nil)
+func NewManager(config *models.ManagerConfig, opts ...ManagerOption) *Manager {
+	return NewManagerWithDatabase(config, nil, opts...)
 }
 
 // NewManagerWithDatabase creates a new governance manager with optional database persistence.
 // The manager always uses in-memory cache for performance.
-// If db is not nil, all changes are also persisted to the database asynchronously.
-func NewManagerWithDatabase(config *models.ManagerConfig, db storage.DatabaseStore) *Manager {
+// If db is not nil, all changes are also persisted to the database, asynchronously unless
+// config.DatabaseWriteMode is models.WriteSync.
+func NewManagerWithDatabase(config *models.ManagerConfig, db storage.DatabaseStore, opts ...ManagerOption) *Manager {
 	if config == nil {
 		config = models.DefaultConfig()
 	}
 
+	options := &managerOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	// Create dual-layer storage (always has cache, database is optional)
-	dualStore := storage.NewDualStore(db)
+	var storeOptions []storage.DualStoreOption
+	if config.DatabaseWriteMode == models.WriteSync {
+		storeOptions = append(storeOptions, storage.WithSyncWrites())
+	}
+	dualStore := storage.NewDualStore(db, storeOptions...)
 
 	// Create registry with dual store
-	reg := registry.NewRegistry(dualStore)
+	reg := registry.NewRegistry(dualStore,
+		registry.WithTombstoneGracePeriod(config.TombstoneGracePeriod),
+		registry.WithMaxSubscriptions(config.MaxSubscriptions),
+		registry.WithNamespaceQuotas(config.NamespaceQuotas, config.DefaultNamespaceQuota),
+		registry.WithStatusHistorySize(config.StatusHistory),
+	)
 
-	// Create event queue with Sequential mode for FIFO processing
-	queueConfig := eventqueue.EventQueueConfig{
-		BufferSize:     config.EventQueueSize,
-		ProcessingMode: eventqueue.Sequential, // Sequential for FIFO event processing
+	// Create event queue: one FIFO worker, or one per shard of services in parallel mode,
+	// counting events for GET /stats
+	eventQueue := queue.NewInstrumentedQueue(newEventQueue(config), queueCapacity(config), config.EventQueueFullWait)
+
+	deadLetters := options.deadLetters
+	if deadLetters == nil {
+		deadLetters = notifier.NewMemoryDeadLetterStore(config.NotificationDeadLetters)
 	}
-	eventQueue := eventqueue.NewEventQueue(queueConfig)
 
 	// Create notifier
-	notif := notifier.NewNotifier(config.NotificationTimeout)
+	notif := notifier.NewNotifier(config.NotificationTimeout,
+		notifier.WithHistorySize(config.NotificationHistory),
+		notifier.WithMaxConcurrency(config.NotificationWorkers),
+		notifier.WithRetryPolicy(notifier.RetryPolicy{
+			MaxAttempts: config.NotificationAttempts,
+			BaseDelay:   config.NotificationBackoff,
+		}),
+		notifier.WithCircuitBreaker(notifier.BreakerPolicy{
+			FailureThreshold: config.NotificationBreakerThreshold,
+			Cooldown:         config.NotificationBreakerCooldown,
+		}),
+		notifier.WithDeliveryHook(options.deliveryHook),
+		notifier.WithDeadLetterStore(deadLetters),
+		notifier.WithTransport("nats", notifier.NewNATSTransport()),
+		notifier.WithHTTPClient(options.notifyClient),
+	)
 
 	// Create health checker
-	healthCheck := notifier.NewHealthChecker(config.HealthCheckTimeout, config.HealthCheckRetry)
+	healthCheck := notifier.NewHealthChecker(config.HealthCheckTimeout, config.HealthCheckRetry,
+		notifier.WithHealthyStatusCodes(config.HealthyStatusCodes...),
+		notifier.WithRetryBackoff(config.HealthCheckBackoff, config.HealthCheckBudget),
+		notifier.WithHealthCheckHTTPClient(options.healthClient),
+	)
+
+	// Create hub streaming live changes to GET /watch clients
+	watchHub := watch.NewHub()
+
+	workerOptions := []worker.WorkerOption{
+		worker.WithMiddleware(options.eventMiddlewares...),
+		worker.WithWatchHub(watchHub),
+		worker.WithHealthThresholds(config.UnhealthyThreshold, config.HealthyThreshold),
+		worker.WithNotificationDwell(config.HealthNotifyDwell),
+		worker.WithReconcileNotifications(!config.DisableReconcileNotify),
+	}
+
+	// Stream registry changes to Kafka if configured
+	var kafkaEmitter *worker.KafkaEmitter
+	if len(config.KafkaBrokers) > 0 && config.KafkaTopic != "" {
+		kafkaEmitter = worker.NewKafkaEmitter(config.KafkaBrokers, config.KafkaTopic, config.KafkaBufferSize)
+		workerOptions = append(workerOptions, worker.WithEventEmitter(kafkaEmitter))
+		logger.Info("Kafka event sink enabled",
+			zap.Strings("brokers", config.KafkaBrokers),
+			zap.String("topic", config.KafkaTopic),
+		)
+	}
+
+	// Record an audit trail of registry mutations if configured
+	auditSink := options.auditSink
+	var auditFile *os.File
+	if auditSink == nil && config.AuditLogFile != "" {
+		file, err := os.OpenFile(config.AuditLogFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o640)
+		if err != nil {
+			logger.Error("Failed to open audit log file, audit logging disabled",
+				zap.String("path", config.AuditLogFile),
+				zap.Error(err),
+			)
+		} else {
+			auditSink, auditFile = worker.NewJSONAuditSink(file), file
+			logger.Info("Audit logging enabled", zap.String("path", config.AuditLogFile))
+		}
+	}
+	if auditSink != nil {
+		workerOptions = append(workerOptions, worker.WithAuditSink(auditSink))
+	}
+
+	// Follow database changes instead of reloading the whole database on every reconcile
+	var databaseWatcher *scheduler.DatabaseWatcher
+	if watchable, ok := db.(storage.WatchingDatabaseStore); ok && config.DatabaseWatchInterval > 0 {
+		databaseWatcher = scheduler.NewDatabaseWatcher(watchable, eventQueue, config.DatabaseWatchInterval)
+		workerOptions = append(workerOptions, worker.WithDatabaseWatch(databaseWatcher.Watching))
+	}
 
 	// Create event worker and register handlers
-	eventWorker := worker.NewEventWorker(reg, notif, healthCheck, dualStore)
+	eventWorker := worker.NewEventWorker(reg, notif, healthCheck, dualStore, workerOptions...)
 	eventWorker.RegisterHandlers(eventQueue)
 
-	// Create schedulers
-	healthCheckScheduler := scheduler.NewHealthCheckScheduler(reg, eventQueue, config.HealthCheckInterval)
+	// Create context for queue
+	queueCtx, queueCancel := context.WithCancel(context.Background())
+
+	// Create schedulers; health checks follow the queue context so shutdown cancels them
+	healthCheckScheduler := scheduler.NewHealthCheckScheduler(queueCtx, reg, eventQueue, config.HealthCheckInterval, config.HealthCheckJitter)
 	reconcileScheduler := scheduler.NewReconcileScheduler(eventQueue, config.NotificationInterval)
+	var repairScheduler *scheduler.RepairScheduler
+	if db != nil {
+		repairInterval := config.DatabaseRepairInterval
+		if repairInterval <= 0 {
+			repairInterval = models.DefaultDatabaseRepairInterval
+		}
+		repairScheduler = scheduler.NewRepairScheduler(eventQueue, repairInterval, dualStore.PendingRepairs)
+	}
+
+	// Watch the database connection and replace the pool if it stays unreachable
+	var databaseMonitor *scheduler.DatabaseMonitor
+	var connectionStats func() models.DatabaseConnectionStats
+	if db != nil && config.DatabaseHealthInterval > 0 {
+		databaseMonitor = scheduler.NewDatabaseMonitor(db, config.DatabaseHealthInterval, config.DatabaseReconnectThreshold)
+		connectionStats = databaseMonitor.Stats
+	}
+
+	// Tracks whether the event queue is processing, for readiness checks
+	queueRunning := &atomic.Bool{}
 
 	// Create HTTP handler
-	handler := api.NewHandler(reg, eventQueue)
+	handler := api.NewHandler(reg, eventQueue,
+		api.WithQueueStatus(queueRunning.Load),
+		api.WithQueueStats(eventQueue.Stats),
+		api.WithLastReconcile(eventWorker.LastReconcile),
+		api.WithNotifier(notif),
+		api.WithDualStore(dualStore),
+		api.WithWatchHub(watchHub),
+		api.WithDatabaseConnection(connectionStats),
+		api.WithDrainMode(func(enabled bool) { setDrainMode(healthCheckScheduler, eventWorker, enabled) }, eventWorker.DrainMode),
+		api.WithAuthToken(config.AuthToken),
+		api.WithMaxBodySize(config.MaxRequestBodySize),
+		api.WithUnknownRoutable(config.UnknownRoutable),
+		api.WithRateLimit(api.RateLimitPolicy{
+			Rate:      config.RateLimit,
+			Burst:     config.RateLimitBurst,
+			KeyHeader: config.RateLimitKeyHeader,
+		}),
+	)
 
 	// Setup HTTP routes
 	mux := http.NewServeMux()
-	mux.HandleFunc("/register", handler.RegisterHandler)
-	mux.HandleFunc("/unregister", handler.UnregisterHandler)
-	mux.HandleFunc("/services", handler.ServicesHandler)
+	mux.HandleFunc("/register", handler.RateLimit(handler.RequireAuth(handler.RegisterHandler)))
+	mux.HandleFunc("/unregister", handler.RateLimit(handler.RequireAuth(handler.UnregisterHandler)))
+	mux.HandleFunc("/unregister/service", handler.RateLimit(handler.RequireAuth(handler.UnregisterServiceHandler)))
+	mux.HandleFunc("/register/batch", handler.RateLimit(handler.RequireAuth(handler.RegisterBatchHandler)))
+	mux.HandleFunc("/unregister/batch", handler.RateLimit(handler.RequireAuth(handler.UnregisterBatchHandler)))
+	mux.HandleFunc("/heartbeat", handler.RateLimit(handler.RequireAuth(handler.HeartbeatHandler)))
+	mux.HandleFunc("/services", api.Gzip(handler.ServicesHandler))
+	mux.HandleFunc("/services/{name}", api.Gzip(handler.ServiceHandler))
+	mux.HandleFunc("/services/{name}/{pod}", handler.RateLimit(handler.RequireAuth(handler.UpdateServiceHandler)))
+	mux.HandleFunc("/services/{name}/{pod}/history", handler.ServiceHistoryHandler)
+	mux.HandleFunc("/reconcile", handler.RateLimit(handler.RequireAuth(handler.ReconcileHandler)))
+	mux.HandleFunc("/healthcheck", handler.RateLimit(handler.RequireAuth(handler.HealthCheckHandler)))
+	mux.HandleFunc("/subscriptions", handler.SubscriptionsHandler)
+	mux.HandleFunc("/dns/zone", handler.DNSZoneHandler)
+	mux.HandleFunc("/quotas", handler.RequireAuth(handler.QuotasHandler))
+	mux.HandleFunc("/changes", handler.ChangesHandler)
+	mux.HandleFunc("/watch", handler.WatchHandler)
+	mux.HandleFunc("/subscribers/{key}/notifications", handler.RequireAuth(handler.SubscriberNotificationsHandler))
+	mux.HandleFunc("/breakers", handler.RequireAuth(handler.BreakersHandler))
+	mux.Handle("/notifications/breakers", http.RedirectHandler("/breakers", http.StatusPermanentRedirect)) // Former path
+	mux.HandleFunc("/breakers/reset", handler.RequireAuth(handler.ResetBreakerHandler))
+	mux.HandleFunc("/notifications/deadletters", handler.RequireAuth(handler.DeadLettersHandler))
+	mux.HandleFunc("/notifications/deadletters/{id}/replay", handler.RequireAuth(handler.ReplayDeadLetterHandler))
+	mux.HandleFunc("/loglevel", handler.RequireAuth(handler.LogLevelHandler))
+	mux.HandleFunc("/drain", handler.RequireAuth(handler.DrainHandler))
+	mux.HandleFunc("/export", handler.RequireAuth(api.Gzip(handler.ExportHandler)))
+	mux.HandleFunc("/import", handler.RequireAuth(handler.ImportHandler))
+	mux.HandleFunc("/database/diff", handler.RequireAuth(handler.DatabaseDiffHandler))
+	mux.HandleFunc("/stats", handler.StatsHandler)
 	mux.HandleFunc("/health", handler.HealthHandler)
+	mux.HandleFunc("/livez", handler.LivenessHandler)
+	mux.HandleFunc("/readyz", handler.ReadinessHandler)
 
 	// Create HTTP server
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", config.ServerPort),
-		Handler: mux,
+		Handler: api.RequestID(api.Trace(mux.ServeHTTP)),
 	}
-
-	// Create context for queue
-	queueCtx, queueCancel := context.WithCancel(context.Background())
+	// Watch streams never end on their own; close them so Shutdown doesn't wait for them
+	httpServer.RegisterOnShutdown(watchHub.Close)
 
 	return &Manager{
 		config:               config,
@@ -110,34 +342,85 @@ func NewManagerWithDatabase(config *models.ManagerConfig, db storage.DatabaseSto
 		notifier:             notif,
 		healthChecker:        healthCheck,
 		eventWorker:          eventWorker,
+		kafkaEmitter:         kafkaEmitter,
+		auditFile:            auditFile,
 		healthCheckScheduler: healthCheckScheduler,
 		reconcileScheduler:   reconcileScheduler,
+		repairScheduler:      repairScheduler,
+		databaseWatcher:      databaseWatcher,
+		databaseMonitor:      databaseMonitor,
 		httpServer:           httpServer,
 		stopChan:             make(chan struct{}),
 		queueContext:         queueCtx,
 		queueCancel:          queueCancel,
+		queueRunning:         queueRunning,
+	}
+}
+
+// newEventQueue creates the event queue for the configured processing mode
+func newEventQueue(config *models.ManagerConfig) eventqueue.IEventQueue {
+	queueConfig := eventqueue.EventQueueConfig{
+		BufferSize:     config.EventQueueSize,
+		ProcessingMode: eventqueue.Sequential, // Sequential for FIFO event processing
+	}
+
+	if config.EventProcessingMode != models.ProcessingParallel {
+		return eventqueue.NewEventQueue(queueConfig)
+	}
+
+	workers := config.EventWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
 	}
+	logger.Info("Using parallel event processing", zap.Int("event_workers", workers))
+	return queue.NewShardedQueue(queueConfig, workers)
+}
+
+// queueCapacity returns how many events the event queue buffers
+func queueCapacity(config *models.ManagerConfig) int {
+	if config.EventQueueSize <= 0 {
+		return 100 // go-event-queue's default buffer size
+	}
+	return config.EventQueueSize
 }
 
 // Start starts the governance manager
 func (m *Manager) Start() error {
 	logger.Info("Starting governance manager")
 
-	// Start event queue
-	go func() {
-		if err := m.eventQueue.Start(m.queueContext); err != nil {
-			logger.Error("Event queue error", zap.Error(err))
-		}
-	}()
+	// Listen before starting anything else, so a port that is taken fails Start and a random
+	// port (ServerPort 0) is known when Start returns
+	listener, err := net.Listen("tcp", m.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", m.httpServer.Addr, err)
+	}
+	m.listener = listener
+
+	// Start event queue. It only spawns its workers, and must be running before the HTTP
+	// server or a scheduler enqueues the first event.
+	if err := m.eventQueue.Start(m.queueContext); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to start event queue: %w", err)
+	}
+	m.queueRunning.Store(true)
 
 	// Start schedulers
 	go m.healthCheckScheduler.Start()
 	go m.reconcileScheduler.Start()
+	if m.repairScheduler != nil {
+		go m.repairScheduler.Start()
+	}
+	if m.databaseWatcher != nil {
+		go m.databaseWatcher.Start()
+	}
+	if m.databaseMonitor != nil {
+		go m.databaseMonitor.Start()
+	}
 
 	// Start HTTP server
 	go func() {
-		logger.Info("HTTP server starting", zap.Int("port", m.config.ServerPort))
-		if err := m.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Info("HTTP server starting", zap.String("addr", listener.Addr().String()))
+		if err := m.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
 			logger.Error("HTTP server error", zap.Error(err))
 		}
 	}()
@@ -150,46 +433,193 @@ func (m *Manager) Start() error {
 	return nil
 }
 
-// Stop gracefully stops the governance manager
+// Stop gracefully stops the governance manager.
+// New requests and events are refused first, then events already enqueued are processed
+// and pending database writes flushed. Only then is storage closed. ShutdownTimeout bounds
+// all of it: in-flight requests, draining and closing storage share one deadline, and a
+// phase still running when it passes is abandoned. Every phase still runs, and the errors of
+// those that failed or timed out are returned joined; a draining error means some changes
+// may not have been processed or persisted.
 func (m *Manager) Stop() error {
 	logger.Info("Stopping governance manager")
 
+	ctx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout())
+	defer cancel()
+
+	// Report not ready while shutting down
+	m.queueRunning.Store(false)
+
 	// Stop schedulers
 	m.healthCheckScheduler.Stop()
 	m.reconcileScheduler.Stop()
+	if m.repairScheduler != nil {
+		m.repairScheduler.Stop()
+	}
+	if m.databaseWatcher != nil {
+		m.databaseWatcher.Stop()
+	}
+	if m.databaseMonitor != nil {
+		m.databaseMonitor.Stop()
+	}
 
-	// Stop HTTP server
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	var errs []error
 
+	// Stop HTTP server (waits for in-flight requests, which may still enqueue events)
 	if err := m.httpServer.Shutdown(ctx); err != nil {
 		logger.Error("HTTP server shutdown error", zap.Error(err))
+		errs = append(errs, fmt.Errorf("failed to shut down HTTP server: %w", err))
+		// Requests still running past the timeout (e.g. watch streams) are cut off
+		m.httpServer.Close()
 	}
 
-	// Stop event queue
-	if err := m.eventQueue.Stop(); err != nil {
-		logger.Error("Event queue stop error", zap.Error(err))
-	}
+	// Drain the event queue: Stop refuses new events and returns once enqueued ones are processed
+	drainErr := m.drain(ctx)
 	m.queueCancel()
+	if drainErr != nil {
+		errs = append(errs, drainErr)
+	}
+
+	// Write change records still buffered for Kafka
+	if m.kafkaEmitter != nil {
+		if err := m.kafkaEmitter.Close(ctx); err != nil {
+			logger.Error("Kafka event sink close error", zap.Error(err))
+			errs = append(errs, fmt.Errorf("failed to close Kafka event sink: %w", err))
+		}
+	}
+
+	// Health status notifications still waiting out their dwell time would fire after shutdown
+	m.eventWorker.DiscardPendingNotifications()
+
+	// Close notification transports (e.g. NATS connections)
+	if err := m.notifier.Close(); err != nil {
+		logger.Error("Notifier close error", zap.Error(err))
+		errs = append(errs, fmt.Errorf("failed to close notifier: %w", err))
+	}
+
+	// Close the audit log after the last event was handled
+	if m.auditFile != nil {
+		if err := m.auditFile.Close(); err != nil {
+			logger.Error("Audit log close error", zap.Error(err))
+			errs = append(errs, fmt.Errorf("failed to close audit log: %w", err))
+		}
+	}
 
 	// Close storage connection (database if enabled)
-	if err := m.dualStore.Close(); err != nil {
+	if err := m.closeStorage(ctx); err != nil {
 		logger.Error("Storage close error", zap.Error(err))
+		errs = append(errs, err)
 	}
 
 	// Close stop channel
 	close(m.stopChan)
 
-	logger.Info("Governance manager stopped")
+	err := errors.Join(errs...)
+	switch {
+	case drainErr != nil:
+		logger.Error("Governance manager stopped before draining completed, changes may be lost",
+			zap.Error(err),
+		)
+	case err != nil:
+		logger.Warn("Governance manager stopped with errors", zap.Error(err))
+	default:
+		logger.Info("Governance manager stopped")
+	}
 	logger.Sync() // Flush any buffered logs
+	return err
+}
+
+// closeStorage closes the storage layer, giving up waiting once ctx is done. A database
+// connection that doesn't close in time is left to the process exit.
+func (m *Manager) closeStorage(ctx context.Context) error {
+	closed := make(chan error, 1)
+	go func() {
+		closed <- m.dualStore.Close()
+	}()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			return fmt.Errorf("failed to close storage: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out closing storage: %w", ctx.Err())
+	}
+}
+
+// drain waits for the event queue to finish enqueued events and for pending
+// database writes, until ctx is done
+func (m *Manager) drain(ctx context.Context) error {
+	queueDone := make(chan struct{})
+	go func() {
+		if err := m.eventQueue.Stop(); err != nil {
+			logger.Error("Event queue stop error", zap.Error(err))
+		}
+		close(queueDone)
+	}()
+
+	select {
+	case <-queueDone:
+		logger.Info("Event queue drained")
+	case <-ctx.Done():
+		return fmt.Errorf("timed out draining event queue (%d events left): %w",
+			m.eventQueue.GetQueueSize(), ctx.Err())
+	}
+
+	if err := m.dualStore.Flush(ctx); err != nil {
+		return fmt.Errorf("timed out flushing database writes: %w", err)
+	}
+
 	return nil
 }
 
+// shutdownTimeout returns the configured shutdown timeout or the default
+func (m *Manager) shutdownTimeout() time.Duration {
+	if m.config.ShutdownTimeout > 0 {
+		return m.config.ShutdownTimeout
+	}
+	return models.DefaultShutdownTimeout
+}
+
 // Wait blocks until the manager is stopped
 func (m *Manager) Wait() {
 	<-m.stopChan
 }
 
+// Addr returns the address the HTTP server listens on, e.g. to find the port chosen for
+// ServerPort 0. Empty before Start.
+func (m *Manager) Addr() string {
+	if m.listener == nil {
+		return ""
+	}
+	return m.listener.Addr().String()
+}
+
+// Handler returns the manager's HTTP API, e.g. to serve it with httptest instead of a port
+func (m *Manager) Handler() http.Handler {
+	return m.httpServer.Handler
+}
+
+// WaitIdle blocks until every event enqueued before the call has been processed and the
+// database writes it caused are done, or ctx is done. Tests use it to wait for the effect
+// of a request instead of sleeping. The manager must have been started.
+func (m *Manager) WaitIdle(ctx context.Context) error {
+	target := m.eventQueue.Stats().Enqueued
+
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for m.eventQueue.Stats().Processed < target {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for events: %w", ctx.Err())
+		}
+	}
+
+	return m.dualStore.Flush(ctx)
+}
+
 // GetRegistry returns the registry (for testing/debugging)
 func (m *Manager) GetRegistry() *registry.Registry {
 	return m.registry
@@ -200,18 +630,106 @@ func (m *Manager) GetConfig() *models.ManagerConfig {
 	return m.config
 }
 
-// GetServicePods returns all pods for a given service group
-func (m *Manager) GetServicePods(serviceName string) []*models.ServiceInfo {
-	return m.registry.GetByServiceName(serviceName)
+// QueueStats reports the event queue's current size, throughput and lag, e.g. to size
+// EventQueueSize
+func (m *Manager) QueueStats() models.QueueStats {
+	return m.eventQueue.Stats()
+}
+
+// RepairStats reports failed asynchronous database writes waiting to be retried and how
+// many retries succeeded
+func (m *Manager) RepairStats() models.RepairStats {
+	return m.dualStore.RepairStats()
+}
+
+// DatabaseDiff compares the in-memory registry with the database, see storage.DualStore.Diff.
+// Returns storage.ErrNoDatabase when the manager has no database.
+func (m *Manager) DatabaseDiff(ctx context.Context) (*models.StoreDiff, error) {
+	return m.dualStore.Diff(ctx)
+}
+
+// DatabaseConnection returns the database connection state seen by the database monitor.
+// Returns false when the manager has no database or DatabaseHealthInterval is 0.
+func (m *Manager) DatabaseConnection() (models.DatabaseConnectionStats, bool) {
+	if m.databaseMonitor == nil {
+		return models.DatabaseConnectionStats{}, false
+	}
+	return m.databaseMonitor.Stats(), true
+}
+
+// SetDrainMode turns drain mode on or off. While draining, scheduled health checks are paused
+// (checks already queued are aborted) and subscribers are not notified of health status
+// changes, so planned maintenance of the checked targets flips nothing. The HTTP API,
+// on-demand checks and reconciles keep running. Turning it off resumes checks from the next
+// interval, starting their consecutive-result thresholds over.
+func (m *Manager) SetDrainMode(enabled bool) {
+	setDrainMode(m.healthCheckScheduler, m.eventWorker, enabled)
+}
+
+// DrainMode reports whether drain mode is on
+func (m *Manager) DrainMode() bool {
+	return m.eventWorker.DrainMode()
+}
+
+// setDrainMode applies drain mode to the health check scheduler and the event worker
+func setDrainMode(healthCheckScheduler *scheduler.HealthCheckScheduler, eventWorker *worker.EventWorker, enabled bool) {
+	healthCheckScheduler.SetPaused(enabled)
+	eventWorker.SetDrainMode(enabled)
+}
+
+// GetServicePods returns all pods for a given service group. Service groups outside the
+// default namespace are named namespace/service_name, see models.QualifiedServiceName.
+func (m *Manager) GetServicePods(ctx context.Context, serviceName string) []*models.ServiceInfo {
+	return m.registry.GetByServiceName(ctx, serviceName)
+}
+
+// ExportSnapshot returns a versioned JSON snapshot of the whole registry, tombstones
+// included, that ImportSnapshot accepts. Health check headers are included as is.
+func (m *Manager) ExportSnapshot(ctx context.Context) ([]byte, error) {
+	snapshot, err := m.registry.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(snapshot)
+}
+
+// ImportSnapshot replaces the registry with a snapshot from ExportSnapshot. The whole snapshot
+// is validated first, an invalid one returns a *models.ValidationError and changes nothing.
+// Returns once the import has been applied and subscribers sent reconcile notifications.
+// The manager must be started, the import runs on the event queue like every other change.
+func (m *Manager) ImportSnapshot(ctx context.Context, data []byte) error {
+	snapshot, err := models.ParseSnapshot(data)
+	if err != nil {
+		return err
+	}
+
+	event := eventqueue.NewEvent(string(events.EventImport), events.NewImportContext(snapshot))
+	if err := m.eventQueue.Enqueue(event); err != nil {
+		return fmt.Errorf("failed to enqueue import event: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := event.Wait()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-// GetAllServicePods returns a map of service names to their pods
-func (m *Manager) GetAllServicePods() map[string][]*models.ServiceInfo {
-	allServices := m.registry.GetAllServices()
+// GetAllServicePods returns a map of qualified service names to their pods
+func (m *Manager) GetAllServicePods(ctx context.Context) map[string][]*models.ServiceInfo {
+	allServices := m.registry.GetAllServices(ctx)
 	result := make(map[string][]*models.ServiceInfo)
 
 	for _, service := range allServices {
-		result[service.ServiceName] = append(result[service.ServiceName], service)
+		name := service.QualifiedName()
+		result[name] = append(result[name], service)
 	}
 
 	return result
@@ -0,0 +1,237 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chronnie/governance/models"
+)
+
+func TestStopTimesOutOnOpenRequests(t *testing.T) {
+	config := TestConfig()
+	config.ShutdownTimeout = 200 * time.Millisecond
+
+	m := NewManager(config)
+	m.httpServer.Addr = "127.0.0.1:0"
+
+	// A request that only ends when its connection is closed outlives the shutdown timeout
+	started := make(chan struct{})
+	m.httpServer.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+	if err := m.Start(); err != nil {
+		t.Fatalf("Failed to start manager: %v", err)
+	}
+
+	go func() {
+		if resp, err := http.Get("http://" + m.Addr() + "/services"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	start := time.Now()
+	err := m.Stop()
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected Stop to report the timed out HTTP shutdown, got %v", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("Expected Stop to give up after the shutdown timeout, took %v", elapsed)
+	}
+}
+
+// mapDatabase is a database keeping services and subscriptions in maps
+type mapDatabase struct {
+	mu            sync.Mutex
+	services      map[string]*models.ServiceInfo
+	subscriptions map[string][]string
+	changes       []*models.ChangeRecord
+}
+
+func newMapDatabase() *mapDatabase {
+	return &mapDatabase{services: make(map[string]*models.ServiceInfo), subscriptions: make(map[string][]string)}
+}
+
+func (d *mapDatabase) SaveService(ctx context.Context, service *models.ServiceInfo) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	serviceCopy := *service
+	d.services[service.GetKey()] = &serviceCopy
+	return nil
+}
+
+func (d *mapDatabase) GetService(ctx context.Context, key string) (*models.ServiceInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	service, exists := d.services[key]
+	if !exists {
+		return nil, fmt.Errorf("service not found: %s", key)
+	}
+	serviceCopy := *service
+	return &serviceCopy, nil
+}
+
+func (d *mapDatabase) GetAllServices(ctx context.Context) ([]*models.ServiceInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	result := make([]*models.ServiceInfo, 0, len(d.services))
+	for _, service := range d.services {
+		serviceCopy := *service
+		result = append(result, &serviceCopy)
+	}
+	return result, nil
+}
+
+func (d *mapDatabase) DeleteService(ctx context.Context, key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.services, key)
+	return nil
+}
+
+func (d *mapDatabase) UpdateHealthStatus(ctx context.Context, key string, status models.ServiceStatus, timestamp time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if service, exists := d.services[key]; exists {
+		service.Status = status
+		service.LastHealthCheck = timestamp
+	}
+	return nil
+}
+
+func (d *mapDatabase) SaveSubscriptions(ctx context.Context, subscriberKey string, subscriptions []string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscriptions[subscriberKey] = slices.Clone(subscriptions)
+	return nil
+}
+
+func (d *mapDatabase) GetSubscriptions(ctx context.Context, subscriberKey string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.subscriptions[subscriberKey]), nil
+}
+
+func (d *mapDatabase) GetAllSubscriptions(ctx context.Context) (map[string][]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	result := make(map[string][]string, len(d.subscriptions))
+	for subscriberKey, serviceGroups := range d.subscriptions {
+		result[subscriberKey] = slices.Clone(serviceGroups)
+	}
+	return result, nil
+}
+
+func (d *mapDatabase) DeleteSubscriptions(ctx context.Context, subscriberKey string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.subscriptions, subscriberKey)
+	return nil
+}
+
+func (d *mapDatabase) AppendChange(ctx context.Context, change *models.ChangeRecord) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	changeCopy := *change
+	changeCopy.Sequence = int64(len(d.changes) + 1)
+	d.changes = append(d.changes, &changeCopy)
+	return changeCopy.Sequence, nil
+}
+
+func (d *mapDatabase) GetChangesSince(ctx context.Context, since int64, limit int) ([]*models.ChangeRecord, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var result []*models.ChangeRecord
+	for _, change := range d.changes {
+		if change.Sequence > since && len(result) < limit {
+			result = append(result, change)
+		}
+	}
+	return result, nil
+}
+
+func (d *mapDatabase) Close() error                   { return nil }
+func (d *mapDatabase) Ping(ctx context.Context) error { return nil }
+
+func TestParallelProcessing(t *testing.T) {
+	config := TestConfig()
+	config.EventProcessingMode = models.ProcessingParallel
+	config.EventWorkers = 4
+	config.DatabaseWatchInterval = 0 // Reconciles sync the cache from the database
+
+	m := NewManagerWithDatabase(config, newMapDatabase())
+	m.httpServer.Addr = "127.0.0.1:0"
+	if err := m.Start(); err != nil {
+		t.Fatalf("Failed to start manager: %v", err)
+	}
+	defer m.Stop()
+
+	baseURL := "http://" + m.Addr()
+	post := func(path string, body []byte) {
+		resp, err := http.Post(baseURL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Errorf("POST %s failed: %v", path, err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			message, _ := io.ReadAll(resp.Body)
+			t.Errorf("POST %s: unexpected status %d: %s", path, resp.StatusCode, message)
+		}
+	}
+
+	// Registrations of different services land on different shards, while reconciles on
+	// shard 0 sync the cache from the database
+	const services, pods = 8, 4
+	var wg sync.WaitGroup
+	for i := range services {
+		for j := range pods {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				body, _ := json.Marshal(&models.ServiceRegistration{
+					ServiceName:     fmt.Sprintf("service-%d", i),
+					PodName:         fmt.Sprintf("pod-%d", j),
+					Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "127.0.0.1", Port: 8080}},
+					HealthCheckURL:  "http://127.0.0.1:1/health",
+					NotificationURL: "http://127.0.0.1:1/notify",
+					Subscriptions:   []string{fmt.Sprintf("service-%d", (i+1)%services)},
+				})
+				post("/register", body)
+			}()
+		}
+	}
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			post("/reconcile", nil)
+		}()
+	}
+	wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.WaitIdle(ctx); err != nil {
+		t.Fatalf("WaitIdle failed: %v", err)
+	}
+
+	if registered := len(m.GetRegistry().GetAllServices(ctx)); registered != services*pods {
+		t.Errorf("Expected %d registered pods, got %d", services*pods, registered)
+	}
+	if subscribers := m.GetRegistry().GetSubscribers(ctx, "service-0"); len(subscribers) != pods {
+		t.Errorf("Expected %d subscribers of service-0, got %v", pods, subscribers)
+	}
+}
@@ -0,0 +1,48 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chronnie/governance/models"
+)
+
+// TestConfig returns a configuration for managers in tests: periodic health checks and
+// reconciles practically never run on their own, and nothing is rate limited
+func TestConfig() *models.ManagerConfig {
+	config := models.DefaultConfig()
+	config.ServerPort = 0
+	config.HealthCheckInterval = time.Hour
+	config.HealthCheckJitter = 0
+	config.NotificationInterval = time.Hour
+	config.DatabaseHealthInterval = 0
+	config.RateLimit = 0
+	config.ShutdownTimeout = 5 * time.Second
+	return config
+}
+
+// NewTestManager starts an in-memory manager for tests of code that depends on governance.
+// It listens on a random port on 127.0.0.1, see Addr, and is stopped when the test ends
+// (don't call Stop yourself).
+// A nil config uses TestConfig; ServerPort is ignored either way. Use WaitIdle after
+// a request to wait until its event has been processed.
+func NewTestManager(tb testing.TB, config *models.ManagerConfig, opts ...ManagerOption) *Manager {
+	tb.Helper()
+
+	if config == nil {
+		config = TestConfig()
+	}
+
+	m := NewManager(config, opts...)
+	m.httpServer.Addr = "127.0.0.1:0"
+	if err := m.Start(); err != nil {
+		tb.Fatalf("failed to start test manager: %v", err)
+	}
+	tb.Cleanup(func() {
+		if err := m.Stop(); err != nil {
+			tb.Errorf("failed to stop test manager: %v", err)
+		}
+	})
+
+	return m
+}
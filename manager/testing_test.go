@@ -0,0 +1,46 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chronnie/governance/models"
+)
+
+func TestNewTestManager(t *testing.T) {
+	m := NewTestManager(t, nil)
+
+	if m.Addr() == "" {
+		t.Fatal("Expected the listen address to be known after Start")
+	}
+
+	body, _ := json.Marshal(&models.ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "pod-1",
+		Providers:       []models.ProviderInfo{{Protocol: models.ProtocolHTTP, IP: "127.0.0.1", Port: 8080}},
+		HealthCheckURL:  "http://127.0.0.1:8080/health",
+		NotificationURL: "http://127.0.0.1:8080/notify",
+	})
+	resp, err := http.Post("http://"+m.Addr()+"/register", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Register request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d", http.StatusAccepted, resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.WaitIdle(ctx); err != nil {
+		t.Fatalf("WaitIdle failed: %v", err)
+	}
+
+	if pods := m.GetServicePods(ctx, "user-service"); len(pods) != 1 {
+		t.Errorf("Expected the registration to be processed, got %d pods", len(pods))
+	}
+}
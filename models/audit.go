@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// PrincipalSystem identifies changes made by the manager itself, e.g. health status changes
+const PrincipalSystem = "system"
+
+// Caller identifies who requested a registry change
+type Caller struct {
+	Principal  string `json:"principal"`             // "token:<fingerprint>" for authenticated requests, "anonymous" or "system"
+	RemoteAddr string `json:"remote_addr,omitempty"` // Client address of the API request
+}
+
+// AuditRecord is one entry of the audit trail of registry mutations
+type AuditRecord struct {
+	Timestamp   time.Time     `json:"timestamp"`
+	EventType   EventType     `json:"event_type"` // register, unregister or update
+	ServiceKey  string        `json:"service_key"`
+	ServiceName string        `json:"service_name"`
+	PodName     string        `json:"pod_name"`
+	Status      ServiceStatus `json:"status"` // Status after the change
+	Caller      Caller        `json:"caller"`
+}
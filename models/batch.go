@@ -0,0 +1,34 @@
+package models
+
+// BatchItemResult values
+const (
+	BatchResultCreated = "created" // Registration queued for a new service
+	BatchResultUpdated = "updated" // Registration queued for an existing service
+	BatchResultDeleted = "deleted" // Unregistration queued for an existing service
+	BatchResultError   = "error"   // Item rejected, see Error
+)
+
+// BatchItemResult describes the outcome of a single item in a batch request
+type BatchItemResult struct {
+	Index       int    `json:"index"` // Position of the item in the request array
+	ServiceName string `json:"service_name"`
+	PodName     string `json:"pod_name"`
+	Result      string `json:"result"`          // created, updated, deleted or error
+	Error       string `json:"error,omitempty"` // Set when Result is error
+}
+
+// BatchResponse is returned by the batch register/unregister endpoints.
+// The HTTP status is 200 when every item succeeded, 207 when results are mixed,
+// and 400 when every item failed validation.
+type BatchResponse struct {
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BatchItemResult `json:"results"`
+}
+
+// UnregisterRequest identifies a single pod to unregister in a batch request
+type UnregisterRequest struct {
+	Namespace   string `json:"namespace,omitempty"`
+	ServiceName string `json:"service_name"`
+	PodName     string `json:"pod_name"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ChangeRecord is an entry in the append-only registry change feed.
+// Sequence numbers are assigned by the store and strictly increase, so clients can
+// resume from the last sequence they have seen.
+type ChangeRecord struct {
+	Sequence    int64         `json:"sequence"`
+	Timestamp   time.Time     `json:"timestamp"`
+	EventType   EventType     `json:"event_type"` // register, unregister or update (status change)
+	ServiceKey  string        `json:"service_key"`
+	ServiceName string        `json:"service_name"`
+	PodName     string        `json:"pod_name"`
+	Status      ServiceStatus `json:"status"` // Status after the change
+}
@@ -9,26 +9,138 @@ type ManagerConfig struct {
 
 	// Health check settings
 	HealthCheckInterval time.Duration `json:"health_check_interval"` // How often to check health
+	HealthCheckJitter   float64       `json:"health_check_jitter"`   // Fraction of the interval checks are randomly spread over (0 = none)
 	HealthCheckTimeout  time.Duration `json:"health_check_timeout"`  // Timeout for health check HTTP call
 	HealthCheckRetry    int           `json:"health_check_retry"`    // Number of retries before marking unhealthy
+	HealthCheckBackoff  time.Duration `json:"health_check_backoff"`  // Max backoff between retries, which otherwise double from 1s (0 = 30s)
+	HealthCheckBudget   time.Duration `json:"health_check_budget"`   // Max backoff added up over the retries of one check, stops retrying early (0 = 60s)
+	HealthyStatusCodes  []int         `json:"healthy_status_codes"`  // HTTP status codes treated as healthy (empty = 200-299)
+	UnhealthyThreshold  int           `json:"unhealthy_threshold"`   // Consecutive failed checks before marking unhealthy (0 = 1)
+	HealthyThreshold    int           `json:"healthy_threshold"`     // Consecutive successful checks before marking healthy again (0 = 1)
+	HealthNotifyDwell   time.Duration `json:"health_notify_dwell"`   // How long a new health status must hold before subscribers are notified (0 = immediately)
+
+	StatusHistory int `json:"status_history"` // Recent health status transitions kept per pod (0 = default 20)
+
+	UnknownRoutable bool `json:"unknown_routable"` // Whether ?healthy_only=true on /services keeps pods with unknown status
 
 	// Notification settings
 	NotificationInterval time.Duration `json:"notification_interval"` // Periodic reconcile interval
 	NotificationTimeout  time.Duration `json:"notification_timeout"`  // Timeout for notification HTTP call
+	NotificationHistory  int           `json:"notification_history"`  // Recent notifications kept per subscriber (0 = default)
+	NotificationAttempts int           `json:"notification_attempts"` // Delivery attempts per notification incl. retries (0 or 1 = no retry)
+	NotificationBackoff  time.Duration `json:"notification_backoff"`  // Base delay before the first retry, doubled per retry (0 = 500ms)
+	NotificationWorkers  int           `json:"notification_workers"`  // Max notifications delivered concurrently (0 = default 100)
+
+	NotificationBreakerThreshold int           `json:"notification_breaker_threshold"` // Consecutive failed deliveries before a URL's circuit opens (0 = no breaker)
+	NotificationBreakerCooldown  time.Duration `json:"notification_breaker_cooldown"`  // How long an open circuit skips notifications before probing (0 = 30s)
+	NotificationDeadLetters      int           `json:"notification_dead_letters"`      // Undelivered notifications kept for inspection and replay (0 = default 1000)
+
+	DisableReconcileNotify bool `json:"disable_reconcile_notify"` // Reconciles sync the cache without notifying subscribers (false = notify)
+
+	// Subscription settings
+	MaxSubscriptions int `json:"max_subscriptions"` // Most service groups one pod may subscribe to (0 = unlimited)
+
+	// Tenant quota settings
+	NamespaceQuotas       map[string]int `json:"namespace_quotas"`        // Most live pods per namespace, "" being the default namespace (0 = unlimited)
+	DefaultNamespaceQuota int            `json:"default_namespace_quota"` // Most live pods of namespaces not in NamespaceQuotas (0 = unlimited)
+
+	// Soft-delete settings
+	TombstoneGracePeriod time.Duration `json:"tombstone_grace_period"` // How long unregistered pods stay queryable as tombstones (0 = delete immediately)
 
 	// Event queue settings
-	EventQueueSize int `json:"event_queue_size"` // Event queue buffer size
+	EventQueueSize      int            `json:"event_queue_size"`      // Event queue buffer size
+	EventQueueFullWait  time.Duration  `json:"event_queue_full_wait"` // How long enqueueing waits for room in a full queue before rejecting the event (0 = reject immediately)
+	EventProcessingMode ProcessingMode `json:"event_processing_mode"` // sequential or parallel (empty = sequential)
+	EventWorkers        int            `json:"event_workers"`         // Concurrent event workers in parallel mode (0 = number of CPUs)
+
+	// Kafka event sink, enabled when both brokers and topic are set
+	KafkaBrokers    []string `json:"kafka_brokers"`     // Broker addresses (host:port)
+	KafkaTopic      string   `json:"kafka_topic"`       // Topic receiving a record per registry change
+	KafkaBufferSize int      `json:"kafka_buffer_size"` // Records buffered while Kafka is slow or down, newer ones are dropped (0 = 1000)
+
+	// Database settings, used when the manager has a database
+	DatabaseWriteMode      WriteMode     `json:"database_write_mode"`      // async or sync (empty = async)
+	DatabaseRepairInterval time.Duration `json:"database_repair_interval"` // How often failed async database writes are retried (0 = 5s)
+	DatabaseWatchInterval  time.Duration `json:"database_watch_interval"`  // How often changes streamed by the database are reloaded, instead of the whole database on reconcile (0 = no watch)
+	DatabaseHealthInterval time.Duration `json:"database_health_interval"` // How often the database is pinged to detect a lost connection (0 = no monitoring)
+
+	DatabaseReconnectThreshold int `json:"database_reconnect_threshold"` // Consecutive failed pings before the connection pool is replaced (0 = 3)
+
+	// Audit settings
+	AuditLogFile string `json:"audit_log_file"` // File receiving a JSON line per registry mutation with its caller (empty = no audit log)
+
+	// Shutdown settings
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"` // Max time Stop waits for in-flight requests, event and database write draining and storage close (0 = default)
+
+	// API settings
+	AuthToken string `json:"auth_token"` // Bearer token for write/admin endpoints (empty = no auth)
+
+	MaxRequestBodySize int64 `json:"max_request_body_size"` // Largest register/batch register body in bytes, larger ones get 413 (0 = 1 MiB)
+
+	// Rate limit for register/unregister/update/reconcile endpoints, per client
+	RateLimit          float64 `json:"rate_limit"`            // Requests per second per client (0 = unlimited)
+	RateLimitBurst     int     `json:"rate_limit_burst"`      // Requests a client may send at once (0 = rate rounded up)
+	RateLimitKeyHeader string  `json:"rate_limit_key_header"` // Header identifying clients, e.g. X-API-Key (empty = client IP)
 }
 
+// ProcessingMode controls how the manager processes events
+type ProcessingMode string
+
+const (
+	// ProcessingSequential handles all events one at a time in FIFO order
+	ProcessingSequential ProcessingMode = "sequential"
+	// ProcessingParallel handles events of different services concurrently.
+	// Events of the same service are still handled in FIFO order.
+	ProcessingParallel ProcessingMode = "parallel"
+)
+
+// WriteMode controls when registry changes are written to the database
+type WriteMode string
+
+const (
+	// WriteAsync updates the in-memory registry right away and writes the database in the
+	// background. Failed writes are logged and retried; the API never waits for the database.
+	WriteAsync WriteMode = "async"
+	// WriteSync writes the database before the in-memory registry. Registrations are only
+	// acknowledged once persisted and a database failure is returned to the client.
+	WriteSync WriteMode = "sync"
+)
+
+// DefaultDatabaseRepairInterval is used when ManagerConfig.DatabaseRepairInterval is not set
+const DefaultDatabaseRepairInterval = 5 * time.Second
+
+// DefaultShutdownTimeout is used when ManagerConfig.ShutdownTimeout is not set
+const DefaultShutdownTimeout = 30 * time.Second
+
 // DefaultConfig returns a default configuration
 func DefaultConfig() *ManagerConfig {
 	return &ManagerConfig{
 		ServerPort:           8080,
 		HealthCheckInterval:  30 * time.Second,
+		HealthCheckJitter:    0.1,
 		HealthCheckTimeout:   5 * time.Second,
 		HealthCheckRetry:     3,
+		UnhealthyThreshold:   1,
+		HealthyThreshold:     1,
+		StatusHistory:        20,
 		NotificationInterval: 60 * time.Second,
 		NotificationTimeout:  5 * time.Second,
-		EventQueueSize:       1000,
+		NotificationHistory:  20,
+		NotificationAttempts: 3,
+		NotificationBackoff:  500 * time.Millisecond,
+		NotificationWorkers:  100,
+
+		NotificationBreakerThreshold: 5,
+		NotificationBreakerCooldown:  30 * time.Second,
+		NotificationDeadLetters:      1000,
+		EventQueueSize:               1000,
+		EventQueueFullWait:           time.Second,
+		DatabaseRepairInterval:       DefaultDatabaseRepairInterval,
+		DatabaseWatchInterval:        time.Second,
+		DatabaseHealthInterval:       10 * time.Second,
+		DatabaseReconnectThreshold:   3,
+		ShutdownTimeout:              DefaultShutdownTimeout,
+		RateLimit:                    50,
+		RateLimitBurst:               100,
 	}
 }
@@ -0,0 +1,270 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigEnvPrefix prefixes the environment variables read by LoadConfigFromEnv. Each
+// ManagerConfig field is read from the prefix followed by its JSON name in upper case, e.g.
+// GOVERNANCE_SERVER_PORT or GOVERNANCE_HEALTH_CHECK_INTERVAL.
+const ConfigEnvPrefix = "GOVERNANCE_"
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// LoadConfigFromEnv returns DefaultConfig overridden by the GOVERNANCE_* environment
+// variables that are set. Durations use Go syntax (e.g. 30s, 1m30s), lists are comma
+// separated and maps are comma separated key=value pairs (e.g. team-a=10,team-b=20). The result is validated, see ManagerConfig.Validate.
+func LoadConfigFromEnv() (*ManagerConfig, error) {
+	config := DefaultConfig()
+	if err := config.applyEnv(); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// LoadConfigFromFile returns DefaultConfig overridden by the YAML or JSON file at path, then
+// by the environment as in LoadConfigFromEnv. Files ending in .json are parsed as JSON, any
+// other as YAML. Keys are the JSON names of the ManagerConfig fields; unknown keys are
+// rejected to catch typos. Durations are strings in Go syntax, or numbers of nanoseconds as
+// written by json.Marshal. The result is validated, see ManagerConfig.Validate.
+func LoadConfigFromFile(path string) (*ManagerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := make(map[string]any)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &values)
+	} else {
+		err = yaml.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	config := DefaultConfig()
+	fields := configFields()
+	for key, value := range values {
+		index, ok := fields[key]
+		if !ok {
+			return nil, fmt.Errorf("config file %s: unknown setting %q", path, key)
+		}
+		if err := setFileValue(reflect.ValueOf(config).Elem().Field(index), value); err != nil {
+			return nil, fmt.Errorf("config file %s: invalid %s: %w", path, key, err)
+		}
+	}
+
+	if err := config.applyEnv(); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks that the configuration can run a manager: intervals and timeouts the
+// manager waits on are positive, the event queue has room, and no other setting is negative
+// or out of range. Returns a *ValidationError describing the first problem found.
+func (c *ManagerConfig) Validate() error {
+	invalid := func(format string, args ...any) error {
+		return &ValidationError{Message: fmt.Sprintf(format, args...)}
+	}
+
+	if c.ServerPort < 0 || c.ServerPort > 65535 {
+		return invalid("server_port must be between 0 and 65535")
+	}
+	for _, interval := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"health_check_interval", c.HealthCheckInterval},
+		{"health_check_timeout", c.HealthCheckTimeout},
+		{"notification_interval", c.NotificationInterval},
+		{"notification_timeout", c.NotificationTimeout},
+	} {
+		if interval.value <= 0 {
+			return invalid("%s must be positive", interval.name)
+		}
+	}
+	if c.EventQueueSize <= 0 {
+		return invalid("event_queue_size must be positive")
+	}
+	if c.HealthCheckJitter < 0 || c.HealthCheckJitter > 1 {
+		return invalid("health_check_jitter must be between 0 and 1")
+	}
+	for _, code := range c.HealthyStatusCodes {
+		if code < 100 || code > 599 {
+			return invalid("healthy_status_codes has invalid HTTP status code %d", code)
+		}
+	}
+	if c.RateLimit < 0 {
+		return invalid("rate_limit must not be negative")
+	}
+	for namespace, quota := range c.NamespaceQuotas {
+		if quota < 0 {
+			return invalid("namespace_quotas has negative quota for namespace %q", namespace)
+		}
+	}
+	switch c.EventProcessingMode {
+	case "", ProcessingSequential, ProcessingParallel:
+	default:
+		return invalid("event_processing_mode must be %s or %s", ProcessingSequential, ProcessingParallel)
+	}
+	switch c.DatabaseWriteMode {
+	case "", WriteAsync, WriteSync:
+	default:
+		return invalid("database_write_mode must be %s or %s", WriteAsync, WriteSync)
+	}
+
+	// The remaining numbers use 0 for their default, only negative values are wrong
+	value := reflect.ValueOf(c).Elem()
+	for i := range value.NumField() {
+		field := value.Field(i)
+		negative := false
+		switch field.Kind() {
+		case reflect.Int, reflect.Int64:
+			negative = field.Int() < 0
+		case reflect.Float64:
+			negative = field.Float() < 0
+		}
+		if negative {
+			return invalid("%s must not be negative", configFieldName(value.Type().Field(i)))
+		}
+	}
+	return nil
+}
+
+// applyEnv overrides the fields whose environment variable is set
+func (c *ManagerConfig) applyEnv() error {
+	value := reflect.ValueOf(c).Elem()
+	for i := range value.NumField() {
+		name := ConfigEnvPrefix + strings.ToUpper(configFieldName(value.Type().Field(i)))
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(value.Field(i), raw); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setEnvValue parses raw into field according to the field's type
+func setEnvValue(field reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	case reflect.Slice:
+		slice := reflect.MakeSlice(field.Type(), 0, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			element := reflect.New(field.Type().Elem()).Elem()
+			if err := setEnvValue(element, item); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, element)
+		}
+		field.Set(slice)
+	case reflect.Map:
+		entries := reflect.MakeMap(field.Type())
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("expected key=value, got %q", item)
+			}
+			element := reflect.New(field.Type().Elem()).Elem()
+			if err := setEnvValue(element, value); err != nil {
+				return err
+			}
+			entries.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)).Convert(field.Type().Key()), element)
+		}
+		field.Set(entries)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// setFileValue stores a value decoded from a config file in field. Durations may be strings
+// in Go syntax; everything else goes through its JSON encoding, so both file formats accept
+// exactly what ManagerConfig accepts as JSON.
+func setFileValue(field reflect.Value, value any) error {
+	if text, ok := value.(string); ok && field.Type() == durationType {
+		duration, err := time.ParseDuration(text)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, field.Addr().Interface())
+}
+
+// configFields maps the JSON names of the ManagerConfig fields to their index
+func configFields() map[string]int {
+	configType := reflect.TypeFor[ManagerConfig]()
+	fields := make(map[string]int, configType.NumField())
+	for i := range configType.NumField() {
+		fields[configFieldName(configType.Field(i))] = i
+	}
+	return fields
+}
+
+// configFieldName returns the JSON name of a ManagerConfig field
+func configFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Setenv("GOVERNANCE_SERVER_PORT", "9090")
+	t.Setenv("GOVERNANCE_HEALTH_CHECK_INTERVAL", "10s")
+	t.Setenv("GOVERNANCE_HEALTH_CHECK_JITTER", "0.25")
+	t.Setenv("GOVERNANCE_HEALTHY_STATUS_CODES", "200, 204")
+	t.Setenv("GOVERNANCE_KAFKA_BROKERS", "kafka-1:9092,kafka-2:9092")
+	t.Setenv("GOVERNANCE_EVENT_PROCESSING_MODE", "parallel")
+	t.Setenv("GOVERNANCE_UNKNOWN_ROUTABLE", "true")
+	t.Setenv("GOVERNANCE_NAMESPACE_QUOTAS", "team-a=10, =5")
+
+	config, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+
+	if config.ServerPort != 9090 || config.HealthCheckInterval != 10*time.Second || config.HealthCheckJitter != 0.25 {
+		t.Errorf("Expected env overrides, got port %d, interval %v, jitter %v", config.ServerPort, config.HealthCheckInterval, config.HealthCheckJitter)
+	}
+	if !slices.Equal(config.HealthyStatusCodes, []int{200, 204}) {
+		t.Errorf("Expected healthy status codes [200 204], got %v", config.HealthyStatusCodes)
+	}
+	if !slices.Equal(config.KafkaBrokers, []string{"kafka-1:9092", "kafka-2:9092"}) {
+		t.Errorf("Expected two Kafka brokers, got %v", config.KafkaBrokers)
+	}
+	if config.EventProcessingMode != ProcessingParallel || !config.UnknownRoutable {
+		t.Errorf("Expected parallel processing and routable unknown pods, got %q and %v", config.EventProcessingMode, config.UnknownRoutable)
+	}
+	if !maps.Equal(config.NamespaceQuotas, map[string]int{"team-a": 10, "": 5}) {
+		t.Errorf("Expected quotas for team-a and the default namespace, got %v", config.NamespaceQuotas)
+	}
+	// Unset variables keep the defaults
+	if config.NotificationInterval != DefaultConfig().NotificationInterval {
+		t.Errorf("Expected default notification interval, got %v", config.NotificationInterval)
+	}
+}
+
+func TestLoadConfigFromEnvInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		contains string
+	}{
+		{"GOVERNANCE_HEALTH_CHECK_INTERVAL", "30", "GOVERNANCE_HEALTH_CHECK_INTERVAL"},
+		{"GOVERNANCE_SERVER_PORT", "http", "GOVERNANCE_SERVER_PORT"},
+		{"GOVERNANCE_SERVER_PORT", "70000", "server_port must be between 0 and 65535"},
+		{"GOVERNANCE_EVENT_QUEUE_SIZE", "0", "event_queue_size must be positive"},
+		{"GOVERNANCE_NOTIFICATION_INTERVAL", "-1s", "notification_interval must be positive"},
+		{"GOVERNANCE_NOTIFICATION_WORKERS", "-5", "notification_workers must not be negative"},
+		{"GOVERNANCE_NAMESPACE_QUOTAS", "team-a", "GOVERNANCE_NAMESPACE_QUOTAS"},
+		{"GOVERNANCE_NAMESPACE_QUOTAS", "team-a=-1", `negative quota for namespace "team-a"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(tc.name, tc.value)
+			_, err := LoadConfigFromEnv()
+			if err == nil || !strings.Contains(err.Error(), tc.contains) {
+				t.Errorf("Expected error containing %q, got %v", tc.contains, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFromFile(t *testing.T) {
+	yamlPath := writeConfigFile(t, "governance.yaml", `
+server_port: 9090
+health_check_interval: 15s
+notification_timeout: 2000000000
+kafka_brokers: [kafka-1:9092]
+auth_token: from-file
+`)
+	jsonPath := writeConfigFile(t, "governance.json", `{"server_port": 9090, "health_check_interval": "15s", "notification_timeout": 2000000000, "kafka_brokers": ["kafka-1:9092"], "auth_token": "from-file"}`)
+
+	for _, path := range []string{yamlPath, jsonPath} {
+		config, err := LoadConfigFromFile(path)
+		if err != nil {
+			t.Fatalf("LoadConfigFromFile(%s) failed: %v", filepath.Base(path), err)
+		}
+		if config.ServerPort != 9090 || config.HealthCheckInterval != 15*time.Second || config.NotificationTimeout != 2*time.Second {
+			t.Errorf("%s: expected file settings, got port %d, interval %v, timeout %v",
+				filepath.Base(path), config.ServerPort, config.HealthCheckInterval, config.NotificationTimeout)
+		}
+		if !slices.Equal(config.KafkaBrokers, []string{"kafka-1:9092"}) || config.AuthToken != "from-file" {
+			t.Errorf("%s: expected Kafka brokers and auth token from the file, got %v and %q", filepath.Base(path), config.KafkaBrokers, config.AuthToken)
+		}
+		if config.EventQueueSize != DefaultConfig().EventQueueSize {
+			t.Errorf("%s: expected default event queue size, got %d", filepath.Base(path), config.EventQueueSize)
+		}
+	}
+
+	// The environment overrides the file
+	t.Setenv("GOVERNANCE_AUTH_TOKEN", "from-env")
+	config, err := LoadConfigFromFile(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadConfigFromFile failed: %v", err)
+	}
+	if config.AuthToken != "from-env" || config.ServerPort != 9090 {
+		t.Errorf("Expected the env auth token over the file's, got %q", config.AuthToken)
+	}
+}
+
+func TestLoadConfigFromFileInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		contains string
+	}{
+		{"unknown setting", "server_prot: 9090", `unknown setting "server_prot"`},
+		{"bad duration", "health_check_interval: soon", "invalid health_check_interval"},
+		{"wrong type", "event_queue_size: [1]", "invalid event_queue_size"},
+		{"out of range", "health_check_jitter: 2", "health_check_jitter must be between 0 and 1"},
+		{"unknown mode", "database_write_mode: eventual", "database_write_mode must be"},
+		{"wrong quota type", "namespace_quotas: {team-a: many}", "invalid namespace_quotas"},
+		{"malformed", "server_port: [", "failed to parse config file"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadConfigFromFile(writeConfigFile(t, "governance.yaml", tc.content))
+			if err == nil || !strings.Contains(err.Error(), tc.contains) {
+				t.Errorf("Expected error containing %q, got %v", tc.contains, err)
+			}
+		})
+	}
+
+	if _, err := LoadConfigFromFile(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a not exist error for a missing file, got %v", err)
+	}
+}
+
+func TestDefaultConfigIsValid(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("Expected DefaultConfig to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// StatusTransition is a change of a pod's health status, kept in a bounded per-pod history
+// so flapping pods can be told apart from ones that changed once
+type StatusTransition struct {
+	Timestamp time.Time     `json:"timestamp"`
+	OldStatus ServiceStatus `json:"old_status"`
+	NewStatus ServiceStatus `json:"new_status"`
+}
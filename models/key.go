@@ -0,0 +1,61 @@
+package models
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// KeyStrategy derives the registry key of a pod from its service and pod name, and parses
+// keys back. Parse must invert Key for every pair of names.
+type KeyStrategy interface {
+	Key(serviceName, podName string) string
+	Parse(key string) (serviceName, podName string, ok bool)
+}
+
+// ColonKeyStrategy is the default key strategy: service_name:pod_name. Colons and percent
+// signs in the service name are percent-escaped so a key always splits at its first colon;
+// pod names may contain colons as they are. Names without either character give the same
+// keys as before escaping existed.
+type ColonKeyStrategy struct{}
+
+var serviceNameEscaper = strings.NewReplacer("%", "%25", ":", "%3A")
+var serviceNameUnescaper = strings.NewReplacer("%25", "%", "%3A", ":")
+
+func (ColonKeyStrategy) Key(serviceName, podName string) string {
+	return serviceNameEscaper.Replace(serviceName) + ":" + podName
+}
+
+func (ColonKeyStrategy) Parse(key string) (string, string, bool) {
+	serviceName, podName, ok := strings.Cut(key, ":")
+	if !ok {
+		return "", "", false
+	}
+	return serviceNameUnescaper.Replace(serviceName), podName, true
+}
+
+var keyStrategy atomic.Pointer[KeyStrategy]
+
+func init() {
+	SetKeyStrategy(ColonKeyStrategy{})
+}
+
+// SetKeyStrategy replaces the strategy used by ServiceKey, ParseServiceKey and GetKey.
+// Set it once before creating a manager: keys already stored (e.g. in the database) are
+// not migrated. A nil strategy restores ColonKeyStrategy.
+func SetKeyStrategy(strategy KeyStrategy) {
+	if strategy == nil {
+		strategy = ColonKeyStrategy{}
+	}
+	keyStrategy.Store(&strategy)
+}
+
+// ServiceKey returns the registry key of a pod
+func ServiceKey(serviceName, podName string) string {
+	return (*keyStrategy.Load()).Key(serviceName, podName)
+}
+
+// ParseServiceKey splits a registry key back into service and pod name.
+// ok is false if key is not a valid key.
+func ParseServiceKey(key string) (serviceName, podName string, ok bool) {
+	return (*keyStrategy.Load()).Parse(key)
+}
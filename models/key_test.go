@@ -0,0 +1,67 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServiceKeyRoundTrip(t *testing.T) {
+	testCases := []struct {
+		serviceName string
+		podName     string
+		expectedKey string
+	}{
+		{"service-a", "pod-1", "service-a:pod-1"},
+		{"service-a", "pod:with:colons", "service-a:pod:with:colons"},
+		{"ns:service-a", "pod-1", "ns%3Aservice-a:pod-1"},
+		{"100%-service", "pod-1", "100%25-service:pod-1"},
+		{"odd%3Aname", "pod-1", "odd%253Aname:pod-1"},
+		{"a:", ":b", "a%3A::b"},
+		{"", "", ":"},
+	}
+
+	for _, tc := range testCases {
+		key := ServiceKey(tc.serviceName, tc.podName)
+		if key != tc.expectedKey {
+			t.Errorf("ServiceKey(%q, %q) = %q, expected %q", tc.serviceName, tc.podName, key, tc.expectedKey)
+		}
+
+		serviceName, podName, ok := ParseServiceKey(key)
+		if !ok || serviceName != tc.serviceName || podName != tc.podName {
+			t.Errorf("ParseServiceKey(%q) = %q, %q, %v, expected %q, %q", key, serviceName, podName, ok, tc.serviceName, tc.podName)
+		}
+	}
+
+	if _, _, ok := ParseServiceKey("no-colon"); ok {
+		t.Error("Expected a key without separator to be rejected")
+	}
+}
+
+// slashKeyStrategy scopes keys with a slash, like a namespace/name reference
+type slashKeyStrategy struct{}
+
+func (slashKeyStrategy) Key(serviceName, podName string) string {
+	return serviceName + "/" + podName
+}
+
+func (slashKeyStrategy) Parse(key string) (string, string, bool) {
+	return strings.Cut(key, "/")
+}
+
+func TestSetKeyStrategy(t *testing.T) {
+	SetKeyStrategy(slashKeyStrategy{})
+	defer SetKeyStrategy(nil)
+
+	service := &ServiceInfo{ServiceName: "user-service", PodName: "pod:1"}
+	if key := service.GetKey(); key != "user-service/pod:1" {
+		t.Errorf("Expected GetKey to use the custom strategy, got %q", key)
+	}
+	if serviceName, podName, ok := ParseServiceKey("user-service/pod:1"); !ok || serviceName != "user-service" || podName != "pod:1" {
+		t.Errorf("Expected ParseServiceKey to use the custom strategy, got %q, %q", serviceName, podName)
+	}
+
+	SetKeyStrategy(nil)
+	if key := service.GetKey(); key != "user-service:pod:1" {
+		t.Errorf("Expected nil to restore the default strategy, got %q", key)
+	}
+}
@@ -45,9 +45,10 @@ func TestEventTypeConstants(t *testing.T) {
 		EventTypeUnregister,
 		EventTypeUpdate,
 		EventTypeReconcile,
+		EventTypeEvict,
 	}
 
-	expectedValues := []string{"register", "unregister", "update", "reconcile"}
+	expectedValues := []string{"register", "unregister", "update", "reconcile", "evict"}
 
 	for i, eventType := range eventTypes {
 		if string(eventType) != expectedValues[i] {
@@ -211,3 +212,38 @@ func TestPodInfoStruct(t *testing.T) {
 		t.Error("Providers length mismatch")
 	}
 }
+
+func TestMatchSubscription(t *testing.T) {
+	tests := []struct {
+		subscription string
+		serviceGroup string
+		want         bool
+	}{
+		{"edge-gateway", "edge-gateway", true},
+		{"edge-gateway", "edge-gateway-2", false},
+		{"edge-*", "edge-gateway", true},
+		{"edge-*", "edge-", true},
+		{"edge-*", "edge", false},
+		{"edge-*", "core-edge-gateway", false},
+		{"*-gateway", "edge-gateway", true},
+		{"*-gateway", "edge-gateway-2", false},
+		{"edge-*-eu", "edge-gateway-eu", true},
+		{"edge-*-eu", "edge-eu", false},
+		{"edge-*-*-eu", "edge-a-b-eu", true},
+		{"edge-*-*-eu", "edge-a-eu", false},
+		{"*", "anything", true},
+		{"team-a/edge-*", "team-a/edge-gateway", true},
+		{"team-a/*", "team-b/edge-gateway", false},
+		{"*", "team-a/edge-gateway", false}, // Patterns stay within their namespace
+	}
+
+	for _, tt := range tests {
+		if got := MatchSubscription(tt.subscription, tt.serviceGroup); got != tt.want {
+			t.Errorf("MatchSubscription(%q, %q) = %v, want %v", tt.subscription, tt.serviceGroup, got, tt.want)
+		}
+	}
+
+	if IsSubscriptionPattern("edge-gateway") || !IsSubscriptionPattern("edge-*") {
+		t.Error("Expected only subscriptions with a wildcard to be patterns")
+	}
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NamespaceSeparator separates the namespace from the service name in qualified service
+// names. Namespaces, service names and subscriptions may not contain it.
+const NamespaceSeparator = "/"
+
+// maxNamespaceLength caps namespaces like Kubernetes namespace names
+const maxNamespaceLength = 63
+
+// QualifiedServiceName returns the name of a service group across namespaces: the service
+// name itself in the default namespace (""), namespace/service_name otherwise. Registry keys,
+// subscriptions and the change feed use qualified names, so equally named services of
+// different namespaces are separate groups, and the default namespace works as before
+// namespaces existed.
+func QualifiedServiceName(namespace, serviceName string) string {
+	if namespace == "" {
+		return serviceName
+	}
+	return namespace + NamespaceSeparator + serviceName
+}
+
+// SplitQualifiedServiceName splits a qualified service name into namespace and service name
+func SplitQualifiedServiceName(name string) (namespace, serviceName string) {
+	if namespace, serviceName, ok := strings.Cut(name, NamespaceSeparator); ok {
+		return namespace, serviceName
+	}
+	return "", name
+}
+
+// NamespaceOfKey returns the namespace of the pod with the given registry key
+func NamespaceOfKey(key string) string {
+	name, _, _ := ParseServiceKey(key)
+	namespace, _ := SplitQualifiedServiceName(name)
+	return namespace
+}
+
+// QualifiedSubscriptions resolves the subscriptions of a pod in namespace to the qualified
+// service groups (or patterns) they cover: a pod only subscribes within its own namespace
+func QualifiedSubscriptions(namespace string, subscriptions []string) []string {
+	if namespace == "" || len(subscriptions) == 0 {
+		return subscriptions
+	}
+	result := make([]string, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		result = append(result, QualifiedServiceName(namespace, subscription))
+	}
+	return result
+}
+
+// QualifiedName returns the qualified service name of the registration, see QualifiedServiceName
+func (r *ServiceRegistration) QualifiedName() string {
+	return QualifiedServiceName(r.Namespace, r.ServiceName)
+}
+
+// QualifiedName returns the qualified service name of the pod, see QualifiedServiceName
+func (s *ServiceInfo) QualifiedName() string {
+	return QualifiedServiceName(s.Namespace, s.ServiceName)
+}
+
+// SubscriptionGroups returns the qualified service groups and patterns the pod subscribes to
+func (s *ServiceInfo) SubscriptionGroups() []string {
+	return QualifiedSubscriptions(s.Namespace, s.Subscriptions)
+}
+
+// NamespaceUsage reports how many live pods a namespace has against its quota
+type NamespaceUsage struct {
+	Namespace string `json:"namespace"` // Empty for the default namespace
+	Pods      int    `json:"pods"`
+	Quota     int    `json:"quota"` // 0 = unlimited
+}
+
+// validateNamespace checks that a namespace is a lowercase DNS label, like a Kubernetes
+// namespace name, or empty for the default namespace
+func validateNamespace(namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+	if len(namespace) > maxNamespaceLength {
+		return fmt.Errorf("namespace must be at most %d characters", maxNamespaceLength)
+	}
+	for i := 0; i < len(namespace); i++ {
+		c := namespace[i]
+		alphanumeric := c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+		if !alphanumeric && (c != '-' || i == 0 || i == len(namespace)-1) {
+			return fmt.Errorf("namespace %q must consist of lowercase letters, digits and inner dashes", namespace)
+		}
+	}
+	return nil
+}
@@ -10,19 +10,84 @@ const (
 	EventTypeUnregister EventType = "unregister"
 	EventTypeUpdate     EventType = "update"
 	EventTypeReconcile  EventType = "reconcile"
+	EventTypeEvict      EventType = "evict" // The manager removed a pod, see NotificationPayload.Reason
+)
+
+// EventTypes lists every notification event type
+var EventTypes = []EventType{EventTypeRegister, EventTypeUnregister, EventTypeUpdate, EventTypeReconcile, EventTypeEvict}
+
+// Reasons the manager evicts a pod, reported in NotificationPayload.Reason
+const (
+	EvictionReasonTTLExpired = "ttl_expired" // No heartbeat within the registration TTL
+)
+
+// NotificationFormat selects the payload a subscriber receives when a subscribed service changes
+type NotificationFormat string
+
+const (
+	// NotificationFormatFull sends every pod of the service (default)
+	NotificationFormatFull NotificationFormat = "full"
+	// NotificationFormatDelta sends only the pods that changed. Reconcile notifications
+	// always carry every pod, so delta subscribers can resync from them.
+	NotificationFormatDelta NotificationFormat = "delta"
 )
 
 // PodInfo represents information about a pod in the notification
 type PodInfo struct {
-	PodName   string         `json:"pod_name"`
-	Status    ServiceStatus  `json:"status"`
-	Providers []ProviderInfo `json:"providers"`
+	PodName         string            `json:"pod_name"`
+	Status          ServiceStatus     `json:"status"`
+	Providers       []ProviderInfo    `json:"providers"`
+	HealthCheckURL  string            `json:"health_check_url,omitempty"`
+	NotificationURL string            `json:"notification_url,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Revision        int64             `json:"revision,omitempty"` // Registry revision of the last write of the pod
 }
 
 // NotificationPayload is sent to subscribers when service changes occur
 type NotificationPayload struct {
-	ServiceName string      `json:"service_name"`
-	EventType   EventType   `json:"event_type"`
-	Timestamp   time.Time   `json:"timestamp"`
-	Pods        []PodInfo   `json:"pods"`
+	EventID     uint64             `json:"event_id,omitempty"`   // ID of the event that triggered the notification
+	RequestID   string             `json:"request_id,omitempty"` // ID of the API request behind the event, also sent as the X-Request-ID header
+	Namespace   string             `json:"namespace,omitempty"`  // Namespace of the service, empty in the default namespace
+	ServiceName string             `json:"service_name"`
+	EventType   EventType          `json:"event_type"`
+	Timestamp   time.Time          `json:"timestamp"`
+	Pods        []PodInfo          `json:"pods"`               // Every pod of the service, or only the changed ones in the delta format
+	Format      NotificationFormat `json:"format,omitempty"`   // Set to delta when Pods only holds the changed pods
+	PodName     string             `json:"pod_name,omitempty"` // Evicted pod, set for evict events
+	Reason      string             `json:"reason,omitempty"`   // Why the pod was evicted, set for evict events
+	Revision    int64              `json:"revision,omitempty"` // Registry revision when the notification was built
+}
+
+// NotificationRecord describes a single notification delivery attempt to a subscriber.
+// Records are kept in a bounded per-subscriber history for debugging missed notifications.
+type NotificationRecord struct {
+	EventID         uint64    `json:"event_id"`
+	Timestamp       time.Time `json:"timestamp"`
+	ServiceName     string    `json:"service_name"`
+	EventType       EventType `json:"event_type"`
+	NotificationURL string    `json:"notification_url"`
+	Success         bool      `json:"success"`
+	StatusCode      int       `json:"status_code,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	Attempts        int       `json:"attempts,omitempty"` // Delivery attempts made, including retries
+}
+
+// DeliveryResult is the final outcome of one notification delivery, after all retries.
+// It is passed to delivery hooks so callers can alert on failing subscribers.
+type DeliveryResult struct {
+	SubscriberKey string `json:"subscriber_key,omitempty"` // Empty for notifications sent directly to a URL
+	NotificationRecord
+}
+
+// DeadLetter is a notification that could not be delivered, kept so operators can inspect
+// and replay it
+type DeadLetter struct {
+	ID              uint64               `json:"id"`
+	Timestamp       time.Time            `json:"timestamp"`
+	SubscriberKey   string               `json:"subscriber_key,omitempty"` // Empty for notifications sent directly to a URL
+	NotificationURL string               `json:"notification_url"`
+	Payload         *NotificationPayload `json:"payload"`
+	StatusCode      int                  `json:"status_code,omitempty"`
+	Error           string               `json:"error"`
+	Attempts        int                  `json:"attempts,omitempty"`
 }
@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"bytes"
+	"maps"
+	"slices"
+	"time"
+)
 
 // Protocol represents the communication protocol type
 type Protocol string
@@ -11,8 +16,18 @@ const (
 	ProtocolPFCP Protocol = "pfcp"
 	ProtocolGTP  Protocol = "gtp"
 	ProtocolUDP  Protocol = "udp"
+	ProtocolGRPC Protocol = "grpc"
 )
 
+// IsValid reports whether p is one of the known protocols
+func (p Protocol) IsValid() bool {
+	switch p {
+	case ProtocolHTTP, ProtocolTCP, ProtocolPFCP, ProtocolGTP, ProtocolUDP, ProtocolGRPC:
+		return true
+	}
+	return false
+}
+
 // ProviderInfo contains the endpoint information for a service provider
 type ProviderInfo struct {
 	Protocol Protocol `json:"protocol"`
@@ -22,12 +37,69 @@ type ProviderInfo struct {
 
 // ServiceRegistration represents a service registration request
 type ServiceRegistration struct {
-	ServiceName      string         `json:"service_name"`
-	PodName          string         `json:"pod_name"`
-	Providers        []ProviderInfo `json:"providers"`
-	HealthCheckURL   string         `json:"health_check_url"`
-	NotificationURL  string         `json:"notification_url"`
-	Subscriptions    []string       `json:"subscriptions"` // List of service groups to subscribe
+	Namespace          string               `json:"namespace,omitempty"` // Scope of the service name and subscriptions (empty = default namespace)
+	ServiceName        string               `json:"service_name"`
+	PodName            string               `json:"pod_name"`
+	Providers          []ProviderInfo       `json:"providers"`
+	HealthCheckURL     string               `json:"health_check_url"`
+	HealthCheckMethod  string               `json:"health_check_method,omitempty"`  // GET (default), HEAD or POST
+	HealthCheckHeaders map[string]string    `json:"health_check_headers,omitempty"` // Extra request headers, e.g. Authorization
+	HealthCheckMatch   *HealthCheckMatch    `json:"health_check_match,omitempty"`   // Response body required for healthy (nil = status code only)
+	HealthChecks       []HealthCheck        `json:"health_checks,omitempty"`        // Further HTTP health checks, combined with health_check_url per health_check_mode
+	HealthCheckMode    HealthCheckMode      `json:"health_check_mode,omitempty"`    // all (default) or any
+	UDPProbe           *UDPProbe            `json:"udp_probe,omitempty"`            // How PFCP/GTP/UDP providers are probed (nil = send an empty datagram)
+	NotificationURL    string               `json:"notification_url"`
+	NotificationURLs   map[EventType]string `json:"notification_urls,omitempty"`   // Per event type overrides of notification_url, e.g. update notifications sent elsewhere
+	Subscriptions      []string             `json:"subscriptions"`                 // List of service groups to subscribe
+	NotificationFormat NotificationFormat   `json:"notification_format,omitempty"` // Payload sent for changes of subscribed services: full (default) or delta
+	Labels             map[string]string    `json:"labels,omitempty"`              // Arbitrary metadata, e.g. version=canary, region=eu
+	TTLSeconds         int                  `json:"ttl_seconds,omitempty"`         // Unregister the pod unless it sends a heartbeat within this many seconds (0 = never expires)
+}
+
+// HealthCheck is one HTTP health check of a pod, see ServiceRegistration.HealthChecks
+type HealthCheck struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"`  // GET (default), HEAD or POST
+	Headers map[string]string `json:"headers,omitempty"` // Extra request headers, e.g. Authorization
+	Match   *HealthCheckMatch `json:"match,omitempty"`   // Response body required for healthy (nil = status code only)
+}
+
+// HealthCheckMode controls how the results of a pod's HTTP health checks are combined
+type HealthCheckMode string
+
+const (
+	HealthCheckAll HealthCheckMode = "all" // Healthy only if every check passes (default)
+	HealthCheckAny HealthCheckMode = "any" // Healthy if at least one check passes
+)
+
+// HealthCheckMatch describes the body of a healthy HTTP health check response.
+// Every condition that is set must hold in addition to a healthy status code.
+type HealthCheckMatch struct {
+	BodyContains string `json:"body_contains,omitempty"` // Substring the body must contain
+	JSONPath     string `json:"json_path,omitempty"`     // Dot-separated path into a JSON body, e.g. "status" or "checks.0.state"
+	JSONValue    string `json:"json_value,omitempty"`    // Value expected at JSONPath; numbers and booleans as in JSON, e.g. "true"
+}
+
+// UDPProbe configures the liveness probe of services checked over UDP (PFCP, GTP, UDP).
+// The payload is sent to the first such provider. Without ExpectReply the pod is healthy
+// unless sending fails or the host reports the port unreachable; with ExpectReply any
+// reply within the health check timeout is required.
+type UDPProbe struct {
+	Payload     []byte `json:"payload,omitempty"` // Heartbeat datagram, base64 in JSON (empty = zero-length datagram)
+	ExpectReply bool   `json:"expect_reply,omitempty"`
+}
+
+// UDPProtocols are the provider protocols health checked with a UDP probe
+var UDPProtocols = []Protocol{ProtocolPFCP, ProtocolGTP, ProtocolUDP}
+
+// HasProvider reports whether the registration includes a provider with the given protocol
+func (r *ServiceRegistration) HasProvider(protocol Protocol) bool {
+	for _, provider := range r.Providers {
+		if provider.Protocol == protocol {
+			return true
+		}
+	}
+	return false
 }
 
 // ServiceStatus represents the health status of a service
@@ -37,22 +109,208 @@ const (
 	StatusHealthy   ServiceStatus = "healthy"
 	StatusUnhealthy ServiceStatus = "unhealthy"
 	StatusUnknown   ServiceStatus = "unknown"
+	StatusTombstone ServiceStatus = "tombstone" // Unregistered, kept until the soft-delete grace period ends
 )
 
+// IsValid reports whether s is one of the known statuses
+func (s ServiceStatus) IsValid() bool {
+	switch s {
+	case StatusHealthy, StatusUnhealthy, StatusUnknown, StatusTombstone:
+		return true
+	}
+	return false
+}
+
 // ServiceInfo represents the internal service information stored in registry
 type ServiceInfo struct {
-	ServiceName     string
-	PodName         string
-	Providers       []ProviderInfo
-	HealthCheckURL  string
-	NotificationURL string
-	Subscriptions   []string
-	Status          ServiceStatus
-	LastHealthCheck time.Time
-	RegisteredAt    time.Time
-}
-
-// GetKey returns a unique key for the service (service_name:pod_name)
+	Namespace          string `json:",omitempty"` // Empty in the default namespace
+	ServiceName        string
+	PodName            string
+	Providers          []ProviderInfo
+	HealthCheckURL     string
+	HealthCheckMethod  string
+	HealthCheckHeaders map[string]string
+	HealthCheckMatch   *HealthCheckMatch
+	HealthChecks       []HealthCheck
+	HealthCheckMode    HealthCheckMode
+	UDPProbe           *UDPProbe
+	NotificationURL    string
+	NotificationURLs   map[EventType]string
+	Subscriptions      []string
+	NotificationFormat NotificationFormat
+	Labels             map[string]string
+	Status             ServiceStatus
+	LastHealthCheck    time.Time `json:",omitzero"` // Zero until the pod's first health check
+	RegisteredAt       time.Time `json:",omitzero"`
+	DeletedAt          time.Time `json:",omitzero"`  // When the pod unregistered, zero unless it is a tombstone
+	TTLSeconds         int       `json:",omitempty"` // Registration TTL, 0 if the pod never expires
+	ExpiresAt          time.Time `json:",omitzero"`  // When the pod is unregistered unless it sends a heartbeat, zero without a TTL
+	Revision           int64     `json:",omitempty"` // Registry revision of the last write of the pod, see Registry.Revision
+}
+
+// GetKey returns a unique key for the service (service_name:pod_name by default, with the
+// qualified service name outside the default namespace, see KeyStrategy)
 func (s *ServiceInfo) GetKey() string {
-	return s.ServiceName + ":" + s.PodName
+	return ServiceKey(s.QualifiedName(), s.PodName)
+}
+
+// IsTombstone reports whether the service was unregistered and is only kept for the soft-delete grace period
+func (s *ServiceInfo) IsTombstone() bool {
+	return !s.DeletedAt.IsZero()
+}
+
+// HTTPHealthChecks returns every HTTP health check of the service: the one described by
+// HealthCheckURL, if set, followed by HealthChecks
+func (s *ServiceInfo) HTTPHealthChecks() []HealthCheck {
+	checks := make([]HealthCheck, 0, len(s.HealthChecks)+1)
+	if s.HealthCheckURL != "" {
+		checks = append(checks, HealthCheck{
+			URL:     s.HealthCheckURL,
+			Method:  s.HealthCheckMethod,
+			Headers: s.HealthCheckHeaders,
+			Match:   s.HealthCheckMatch,
+		})
+	}
+	return append(checks, s.HealthChecks...)
+}
+
+// NotificationURLFor returns the URL notifications of eventType are sent to: its override in
+// NotificationURLs, or NotificationURL when there is none
+func (s *ServiceInfo) NotificationURLFor(eventType EventType) string {
+	if url, ok := s.NotificationURLs[eventType]; ok && url != "" {
+		return url
+	}
+	return s.NotificationURL
+}
+
+// MatchesLabels reports whether the service has every given label with the same value
+func (s *ServiceInfo) MatchesLabels(selector map[string]string) bool {
+	for key, value := range selector {
+		if actual, ok := s.Labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Registration returns the registration data of the service, e.g. to validate it
+func (s *ServiceInfo) Registration() *ServiceRegistration {
+	return &ServiceRegistration{
+		Namespace:          s.Namespace,
+		ServiceName:        s.ServiceName,
+		PodName:            s.PodName,
+		Providers:          s.Providers,
+		HealthCheckURL:     s.HealthCheckURL,
+		HealthCheckMethod:  s.HealthCheckMethod,
+		HealthCheckHeaders: s.HealthCheckHeaders,
+		HealthCheckMatch:   s.HealthCheckMatch,
+		HealthChecks:       s.HealthChecks,
+		HealthCheckMode:    s.HealthCheckMode,
+		UDPProbe:           s.UDPProbe,
+		NotificationURL:    s.NotificationURL,
+		NotificationURLs:   s.NotificationURLs,
+		Subscriptions:      s.Subscriptions,
+		NotificationFormat: s.NotificationFormat,
+		Labels:             s.Labels,
+		TTLSeconds:         s.TTLSeconds,
+	}
+}
+
+// Equal reports whether two registrations describe a pod identically. Nil and empty lists
+// and maps count as equal.
+func (r *ServiceRegistration) Equal(other *ServiceRegistration) bool {
+	return r.Namespace == other.Namespace &&
+		r.ServiceName == other.ServiceName &&
+		r.PodName == other.PodName &&
+		slices.Equal(r.Providers, other.Providers) &&
+		r.HealthCheckURL == other.HealthCheckURL &&
+		r.HealthCheckMethod == other.HealthCheckMethod &&
+		maps.Equal(r.HealthCheckHeaders, other.HealthCheckHeaders) &&
+		equalMatch(r.HealthCheckMatch, other.HealthCheckMatch) &&
+		slices.EqualFunc(r.HealthChecks, other.HealthChecks, equalHealthCheck) &&
+		r.HealthCheckMode == other.HealthCheckMode &&
+		equalUDPProbe(r.UDPProbe, other.UDPProbe) &&
+		r.NotificationURL == other.NotificationURL &&
+		maps.Equal(r.NotificationURLs, other.NotificationURLs) &&
+		slices.Equal(r.Subscriptions, other.Subscriptions) &&
+		r.NotificationFormat == other.NotificationFormat &&
+		maps.Equal(r.Labels, other.Labels) &&
+		r.TTLSeconds == other.TTLSeconds
+}
+
+func equalHealthCheck(a, b HealthCheck) bool {
+	return a.URL == b.URL && a.Method == b.Method && maps.Equal(a.Headers, b.Headers) && equalMatch(a.Match, b.Match)
+}
+
+func equalMatch(a, b *HealthCheckMatch) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalUDPProbe(a, b *UDPProbe) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return bytes.Equal(a.Payload, b.Payload) && a.ExpectReply == b.ExpectReply
+}
+
+// ServiceUpdate is a partial update of the mutable fields of a registered pod.
+// Fields left nil are unchanged; an empty health_check_url removes the URL and an
+// empty labels object removes all labels.
+type ServiceUpdate struct {
+	Providers      []ProviderInfo    `json:"providers,omitempty"`
+	HealthCheckURL *string           `json:"health_check_url,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+}
+
+// Apply sets the fields present in the update on the service
+func (u *ServiceUpdate) Apply(s *ServiceInfo) {
+	if u.Providers != nil {
+		s.Providers = u.Providers
+	}
+	if u.HealthCheckURL != nil {
+		s.HealthCheckURL = *u.HealthCheckURL
+	}
+	if u.Labels != nil {
+		s.Labels = u.Labels
+	}
+}
+
+// HeartbeatRequest renews the TTL of a registered pod
+type HeartbeatRequest struct {
+	Namespace   string `json:"namespace,omitempty"`
+	ServiceName string `json:"service_name"`
+	PodName     string `json:"pod_name"`
+}
+
+// IsExpired reports whether the pod has a TTL and missed its heartbeat
+func (s *ServiceInfo) IsExpired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && now.After(s.ExpiresAt)
+}
+
+// UDPProvider returns the first PFCP, GTP or UDP provider of the service, if any
+func (s *ServiceInfo) UDPProvider() (ProviderInfo, bool) {
+	for _, provider := range s.Providers {
+		if slices.Contains(UDPProtocols, provider.Protocol) {
+			return provider, true
+		}
+	}
+	return ProviderInfo{}, false
+}
+
+// TCPProvider returns the first TCP provider of the service, if any
+func (s *ServiceInfo) TCPProvider() (ProviderInfo, bool) {
+	return s.Provider(ProtocolTCP)
+}
+
+// Provider returns the first provider of the service with the given protocol, if any
+func (s *ServiceInfo) Provider(protocol Protocol) (ProviderInfo, bool) {
+	for _, provider := range s.Providers {
+		if provider.Protocol == protocol {
+			return provider, true
+		}
+	}
+	return ProviderInfo{}, false
 }
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SnapshotVersion is the snapshot format written by ExportSnapshot. Snapshots of any
+// other version are rejected on import.
+const SnapshotVersion = 1
+
+// Snapshot is the full registry state, used to back up a manager or seed another one.
+// Subscriptions are not stored separately, they are rebuilt from each service.
+type Snapshot struct {
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Services   []*ServiceInfo `json:"services"`
+}
+
+// ParseSnapshot decodes a snapshot and validates all of it, so that an invalid snapshot
+// can be rejected before anything is applied
+func ParseSnapshot(data []byte) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, &ValidationError{Message: "invalid snapshot: " + err.Error()}
+	}
+	if err := snapshot.Validate(); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Validate checks that the snapshot has a supported version and that every service in it
+// would pass registration validation. Returns a *ValidationError describing the first problem found.
+func (s *Snapshot) Validate() error {
+	if s.Version != SnapshotVersion {
+		return &ValidationError{Message: fmt.Sprintf("unsupported snapshot version %d, expected %d", s.Version, SnapshotVersion)}
+	}
+
+	seen := make(map[string]bool, len(s.Services))
+	for i, service := range s.Services {
+		if service == nil {
+			return &ValidationError{Message: fmt.Sprintf("services[%d] is null", i)}
+		}
+		if err := service.Registration().Validate(); err != nil {
+			return &ValidationError{Message: fmt.Sprintf("services[%d]: %s", i, err.Error())}
+		}
+		if service.Status != "" && !service.Status.IsValid() {
+			return &ValidationError{Message: fmt.Sprintf("services[%d]: unknown status %q", i, service.Status)}
+		}
+
+		key := service.GetKey()
+		if seen[key] {
+			return &ValidationError{Message: fmt.Sprintf("services[%d]: duplicate service %s", i, key)}
+		}
+		seen[key] = true
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// QueueStats describes the event queue load, for capacity planning of EventQueueSize
+type QueueStats struct {
+	Size            int     `json:"queue_size"`        // Events waiting to be processed
+	Capacity        int     `json:"queue_capacity"`    // Events the queue can buffer
+	Enqueued        uint64  `json:"enqueued"`          // Events accepted since start
+	Rejected        uint64  `json:"rejected"`          // Events rejected because the queue was full
+	Processed       uint64  `json:"processed"`         // Events handled since start
+	EventsPerSecond float64 `json:"events_per_second"` // Processing rate over the last minute
+	LagMillis       int64   `json:"lag_ms"`            // How long the last processed event waited in the queue
+}
+
+// RepairStats describes asynchronous database writes that failed and are retried until the
+// database matches the in-memory registry again
+type RepairStats struct {
+	Pending      int    `json:"pending"`       // Services whose database record may be out of date
+	Repaired     uint64 `json:"repaired"`      // Pending repairs completed by a retry since start
+	FailedWrites uint64 `json:"failed_writes"` // Asynchronous writes and retries that failed since start
+}
+
+// ConnectionState describes whether the database is reachable
+type ConnectionState string
+
+const (
+	// ConnectionConnected means the last ping succeeded
+	ConnectionConnected ConnectionState = "connected"
+	// ConnectionDisconnected means the database did not answer the last ping
+	ConnectionDisconnected ConnectionState = "disconnected"
+	// ConnectionReconnecting means a new connection pool is being opened
+	ConnectionReconnecting ConnectionState = "reconnecting"
+)
+
+// DatabaseConnectionStats describes the database connection as seen by periodic pings
+type DatabaseConnectionStats struct {
+	State               ConnectionState `json:"state"`
+	ConsecutiveFailures int             `json:"consecutive_failures"` // Pings failed since the last successful one
+	Reconnects          uint64          `json:"reconnects"`           // Connection pools replaced since start
+	FailedReconnects    uint64          `json:"failed_reconnects"`    // Attempts to replace the connection pool that failed since start
+	LastError           string          `json:"last_error,omitempty"` // Error of the last failed ping or reconnect
+	Since               time.Time       `json:"since"`                // When State last changed
+}
+
+// StoreDiff lists where the in-memory cache and the database disagree. Keys are sorted.
+// Asynchronous writes still in flight show up as differences until they complete.
+type StoreDiff struct {
+	OnlyInCache          []string         `json:"only_in_cache"`         // Services cached but missing from the database
+	OnlyInDatabase       []string         `json:"only_in_database"`      // Services in the database but not cached
+	StatusMismatch       []StatusMismatch `json:"status_mismatch"`       // Services whose health status differs
+	SubscriptionMismatch []string         `json:"subscription_mismatch"` // Subscribers whose service groups differ
+}
+
+// StatusMismatch is a service whose health status differs between cache and database
+type StatusMismatch struct {
+	Key            string        `json:"key"`
+	CacheStatus    ServiceStatus `json:"cache_status"`
+	DatabaseStatus ServiceStatus `json:"database_status"`
+}
+
+// InSync reports whether cache and database agree
+func (d *StoreDiff) InSync() bool {
+	return len(d.OnlyInCache) == 0 && len(d.OnlyInDatabase) == 0 &&
+		len(d.StatusMismatch) == 0 && len(d.SubscriptionMismatch) == 0
+}
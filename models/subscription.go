@@ -0,0 +1,46 @@
+package models
+
+import "strings"
+
+// SubscriptionWildcard in a subscription matches any run of characters (including none) in
+// a service group name, e.g. "edge-*" matches "edge-gateway" and "edge-" but not "edge".
+// All other characters match literally, and a subscription without wildcards only matches
+// the service group of the same name. Wildcards don't match across namespaces.
+const SubscriptionWildcard = "*"
+
+// IsSubscriptionPattern reports whether a subscription contains a wildcard
+func IsSubscriptionPattern(subscription string) bool {
+	return strings.Contains(subscription, SubscriptionWildcard)
+}
+
+// MatchSubscription reports whether a subscription (a service group name or a pattern)
+// covers serviceGroup. Both are qualified service names (see QualifiedServiceName).
+func MatchSubscription(subscription, serviceGroup string) bool {
+	// Only groups of the pattern's own namespace match, e.g. "*" not "team-a/gateway"
+	if strings.Count(subscription, NamespaceSeparator) != strings.Count(serviceGroup, NamespaceSeparator) {
+		return false
+	}
+
+	parts := strings.Split(subscription, SubscriptionWildcard)
+	if len(parts) == 1 {
+		return subscription == serviceGroup
+	}
+
+	// The first part anchors the start and the last part the end; the parts in between
+	// must appear in order
+	first, last := parts[0], parts[len(parts)-1]
+	if len(serviceGroup) < len(first)+len(last) ||
+		!strings.HasPrefix(serviceGroup, first) || !strings.HasSuffix(serviceGroup, last) {
+		return false
+	}
+
+	rest := serviceGroup[len(first) : len(serviceGroup)-len(last)]
+	for _, part := range parts[1 : len(parts)-1] {
+		index := strings.Index(rest, part)
+		if index < 0 {
+			return false
+		}
+		rest = rest[index+len(part):]
+	}
+	return true
+}
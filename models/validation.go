@@ -0,0 +1,176 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ValidationError describes why a registration was rejected
+type ValidationError struct {
+	Message string
+	Index   *int // Provider index, when the error concerns a single provider
+}
+
+func (e *ValidationError) Error() string {
+	if e.Index != nil {
+		return e.Message + " (provider index: " + strconv.Itoa(*e.Index) + ")"
+	}
+	return e.Message
+}
+
+// Validate checks that the registration is complete and well-formed.
+// Returns a *ValidationError describing the first problem found.
+func (r *ServiceRegistration) Validate() error {
+	if err := validateNamespace(r.Namespace); err != nil {
+		return &ValidationError{Message: err.Error()}
+	}
+	if r.ServiceName == "" {
+		return &ValidationError{Message: "service_name is required"}
+	}
+	if strings.Contains(r.ServiceName, NamespaceSeparator) {
+		return &ValidationError{Message: fmt.Sprintf("service_name must not contain %q", NamespaceSeparator)}
+	}
+	if r.PodName == "" {
+		return &ValidationError{Message: "pod_name is required"}
+	}
+	if len(r.Providers) == 0 {
+		return &ValidationError{Message: "at least one provider is required"}
+	}
+	if r.HealthCheckURL == "" && len(r.HealthChecks) == 0 && !r.HasProvider(ProtocolTCP) && !r.HasProvider(ProtocolGRPC) && !r.hasUDPProvider() {
+		return &ValidationError{Message: "health_check_url is required unless health_checks or a tcp, grpc, pfcp, gtp or udp provider is registered"}
+	}
+	if r.NotificationURL == "" {
+		return &ValidationError{Message: "notification_url is required"}
+	}
+
+	// Validate providers
+	for i, provider := range r.Providers {
+		if provider.Protocol == "" {
+			return &ValidationError{Message: "provider protocol is required", Index: &i}
+		}
+		if provider.IP == "" {
+			return &ValidationError{Message: "provider IP is required", Index: &i}
+		}
+		if net.ParseIP(provider.IP) == nil {
+			return &ValidationError{Message: fmt.Sprintf("provider IP %q is not a valid IP address", provider.IP), Index: &i}
+		}
+		if provider.Port <= 0 || provider.Port > 65535 {
+			return &ValidationError{Message: "provider port must be between 1 and 65535", Index: &i}
+		}
+	}
+
+	// Validate URLs
+	if r.HealthCheckURL != "" {
+		if err := validateURL(r.HealthCheckURL, "http", "https"); err != nil {
+			return &ValidationError{Message: "health_check_url " + err.Error()}
+		}
+	}
+	if err := validateURL(r.NotificationURL, "http", "https", "nats"); err != nil {
+		return &ValidationError{Message: "notification_url " + err.Error()}
+	}
+	for eventType, notificationURL := range r.NotificationURLs {
+		if !slices.Contains(EventTypes, eventType) {
+			return &ValidationError{Message: fmt.Sprintf("notification_urls has unknown event type %q", eventType)}
+		}
+		if err := validateURL(notificationURL, "http", "https", "nats"); err != nil {
+			return &ValidationError{Message: fmt.Sprintf("notification_urls[%s] %s", eventType, err.Error())}
+		}
+	}
+
+	// Validate health check request options
+	if r.HealthCheckMatch != nil && r.HealthCheckURL == "" {
+		return &ValidationError{Message: "health_check_match requires a health_check_url"}
+	}
+	if msg := validateHealthCheckOptions(r.HealthCheckMethod, r.HealthCheckHeaders, r.HealthCheckMatch); msg != "" {
+		return &ValidationError{Message: "health_check_" + msg}
+	}
+	for i, check := range r.HealthChecks {
+		if check.URL == "" {
+			return &ValidationError{Message: fmt.Sprintf("health_checks[%d].url is required", i)}
+		}
+		if err := validateURL(check.URL, "http", "https"); err != nil {
+			return &ValidationError{Message: fmt.Sprintf("health_checks[%d].url %s", i, err.Error())}
+		}
+		if msg := validateHealthCheckOptions(check.Method, check.Headers, check.Match); msg != "" {
+			return &ValidationError{Message: fmt.Sprintf("health_checks[%d].%s", i, msg)}
+		}
+	}
+	switch r.HealthCheckMode {
+	case "", HealthCheckAll, HealthCheckAny:
+	default:
+		return &ValidationError{Message: fmt.Sprintf("health_check_mode must be %q or %q, got %q",
+			HealthCheckAll, HealthCheckAny, r.HealthCheckMode)}
+	}
+
+	switch r.NotificationFormat {
+	case "", NotificationFormatFull, NotificationFormatDelta:
+	default:
+		return &ValidationError{Message: fmt.Sprintf("notification_format must be %q or %q, got %q",
+			NotificationFormatFull, NotificationFormatDelta, r.NotificationFormat)}
+	}
+
+	if r.TTLSeconds < 0 {
+		return &ValidationError{Message: "ttl_seconds must not be negative"}
+	}
+
+	// Subscriptions are resolved within the pod's namespace
+	for i, subscription := range r.Subscriptions {
+		if strings.Contains(subscription, NamespaceSeparator) {
+			return &ValidationError{Message: fmt.Sprintf("subscriptions[%d] must not contain %q", i, NamespaceSeparator)}
+		}
+	}
+
+	return nil
+}
+
+// HealthCheckMethods are the HTTP methods a service may use for its health check
+var HealthCheckMethods = []string{"GET", "HEAD", "POST"}
+
+// validateHealthCheckOptions checks the method, headers and body match of an HTTP health check.
+// Returns an empty string if they are valid, otherwise the problem prefixed with the field name
+// (method, headers or match).
+func validateHealthCheckOptions(method string, headers map[string]string, match *HealthCheckMatch) string {
+	if method != "" && !slices.Contains(HealthCheckMethods, method) {
+		return fmt.Sprintf("method must be one of %s, got %q", strings.Join(HealthCheckMethods, ", "), method)
+	}
+	for name := range headers {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			return fmt.Sprintf("headers has invalid header name %q", name)
+		}
+	}
+	if match != nil {
+		if method == "HEAD" {
+			return "match cannot be used with HEAD, the response has no body"
+		}
+		if (match.JSONPath == "") != (match.JSONValue == "") {
+			return "match json_path and json_value must be set together"
+		}
+	}
+	return ""
+}
+
+// hasUDPProvider reports whether the registration has a provider health checked over UDP
+func (r *ServiceRegistration) hasUDPProvider() bool {
+	return slices.ContainsFunc(r.Providers, func(provider ProviderInfo) bool {
+		return slices.Contains(UDPProtocols, provider.Protocol)
+	})
+}
+
+// validateURL checks that raw is an absolute URL with a host and one of the given schemes
+func validateURL(raw string, schemes ...string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("is not a valid URL: %v", err)
+	}
+	if !slices.Contains(schemes, u.Scheme) {
+		return fmt.Errorf("must use %s, got %q", strings.Join(schemes, " or "), raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("must include a host, got %q", raw)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func validRegistration() *ServiceRegistration {
+	return &ServiceRegistration{
+		ServiceName:     "user-service",
+		PodName:         "user-service-pod-1",
+		Providers:       []ProviderInfo{{Protocol: ProtocolHTTP, IP: "192.168.1.10", Port: 8080}},
+		HealthCheckURL:  "http://192.168.1.10:8080/health",
+		NotificationURL: "http://192.168.1.10:8080/notify",
+	}
+}
+
+func TestValidateValidRegistration(t *testing.T) {
+	if err := validRegistration().Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// TCP provider without health check URL
+	reg := validRegistration()
+	reg.HealthCheckURL = ""
+	reg.Providers = []ProviderInfo{{Protocol: ProtocolTCP, IP: "::1", Port: 9000}}
+	if err := reg.Validate(); err != nil {
+		t.Errorf("Expected no error for TCP provider without health check URL, got %v", err)
+	}
+
+	// gRPC provider without health check URL
+	reg.Providers = []ProviderInfo{{Protocol: ProtocolGRPC, IP: "192.168.1.10", Port: 9090}}
+	if err := reg.Validate(); err != nil {
+		t.Errorf("Expected no error for gRPC provider without health check URL, got %v", err)
+	}
+
+	// PFCP provider without health check URL
+	reg.Providers = []ProviderInfo{{Protocol: ProtocolPFCP, IP: "192.168.1.10", Port: 8805}}
+	if err := reg.Validate(); err != nil {
+		t.Errorf("Expected no error for PFCP provider without health check URL, got %v", err)
+	}
+
+	// Health checks instead of a single health check URL
+	reg = validRegistration()
+	reg.HealthCheckURL = ""
+	reg.HealthChecks = []HealthCheck{
+		{URL: "http://192.168.1.10:8080/live"},
+		{URL: "https://192.168.1.10:8443/ready", Method: "POST", Match: &HealthCheckMatch{BodyContains: "ok"}},
+	}
+	reg.HealthCheckMode = HealthCheckAny
+	if err := reg.Validate(); err != nil {
+		t.Errorf("Expected no error for health checks without health check URL, got %v", err)
+	}
+
+	// NATS notification URL
+	reg = validRegistration()
+	reg.NotificationURL = "nats://192.168.1.10:4222/governance"
+	if err := reg.Validate(); err != nil {
+		t.Errorf("Expected no error for NATS notification URL, got %v", err)
+	}
+}
+
+func TestValidateInvalidRegistration(t *testing.T) {
+	testCases := []struct {
+		name     string
+		modify   func(reg *ServiceRegistration)
+		contains string
+	}{
+		{"empty service name", func(r *ServiceRegistration) { r.ServiceName = "" }, "service_name is required"},
+		{"empty pod name", func(r *ServiceRegistration) { r.PodName = "" }, "pod_name is required"},
+		{"no providers", func(r *ServiceRegistration) { r.Providers = nil }, "at least one provider"},
+		{"missing health check URL", func(r *ServiceRegistration) { r.HealthCheckURL = "" }, "health_check_url is required"},
+		{"missing notification URL", func(r *ServiceRegistration) { r.NotificationURL = "" }, "notification_url is required"},
+		{"missing protocol", func(r *ServiceRegistration) { r.Providers[0].Protocol = "" }, "provider protocol is required"},
+		{"missing IP", func(r *ServiceRegistration) { r.Providers[0].IP = "" }, "provider IP is required"},
+		{"invalid IP", func(r *ServiceRegistration) { r.Providers[0].IP = "not-an-ip" }, "not a valid IP address"},
+		{"port zero", func(r *ServiceRegistration) { r.Providers[0].Port = 0 }, "port must be between"},
+		{"port too high", func(r *ServiceRegistration) { r.Providers[0].Port = 65536 }, "port must be between"},
+		{"health check URL without scheme", func(r *ServiceRegistration) { r.HealthCheckURL = "192.168.1.10:8080/health" }, "health_check_url"},
+		{"health check URL with bad scheme", func(r *ServiceRegistration) { r.HealthCheckURL = "ftp://192.168.1.10/health" }, "must use http or https"},
+		{"health check URL with nats scheme", func(r *ServiceRegistration) { r.HealthCheckURL = "nats://192.168.1.10:4222" }, "must use http or https"},
+		{"notification URL with bad scheme", func(r *ServiceRegistration) { r.NotificationURL = "ftp://192.168.1.10/notify" }, "must use http or https or nats"},
+		{"notification URL without host", func(r *ServiceRegistration) { r.NotificationURL = "http:///notify" }, "must include a host"},
+		{"malformed notification URL", func(r *ServiceRegistration) { r.NotificationURL = "http://[::1" }, "notification_url is not a valid URL"},
+		{"unsupported health check method", func(r *ServiceRegistration) { r.HealthCheckMethod = "DELETE" }, "health_check_method must be one of"},
+		{"lowercase health check method", func(r *ServiceRegistration) { r.HealthCheckMethod = "head" }, "health_check_method must be one of"},
+		{"invalid health check header", func(r *ServiceRegistration) { r.HealthCheckHeaders = map[string]string{"X Token": "secret"} }, "invalid header name"},
+		{"body match with HEAD", func(r *ServiceRegistration) {
+			r.HealthCheckMethod = "HEAD"
+			r.HealthCheckMatch = &HealthCheckMatch{BodyContains: "ok"}
+		}, "cannot be used with HEAD"},
+		{"json path without value", func(r *ServiceRegistration) { r.HealthCheckMatch = &HealthCheckMatch{JSONPath: "status"} }, "must be set together"},
+		{"health check without URL", func(r *ServiceRegistration) { r.HealthChecks = []HealthCheck{{Method: "GET"}} }, "health_checks[0].url is required"},
+		{"health check with bad scheme", func(r *ServiceRegistration) { r.HealthChecks = []HealthCheck{{URL: "tcp://192.168.1.10:9000"}} }, "must use http or https"},
+		{"health check with unsupported method", func(r *ServiceRegistration) {
+			r.HealthChecks = []HealthCheck{{URL: "http://192.168.1.10:8080/ready", Method: "PUT"}}
+		}, "health_checks[0].method must be one of"},
+		{"health check body match with HEAD", func(r *ServiceRegistration) {
+			r.HealthChecks = []HealthCheck{{URL: "http://192.168.1.10:8080/ready", Method: "HEAD", Match: &HealthCheckMatch{BodyContains: "ok"}}}
+		}, "cannot be used with HEAD"},
+		{"unknown health check mode", func(r *ServiceRegistration) { r.HealthCheckMode = "most" }, "health_check_mode must be"},
+		{"unknown notification format", func(r *ServiceRegistration) { r.NotificationFormat = "compact" }, "notification_format must be"},
+		{"notification url override for unknown event type", func(r *ServiceRegistration) {
+			r.NotificationURLs = map[EventType]string{"health": "http://192.168.1.10:8080/alerts"}
+		}, `notification_urls has unknown event type "health"`},
+		{"invalid notification url override", func(r *ServiceRegistration) {
+			r.NotificationURLs = map[EventType]string{EventTypeUpdate: "/alerts"}
+		}, "notification_urls[update]"},
+		{"negative ttl", func(r *ServiceRegistration) { r.TTLSeconds = -1 }, "ttl_seconds must not be negative"},
+		{"uppercase namespace", func(r *ServiceRegistration) { r.Namespace = "Team-A" }, "namespace"},
+		{"namespace with trailing dash", func(r *ServiceRegistration) { r.Namespace = "team-" }, "namespace"},
+		{"service name with separator", func(r *ServiceRegistration) { r.ServiceName = "team-a/gateway" }, "service_name"},
+		{"subscription with separator", func(r *ServiceRegistration) { r.Subscriptions = []string{"team-a/gateway"} }, "subscriptions[0]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reg := validRegistration()
+			tc.modify(reg)
+
+			err := reg.Validate()
+			if err == nil {
+				t.Fatal("Expected validation error, got nil")
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Errorf("Expected *ValidationError, got %T", err)
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Errorf("Expected error containing %q, got %q", tc.contains, err.Error())
+			}
+		})
+	}
+}
+
+func TestValidationErrorIndex(t *testing.T) {
+	index := 12
+	err := &ValidationError{Message: "provider IP is required", Index: &index}
+	if err.Error() != "provider IP is required (provider index: 12)" {
+		t.Errorf("Unexpected error message: %s", err.Error())
+	}
+}
+
+func TestParseSnapshot(t *testing.T) {
+	service := func(podName string) *ServiceInfo {
+		reg := validRegistration()
+		return &ServiceInfo{
+			ServiceName:     reg.ServiceName,
+			PodName:         podName,
+			Providers:       reg.Providers,
+			HealthCheckURL:  reg.HealthCheckURL,
+			NotificationURL: reg.NotificationURL,
+			Status:          StatusHealthy,
+		}
+	}
+	encode := func(snapshot *Snapshot) []byte {
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			t.Fatalf("Failed to encode snapshot: %v", err)
+		}
+		return data
+	}
+
+	snapshot, err := ParseSnapshot(encode(&Snapshot{Version: SnapshotVersion, Services: []*ServiceInfo{service("pod-1"), service("pod-2")}}))
+	if err != nil {
+		t.Fatalf("Expected valid snapshot, got %v", err)
+	}
+	if len(snapshot.Services) != 2 || snapshot.Services[1].Status != StatusHealthy {
+		t.Errorf("Unexpected services after parsing: %+v", snapshot.Services)
+	}
+
+	invalidStatus := service("pod-1")
+	invalidStatus.Status = "sleeping"
+	missingPod := service("")
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"not JSON", []byte("{"), "invalid snapshot"},
+		{"unsupported version", encode(&Snapshot{Version: 2}), "unsupported snapshot version 2"},
+		{"invalid service", encode(&Snapshot{Version: SnapshotVersion, Services: []*ServiceInfo{service("pod-1"), missingPod}}), "services[1]: pod_name is required"},
+		{"unknown status", encode(&Snapshot{Version: SnapshotVersion, Services: []*ServiceInfo{invalidStatus}}), `services[0]: unknown status "sleeping"`},
+		{"duplicate", encode(&Snapshot{Version: SnapshotVersion, Services: []*ServiceInfo{service("pod-1"), service("pod-1")}}), "services[1]: duplicate service"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSnapshot(tt.data)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected *ValidationError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Package balance helps subscribers pick a pod of a service group from their notifications.
+//
+// Pods are chosen by rendezvous (highest random weight) hashing: each pod gets a score from
+// the hash of the key and its pod name, and the pod with the highest score wins. The choice
+// only depends on the key and the set of pod names, so independent clients holding the same
+// pods pick the same pod for a key. When a pod leaves, only the keys it had move to other
+// pods; when one joins, it only takes over the keys it now wins.
+package balance
+
+import (
+	"hash/fnv"
+
+	"github.com/chronnie/governance/models"
+)
+
+// Pick returns the healthy pod of pods that key maps to, false if none is healthy
+func Pick(pods []models.PodInfo, key string) (models.PodInfo, bool) {
+	var best models.PodInfo
+	var bestScore uint64
+	found := false
+	for _, pod := range pods {
+		if pod.Status != models.StatusHealthy {
+			continue
+		}
+		s := score(key, pod.PodName)
+		// Ties are broken by pod name, so the result doesn't depend on the order of pods
+		if !found || s > bestScore || s == bestScore && pod.PodName < best.PodName {
+			best, bestScore, found = pod, s, true
+		}
+	}
+	return best, found
+}
+
+// PickFromPayload returns the healthy pod of the payload's service that key maps to, see Pick.
+// Delta payloads only list the changed pods, so for them it returns false: subscribers using
+// the delta format keep their own pod list and call Pick with it.
+func PickFromPayload(payload *models.NotificationPayload, key string) (models.PodInfo, bool) {
+	if payload == nil || payload.Format == models.NotificationFormatDelta {
+		return models.PodInfo{}, false
+	}
+	return Pick(payload.Pods, key)
+}
+
+// score hashes key and podName into the pod's weight for key
+func score(key, podName string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	hash.Write([]byte{0}) // Keeps ("ab", "c") and ("a", "bc") apart
+	hash.Write([]byte(podName))
+	return mix(hash.Sum64())
+}
+
+// mix is the splitmix64 finalizer. FNV spreads inputs that only differ in their last bytes
+// poorly, and pod names often only differ in a trailing index.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,105 @@
+package balance
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/chronnie/governance/models"
+)
+
+func healthyPods(names ...string) []models.PodInfo {
+	pods := make([]models.PodInfo, 0, len(names))
+	for _, name := range names {
+		pods = append(pods, models.PodInfo{PodName: name, Status: models.StatusHealthy})
+	}
+	return pods
+}
+
+func TestPickIsDeterministic(t *testing.T) {
+	pods := healthyPods("pod-0", "pod-1", "pod-2", "pod-3")
+	reversed := slices.Clone(pods)
+	slices.Reverse(reversed)
+
+	for i := range 100 {
+		key := fmt.Sprintf("session-%d", i)
+		first, ok := Pick(pods, key)
+		if !ok {
+			t.Fatalf("Expected a pod for %s", key)
+		}
+		if second, _ := Pick(reversed, key); second.PodName != first.PodName {
+			t.Errorf("Key %s: expected %s regardless of pod order, got %s", key, first.PodName, second.PodName)
+		}
+	}
+}
+
+func TestPickSkipsUnhealthyPods(t *testing.T) {
+	pods := healthyPods("pod-0", "pod-1")
+	pods[0].Status = models.StatusUnhealthy
+	pods = append(pods, models.PodInfo{PodName: "pod-2", Status: models.StatusUnknown})
+
+	for i := range 20 {
+		if pod, ok := Pick(pods, fmt.Sprintf("session-%d", i)); !ok || pod.PodName != "pod-1" {
+			t.Errorf("Expected the only healthy pod, got %s", pod.PodName)
+		}
+	}
+
+	pods[1].Status = models.StatusUnhealthy
+	if _, ok := Pick(pods, "session"); ok {
+		t.Error("Expected no pod without healthy pods")
+	}
+}
+
+func TestPickMinimizesReshuffling(t *testing.T) {
+	pods := healthyPods("pod-0", "pod-1", "pod-2", "pod-3", "pod-4")
+	const keys = 1000
+
+	before := make(map[string]string, keys)
+	counts := make(map[string]int)
+	for i := range keys {
+		key := fmt.Sprintf("session-%d", i)
+		pod, _ := Pick(pods, key)
+		before[key] = pod.PodName
+		counts[pod.PodName]++
+	}
+	// Roughly even spread: every pod gets at least half its fair share
+	for _, pod := range pods {
+		if counts[pod.PodName] < keys/len(pods)/2 {
+			t.Errorf("Expected %s to get about %d keys, got %d", pod.PodName, keys/len(pods), counts[pod.PodName])
+		}
+	}
+
+	// Removing a pod only moves its own keys
+	remaining := slices.DeleteFunc(slices.Clone(pods), func(pod models.PodInfo) bool { return pod.PodName == "pod-2" })
+	for key, previous := range before {
+		pod, _ := Pick(remaining, key)
+		if previous != "pod-2" && pod.PodName != previous {
+			t.Errorf("Key %s moved from %s to %s after pod-2 left", key, previous, pod.PodName)
+		}
+	}
+
+	// Adding a pod only moves keys to the new pod
+	added := append(slices.Clone(pods), models.PodInfo{PodName: "pod-5", Status: models.StatusHealthy})
+	for key, previous := range before {
+		pod, _ := Pick(added, key)
+		if pod.PodName != previous && pod.PodName != "pod-5" {
+			t.Errorf("Key %s moved from %s to %s after pod-5 joined", key, previous, pod.PodName)
+		}
+	}
+}
+
+func TestPickFromPayload(t *testing.T) {
+	payload := &models.NotificationPayload{
+		ServiceName: "user-service",
+		Pods:        healthyPods("pod-0", "pod-1"),
+	}
+	expected, _ := Pick(payload.Pods, "session")
+	if pod, ok := PickFromPayload(payload, "session"); !ok || pod.PodName != expected.PodName {
+		t.Errorf("Expected %s, got %s", expected.PodName, pod.PodName)
+	}
+
+	payload.Format = models.NotificationFormatDelta
+	if _, ok := PickFromPayload(payload, "session"); ok {
+		t.Error("Expected no pod from a delta payload")
+	}
+}
@@ -1,16 +1,24 @@
 package logger
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var (
 	// Global logger instance
 	globalLogger *zap.Logger
 	sugar        *zap.SugaredLogger
+
+	// Level shared by every logger built by NewLogger, adjustable at runtime with SetLevel
+	level = zap.NewAtomicLevel()
 )
 
 func init() {
@@ -23,6 +31,10 @@ func init() {
 //   - GOVERNANCE_LOG_ENABLED: "true" to enable logging, anything else disables it (default: false)
 //   - GOVERNANCE_LOG_LEVEL: "debug", "info", "warn", "error" (default: "info")
 //   - GOVERNANCE_LOG_FORMAT: "json" or "console" (default: "console")
+//   - GOVERNANCE_LOG_FILE: path of a log file rotated by size instead of stdout/stderr (default: unset)
+//   - GOVERNANCE_LOG_MAX_SIZE: megabytes before the log file is rotated (default: 100)
+//   - GOVERNANCE_LOG_MAX_AGE: days to keep rotated log files (default: 0, keep all)
+//   - GOVERNANCE_LOG_MAX_BACKUPS: number of rotated log files to keep (default: 0, keep all)
 func NewLogger() *zap.Logger {
 	// Check if logging is enabled
 	enabled := strings.ToLower(os.Getenv("GOVERNANCE_LOG_ENABLED")) == "true"
@@ -32,19 +44,8 @@ func NewLogger() *zap.Logger {
 	}
 
 	// Determine log level
-	levelStr := strings.ToLower(os.Getenv("GOVERNANCE_LOG_LEVEL"))
-	var level zap.AtomicLevel
-	switch levelStr {
-	case "debug":
-		level = zap.NewAtomicLevelAt(zap.DebugLevel)
-	case "info":
-		level = zap.NewAtomicLevelAt(zap.InfoLevel)
-	case "warn":
-		level = zap.NewAtomicLevelAt(zap.WarnLevel)
-	case "error":
-		level = zap.NewAtomicLevelAt(zap.ErrorLevel)
-	default:
-		level = zap.NewAtomicLevelAt(zap.InfoLevel)
+	if err := SetLevel(os.Getenv("GOVERNANCE_LOG_LEVEL")); err != nil {
+		level.SetLevel(zap.InfoLevel)
 	}
 
 	// Determine format
@@ -57,7 +58,14 @@ func NewLogger() *zap.Logger {
 	}
 	config.Level = level
 
-	logger, err := config.Build()
+	var options []zap.Option
+	if path := os.Getenv("GOVERNANCE_LOG_FILE"); path != "" {
+		options = append(options, zap.WrapCore(func(zapcore.Core) zapcore.Core {
+			return newFileCore(config, path)
+		}))
+	}
+
+	logger, err := config.Build(options...)
 	if err != nil {
 		// Fallback to no-op logger if build fails
 		return zap.NewNop()
@@ -66,6 +74,63 @@ func NewLogger() *zap.Logger {
 	return logger
 }
 
+// SetLevel changes the level of the global logger at runtime.
+// Accepts "debug", "info", "warn" or "error" (case-insensitive).
+func SetLevel(name string) error {
+	var l zapcore.Level
+	switch strings.ToLower(name) {
+	case "debug":
+		l = zap.DebugLevel
+	case "info":
+		l = zap.InfoLevel
+	case "warn":
+		l = zap.WarnLevel
+	case "error":
+		l = zap.ErrorLevel
+	default:
+		return fmt.Errorf("invalid log level %q, expected debug, info, warn or error", name)
+	}
+	level.SetLevel(l)
+	return nil
+}
+
+// Level returns the current level of the global logger
+func Level() string {
+	return level.Level().String()
+}
+
+// newFileCore creates a core writing to a rotating log file, encoded and sampled like config
+func newFileCore(config zap.Config, path string) zapcore.Core {
+	writer := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    envInt("GOVERNANCE_LOG_MAX_SIZE", 100),
+		MaxAge:     envInt("GOVERNANCE_LOG_MAX_AGE", 0),
+		MaxBackups: envInt("GOVERNANCE_LOG_MAX_BACKUPS", 0),
+	}
+
+	var encoder zapcore.Encoder
+	if config.Encoding == "json" {
+		encoder = zapcore.NewJSONEncoder(config.EncoderConfig)
+	} else {
+		encoder = zapcore.NewConsoleEncoder(config.EncoderConfig)
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.AddSync(writer), config.Level)
+	if config.Sampling != nil {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, config.Sampling.Initial, config.Sampling.Thereafter)
+	}
+	return core
+}
+
+// envInt reads a non-negative integer environment variable, falling back to def if unset or invalid
+func envInt(name string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value < 0 {
+		return def
+	}
+	return value
+}
+
 // Get returns the global logger instance
 func Get() *zap.Logger {
 	return globalLogger
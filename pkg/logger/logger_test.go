@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewLoggerWritesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "governance.log")
+	t.Setenv("GOVERNANCE_LOG_ENABLED", "true")
+	t.Setenv("GOVERNANCE_LOG_FORMAT", "json")
+	t.Setenv("GOVERNANCE_LOG_FILE", path)
+
+	logger := NewLogger()
+	logger.Info("written to file")
+	logger.Debug("below the level")
+	logger.Sync()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), `"msg":"written to file"`) {
+		t.Errorf("Expected log line in file, got %q", data)
+	}
+	if strings.Contains(string(data), "below the level") {
+		t.Error("Expected debug message to be filtered by the level")
+	}
+}
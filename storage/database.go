@@ -2,9 +2,12 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
 )
 
 // DatabaseStore defines the interface for database persistence layer.
@@ -46,6 +49,16 @@ type DatabaseStore interface {
 	// DeleteSubscriptions removes all subscriptions for a subscriber
 	DeleteSubscriptions(ctx context.Context, subscriberKey string) error
 
+	// Change feed operations
+
+	// AppendChange appends a record to the change feed and returns its assigned sequence number.
+	// Sequence numbers must be strictly increasing.
+	AppendChange(ctx context.Context, change *models.ChangeRecord) (int64, error)
+
+	// GetChangesSince returns up to limit change records with a sequence greater than since,
+	// ordered by sequence
+	GetChangesSince(ctx context.Context, since int64, limit int) ([]*models.ChangeRecord, error)
+
 	// Lifecycle operations
 
 	// Close closes the database connection and cleans up resources
@@ -54,3 +67,73 @@ type DatabaseStore interface {
 	// Ping checks if the database is accessible
 	Ping(ctx context.Context) error
 }
+
+// BatchDatabaseStore is implemented by database stores that can persist many services
+// in a few round-trips (e.g. multi-row upserts). Use SaveServices rather than calling it
+// directly so stores without native support fall back to per-service writes.
+type BatchDatabaseStore interface {
+	// SaveServices stores or updates all given services
+	SaveServices(ctx context.Context, services []*models.ServiceInfo) error
+}
+
+// SaveServices persists services using the store's batch path when it has one,
+// otherwise one SaveService call per service
+func SaveServices(ctx context.Context, db DatabaseStore, services []*models.ServiceInfo) error {
+	if len(services) == 0 {
+		return nil
+	}
+
+	if batch, ok := db.(BatchDatabaseStore); ok {
+		return batch.SaveServices(ctx, services)
+	}
+
+	for _, service := range services {
+		if err := db.SaveService(ctx, service); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BatchSize is the number of rows written per statement by SQL batch implementations,
+// kept well below driver placeholder limits
+const BatchSize = 500
+
+// DefaultConnectBackoff is the delay before the first connect retry when none is configured
+const DefaultConnectBackoff = time.Second
+
+// maxConnectBackoff caps the delay between connect retries
+const maxConnectBackoff = 30 * time.Second
+
+// PingWithRetry pings a database until it answers, for stores whose database may still be
+// starting up. It retries up to retries times, waiting backoff (0 = DefaultConnectBackoff)
+// before the first retry and doubling the wait after each one, up to 30s.
+// With retries <= 0 it pings once and fails fast.
+func PingWithRetry(ctx context.Context, ping func(ctx context.Context) error, retries int, backoff time.Duration) error {
+	if backoff <= 0 {
+		backoff = DefaultConnectBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := ping(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= retries {
+			return fmt.Errorf("database not reachable after %d attempts: %w", attempt+1, err)
+		}
+
+		logger.Warn("Database not reachable, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("database not reachable: %w", ctx.Err())
+		}
+		backoff = min(2*backoff, maxConnectBackoff)
+	}
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPingWithRetry(t *testing.T) {
+	down := errors.New("connection refused")
+
+	// Database comes up on the third attempt
+	attempts := 0
+	ping := func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return down
+		}
+		return nil
+	}
+	if err := PingWithRetry(context.Background(), ping, 5, time.Millisecond); err != nil {
+		t.Fatalf("Expected ping to succeed after retries, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+
+	// No retries fails on the first error
+	attempts = 0
+	failing := func(ctx context.Context) error {
+		attempts++
+		return down
+	}
+	if err := PingWithRetry(context.Background(), failing, 0, time.Millisecond); !errors.Is(err, down) {
+		t.Errorf("Expected ping error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt without retries, got %d", attempts)
+	}
+
+	// Retries are exhausted
+	attempts = 0
+	if err := PingWithRetry(context.Background(), failing, 2, time.Millisecond); !errors.Is(err, down) {
+		t.Errorf("Expected ping error, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/chronnie/governance/models"
+)
+
+// ErrNoDatabase is returned by operations that need a database when the DualStore has none
+var ErrNoDatabase = errors.New("no database configured")
+
+// Diff compares the in-memory cache with the database: services present in only one of
+// them, services whose health status differs and subscribers whose service groups differ.
+// It is a diagnostic and changes nothing; use RetryFailedWrites or SyncFromDatabase to
+// bring them back in line. Returns ErrNoDatabase without a database.
+func (d *DualStore) Diff(ctx context.Context) (*models.StoreDiff, error) {
+	if d.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	stored, err := d.db.GetAllServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	storedSubs, err := d.db.GetAllSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.StoreDiff{
+		OnlyInCache:          []string{},
+		OnlyInDatabase:       []string{},
+		StatusMismatch:       []models.StatusMismatch{},
+		SubscriptionMismatch: []string{},
+	}
+
+	inDatabase := make(map[string]*models.ServiceInfo, len(stored))
+	for _, service := range stored {
+		inDatabase[service.GetKey()] = service
+	}
+
+	d.cache.mu.RLock()
+	for key, cached := range d.cache.services {
+		service, exists := inDatabase[key]
+		if !exists {
+			diff.OnlyInCache = append(diff.OnlyInCache, key)
+			continue
+		}
+		if cached.Status != service.Status {
+			diff.StatusMismatch = append(diff.StatusMismatch, models.StatusMismatch{
+				Key:            key,
+				CacheStatus:    cached.Status,
+				DatabaseStatus: service.Status,
+			})
+		}
+	}
+	for key := range inDatabase {
+		if _, exists := d.cache.services[key]; !exists {
+			diff.OnlyInDatabase = append(diff.OnlyInDatabase, key)
+		}
+	}
+
+	// The cache indexes subscriptions by service group, the database by subscriber
+	cachedSubs := make(map[string]map[string]struct{})
+	for serviceGroup, subscribers := range d.cache.subscriptions {
+		for _, subscriberKey := range subscribers {
+			if cachedSubs[subscriberKey] == nil {
+				cachedSubs[subscriberKey] = make(map[string]struct{})
+			}
+			cachedSubs[subscriberKey][serviceGroup] = struct{}{}
+		}
+	}
+	d.cache.mu.RUnlock()
+	for _, subscriberKey := range sortedUnion(cachedSubs, storedSubs) {
+		stored := make(map[string]struct{}, len(storedSubs[subscriberKey]))
+		for _, serviceGroup := range models.QualifiedSubscriptions(models.NamespaceOfKey(subscriberKey), storedSubs[subscriberKey]) {
+			stored[serviceGroup] = struct{}{}
+		}
+		if !maps.Equal(cachedSubs[subscriberKey], stored) {
+			diff.SubscriptionMismatch = append(diff.SubscriptionMismatch, subscriberKey)
+		}
+	}
+
+	slices.Sort(diff.OnlyInCache)
+	slices.Sort(diff.OnlyInDatabase)
+	slices.SortFunc(diff.StatusMismatch, func(a, b models.StatusMismatch) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return diff, nil
+}
+
+// sortedUnion returns the keys of both maps, sorted
+func sortedUnion[A, B any](a map[string]A, b map[string]B) []string {
+	keys := slices.Collect(maps.Keys(a))
+	for key := range b {
+		if _, exists := a[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
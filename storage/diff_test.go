@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/chronnie/governance/models"
+)
+
+// subscribedStore is a flakyStore that also reports subscriptions
+type subscribedStore struct {
+	*flakyStore
+	subscriptions map[string][]string
+}
+
+func (s *subscribedStore) GetAllSubscriptions(ctx context.Context) (map[string][]string, error) {
+	return s.subscriptions, nil
+}
+
+func TestDiff(t *testing.T) {
+	db := &subscribedStore{flakyStore: newFlakyStore(), subscriptions: map[string][]string{}}
+	d := NewDualStore(db)
+	ctx := context.Background()
+
+	service := func(podName string, status models.ServiceStatus) *models.ServiceInfo {
+		return &models.ServiceInfo{ServiceName: "user-service", PodName: podName, Status: status}
+	}
+
+	// Write the cache and database separately, as a lost async write would leave them
+	for _, pod := range []string{"pod-1", "pod-2", "pod-3"} {
+		d.cache.SaveService(ctx, service(pod, models.StatusHealthy))
+	}
+	d.cache.AddSubscription(ctx, "user-service:pod-1", "order-service")
+	d.cache.AddSubscription(ctx, "user-service:pod-2", "order-service")
+	d.cache.AddSubscription(ctx, "user-service:pod-2", "payment-service")
+
+	db.SaveService(ctx, service("pod-1", models.StatusHealthy))
+	db.SaveService(ctx, service("pod-2", models.StatusUnhealthy))
+	db.SaveService(ctx, service("pod-4", models.StatusHealthy))
+	db.subscriptions["user-service:pod-1"] = []string{"order-service"}
+	db.subscriptions["user-service:pod-2"] = []string{"payment-service"}
+	db.subscriptions["user-service:pod-4"] = []string{"order-service"}
+
+	diff, err := d.Diff(ctx)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if diff.InSync() {
+		t.Fatal("Expected cache and database to differ")
+	}
+	if !slices.Equal(diff.OnlyInCache, []string{"user-service:pod-3"}) {
+		t.Errorf("Expected pod-3 only in cache, got %v", diff.OnlyInCache)
+	}
+	if !slices.Equal(diff.OnlyInDatabase, []string{"user-service:pod-4"}) {
+		t.Errorf("Expected pod-4 only in database, got %v", diff.OnlyInDatabase)
+	}
+	expectedStatus := []models.StatusMismatch{{Key: "user-service:pod-2", CacheStatus: models.StatusHealthy, DatabaseStatus: models.StatusUnhealthy}}
+	if !slices.Equal(diff.StatusMismatch, expectedStatus) {
+		t.Errorf("Expected pod-2 status mismatch, got %v", diff.StatusMismatch)
+	}
+	if !slices.Equal(diff.SubscriptionMismatch, []string{"user-service:pod-2", "user-service:pod-4"}) {
+		t.Errorf("Expected pod-2 and pod-4 subscriptions to differ, got %v", diff.SubscriptionMismatch)
+	}
+
+	// Once the database catches up nothing differs
+	if err := d.SyncToDatabase(ctx); err != nil {
+		t.Fatalf("SyncToDatabase failed: %v", err)
+	}
+	db.DeleteService(ctx, "user-service:pod-4")
+	db.subscriptions = map[string][]string{
+		"user-service:pod-1": {"order-service"},
+		"user-service:pod-2": {"payment-service", "order-service"},
+	}
+	if diff, _ := d.Diff(ctx); !diff.InSync() {
+		t.Errorf("Expected cache and database in sync, got %+v", diff)
+	}
+
+	if _, err := NewDualStore(nil).Diff(ctx); !errors.Is(err, ErrNoDatabase) {
+		t.Errorf("Expected ErrNoDatabase without a database, got %v", err)
+	}
+}
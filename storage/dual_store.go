@@ -3,13 +3,19 @@ package storage
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/pkg/logger"
+	"go.uber.org/zap"
 )
 
-// inMemoryCache is a simple in-memory cache embedded in DualStore
+// inMemoryCache is a simple in-memory cache embedded in DualStore. It is safe for concurrent
+// use: in parallel processing mode several event workers write it at once, while HTTP
+// handlers and database syncs read and write it too.
 type inMemoryCache struct {
+	mu            sync.RWMutex
 	services      map[string]*models.ServiceInfo
 	subscriptions map[string][]string
 }
@@ -32,11 +38,21 @@ func (c *inMemoryCache) SaveService(ctx context.Context, service *models.Service
 		return fmt.Errorf("service key cannot be empty")
 	}
 	serviceCopy := *service
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.services[key] = &serviceCopy
 	return nil
 }
 
 func (c *inMemoryCache) GetService(ctx context.Context, key string) (*models.ServiceInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.getService(key)
+}
+
+// getService returns a copy of the service with the given key. Caller must hold c.mu.
+func (c *inMemoryCache) getService(key string) (*models.ServiceInfo, error) {
 	service, exists := c.services[key]
 	if !exists {
 		return nil, fmt.Errorf("service not found: %s", key)
@@ -46,9 +62,12 @@ func (c *inMemoryCache) GetService(ctx context.Context, key string) (*models.Ser
 }
 
 func (c *inMemoryCache) GetServicesByName(ctx context.Context, serviceName string) ([]*models.ServiceInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	var result []*models.ServiceInfo
 	for _, service := range c.services {
-		if service.ServiceName == serviceName {
+		if service.QualifiedName() == serviceName {
 			serviceCopy := *service
 			result = append(result, &serviceCopy)
 		}
@@ -57,6 +76,9 @@ func (c *inMemoryCache) GetServicesByName(ctx context.Context, serviceName strin
 }
 
 func (c *inMemoryCache) GetAllServices(ctx context.Context) ([]*models.ServiceInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	result := make([]*models.ServiceInfo, 0, len(c.services))
 	for _, service := range c.services {
 		serviceCopy := *service
@@ -65,7 +87,24 @@ func (c *inMemoryCache) GetAllServices(ctx context.Context) ([]*models.ServiceIn
 	return result, nil
 }
 
+func (c *inMemoryCache) GetServicesByStatus(ctx context.Context, status models.ServiceStatus) ([]*models.ServiceInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []*models.ServiceInfo
+	for _, service := range c.services {
+		if service.Status == status {
+			serviceCopy := *service
+			result = append(result, &serviceCopy)
+		}
+	}
+	return result, nil
+}
+
 func (c *inMemoryCache) DeleteService(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if _, exists := c.services[key]; !exists {
 		return fmt.Errorf("service not found: %s", key)
 	}
@@ -74,6 +113,9 @@ func (c *inMemoryCache) DeleteService(ctx context.Context, key string) error {
 }
 
 func (c *inMemoryCache) UpdateHealthStatus(ctx context.Context, key string, status models.ServiceStatus, timestamp time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	service, exists := c.services[key]
 	if !exists {
 		return fmt.Errorf("service not found: %s", key)
@@ -91,6 +133,9 @@ func (c *inMemoryCache) AddSubscription(ctx context.Context, subscriberKey strin
 		return fmt.Errorf("service group cannot be empty")
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	subscribers := c.subscriptions[serviceGroup]
 	for _, sub := range subscribers {
 		if sub == subscriberKey {
@@ -102,9 +147,18 @@ func (c *inMemoryCache) AddSubscription(ctx context.Context, subscriberKey strin
 }
 
 func (c *inMemoryCache) RemoveSubscription(ctx context.Context, subscriberKey string, serviceGroup string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.removeSubscription(subscriberKey, serviceGroup)
+	return nil
+}
+
+// removeSubscription removes a subscriber from a service group. Caller must hold c.mu.
+func (c *inMemoryCache) removeSubscription(subscriberKey string, serviceGroup string) {
 	subscribers, exists := c.subscriptions[serviceGroup]
 	if !exists {
-		return nil
+		return
 	}
 
 	for i, sub := range subscribers {
@@ -113,38 +167,35 @@ func (c *inMemoryCache) RemoveSubscription(ctx context.Context, subscriberKey st
 			if len(c.subscriptions[serviceGroup]) == 0 {
 				delete(c.subscriptions, serviceGroup)
 			}
-			return nil
+			return
 		}
 	}
-	return nil
 }
 
 func (c *inMemoryCache) RemoveAllSubscriptions(ctx context.Context, subscriberKey string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	for serviceGroup := range c.subscriptions {
-		c.RemoveSubscription(ctx, subscriberKey, serviceGroup)
+		c.removeSubscription(subscriberKey, serviceGroup)
 	}
 	return nil
 }
 
 func (c *inMemoryCache) GetSubscribers(ctx context.Context, serviceGroup string) ([]string, error) {
-	subscribers, exists := c.subscriptions[serviceGroup]
-	if !exists {
-		return []string{}, nil
-	}
-	result := make([]string, len(subscribers))
-	copy(result, subscribers)
-	return result, nil
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return MatchingSubscribers(c.subscriptions, serviceGroup), nil
 }
 
 func (c *inMemoryCache) GetSubscriberServices(ctx context.Context, serviceGroup string) ([]*models.ServiceInfo, error) {
-	subscribers, err := c.GetSubscribers(ctx, serviceGroup)
-	if err != nil {
-		return nil, err
-	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 
+	subscribers := MatchingSubscribers(c.subscriptions, serviceGroup)
 	result := make([]*models.ServiceInfo, 0, len(subscribers))
 	for _, subscriberKey := range subscribers {
-		service, err := c.GetService(ctx, subscriberKey)
+		service, err := c.getService(subscriberKey)
 		if err != nil {
 			continue // Skip services that no longer exist
 		}
@@ -153,23 +204,154 @@ func (c *inMemoryCache) GetSubscriberServices(ctx context.Context, serviceGroup
 	return result, nil
 }
 
+// maxCachedChanges bounds the in-memory change feed used when no database is configured
+const maxCachedChanges = 10000
+
+// changeLog is the in-memory change feed used when database persistence is disabled.
+// It is read by HTTP handlers while the worker appends, so access is guarded by a mutex.
+type changeLog struct {
+	mu           sync.Mutex
+	changes      []*models.ChangeRecord
+	lastSequence int64
+}
+
+func (l *changeLog) append(change *models.ChangeRecord) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastSequence++
+	changeCopy := *change
+	changeCopy.Sequence = l.lastSequence
+	l.changes = append(l.changes, &changeCopy)
+
+	// Drop the oldest records once the log is over capacity
+	if len(l.changes) > maxCachedChanges {
+		l.changes = l.changes[len(l.changes)-maxCachedChanges:]
+	}
+
+	return l.lastSequence
+}
+
+func (l *changeLog) since(since int64, limit int) []*models.ChangeRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := make([]*models.ChangeRecord, 0)
+	for _, change := range l.changes {
+		if change.Sequence <= since {
+			continue
+		}
+		if len(result) >= limit {
+			break
+		}
+		changeCopy := *change
+		result = append(result, &changeCopy)
+	}
+	return result
+}
+
 // DualStore combines in-memory cache with optional database persistence.
-// All reads/writes go to memory for performance.
-// Database writes happen asynchronously (fire-and-forget).
+// All reads go to memory for performance.
+// By default database writes happen asynchronously; Flush waits for them. Failed writes are
+// logged and kept in a repair log until RetryFailedWrites succeeds. With WithSyncWrites they happen before the cache is updated and
+// a failure is returned to the caller.
 type DualStore struct {
-	cache *inMemoryCache
-	db    DatabaseStore // nil if database persistence is disabled
+	cache      *inMemoryCache
+	db         DatabaseStore  // nil if database persistence is disabled
+	changes    *changeLog     // Change feed used when db is nil
+	pending    sync.WaitGroup // In-flight asynchronous database writes
+	syncWrites bool           // Write the database before returning instead of in the background
+	repairs    *repairLog     // Services whose asynchronous database write failed
+	inflight   *inflightLog   // Services with an asynchronous database write still running
 }
 
 // Ensure DualStore implements RegistryStore
 var _ RegistryStore = (*DualStore)(nil)
 
+// DualStoreOption configures optional DualStore behaviour
+type DualStoreOption func(*DualStore)
+
+// WithSyncWrites makes writes wait for the database. A failed database write is returned
+// and leaves the cache unchanged, so callers know the change was not persisted. Writes get
+// as slow as the database and fail while it is down.
+func WithSyncWrites() DualStoreOption {
+	return func(d *DualStore) {
+		d.syncWrites = true
+	}
+}
+
 // NewDualStore creates a new dual-layer storage.
 // If db is nil, only in-memory cache is used (no persistence).
-func NewDualStore(db DatabaseStore) *DualStore {
-	return &DualStore{
-		cache: newInMemoryCache(),
-		db:    db,
+func NewDualStore(db DatabaseStore, opts ...DualStoreOption) *DualStore {
+	d := &DualStore{
+		cache:    newInMemoryCache(),
+		db:       db,
+		changes:  &changeLog{},
+		repairs:  newRepairLog(),
+		inflight: newInflightLog(),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// SyncWrites reports whether writes wait for the database (see WithSyncWrites)
+func (d *DualStore) SyncWrites() bool {
+	return d.syncWrites
+}
+
+// writeDatabase runs a database write if persistence is enabled: right away in sync mode,
+// returning its error, otherwise in the background
+func (d *DualStore) writeDatabase(ctx context.Context, operation, key string, write func(ctx context.Context) error) error {
+	if d.db == nil {
+		return nil
+	}
+	if !d.syncWrites {
+		d.persist(operation, key, write)
+		return nil
+	}
+	if err := write(ctx); err != nil {
+		return fmt.Errorf("database %s failed for %s: %w", operation, key, err)
+	}
+	return nil
+}
+
+// persist runs a database write in the background, tracked so Flush can wait for it.
+// Writes of the same service run one after another in the order they were made.
+// Failures are logged and added to the repair log.
+func (d *DualStore) persist(operation, key string, write func(ctx context.Context) error) {
+	previous, done := d.inflight.start(key)
+	d.pending.Go(func() {
+		defer d.inflight.finish(key, done)
+		if previous != nil {
+			<-previous
+		}
+		if err := write(context.Background()); err != nil {
+			d.repairs.add(key)
+			logger.Error("Asynchronous database write failed, queued for repair",
+				zap.String("operation", operation),
+				zap.String("key", key),
+				zap.Error(err),
+			)
+		}
+	})
+}
+
+// Flush waits for in-flight asynchronous database writes to finish.
+// Returns ctx.Err() if ctx is done first.
+func (d *DualStore) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -178,19 +360,30 @@ func (d *DualStore) GetDatabase() DatabaseStore {
 	return d.db
 }
 
-// SaveService stores to cache immediately, then persists to database asynchronously
+// SaveService stores to cache immediately, then persists to database asynchronously.
+// In sync mode the database is written first.
 func (d *DualStore) SaveService(ctx context.Context, service *models.ServiceInfo) error {
+	if d.syncWrites {
+		if service == nil || service.GetKey() == "" {
+			return d.cache.SaveService(ctx, service) // Rejected before reaching the database
+		}
+		if err := d.writeDatabase(ctx, "save", service.GetKey(), func(ctx context.Context) error {
+			return d.db.SaveService(ctx, service)
+		}); err != nil {
+			return err
+		}
+		return d.cache.SaveService(ctx, service)
+	}
+
 	// Always save to cache first (synchronous)
 	if err := d.cache.SaveService(ctx, service); err != nil {
 		return err
 	}
 
 	// Persist to database asynchronously if enabled
-	if d.db != nil {
-		go d.db.SaveService(context.Background(), service)
-	}
-
-	return nil
+	return d.writeDatabase(ctx, "save", service.GetKey(), func(ctx context.Context) error {
+		return d.db.SaveService(ctx, service)
+	})
 }
 
 // GetService retrieves from cache (fast)
@@ -208,34 +401,57 @@ func (d *DualStore) GetAllServices(ctx context.Context) ([]*models.ServiceInfo,
 	return d.cache.GetAllServices(ctx)
 }
 
-// DeleteService deletes from cache immediately, then from database asynchronously
+// GetServicesByStatus retrieves from cache (fast)
+func (d *DualStore) GetServicesByStatus(ctx context.Context, status models.ServiceStatus) ([]*models.ServiceInfo, error) {
+	return d.cache.GetServicesByStatus(ctx, status)
+}
+
+// DeleteService deletes from cache immediately, then from database asynchronously.
+// In sync mode the database is written first.
 func (d *DualStore) DeleteService(ctx context.Context, key string) error {
+	write := func(ctx context.Context) error { return d.db.DeleteService(ctx, key) }
+
+	if d.syncWrites {
+		if _, err := d.cache.GetService(ctx, key); err != nil {
+			return err
+		}
+		if err := d.writeDatabase(ctx, "delete", key, write); err != nil {
+			return err
+		}
+		return d.cache.DeleteService(ctx, key)
+	}
+
 	// Always delete from cache first (synchronous)
 	if err := d.cache.DeleteService(ctx, key); err != nil {
 		return err
 	}
 
 	// Delete from database asynchronously if enabled
-	if d.db != nil {
-		go d.db.DeleteService(context.Background(), key)
-	}
-
-	return nil
+	return d.writeDatabase(ctx, "delete", key, write)
 }
 
-// UpdateHealthStatus updates cache immediately, then database asynchronously
+// UpdateHealthStatus updates cache immediately, then database asynchronously.
+// In sync mode the database is written first.
 func (d *DualStore) UpdateHealthStatus(ctx context.Context, key string, status models.ServiceStatus, timestamp time.Time) error {
+	write := func(ctx context.Context) error { return d.db.UpdateHealthStatus(ctx, key, status, timestamp) }
+
+	if d.syncWrites {
+		if _, err := d.cache.GetService(ctx, key); err != nil {
+			return err
+		}
+		if err := d.writeDatabase(ctx, "health status update", key, write); err != nil {
+			return err
+		}
+		return d.cache.UpdateHealthStatus(ctx, key, status, timestamp)
+	}
+
 	// Always update cache first (synchronous)
 	if err := d.cache.UpdateHealthStatus(ctx, key, status, timestamp); err != nil {
 		return err
 	}
 
 	// Update database asynchronously if enabled
-	if d.db != nil {
-		go d.db.UpdateHealthStatus(context.Background(), key, status, timestamp)
-	}
-
-	return nil
+	return d.writeDatabase(ctx, "health status update", key, write)
 }
 
 // AddSubscription adds to cache immediately, then persists to database asynchronously
@@ -276,19 +492,25 @@ func (d *DualStore) RemoveSubscription(ctx context.Context, subscriberKey string
 	return nil
 }
 
-// RemoveAllSubscriptions removes from cache immediately, then from database asynchronously
+// RemoveAllSubscriptions removes from cache immediately, then from database asynchronously.
+// In sync mode the database is written first.
 func (d *DualStore) RemoveAllSubscriptions(ctx context.Context, subscriberKey string) error {
+	write := func(ctx context.Context) error { return d.db.DeleteSubscriptions(ctx, subscriberKey) }
+
+	if d.syncWrites {
+		if err := d.writeDatabase(ctx, "subscriptions delete", subscriberKey, write); err != nil {
+			return err
+		}
+		return d.cache.RemoveAllSubscriptions(ctx, subscriberKey)
+	}
+
 	// Always remove from cache first (synchronous)
 	if err := d.cache.RemoveAllSubscriptions(ctx, subscriberKey); err != nil {
 		return err
 	}
 
 	// Delete from database asynchronously if enabled
-	if d.db != nil {
-		go d.db.DeleteSubscriptions(context.Background(), subscriberKey)
-	}
-
-	return nil
+	return d.writeDatabase(ctx, "subscriptions delete", subscriberKey, write)
 }
 
 // GetSubscribers retrieves from cache (fast)
@@ -301,6 +523,25 @@ func (d *DualStore) GetSubscriberServices(ctx context.Context, serviceGroup stri
 	return d.cache.GetSubscriberServices(ctx, serviceGroup)
 }
 
+// AppendChange records a change in the change feed and returns its sequence number.
+// Unlike other writes this goes to the database synchronously: the caller is the single
+// event worker, so waiting keeps sequence numbers in the same order as the mutations.
+// Without a database the change is kept in a bounded in-memory log that resets on restart.
+func (d *DualStore) AppendChange(ctx context.Context, change *models.ChangeRecord) (int64, error) {
+	if d.db != nil {
+		return d.db.AppendChange(ctx, change)
+	}
+	return d.changes.append(change), nil
+}
+
+// GetChangesSince returns up to limit changes with a sequence greater than since
+func (d *DualStore) GetChangesSince(ctx context.Context, since int64, limit int) ([]*models.ChangeRecord, error) {
+	if d.db != nil {
+		return d.db.GetChangesSince(ctx, since, limit)
+	}
+	return d.changes.since(since, limit), nil
+}
+
 // Close closes the database connection (cache doesn't need closing)
 func (d *DualStore) Close() error {
 	if d.db != nil {
@@ -319,6 +560,7 @@ func (d *DualStore) Ping(ctx context.Context) error {
 
 // SyncFromDatabase loads all data from database into cache.
 // This is called during reconciliation to ensure cache and database are in sync.
+// Services with a pending repair are skipped, their cached state is newer.
 // Returns the number of services and subscriptions synced.
 func (d *DualStore) SyncFromDatabase(ctx context.Context) (servicesSynced int, subscriptionsSynced int, err error) {
 	if d.db == nil {
@@ -331,11 +573,14 @@ func (d *DualStore) SyncFromDatabase(ctx context.Context) (servicesSynced int, s
 		return 0, 0, err
 	}
 
-	// Update cache with database data
+	// Update cache with database data, except where the database is known to be behind
 	for _, service := range services {
+		if d.repairs.has(service.GetKey()) {
+			continue
+		}
 		d.cache.SaveService(ctx, service)
+		servicesSynced++
 	}
-	servicesSynced = len(services)
 
 	// Load all subscriptions from database
 	allSubs, err := d.db.GetAllSubscriptions(ctx)
@@ -345,7 +590,10 @@ func (d *DualStore) SyncFromDatabase(ctx context.Context) (servicesSynced int, s
 
 	// Update cache with subscription data
 	for subscriberKey, serviceGroups := range allSubs {
-		for _, serviceGroup := range serviceGroups {
+		if d.repairs.has(subscriberKey) {
+			continue
+		}
+		for _, serviceGroup := range models.QualifiedSubscriptions(models.NamespaceOfKey(subscriberKey), serviceGroups) {
 			d.cache.AddSubscription(ctx, subscriberKey, serviceGroup)
 		}
 		subscriptionsSynced += len(serviceGroups)
@@ -367,13 +615,13 @@ func (d *DualStore) SyncToDatabase(ctx context.Context) error {
 		return err
 	}
 
-	// Write to database
-	for _, service := range services {
-		if err := d.db.SaveService(ctx, service); err != nil {
-			return err
-		}
+	// Write to database, batched when the store supports it
+	if err := SaveServices(ctx, d.db, services); err != nil {
+		return err
+	}
 
-		// Also save subscriptions
+	// Also save subscriptions
+	for _, service := range services {
 		if len(service.Subscriptions) > 0 {
 			key := service.GetKey()
 			if err := d.db.SaveSubscriptions(ctx, key, service.Subscriptions); err != nil {
@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chronnie/governance/models"
+)
+
+// countingStore counts database round-trips. Methods the tests don't use are
+// left to the embedded nil interface.
+type countingStore struct {
+	DatabaseStore
+	calls int
+	saved int
+}
+
+func (s *countingStore) SaveService(ctx context.Context, service *models.ServiceInfo) error {
+	s.calls++
+	s.saved++
+	return nil
+}
+
+func (s *countingStore) SaveSubscriptions(ctx context.Context, subscriberKey string, subscriptions []string) error {
+	return nil
+}
+
+// batchCountingStore simulates a store with multi-row upserts of BatchSize rows per statement
+type batchCountingStore struct {
+	countingStore
+}
+
+func (s *batchCountingStore) SaveServices(ctx context.Context, services []*models.ServiceInfo) error {
+	for start := 0; start < len(services); start += BatchSize {
+		s.calls++
+		s.saved += min(BatchSize, len(services)-start)
+	}
+	return nil
+}
+
+func populate(t *testing.T, d *DualStore, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		service := &models.ServiceInfo{ServiceName: "user-service", PodName: fmt.Sprintf("pod-%d", i)}
+		if err := d.cache.SaveService(context.Background(), service); err != nil {
+			t.Fatalf("Failed to populate cache: %v", err)
+		}
+	}
+}
+
+func TestSyncToDatabaseRoundTrips(t *testing.T) {
+	const services = 2000
+
+	// Fallback: one round-trip per service
+	fallback := &countingStore{}
+	d := NewDualStore(fallback)
+	populate(t, d, services)
+	if err := d.SyncToDatabase(context.Background()); err != nil {
+		t.Fatalf("SyncToDatabase failed: %v", err)
+	}
+
+	// Batch: one round-trip per BatchSize services
+	batch := &batchCountingStore{}
+	d = NewDualStore(batch)
+	populate(t, d, services)
+	if err := d.SyncToDatabase(context.Background()); err != nil {
+		t.Fatalf("SyncToDatabase failed: %v", err)
+	}
+
+	if fallback.saved != services || batch.saved != services {
+		t.Fatalf("Expected %d services saved, got fallback=%d batch=%d", services, fallback.saved, batch.saved)
+	}
+	if fallback.calls != services {
+		t.Errorf("Expected %d round-trips without batching, got %d", services, fallback.calls)
+	}
+
+	// 2000 services at 500 per statement: 4 round-trips instead of 2000
+	expected := (services + BatchSize - 1) / BatchSize
+	if batch.calls != expected {
+		t.Errorf("Expected %d round-trips with batching, got %d", expected, batch.calls)
+	}
+	t.Logf("Round-trips for %d services: %d without batching, %d with batching", services, fallback.calls, batch.calls)
+}
+
+// slowStore blocks SaveService until released
+type slowStore struct {
+	DatabaseStore
+	release chan struct{}
+}
+
+func (s *slowStore) SaveService(ctx context.Context, service *models.ServiceInfo) error {
+	<-s.release
+	return nil
+}
+
+func TestFlushWaitsForPendingWrites(t *testing.T) {
+	db := &slowStore{release: make(chan struct{})}
+	d := NewDualStore(db)
+
+	service := &models.ServiceInfo{ServiceName: "user-service", PodName: "pod-1"}
+	if err := d.SaveService(context.Background(), service); err != nil {
+		t.Fatalf("SaveService failed: %v", err)
+	}
+
+	// Write still blocked: Flush must time out
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := d.Flush(ctx); err == nil {
+		t.Fatal("Expected Flush to time out while a write is pending")
+	}
+
+	close(db.release)
+	if err := d.Flush(context.Background()); err != nil {
+		t.Errorf("Expected Flush to succeed after write completed, got %v", err)
+	}
+}
+
+// orderedStore blocks saves until released and records the order writes land in
+type orderedStore struct {
+	slowStore
+	mu     sync.Mutex
+	writes []string
+}
+
+func (s *orderedStore) SaveService(ctx context.Context, service *models.ServiceInfo) error {
+	s.slowStore.SaveService(ctx, service)
+	s.record("save")
+	return nil
+}
+
+func (s *orderedStore) UpdateHealthStatus(ctx context.Context, key string, status models.ServiceStatus, timestamp time.Time) error {
+	s.record("status " + string(status))
+	return nil
+}
+
+func (s *orderedStore) record(write string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes = append(s.writes, write)
+}
+
+func TestAsyncWritesKeepOrderPerService(t *testing.T) {
+	db := &orderedStore{slowStore: slowStore{release: make(chan struct{})}}
+	d := NewDualStore(db)
+	ctx := context.Background()
+
+	service := &models.ServiceInfo{ServiceName: "user-service", PodName: "pod-1", Status: models.StatusUnknown}
+	if err := d.SaveService(ctx, service); err != nil {
+		t.Fatalf("SaveService failed: %v", err)
+	}
+	if err := d.UpdateHealthStatus(ctx, service.GetKey(), models.StatusHealthy, time.Now()); err != nil {
+		t.Fatalf("UpdateHealthStatus failed: %v", err)
+	}
+
+	// The status update must not overtake the blocked save
+	time.Sleep(20 * time.Millisecond)
+	close(db.release)
+	if err := d.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if !slices.Equal(db.writes, []string{"save", "status healthy"}) {
+		t.Errorf("Expected the save before the status update, got %v", db.writes)
+	}
+}
+
+// failingStore fails every write
+type failingStore struct {
+	DatabaseStore
+	saves int
+}
+
+func (s *failingStore) SaveService(ctx context.Context, service *models.ServiceInfo) error {
+	s.saves++
+	return fmt.Errorf("connection refused")
+}
+
+func (s *failingStore) DeleteService(ctx context.Context, key string) error {
+	return fmt.Errorf("connection refused")
+}
+
+func TestSyncWritesReturnDatabaseErrors(t *testing.T) {
+	db := &failingStore{}
+	d := NewDualStore(db, WithSyncWrites())
+	ctx := context.Background()
+
+	service := &models.ServiceInfo{ServiceName: "user-service", PodName: "pod-1"}
+	if err := d.SaveService(ctx, service); err == nil {
+		t.Fatal("Expected SaveService to return the database error")
+	}
+	if _, err := d.GetService(ctx, service.GetKey()); err == nil {
+		t.Error("Expected failed write to leave the cache unchanged")
+	}
+
+	// Deleting a cached service keeps it cached when the database fails
+	populate(t, d, 1)
+	if err := d.DeleteService(ctx, "user-service:pod-0"); err == nil {
+		t.Fatal("Expected DeleteService to return the database error")
+	}
+	if _, err := d.GetService(ctx, "user-service:pod-0"); err != nil {
+		t.Error("Expected failed delete to keep the service cached")
+	}
+
+	// Invalid services are rejected before reaching the database
+	if err := d.SaveService(ctx, nil); err == nil {
+		t.Error("Expected nil service to be rejected")
+	}
+	if db.saves != 1 {
+		t.Errorf("Expected 1 database save, got %d", db.saves)
+	}
+}
+
+func TestAsyncWritesIgnoreDatabaseErrors(t *testing.T) {
+	db := &failingStore{}
+	d := NewDualStore(db)
+	ctx := context.Background()
+
+	service := &models.ServiceInfo{ServiceName: "user-service", PodName: "pod-1"}
+	if err := d.SaveService(ctx, service); err != nil {
+		t.Fatalf("Expected async SaveService to succeed, got %v", err)
+	}
+	if err := d.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if db.saves != 1 {
+		t.Errorf("Expected 1 database save, got %d", db.saves)
+	}
+	if _, err := d.GetService(ctx, service.GetKey()); err != nil {
+		t.Error("Expected service to be cached despite the database failure")
+	}
+}
@@ -0,0 +1,370 @@
+package etcd
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/chronnie/governance/models"
+	"github.com/chronnie/governance/storage"
+)
+
+// Config holds etcd connection configuration
+type Config struct {
+	Endpoints   []string
+	DialTimeout time.Duration
+	// Optional parameters
+	Username  string
+	Password  string
+	TLS       *tls.Config // Nil disables TLS
+	KeyPrefix string      // Root of all keys, defaults to /governance
+}
+
+// DatabaseStore implements storage.DatabaseStore using etcd.
+//
+// Layout under the key prefix:
+//
+//	<prefix>/services/<serviceName:podName>        ServiceInfo as JSON
+//	<prefix>/subscriptions/<subscriberKey>         []string of service groups as JSON
+//	<prefix>/changes/<zero-padded sequence>        change record as JSON
+//	<prefix>/counters/changes                      last assigned change sequence
+type DatabaseStore struct {
+	client *clientv3.Client
+	prefix string
+}
+
+// Ensure DatabaseStore implements storage.DatabaseStore
+var _ storage.DatabaseStore = (*DatabaseStore)(nil)
+
+// NewDatabaseStore creates a new etcd database store
+func NewDatabaseStore(cfg Config) (*DatabaseStore, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, fmt.Errorf("at least one endpoint is required")
+	}
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "/governance"
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   cfg.Endpoints,
+		DialTimeout: cfg.DialTimeout,
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+		TLS:         cfg.TLS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd client: %w", err)
+	}
+
+	store := &DatabaseStore{
+		client: client,
+		prefix: strings.TrimSuffix(cfg.KeyPrefix, "/"),
+	}
+
+	// Verify connection
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DialTimeout)
+	defer cancel()
+
+	if err := store.Ping(ctx); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to reach etcd: %w", err)
+	}
+
+	return store, nil
+}
+
+func (d *DatabaseStore) servicesPrefix() string      { return d.prefix + "/services/" }
+func (d *DatabaseStore) subscriptionsPrefix() string { return d.prefix + "/subscriptions/" }
+func (d *DatabaseStore) changesPrefix() string       { return d.prefix + "/changes/" }
+func (d *DatabaseStore) changesCounterKey() string   { return d.prefix + "/counters/changes" }
+
+// changeKey returns the key of a change record; sequences are zero-padded so
+// lexical key order matches numeric order for range reads
+func (d *DatabaseStore) changeKey(sequence int64) string {
+	return fmt.Sprintf("%s%020d", d.changesPrefix(), sequence)
+}
+
+// SaveService stores or updates a service entry
+func (d *DatabaseStore) SaveService(ctx context.Context, service *models.ServiceInfo) error {
+	if service == nil {
+		return fmt.Errorf("service cannot be nil")
+	}
+
+	key := service.GetKey()
+	if key == "" {
+		return fmt.Errorf("service key cannot be empty")
+	}
+
+	data, err := json.Marshal(service)
+	if err != nil {
+		return fmt.Errorf("failed to marshal service: %w", err)
+	}
+
+	if _, err := d.client.Put(ctx, d.servicesPrefix()+key, string(data)); err != nil {
+		return fmt.Errorf("failed to save service: %w", err)
+	}
+
+	return nil
+}
+
+// GetService retrieves a single service by its composite key
+func (d *DatabaseStore) GetService(ctx context.Context, key string) (*models.ServiceInfo, error) {
+	resp, err := d.client.Get(ctx, d.servicesPrefix()+key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, fmt.Errorf("service not found: %s", key)
+	}
+
+	var service models.ServiceInfo
+	if err := json.Unmarshal(resp.Kvs[0].Value, &service); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal service: %w", err)
+	}
+
+	return &service, nil
+}
+
+// GetAllServices retrieves all registered services with a single prefix range read
+func (d *DatabaseStore) GetAllServices(ctx context.Context) ([]*models.ServiceInfo, error) {
+	resp, err := d.client.Get(ctx, d.servicesPrefix(), clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query services: %w", err)
+	}
+
+	result := make([]*models.ServiceInfo, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var service models.ServiceInfo
+		if err := json.Unmarshal(kv.Value, &service); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal service %s: %w", kv.Key, err)
+		}
+		result = append(result, &service)
+	}
+
+	return result, nil
+}
+
+// DeleteService removes a service entry by its composite key
+func (d *DatabaseStore) DeleteService(ctx context.Context, key string) error {
+	resp, err := d.client.Delete(ctx, d.servicesPrefix()+key)
+	if err != nil {
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+	if resp.Deleted == 0 {
+		return fmt.Errorf("service not found: %s", key)
+	}
+
+	return nil
+}
+
+// UpdateHealthStatus updates the health status and last check timestamp.
+// The read-modify-write is guarded by a compare on the key's mod revision,
+// so a concurrent write from another manager is never overwritten with stale data.
+func (d *DatabaseStore) UpdateHealthStatus(ctx context.Context, key string, status models.ServiceStatus, timestamp time.Time) error {
+	etcdKey := d.servicesPrefix() + key
+
+	for {
+		resp, err := d.client.Get(ctx, etcdKey)
+		if err != nil {
+			return fmt.Errorf("failed to get service: %w", err)
+		}
+		if len(resp.Kvs) == 0 {
+			return fmt.Errorf("service not found: %s", key)
+		}
+
+		var service models.ServiceInfo
+		if err := json.Unmarshal(resp.Kvs[0].Value, &service); err != nil {
+			return fmt.Errorf("failed to unmarshal service: %w", err)
+		}
+		service.Status = status
+		service.LastHealthCheck = timestamp
+
+		data, err := json.Marshal(&service)
+		if err != nil {
+			return fmt.Errorf("failed to marshal service: %w", err)
+		}
+
+		txnResp, err := d.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(etcdKey), "=", resp.Kvs[0].ModRevision)).
+			Then(clientv3.OpPut(etcdKey, string(data))).
+			Commit()
+		if err != nil {
+			return fmt.Errorf("failed to update health status: %w", err)
+		}
+		if txnResp.Succeeded {
+			return nil
+		}
+		// Modified concurrently, retry with the latest value
+	}
+}
+
+// SaveSubscriptions saves all subscriptions for a service (replaces existing)
+func (d *DatabaseStore) SaveSubscriptions(ctx context.Context, subscriberKey string, subscriptions []string) error {
+	if len(subscriptions) == 0 {
+		return d.DeleteSubscriptions(ctx, subscriberKey)
+	}
+
+	data, err := json.Marshal(subscriptions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal subscriptions: %w", err)
+	}
+
+	if _, err := d.client.Put(ctx, d.subscriptionsPrefix()+subscriberKey, string(data)); err != nil {
+		return fmt.Errorf("failed to save subscriptions: %w", err)
+	}
+
+	return nil
+}
+
+// GetSubscriptions retrieves all service groups that a subscriber is subscribed to
+func (d *DatabaseStore) GetSubscriptions(ctx context.Context, subscriberKey string) ([]string, error) {
+	resp, err := d.client.Get(ctx, d.subscriptionsPrefix()+subscriberKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return []string{}, nil
+	}
+
+	var subscriptions []string
+	if err := json.Unmarshal(resp.Kvs[0].Value, &subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// GetAllSubscriptions retrieves all subscription relationships with a single prefix range read
+func (d *DatabaseStore) GetAllSubscriptions(ctx context.Context) (map[string][]string, error) {
+	prefix := d.subscriptionsPrefix()
+
+	resp, err := d.client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+
+	result := make(map[string][]string, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var subscriptions []string
+		if err := json.Unmarshal(kv.Value, &subscriptions); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal subscriptions %s: %w", kv.Key, err)
+		}
+		if len(subscriptions) > 0 {
+			result[strings.TrimPrefix(string(kv.Key), prefix)] = subscriptions
+		}
+	}
+
+	return result, nil
+}
+
+// DeleteSubscriptions removes all subscriptions for a subscriber
+func (d *DatabaseStore) DeleteSubscriptions(ctx context.Context, subscriberKey string) error {
+	if _, err := d.client.Delete(ctx, d.subscriptionsPrefix()+subscriberKey); err != nil {
+		return fmt.Errorf("failed to delete subscriptions: %w", err)
+	}
+	return nil
+}
+
+// AppendChange appends a record to the change feed and returns its sequence number.
+// The counter increment and the record write happen in one transaction that only
+// commits if the counter wasn't changed in between.
+func (d *DatabaseStore) AppendChange(ctx context.Context, change *models.ChangeRecord) (int64, error) {
+	if change == nil {
+		return 0, fmt.Errorf("change cannot be nil")
+	}
+
+	counterKey := d.changesCounterKey()
+
+	for {
+		resp, err := d.client.Get(ctx, counterKey)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read change sequence: %w", err)
+		}
+
+		var last int64
+		var modRevision int64
+		if len(resp.Kvs) > 0 {
+			last, err = strconv.ParseInt(string(resp.Kvs[0].Value), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid change sequence: %w", err)
+			}
+			modRevision = resp.Kvs[0].ModRevision
+		}
+
+		record := *change
+		record.Sequence = last + 1
+		data, err := json.Marshal(&record)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal change: %w", err)
+		}
+
+		// ModRevision 0 means the counter key doesn't exist yet
+		txnResp, err := d.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(counterKey), "=", modRevision)).
+			Then(
+				clientv3.OpPut(counterKey, strconv.FormatInt(record.Sequence, 10)),
+				clientv3.OpPut(d.changeKey(record.Sequence), string(data)),
+			).
+			Commit()
+		if err != nil {
+			return 0, fmt.Errorf("failed to append change: %w", err)
+		}
+		if txnResp.Succeeded {
+			return record.Sequence, nil
+		}
+		// Another writer took the sequence, retry
+	}
+}
+
+// GetChangesSince returns change records with a sequence greater than since
+func (d *DatabaseStore) GetChangesSince(ctx context.Context, since int64, limit int) ([]*models.ChangeRecord, error) {
+	resp, err := d.client.Get(ctx, d.changeKey(since+1),
+		clientv3.WithRange(clientv3.GetPrefixRangeEnd(d.changesPrefix())),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend),
+		clientv3.WithLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query changes: %w", err)
+	}
+
+	result := make([]*models.ChangeRecord, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var change models.ChangeRecord
+		if err := json.Unmarshal(kv.Value, &change); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal change %s: %w", kv.Key, err)
+		}
+		result = append(result, &change)
+	}
+
+	return result, nil
+}
+
+// Close closes the etcd client
+func (d *DatabaseStore) Close() error {
+	if d.client != nil {
+		return d.client.Close()
+	}
+	return nil
+}
+
+// Ping checks if etcd is accessible using the status API of the first reachable endpoint
+func (d *DatabaseStore) Ping(ctx context.Context) error {
+	var lastErr error
+	for _, endpoint := range d.client.Endpoints() {
+		if _, err := d.client.Status(ctx, endpoint); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("no etcd endpoint reachable: %w", lastErr)
+}
@@ -18,12 +18,16 @@ type RegistryStore interface {
 	// GetService retrieves a single service by its composite key (serviceName:podName)
 	GetService(ctx context.Context, key string) (*models.ServiceInfo, error)
 
-	// GetServicesByName retrieves all pods for a given service name
+	// GetServicesByName retrieves all pods for a given qualified service name
+	// (see models.QualifiedServiceName)
 	GetServicesByName(ctx context.Context, serviceName string) ([]*models.ServiceInfo, error)
 
 	// GetAllServices retrieves all registered services across all service groups
 	GetAllServices(ctx context.Context) ([]*models.ServiceInfo, error)
 
+	// GetServicesByStatus retrieves all services with the given health status
+	GetServicesByStatus(ctx context.Context, status models.ServiceStatus) ([]*models.ServiceInfo, error)
+
 	// DeleteService removes a service entry by its composite key
 	DeleteService(ctx context.Context, key string) error
 
@@ -43,7 +47,8 @@ type RegistryStore interface {
 	// RemoveAllSubscriptions removes all subscriptions for a given subscriber
 	RemoveAllSubscriptions(ctx context.Context, subscriberKey string) error
 
-	// GetSubscribers returns all subscriber keys for a given service group
+	// GetSubscribers returns all subscriber keys for a given service group, including
+	// subscribers of wildcard patterns matching it (see models.MatchSubscription)
 	GetSubscribers(ctx context.Context, serviceGroup string) ([]string, error)
 
 	// GetSubscriberServices returns full ServiceInfo objects for all subscribers of a service group
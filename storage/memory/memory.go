@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/chronnie/governance/models"
@@ -11,9 +12,10 @@ import (
 )
 
 // MemoryStore implements storage.RegistryStore using in-memory maps.
-// This is the default storage implementation and is lock-free since
-// it's accessed only by the single event queue worker.
+// This is the default storage implementation. It is safe for concurrent use:
+// HTTP handlers read it while event workers write to it.
 type MemoryStore struct {
+	mu            sync.RWMutex
 	services      map[string]*models.ServiceInfo // Key: "serviceName:podName"
 	subscriptions map[string][]string            // Key: serviceGroup, Value: list of subscriber keys
 }
@@ -42,6 +44,10 @@ func (m *MemoryStore) SaveService(ctx context.Context, service *models.ServiceIn
 
 	// Store a copy to avoid external mutations
 	serviceCopy := *service
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.services[key] = &serviceCopy
 
 	return nil
@@ -49,6 +55,14 @@ func (m *MemoryStore) SaveService(ctx context.Context, service *models.ServiceIn
 
 // GetService retrieves a single service by its composite key
 func (m *MemoryStore) GetService(ctx context.Context, key string) (*models.ServiceInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.getService(key)
+}
+
+// getService returns a copy of a service. Caller must hold m.mu.
+func (m *MemoryStore) getService(key string) (*models.ServiceInfo, error) {
 	service, exists := m.services[key]
 	if !exists {
 		return nil, fmt.Errorf("service not found: %s", key)
@@ -61,10 +75,13 @@ func (m *MemoryStore) GetService(ctx context.Context, key string) (*models.Servi
 
 // GetServicesByName retrieves all pods for a given service name
 func (m *MemoryStore) GetServicesByName(ctx context.Context, serviceName string) ([]*models.ServiceInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	var result []*models.ServiceInfo
 
 	for _, service := range m.services {
-		if service.ServiceName == serviceName {
+		if service.QualifiedName() == serviceName {
 			serviceCopy := *service
 			result = append(result, &serviceCopy)
 		}
@@ -75,6 +92,9 @@ func (m *MemoryStore) GetServicesByName(ctx context.Context, serviceName string)
 
 // GetAllServices retrieves all registered services
 func (m *MemoryStore) GetAllServices(ctx context.Context) ([]*models.ServiceInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	result := make([]*models.ServiceInfo, 0, len(m.services))
 
 	for _, service := range m.services {
@@ -85,8 +105,28 @@ func (m *MemoryStore) GetAllServices(ctx context.Context) ([]*models.ServiceInfo
 	return result, nil
 }
 
+// GetServicesByStatus retrieves all services with the given health status
+func (m *MemoryStore) GetServicesByStatus(ctx context.Context, status models.ServiceStatus) ([]*models.ServiceInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*models.ServiceInfo
+
+	for _, service := range m.services {
+		if service.Status == status {
+			serviceCopy := *service
+			result = append(result, &serviceCopy)
+		}
+	}
+
+	return result, nil
+}
+
 // DeleteService removes a service entry by its composite key
 func (m *MemoryStore) DeleteService(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if _, exists := m.services[key]; !exists {
 		return fmt.Errorf("service not found: %s", key)
 	}
@@ -97,6 +137,9 @@ func (m *MemoryStore) DeleteService(ctx context.Context, key string) error {
 
 // UpdateHealthStatus updates the health status and last check timestamp
 func (m *MemoryStore) UpdateHealthStatus(ctx context.Context, key string, status models.ServiceStatus, timestamp time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	service, exists := m.services[key]
 	if !exists {
 		return fmt.Errorf("service not found: %s", key)
@@ -117,6 +160,9 @@ func (m *MemoryStore) AddSubscription(ctx context.Context, subscriberKey string,
 		return errors.New("service group cannot be empty")
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	subscribers := m.subscriptions[serviceGroup]
 
 	// Check if already subscribed
@@ -133,9 +179,18 @@ func (m *MemoryStore) AddSubscription(ctx context.Context, subscriberKey string,
 
 // RemoveSubscription removes a subscriber from a service group
 func (m *MemoryStore) RemoveSubscription(ctx context.Context, subscriberKey string, serviceGroup string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removeSubscription(subscriberKey, serviceGroup)
+	return nil
+}
+
+// removeSubscription removes a subscriber from a service group. Caller must hold m.mu.
+func (m *MemoryStore) removeSubscription(subscriberKey string, serviceGroup string) {
 	subscribers, exists := m.subscriptions[serviceGroup]
 	if !exists {
-		return nil // No subscriptions for this service group
+		return // No subscriptions for this service group
 	}
 
 	// Find and remove the subscriber
@@ -148,46 +203,48 @@ func (m *MemoryStore) RemoveSubscription(ctx context.Context, subscriberKey stri
 				delete(m.subscriptions, serviceGroup)
 			}
 
-			return nil
+			return
 		}
 	}
 
-	return nil // Subscriber not found, but that's okay
+	// Subscriber not found, but that's okay
 }
 
 // RemoveAllSubscriptions removes all subscriptions for a given subscriber
 func (m *MemoryStore) RemoveAllSubscriptions(ctx context.Context, subscriberKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	for serviceGroup := range m.subscriptions {
-		m.RemoveSubscription(ctx, subscriberKey, serviceGroup)
+		m.removeSubscription(subscriberKey, serviceGroup)
 	}
 	return nil
 }
 
 // GetSubscribers returns all subscriber keys for a given service group
 func (m *MemoryStore) GetSubscribers(ctx context.Context, serviceGroup string) ([]string, error) {
-	subscribers, exists := m.subscriptions[serviceGroup]
-	if !exists {
-		return []string{}, nil
-	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-	// Return a copy to avoid external mutations
-	result := make([]string, len(subscribers))
-	copy(result, subscribers)
+	return m.getSubscribers(serviceGroup), nil
+}
 
-	return result, nil
+// getSubscribers returns a copy of a service group's subscriber keys, including subscribers
+// of matching wildcard patterns. Caller must hold m.mu.
+func (m *MemoryStore) getSubscribers(serviceGroup string) []string {
+	return storage.MatchingSubscribers(m.subscriptions, serviceGroup)
 }
 
 // GetSubscriberServices returns full ServiceInfo objects for all subscribers
 func (m *MemoryStore) GetSubscriberServices(ctx context.Context, serviceGroup string) ([]*models.ServiceInfo, error) {
-	subscribers, err := m.GetSubscribers(ctx, serviceGroup)
-	if err != nil {
-		return nil, err
-	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
+	subscribers := m.getSubscribers(serviceGroup)
 	result := make([]*models.ServiceInfo, 0, len(subscribers))
 
 	for _, subscriberKey := range subscribers {
-		service, err := m.GetService(ctx, subscriberKey)
+		service, err := m.getService(subscriberKey)
 		if err != nil {
 			// Skip services that no longer exist
 			continue
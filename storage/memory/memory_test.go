@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chronnie/governance/models"
+)
+
+// TestMemoryStoreConcurrentAccess hammers the store from many goroutines.
+// Run with -race to detect unsynchronized map access.
+func TestMemoryStoreConcurrentAccess(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	const goroutines = 16
+	const iterations = 200
+
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		serviceName := "service-" + strconv.Itoa(g%4)
+
+		// Writers
+		wg.Go(func() {
+			for i := range iterations {
+				service := &models.ServiceInfo{
+					ServiceName: serviceName,
+					PodName:     "pod-" + strconv.Itoa(g) + "-" + strconv.Itoa(i%10),
+					Status:      models.StatusUnknown,
+				}
+				key := service.GetKey()
+
+				store.SaveService(ctx, service)
+				store.AddSubscription(ctx, key, "service-"+strconv.Itoa(i%4))
+				store.UpdateHealthStatus(ctx, key, models.StatusHealthy, time.Now())
+				if i%3 == 0 {
+					store.RemoveSubscription(ctx, key, "service-"+strconv.Itoa(i%4))
+				}
+				if i%5 == 0 {
+					store.RemoveAllSubscriptions(ctx, key)
+					store.DeleteService(ctx, key)
+				}
+			}
+		})
+
+		// Readers
+		wg.Go(func() {
+			for range iterations {
+				store.GetAllServices(ctx)
+				store.GetServicesByName(ctx, serviceName)
+				store.GetService(ctx, serviceName+":pod-0-0")
+				store.GetSubscribers(ctx, serviceName)
+				store.GetSubscriberServices(ctx, serviceName)
+			}
+		})
+	}
+	wg.Wait()
+
+	// Every pod that wasn't deleted last must still be consistent
+	services, err := store.GetAllServices(ctx)
+	if err != nil {
+		t.Fatalf("GetAllServices failed: %v", err)
+	}
+	for _, service := range services {
+		if service.Status != models.StatusHealthy {
+			t.Errorf("Expected %s to be healthy, got %s", service.GetKey(), service.Status)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package mongodb
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -25,35 +26,93 @@ type Config struct {
 
 // DatabaseStore implements storage.DatabaseStore using MongoDB
 type DatabaseStore struct {
+	mu      sync.RWMutex
+	current *connection // Replaced by Reconnect
+	cfg     Config
+}
+
+// connection is a MongoDB client with the collections used by the store
+type connection struct {
 	client             *mongo.Client
 	database           *mongo.Database
 	servicesCollection *mongo.Collection
+	changesCollection  *mongo.Collection
+	countersCollection *mongo.Collection
 }
 
-// Ensure DatabaseStore implements storage.DatabaseStore
-var _ storage.DatabaseStore = (*DatabaseStore)(nil)
+// Ensure DatabaseStore implements storage.DatabaseStore and storage.ReconnectingDatabaseStore
+var (
+	_ storage.DatabaseStore             = (*DatabaseStore)(nil)
+	_ storage.ReconnectingDatabaseStore = (*DatabaseStore)(nil)
+)
 
 // serviceDoc represents the MongoDB document structure for services
 type serviceDoc struct {
-	ServiceKey      string                `bson:"_id"`
-	ServiceName     string                `bson:"service_name"`
-	PodName         string                `bson:"pod_name"`
-	Providers       []models.ProviderInfo `bson:"providers"`
-	HealthCheckURL  string                `bson:"health_check_url"`
-	NotificationURL string                `bson:"notification_url"`
-	Subscriptions   []string              `bson:"subscriptions"`
-	Status          models.ServiceStatus  `bson:"status"`
-	LastHealthCheck time.Time             `bson:"last_health_check"`
-	RegisteredAt    time.Time             `bson:"registered_at"`
-	UpdatedAt       time.Time             `bson:"updated_at"`
+	ServiceKey         string                      `bson:"_id"`
+	Namespace          string                      `bson:"namespace,omitempty"`
+	ServiceName        string                      `bson:"service_name"`
+	PodName            string                      `bson:"pod_name"`
+	Providers          []models.ProviderInfo       `bson:"providers"`
+	HealthCheckURL     string                      `bson:"health_check_url"`
+	HealthCheckMethod  string                      `bson:"health_check_method,omitempty"`
+	HealthCheckHeaders map[string]string           `bson:"health_check_headers,omitempty"`
+	HealthCheckMatch   *models.HealthCheckMatch    `bson:"health_check_match,omitempty"`
+	HealthChecks       []models.HealthCheck        `bson:"health_checks,omitempty"`
+	HealthCheckMode    models.HealthCheckMode      `bson:"health_check_mode,omitempty"`
+	UDPProbe           *models.UDPProbe            `bson:"udp_probe,omitempty"`
+	NotificationURL    string                      `bson:"notification_url"`
+	NotificationURLs   map[models.EventType]string `bson:"notification_urls,omitempty"`
+	Subscriptions      []string                    `bson:"subscriptions"`
+	NotificationFormat models.NotificationFormat   `bson:"notification_format,omitempty"`
+	Labels             map[string]string           `bson:"labels,omitempty"`
+	Status             models.ServiceStatus        `bson:"status"`
+	LastHealthCheck    time.Time                   `bson:"last_health_check"`
+	RegisteredAt       time.Time                   `bson:"registered_at"`
+	DeletedAt          time.Time                   `bson:"deleted_at,omitempty"`
+	TTLSeconds         int                         `bson:"ttl_seconds,omitempty"`
+	ExpiresAt          time.Time                   `bson:"expires_at,omitempty"`
+	Revision           int64                       `bson:"revision,omitempty"`
+	UpdatedAt          time.Time                   `bson:"updated_at"`
+}
+
+// changeDoc represents the MongoDB document structure for change feed records
+type changeDoc struct {
+	Sequence    int64                `bson:"_id"`
+	EventType   models.EventType     `bson:"event_type"`
+	ServiceKey  string               `bson:"service_key"`
+	ServiceName string               `bson:"service_name"`
+	PodName     string               `bson:"pod_name"`
+	Status      models.ServiceStatus `bson:"status"`
+	CreatedAt   time.Time            `bson:"created_at"`
 }
 
+// changesCounterID is the counters document holding the last change sequence
+const changesCounterID = "service_changes"
+
 // NewDatabaseStore creates a new MongoDB database store and initializes collections
 func NewDatabaseStore(cfg Config) (*DatabaseStore, error) {
 	if cfg.ConnectTimeout == 0 {
 		cfg.ConnectTimeout = 10 * time.Second
 	}
 
+	conn, err := connect(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &DatabaseStore{current: conn, cfg: cfg}
+
+	// Create indexes
+	if err := store.createIndexes(context.Background()); err != nil {
+		conn.client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return store, nil
+}
+
+// connect creates a client for cfg and verifies it with a ping
+func connect(ctx context.Context, cfg Config) (*connection, error) {
 	clientOpts := options.Client().ApplyURI(cfg.URI)
 
 	if cfg.MaxPoolSize > 0 {
@@ -64,7 +123,7 @@ func NewDatabaseStore(cfg Config) (*DatabaseStore, error) {
 		clientOpts.SetMinPoolSize(cfg.MinPoolSize)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
+	ctx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
 	defer cancel()
 
 	client, err := mongo.Connect(ctx, clientOpts)
@@ -74,25 +133,45 @@ func NewDatabaseStore(cfg Config) (*DatabaseStore, error) {
 
 	// Ping to verify connection
 	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
 		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
 	}
 
 	database := client.Database(cfg.Database)
-	servicesCollection := database.Collection("services")
-
-	store := &DatabaseStore{
+	return &connection{
 		client:             client,
 		database:           database,
-		servicesCollection: servicesCollection,
-	}
+		servicesCollection: database.Collection("services"),
+		changesCollection:  database.Collection("service_changes"),
+		countersCollection: database.Collection("counters"),
+	}, nil
+}
 
-	// Create indexes
-	if err := store.createIndexes(context.Background()); err != nil {
-		client.Disconnect(context.Background())
-		return nil, fmt.Errorf("failed to create indexes: %w", err)
+// conn returns the current client
+func (d *DatabaseStore) conn() *connection {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.current
+}
+
+// Reconnect replaces the client with a new one, e.g. after MongoDB became unreachable for
+// longer than the driver's own server selection retries. The old client is disconnected
+// once the new one answered a ping; if connecting fails the old client is kept.
+func (d *DatabaseStore) Reconnect(ctx context.Context) error {
+	conn, err := connect(ctx, d.cfg)
+	if err != nil {
+		return err
 	}
 
-	return store, nil
+	d.mu.Lock()
+	old := d.current
+	d.current = conn
+	d.mu.Unlock()
+
+	disconnectCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	old.client.Disconnect(disconnectCtx) // Stale anyway, in-flight operations finish on the old client or fail
+	return nil
 }
 
 // createIndexes creates necessary indexes for optimal query performance
@@ -109,7 +188,7 @@ func (d *DatabaseStore) createIndexes(ctx context.Context) error {
 		},
 	}
 
-	if _, err := d.servicesCollection.Indexes().CreateMany(ctx, servicesIndexes); err != nil {
+	if _, err := d.conn().servicesCollection.Indexes().CreateMany(ctx, servicesIndexes); err != nil {
 		return fmt.Errorf("failed to create services indexes: %w", err)
 	}
 
@@ -119,32 +198,60 @@ func (d *DatabaseStore) createIndexes(ctx context.Context) error {
 // toServiceDoc converts ServiceInfo to serviceDoc
 func toServiceDoc(service *models.ServiceInfo) *serviceDoc {
 	return &serviceDoc{
-		ServiceKey:      service.GetKey(),
-		ServiceName:     service.ServiceName,
-		PodName:         service.PodName,
-		Providers:       service.Providers,
-		HealthCheckURL:  service.HealthCheckURL,
-		NotificationURL: service.NotificationURL,
-		Subscriptions:   service.Subscriptions,
-		Status:          service.Status,
-		LastHealthCheck: service.LastHealthCheck,
-		RegisteredAt:    service.RegisteredAt,
-		UpdatedAt:       time.Now(),
+		ServiceKey:         service.GetKey(),
+		Namespace:          service.Namespace,
+		ServiceName:        service.ServiceName,
+		PodName:            service.PodName,
+		Providers:          service.Providers,
+		HealthCheckURL:     service.HealthCheckURL,
+		HealthCheckMethod:  service.HealthCheckMethod,
+		HealthCheckHeaders: service.HealthCheckHeaders,
+		HealthCheckMatch:   service.HealthCheckMatch,
+		HealthChecks:       service.HealthChecks,
+		HealthCheckMode:    service.HealthCheckMode,
+		UDPProbe:           service.UDPProbe,
+		NotificationURL:    service.NotificationURL,
+		NotificationURLs:   service.NotificationURLs,
+		Subscriptions:      service.Subscriptions,
+		NotificationFormat: service.NotificationFormat,
+		Labels:             service.Labels,
+		Status:             service.Status,
+		LastHealthCheck:    service.LastHealthCheck,
+		RegisteredAt:       service.RegisteredAt,
+		DeletedAt:          service.DeletedAt,
+		TTLSeconds:         service.TTLSeconds,
+		ExpiresAt:          service.ExpiresAt,
+		Revision:           service.Revision,
+		UpdatedAt:          time.Now(),
 	}
 }
 
 // toServiceInfo converts serviceDoc to ServiceInfo
 func (doc *serviceDoc) toServiceInfo() *models.ServiceInfo {
 	return &models.ServiceInfo{
-		ServiceName:     doc.ServiceName,
-		PodName:         doc.PodName,
-		Providers:       doc.Providers,
-		HealthCheckURL:  doc.HealthCheckURL,
-		NotificationURL: doc.NotificationURL,
-		Subscriptions:   doc.Subscriptions,
-		Status:          doc.Status,
-		LastHealthCheck: doc.LastHealthCheck,
-		RegisteredAt:    doc.RegisteredAt,
+		Namespace:          doc.Namespace,
+		ServiceName:        doc.ServiceName,
+		PodName:            doc.PodName,
+		Providers:          doc.Providers,
+		HealthCheckURL:     doc.HealthCheckURL,
+		HealthCheckMethod:  doc.HealthCheckMethod,
+		HealthCheckHeaders: doc.HealthCheckHeaders,
+		HealthCheckMatch:   doc.HealthCheckMatch,
+		HealthChecks:       doc.HealthChecks,
+		HealthCheckMode:    doc.HealthCheckMode,
+		UDPProbe:           doc.UDPProbe,
+		NotificationURL:    doc.NotificationURL,
+		NotificationURLs:   doc.NotificationURLs,
+		Subscriptions:      doc.Subscriptions,
+		NotificationFormat: doc.NotificationFormat,
+		Labels:             doc.Labels,
+		Status:             doc.Status,
+		LastHealthCheck:    doc.LastHealthCheck,
+		RegisteredAt:       doc.RegisteredAt,
+		DeletedAt:          doc.DeletedAt,
+		TTLSeconds:         doc.TTLSeconds,
+		ExpiresAt:          doc.ExpiresAt,
+		Revision:           doc.Revision,
 	}
 }
 
@@ -162,7 +269,7 @@ func (d *DatabaseStore) SaveService(ctx context.Context, service *models.Service
 	doc := toServiceDoc(service)
 
 	opts := options.Replace().SetUpsert(true)
-	_, err := d.servicesCollection.ReplaceOne(
+	_, err := d.conn().servicesCollection.ReplaceOne(
 		ctx,
 		bson.M{"_id": key},
 		doc,
@@ -180,7 +287,7 @@ func (d *DatabaseStore) SaveService(ctx context.Context, service *models.Service
 func (d *DatabaseStore) GetService(ctx context.Context, key string) (*models.ServiceInfo, error) {
 	var doc serviceDoc
 
-	err := d.servicesCollection.FindOne(ctx, bson.M{"_id": key}).Decode(&doc)
+	err := d.conn().servicesCollection.FindOne(ctx, bson.M{"_id": key}).Decode(&doc)
 	if err == mongo.ErrNoDocuments {
 		return nil, fmt.Errorf("service not found: %s", key)
 	}
@@ -198,7 +305,7 @@ func (d *DatabaseStore) GetAllServices(ctx context.Context) ([]*models.ServiceIn
 		{Key: "pod_name", Value: 1},
 	})
 
-	cursor, err := d.servicesCollection.Find(ctx, bson.M{}, opts)
+	cursor, err := d.conn().servicesCollection.Find(ctx, bson.M{}, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query services: %w", err)
 	}
@@ -223,7 +330,7 @@ func (d *DatabaseStore) GetAllServices(ctx context.Context) ([]*models.ServiceIn
 
 // DeleteService removes a service entry by its composite key
 func (d *DatabaseStore) DeleteService(ctx context.Context, key string) error {
-	result, err := d.servicesCollection.DeleteOne(ctx, bson.M{"_id": key})
+	result, err := d.conn().servicesCollection.DeleteOne(ctx, bson.M{"_id": key})
 	if err != nil {
 		return fmt.Errorf("failed to delete service: %w", err)
 	}
@@ -245,7 +352,7 @@ func (d *DatabaseStore) UpdateHealthStatus(ctx context.Context, key string, stat
 		},
 	}
 
-	result, err := d.servicesCollection.UpdateOne(ctx, bson.M{"_id": key}, update)
+	result, err := d.conn().servicesCollection.UpdateOne(ctx, bson.M{"_id": key}, update)
 	if err != nil {
 		return fmt.Errorf("failed to update health status: %w", err)
 	}
@@ -268,7 +375,7 @@ func (d *DatabaseStore) SaveSubscriptions(ctx context.Context, subscriberKey str
 func (d *DatabaseStore) GetSubscriptions(ctx context.Context, subscriberKey string) ([]string, error) {
 	var doc serviceDoc
 
-	err := d.servicesCollection.FindOne(ctx, bson.M{"_id": subscriberKey}).Decode(&doc)
+	err := d.conn().servicesCollection.FindOne(ctx, bson.M{"_id": subscriberKey}).Decode(&doc)
 	if err == mongo.ErrNoDocuments {
 		return []string{}, nil
 	}
@@ -281,7 +388,7 @@ func (d *DatabaseStore) GetSubscriptions(ctx context.Context, subscriberKey stri
 
 // GetAllSubscriptions retrieves all subscription relationships
 func (d *DatabaseStore) GetAllSubscriptions(ctx context.Context) (map[string][]string, error) {
-	cursor, err := d.servicesCollection.Find(ctx, bson.M{})
+	cursor, err := d.conn().servicesCollection.Find(ctx, bson.M{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
 	}
@@ -314,17 +421,91 @@ func (d *DatabaseStore) DeleteSubscriptions(ctx context.Context, subscriberKey s
 	return nil
 }
 
+// AppendChange appends a record to the change feed and returns its sequence number.
+// Sequences come from an atomically incremented counter document.
+func (d *DatabaseStore) AppendChange(ctx context.Context, change *models.ChangeRecord) (int64, error) {
+	if change == nil {
+		return 0, fmt.Errorf("change cannot be nil")
+	}
+
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := d.conn().countersCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": changesCounterID},
+		bson.M{"$inc": bson.M{"seq": int64(1)}},
+		opts,
+	).Decode(&counter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate change sequence: %w", err)
+	}
+
+	doc := &changeDoc{
+		Sequence:    counter.Seq,
+		EventType:   change.EventType,
+		ServiceKey:  change.ServiceKey,
+		ServiceName: change.ServiceName,
+		PodName:     change.PodName,
+		Status:      change.Status,
+		CreatedAt:   change.Timestamp,
+	}
+	if _, err := d.conn().changesCollection.InsertOne(ctx, doc); err != nil {
+		return 0, fmt.Errorf("failed to append change: %w", err)
+	}
+
+	return counter.Seq, nil
+}
+
+// GetChangesSince returns change records with a sequence greater than since
+func (d *DatabaseStore) GetChangesSince(ctx context.Context, since int64, limit int) ([]*models.ChangeRecord, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := d.conn().changesCollection.Find(ctx, bson.M{"_id": bson.M{"$gt": since}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query changes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var result []*models.ChangeRecord
+
+	for cursor.Next(ctx) {
+		var doc changeDoc
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode change: %w", err)
+		}
+		result = append(result, &models.ChangeRecord{
+			Sequence:    doc.Sequence,
+			Timestamp:   doc.CreatedAt,
+			EventType:   doc.EventType,
+			ServiceKey:  doc.ServiceKey,
+			ServiceName: doc.ServiceName,
+			PodName:     doc.PodName,
+			Status:      doc.Status,
+		})
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return result, nil
+}
+
 // Close closes the MongoDB connection
 func (d *DatabaseStore) Close() error {
-	if d.client != nil {
+	if conn := d.conn(); conn != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		return d.client.Disconnect(ctx)
+		return conn.client.Disconnect(ctx)
 	}
 	return nil
 }
 
 // Ping checks if the database is accessible
 func (d *DatabaseStore) Ping(ctx context.Context) error {
-	return d.client.Ping(ctx, nil)
+	return d.conn().client.Ping(ctx, nil)
 }
@@ -0,0 +1,75 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/chronnie/governance/storage"
+)
+
+// changeStreamsNotSupported is the server error code for change streams on a standalone mongod
+const changeStreamsNotSupported = 40573
+
+// Ensure DatabaseStore can stream changes
+var _ storage.WatchingDatabaseStore = (*DatabaseStore)(nil)
+
+// serviceWatch is a change stream on the services collection
+type serviceWatch struct {
+	stream *mongo.ChangeStream
+}
+
+// changeEvent is the part of a change stream event the watch needs
+type changeEvent struct {
+	DocumentKey struct {
+		ServiceKey string `bson:"_id"`
+	} `bson:"documentKey"`
+}
+
+// WatchServices opens a change stream on the services collection.
+// Change streams need a replica set or sharded cluster; on a standalone server it returns
+// storage.ErrWatchUnsupported.
+func (d *DatabaseStore) WatchServices(ctx context.Context) (storage.ServiceWatch, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{
+			{Key: "operationType", Value: bson.D{{Key: "$in", Value: bson.A{"insert", "update", "replace", "delete"}}}},
+		}}},
+		{{Key: "$project", Value: bson.D{{Key: "documentKey", Value: 1}}}},
+	}
+
+	stream, err := d.conn().servicesCollection.Watch(ctx, pipeline, options.ChangeStream())
+	if err != nil {
+		var serverErr mongo.ServerError
+		if errors.As(err, &serverErr) && serverErr.HasErrorCode(changeStreamsNotSupported) {
+			return nil, fmt.Errorf("%w: %v", storage.ErrWatchUnsupported, err)
+		}
+		return nil, fmt.Errorf("failed to open change stream: %w", err)
+	}
+
+	return &serviceWatch{stream: stream}, nil
+}
+
+// Next blocks until a service document changes and returns its key
+func (w *serviceWatch) Next(ctx context.Context) (string, error) {
+	if !w.stream.Next(ctx) {
+		if err := w.stream.Err(); err != nil {
+			return "", fmt.Errorf("change stream failed: %w", err)
+		}
+		return "", fmt.Errorf("change stream closed")
+	}
+
+	var event changeEvent
+	if err := w.stream.Decode(&event); err != nil {
+		return "", fmt.Errorf("failed to decode change event: %w", err)
+	}
+	return event.DocumentKey.ServiceKey, nil
+}
+
+// Close closes the change stream
+func (w *serviceWatch) Close(ctx context.Context) error {
+	return w.stream.Close(ctx)
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -24,21 +26,82 @@ type Config struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	ConnectRetries  int           // Extra attempts to reach the database on startup (0 = fail immediately)
+	ConnectBackoff  time.Duration // Delay before the first retry, doubled per retry (0 = 1s)
+	SkipMigrations  bool          // Don't run Migrate in NewDatabaseStore, e.g. when the schema is managed separately
+	// ReplicaDSN optionally points at a read replica, e.g. "user:password@tcp(host:3306)/db?parseTime=true"
+	// (parseTime is required). GetService, GetAllServices, GetServicesByStatus and GetAllSubscriptions
+	// read from it, everything else uses the primary. Empty = read from the primary.
+	ReplicaDSN string
 }
 
 // DatabaseStore implements storage.DatabaseStore using MySQL
 type DatabaseStore struct {
-	db *sql.DB
+	mu      sync.RWMutex
+	db      *sql.DB // Current connection pool, replaced by Reconnect
+	replica *sql.DB // Read replica pool, nil without ReplicaDSN
+	cfg     Config
 }
 
-// Ensure DatabaseStore implements storage.DatabaseStore
-var _ storage.DatabaseStore = (*DatabaseStore)(nil)
+// Ensure DatabaseStore implements storage.DatabaseStore, storage.BatchDatabaseStore and
+// storage.ReconnectingDatabaseStore
+var (
+	_ storage.DatabaseStore             = (*DatabaseStore)(nil)
+	_ storage.BatchDatabaseStore        = (*DatabaseStore)(nil)
+	_ storage.ReconnectingDatabaseStore = (*DatabaseStore)(nil)
+)
 
 // NewDatabaseStore creates a new MySQL database store and initializes tables
 func NewDatabaseStore(cfg Config) (*DatabaseStore, error) {
+	db, err := open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &DatabaseStore{db: db, cfg: cfg}
+
+	// Wait for the database, it may still be starting when the manager comes up
+	if err := storage.PingWithRetry(context.Background(), store.Ping, cfg.ConnectRetries, cfg.ConnectBackoff); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// Bring the schema up to date
+	if !cfg.SkipMigrations {
+		if err := store.Migrate(context.Background()); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to migrate schema: %w", err)
+		}
+	}
+
+	if cfg.ReplicaDSN != "" {
+		replica, err := openDSN(cfg.ReplicaDSN, cfg)
+		if err == nil {
+			err = storage.PingWithRetry(context.Background(), replica.PingContext, cfg.ConnectRetries, cfg.ConnectBackoff)
+			if err != nil {
+				replica.Close()
+			}
+		}
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to connect to read replica: %w", err)
+		}
+		store.replica = replica
+	}
+
+	return store, nil
+}
+
+// open creates a connection pool for the primary database of cfg
+func open(cfg Config) (*sql.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&loc=Local",
 		cfg.Username, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
 
+	return openDSN(dsn, cfg)
+}
+
+// openDSN creates a connection pool for dsn with the pool settings of cfg
+func openDSN(dsn string, cfg Config) (*sql.DB, error) {
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -63,19 +126,63 @@ func NewDatabaseStore(cfg Config) (*DatabaseStore, error) {
 		db.SetConnMaxLifetime(5 * time.Minute)
 	}
 
-	store := &DatabaseStore{db: db}
+	return db, nil
+}
 
-	// Initialize tables
-	if err := store.initTables(context.Background()); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to initialize tables: %w", err)
+// migration is a versioned schema change applied by Migrate
+type migration struct {
+	version     int
+	description string
+	apply       func(d *DatabaseStore, ctx context.Context) error
+}
+
+// migrations are applied in version order, each at most once.
+// Append new migrations to the end; never change one that has been released.
+var migrations = []migration{
+	{version: 1, description: "services and change feed tables", apply: (*DatabaseStore).createInitialSchema},
+	{version: 2, description: "service TTL", apply: (*DatabaseStore).addServiceTTL},
+	{version: 3, description: "multiple health checks", apply: (*DatabaseStore).addHealthChecks},
+	{version: 4, description: "notification format", apply: (*DatabaseStore).addNotificationFormat},
+	{version: 5, description: "notification URL overrides", apply: (*DatabaseStore).addNotificationURLs},
+	{version: 6, description: "service revision", apply: (*DatabaseStore).addServiceRevision},
+	{version: 7, description: "namespaces", apply: (*DatabaseStore).addNamespace},
+}
+
+// Migrate creates or upgrades the schema by applying the migrations newer than the version
+// recorded in schema_migrations. It is idempotent and safe to run on every start, also by
+// several managers at once: every migration only creates what is missing.
+func (d *DatabaseStore) Migrate(ctx context.Context) error {
+	_, err := d.conn().ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
 	}
 
-	return store, nil
+	var current int
+	if err := d.conn().QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := m.apply(d, ctx); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+		if _, err := d.conn().ExecContext(ctx, `INSERT IGNORE INTO schema_migrations (version) VALUES (?)`, m.version); err != nil {
+			return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+		}
+	}
+
+	return nil
 }
 
-// initTables creates the necessary database tables if they don't exist
-func (d *DatabaseStore) initTables(ctx context.Context) error {
+// createInitialSchema creates the tables if they don't exist and adds columns missing from
+// tables created by versions before schema_migrations was introduced
+func (d *DatabaseStore) createInitialSchema(ctx context.Context) error {
 	queries := []string{
 		// Services table
 		`CREATE TABLE IF NOT EXISTS services (
@@ -86,6 +193,12 @@ func (d *DatabaseStore) initTables(ctx context.Context) error {
 			health_check_url VARCHAR(512) NOT NULL,
 			notification_url VARCHAR(512) NOT NULL,
 			subscriptions JSON NOT NULL,
+			labels JSON,
+			deleted_at DATETIME(6) NULL,
+			health_check_method VARCHAR(10) NOT NULL DEFAULT '',
+			health_check_headers JSON,
+			health_check_match JSON,
+			udp_probe JSON,
 			status VARCHAR(20) NOT NULL,
 			last_health_check DATETIME NOT NULL,
 			registered_at DATETIME NOT NULL,
@@ -93,14 +206,110 @@ func (d *DatabaseStore) initTables(ctx context.Context) error {
 			INDEX idx_service_name (service_name),
 			INDEX idx_status (status)
 		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+
+		// Append-only change feed
+		`CREATE TABLE IF NOT EXISTS service_changes (
+			sequence BIGINT AUTO_INCREMENT PRIMARY KEY,
+			event_type VARCHAR(20) NOT NULL,
+			service_key VARCHAR(255) NOT NULL,
+			service_name VARCHAR(128) NOT NULL,
+			pod_name VARCHAR(128) NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			created_at DATETIME(6) NOT NULL
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
 	}
 
 	for _, query := range queries {
-		if _, err := d.db.ExecContext(ctx, query); err != nil {
+		if _, err := d.conn().ExecContext(ctx, query); err != nil {
 			return fmt.Errorf("failed to execute query: %w", err)
 		}
 	}
 
+	// Tables created before labels were added
+	if err := d.addColumnIfMissing(ctx, "services", "labels", "JSON NULL"); err != nil {
+		return err
+	}
+
+	// Tables created before soft-delete was added
+	if err := d.addColumnIfMissing(ctx, "services", "deleted_at", "DATETIME(6) NULL"); err != nil {
+		return err
+	}
+
+	// Tables created before health check method and headers were added
+	if err := d.addColumnIfMissing(ctx, "services", "health_check_method", "VARCHAR(10) NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing(ctx, "services", "health_check_headers", "JSON NULL"); err != nil {
+		return err
+	}
+
+	// Tables created before health check body matching was added
+	if err := d.addColumnIfMissing(ctx, "services", "health_check_match", "JSON NULL"); err != nil {
+		return err
+	}
+
+	// Tables created before UDP probes were added
+	if err := d.addColumnIfMissing(ctx, "services", "udp_probe", "JSON NULL"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// addServiceTTL adds the registration TTL and expiry of services
+func (d *DatabaseStore) addServiceTTL(ctx context.Context) error {
+	if err := d.addColumnIfMissing(ctx, "services", "ttl_seconds", "INT NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	return d.addColumnIfMissing(ctx, "services", "expires_at", "DATETIME(6) NULL")
+}
+
+// addHealthChecks adds the additional HTTP health checks of services and how they combine
+func (d *DatabaseStore) addHealthChecks(ctx context.Context) error {
+	if err := d.addColumnIfMissing(ctx, "services", "health_checks", "JSON NULL"); err != nil {
+		return err
+	}
+	return d.addColumnIfMissing(ctx, "services", "health_check_mode", "VARCHAR(10) NOT NULL DEFAULT ''")
+}
+
+// addNotificationFormat adds the notification payload format subscribers receive
+func (d *DatabaseStore) addNotificationFormat(ctx context.Context) error {
+	return d.addColumnIfMissing(ctx, "services", "notification_format", "VARCHAR(10) NOT NULL DEFAULT ''")
+}
+
+// addNotificationURLs adds the per event type notification URLs of subscribers
+func (d *DatabaseStore) addNotificationURLs(ctx context.Context) error {
+	return d.addColumnIfMissing(ctx, "services", "notification_urls", "JSON NULL")
+}
+
+// addServiceRevision adds the revision the registry stamps on every write of a service
+func (d *DatabaseStore) addServiceRevision(ctx context.Context) error {
+	return d.addColumnIfMissing(ctx, "services", "revision", "BIGINT NOT NULL DEFAULT 0")
+}
+
+// addNamespace adds the namespace scoping a service's name and subscriptions
+func (d *DatabaseStore) addNamespace(ctx context.Context) error {
+	return d.addColumnIfMissing(ctx, "services", "namespace", "VARCHAR(63) NOT NULL DEFAULT ''")
+}
+
+// addColumnIfMissing adds a column to an existing table.
+// MySQL has no ADD COLUMN IF NOT EXISTS, so the schema is checked first.
+func (d *DatabaseStore) addColumnIfMissing(ctx context.Context, table, column, definition string) error {
+	var count int
+	err := d.conn().QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?`,
+		table, column).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check column %s.%s: %w", table, column, err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if _, err := d.conn().ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
 	return nil
 }
 
@@ -126,22 +335,66 @@ func (d *DatabaseStore) SaveService(ctx context.Context, service *models.Service
 		return fmt.Errorf("failed to marshal subscriptions: %w", err)
 	}
 
+	labelsJSON, err := json.Marshal(service.Labels)
+	if err != nil {
+		return fmt.Errorf("failed to marshal labels: %w", err)
+	}
+
+	headersJSON, err := json.Marshal(service.HealthCheckHeaders)
+	if err != nil {
+		return fmt.Errorf("failed to marshal health check headers: %w", err)
+	}
+
+	matchJSON, err := json.Marshal(service.HealthCheckMatch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal health check match: %w", err)
+	}
+
+	probeJSON, err := json.Marshal(service.UDPProbe)
+	if err != nil {
+		return fmt.Errorf("failed to marshal udp probe: %w", err)
+	}
+
+	checksJSON, err := json.Marshal(service.HealthChecks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal health checks: %w", err)
+	}
+
+	urlsJSON, err := json.Marshal(service.NotificationURLs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification urls: %w", err)
+	}
+
 	query := `INSERT INTO services
 		(service_key, service_name, pod_name, providers, health_check_url, notification_url,
-		 subscriptions, status, last_health_check, registered_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 subscriptions, status, last_health_check, registered_at, labels, deleted_at, health_check_method, health_check_headers, health_check_match, udp_probe, ttl_seconds, expires_at, health_checks, health_check_mode, notification_format, notification_urls, revision, namespace)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE
 		providers = VALUES(providers),
 		health_check_url = VALUES(health_check_url),
 		notification_url = VALUES(notification_url),
 		subscriptions = VALUES(subscriptions),
 		status = VALUES(status),
-		last_health_check = VALUES(last_health_check)`
-
-	_, err = d.db.ExecContext(ctx, query,
+		last_health_check = VALUES(last_health_check),
+		labels = VALUES(labels),
+		deleted_at = VALUES(deleted_at),
+		health_check_method = VALUES(health_check_method),
+		health_check_headers = VALUES(health_check_headers),
+		health_check_match = VALUES(health_check_match),
+		udp_probe = VALUES(udp_probe),
+		ttl_seconds = VALUES(ttl_seconds),
+		expires_at = VALUES(expires_at),
+		health_checks = VALUES(health_checks),
+		health_check_mode = VALUES(health_check_mode),
+		notification_format = VALUES(notification_format),
+		notification_urls = VALUES(notification_urls),
+		revision = VALUES(revision),
+		namespace = VALUES(namespace)`
+
+	_, err = d.conn().ExecContext(ctx, query,
 		key, service.ServiceName, service.PodName,
 		providersJSON, service.HealthCheckURL, service.NotificationURL,
-		subscriptionsJSON, service.Status, service.LastHealthCheck, service.RegisteredAt)
+		subscriptionsJSON, service.Status, service.LastHealthCheck, service.RegisteredAt, labelsJSON, nullTime(service.DeletedAt), service.HealthCheckMethod, headersJSON, matchJSON, probeJSON, service.TTLSeconds, nullTime(service.ExpiresAt), checksJSON, service.HealthCheckMode, service.NotificationFormat, urlsJSON, service.Revision, service.Namespace)
 
 	if err != nil {
 		return fmt.Errorf("failed to save service: %w", err)
@@ -150,19 +403,125 @@ func (d *DatabaseStore) SaveService(ctx context.Context, service *models.Service
 	return nil
 }
 
+// SaveServices stores or updates services with multi-row upserts,
+// one statement per storage.BatchSize services, in a single transaction
+func (d *DatabaseStore) SaveServices(ctx context.Context, services []*models.ServiceInfo) error {
+	tx, err := d.conn().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(services); start += storage.BatchSize {
+		chunk := services[start:min(start+storage.BatchSize, len(services))]
+
+		placeholders := make([]string, 0, len(chunk))
+		args := make([]interface{}, 0, len(chunk)*24)
+
+		for _, service := range chunk {
+			if service == nil {
+				return fmt.Errorf("service cannot be nil")
+			}
+
+			providersJSON, err := json.Marshal(service.Providers)
+			if err != nil {
+				return fmt.Errorf("failed to marshal providers: %w", err)
+			}
+
+			subscriptionsJSON, err := json.Marshal(service.Subscriptions)
+			if err != nil {
+				return fmt.Errorf("failed to marshal subscriptions: %w", err)
+			}
+
+			labelsJSON, err := json.Marshal(service.Labels)
+			if err != nil {
+				return fmt.Errorf("failed to marshal labels: %w", err)
+			}
+
+			headersJSON, err := json.Marshal(service.HealthCheckHeaders)
+			if err != nil {
+				return fmt.Errorf("failed to marshal health check headers: %w", err)
+			}
+
+			matchJSON, err := json.Marshal(service.HealthCheckMatch)
+			if err != nil {
+				return fmt.Errorf("failed to marshal health check match: %w", err)
+			}
+
+			probeJSON, err := json.Marshal(service.UDPProbe)
+			if err != nil {
+				return fmt.Errorf("failed to marshal udp probe: %w", err)
+			}
+
+			checksJSON, err := json.Marshal(service.HealthChecks)
+			if err != nil {
+				return fmt.Errorf("failed to marshal health checks: %w", err)
+			}
+
+			urlsJSON, err := json.Marshal(service.NotificationURLs)
+			if err != nil {
+				return fmt.Errorf("failed to marshal notification urls: %w", err)
+			}
+
+			placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+			args = append(args,
+				service.GetKey(), service.ServiceName, service.PodName,
+				providersJSON, service.HealthCheckURL, service.NotificationURL,
+				subscriptionsJSON, service.Status, service.LastHealthCheck, service.RegisteredAt, labelsJSON, nullTime(service.DeletedAt), service.HealthCheckMethod, headersJSON, matchJSON, probeJSON, service.TTLSeconds, nullTime(service.ExpiresAt), checksJSON, service.HealthCheckMode, service.NotificationFormat, urlsJSON, service.Revision, service.Namespace)
+		}
+
+		query := `INSERT INTO services
+		(service_key, service_name, pod_name, providers, health_check_url, notification_url,
+		 subscriptions, status, last_health_check, registered_at, labels, deleted_at, health_check_method, health_check_headers, health_check_match, udp_probe, ttl_seconds, expires_at, health_checks, health_check_mode, notification_format, notification_urls, revision, namespace)
+		VALUES ` + strings.Join(placeholders, ", ") + `
+		ON DUPLICATE KEY UPDATE
+		providers = VALUES(providers),
+		health_check_url = VALUES(health_check_url),
+		notification_url = VALUES(notification_url),
+		subscriptions = VALUES(subscriptions),
+		status = VALUES(status),
+		last_health_check = VALUES(last_health_check),
+		labels = VALUES(labels),
+		deleted_at = VALUES(deleted_at),
+		health_check_method = VALUES(health_check_method),
+		health_check_headers = VALUES(health_check_headers),
+		health_check_match = VALUES(health_check_match),
+		udp_probe = VALUES(udp_probe),
+		ttl_seconds = VALUES(ttl_seconds),
+		expires_at = VALUES(expires_at),
+		health_checks = VALUES(health_checks),
+		health_check_mode = VALUES(health_check_mode),
+		notification_format = VALUES(notification_format),
+		notification_urls = VALUES(notification_urls),
+		revision = VALUES(revision),
+		namespace = VALUES(namespace)`
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to save services: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit services: %w", err)
+	}
+
+	return nil
+}
+
 // GetService retrieves a single service by its composite key
 func (d *DatabaseStore) GetService(ctx context.Context, key string) (*models.ServiceInfo, error) {
 	query := `SELECT service_name, pod_name, providers, health_check_url, notification_url,
-		subscriptions, status, last_health_check, registered_at
+		subscriptions, status, last_health_check, registered_at, labels, deleted_at, health_check_method, health_check_headers, health_check_match, udp_probe, ttl_seconds, expires_at, health_checks, health_check_mode, notification_format, notification_urls, revision, namespace
 		FROM services WHERE service_key = ?`
 
 	var service models.ServiceInfo
-	var providersJSON, subscriptionsJSON []byte
+	var providersJSON, subscriptionsJSON, labelsJSON, headersJSON, matchJSON, probeJSON, checksJSON, urlsJSON []byte
+	var deletedAt, expiresAt sql.NullTime
 
-	err := d.db.QueryRowContext(ctx, query, key).Scan(
+	err := d.readConn().QueryRowContext(ctx, query, key).Scan(
 		&service.ServiceName, &service.PodName,
 		&providersJSON, &service.HealthCheckURL, &service.NotificationURL,
-		&subscriptionsJSON, &service.Status, &service.LastHealthCheck, &service.RegisteredAt)
+		&subscriptionsJSON, &service.Status, &service.LastHealthCheck, &service.RegisteredAt, &labelsJSON, &deletedAt, &service.HealthCheckMethod, &headersJSON, &matchJSON, &probeJSON, &service.TTLSeconds, &expiresAt, &checksJSON, &service.HealthCheckMode, &service.NotificationFormat, &urlsJSON, &service.Revision, &service.Namespace)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("service not found: %s", key)
@@ -180,17 +539,67 @@ func (d *DatabaseStore) GetService(ctx context.Context, key string) (*models.Ser
 		return nil, fmt.Errorf("failed to unmarshal subscriptions: %w", err)
 	}
 
+	// Rows written before labels existed have NULL labels
+	if len(labelsJSON) > 0 {
+		if err := json.Unmarshal(labelsJSON, &service.Labels); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal labels: %w", err)
+		}
+	}
+
+	if len(headersJSON) > 0 {
+		if err := json.Unmarshal(headersJSON, &service.HealthCheckHeaders); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal health check headers: %w", err)
+		}
+	}
+
+	if len(matchJSON) > 0 {
+		if err := json.Unmarshal(matchJSON, &service.HealthCheckMatch); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal health check match: %w", err)
+		}
+	}
+
+	if len(probeJSON) > 0 {
+		if err := json.Unmarshal(probeJSON, &service.UDPProbe); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal udp probe: %w", err)
+		}
+	}
+
+	if len(checksJSON) > 0 {
+		if err := json.Unmarshal(checksJSON, &service.HealthChecks); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal health checks: %w", err)
+		}
+	}
+
+	if len(urlsJSON) > 0 {
+		if err := json.Unmarshal(urlsJSON, &service.NotificationURLs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal notification urls: %w", err)
+		}
+	}
+
+	service.DeletedAt = deletedAt.Time
+	service.ExpiresAt = expiresAt.Time
+
 	return &service, nil
 }
 
 // GetAllServices retrieves all registered services
 func (d *DatabaseStore) GetAllServices(ctx context.Context) ([]*models.ServiceInfo, error) {
+	return d.queryServices(ctx, "")
+}
+
+// GetServicesByStatus retrieves all services with the given health status
+func (d *DatabaseStore) GetServicesByStatus(ctx context.Context, status models.ServiceStatus) ([]*models.ServiceInfo, error) {
+	return d.queryServices(ctx, "WHERE status = ?", status)
+}
+
+// queryServices retrieves the services matching an optional WHERE clause, ordered by key
+func (d *DatabaseStore) queryServices(ctx context.Context, where string, args ...any) ([]*models.ServiceInfo, error) {
 	query := `SELECT service_name, pod_name, providers, health_check_url, notification_url,
-		subscriptions, status, last_health_check, registered_at
-		FROM services
+		subscriptions, status, last_health_check, registered_at, labels, deleted_at, health_check_method, health_check_headers, health_check_match, udp_probe, ttl_seconds, expires_at, health_checks, health_check_mode, notification_format, notification_urls, revision, namespace
+		FROM services ` + where + `
 		ORDER BY service_name, pod_name`
 
-	rows, err := d.db.QueryContext(ctx, query)
+	rows, err := d.readConn().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query services: %w", err)
 	}
@@ -200,12 +609,13 @@ func (d *DatabaseStore) GetAllServices(ctx context.Context) ([]*models.ServiceIn
 
 	for rows.Next() {
 		var service models.ServiceInfo
-		var providersJSON, subscriptionsJSON []byte
+		var providersJSON, subscriptionsJSON, labelsJSON, headersJSON, matchJSON, probeJSON, checksJSON, urlsJSON []byte
+		var deletedAt, expiresAt sql.NullTime
 
 		err := rows.Scan(
 			&service.ServiceName, &service.PodName,
 			&providersJSON, &service.HealthCheckURL, &service.NotificationURL,
-			&subscriptionsJSON, &service.Status, &service.LastHealthCheck, &service.RegisteredAt)
+			&subscriptionsJSON, &service.Status, &service.LastHealthCheck, &service.RegisteredAt, &labelsJSON, &deletedAt, &service.HealthCheckMethod, &headersJSON, &matchJSON, &probeJSON, &service.TTLSeconds, &expiresAt, &checksJSON, &service.HealthCheckMode, &service.NotificationFormat, &urlsJSON, &service.Revision, &service.Namespace)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan service: %w", err)
@@ -220,6 +630,46 @@ func (d *DatabaseStore) GetAllServices(ctx context.Context) ([]*models.ServiceIn
 			return nil, fmt.Errorf("failed to unmarshal subscriptions: %w", err)
 		}
 
+		// Rows written before labels existed have NULL labels
+		if len(labelsJSON) > 0 {
+			if err := json.Unmarshal(labelsJSON, &service.Labels); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal labels: %w", err)
+			}
+		}
+
+		if len(headersJSON) > 0 {
+			if err := json.Unmarshal(headersJSON, &service.HealthCheckHeaders); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal health check headers: %w", err)
+			}
+		}
+
+		if len(matchJSON) > 0 {
+			if err := json.Unmarshal(matchJSON, &service.HealthCheckMatch); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal health check match: %w", err)
+			}
+		}
+
+		if len(probeJSON) > 0 {
+			if err := json.Unmarshal(probeJSON, &service.UDPProbe); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal udp probe: %w", err)
+			}
+		}
+
+		if len(checksJSON) > 0 {
+			if err := json.Unmarshal(checksJSON, &service.HealthChecks); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal health checks: %w", err)
+			}
+		}
+
+		if len(urlsJSON) > 0 {
+			if err := json.Unmarshal(urlsJSON, &service.NotificationURLs); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal notification urls: %w", err)
+			}
+		}
+
+		service.DeletedAt = deletedAt.Time
+		service.ExpiresAt = expiresAt.Time
+
 		result = append(result, &service)
 	}
 
@@ -234,7 +684,7 @@ func (d *DatabaseStore) GetAllServices(ctx context.Context) ([]*models.ServiceIn
 func (d *DatabaseStore) DeleteService(ctx context.Context, key string) error {
 	query := `DELETE FROM services WHERE service_key = ?`
 
-	result, err := d.db.ExecContext(ctx, query, key)
+	result, err := d.conn().ExecContext(ctx, query, key)
 	if err != nil {
 		return fmt.Errorf("failed to delete service: %w", err)
 	}
@@ -255,7 +705,7 @@ func (d *DatabaseStore) DeleteService(ctx context.Context, key string) error {
 func (d *DatabaseStore) UpdateHealthStatus(ctx context.Context, key string, status models.ServiceStatus, timestamp time.Time) error {
 	query := `UPDATE services SET status = ?, last_health_check = ? WHERE service_key = ?`
 
-	result, err := d.db.ExecContext(ctx, query, status, timestamp, key)
+	result, err := d.conn().ExecContext(ctx, query, status, timestamp, key)
 	if err != nil {
 		return fmt.Errorf("failed to update health status: %w", err)
 	}
@@ -285,7 +735,7 @@ func (d *DatabaseStore) GetSubscriptions(ctx context.Context, subscriberKey stri
 	query := `SELECT subscriptions FROM services WHERE service_key = ?`
 
 	var subscriptionsJSON []byte
-	err := d.db.QueryRowContext(ctx, query, subscriberKey).Scan(&subscriptionsJSON)
+	err := d.conn().QueryRowContext(ctx, query, subscriberKey).Scan(&subscriptionsJSON)
 
 	if err == sql.ErrNoRows {
 		return []string{}, nil
@@ -306,7 +756,7 @@ func (d *DatabaseStore) GetSubscriptions(ctx context.Context, subscriberKey stri
 func (d *DatabaseStore) GetAllSubscriptions(ctx context.Context) (map[string][]string, error) {
 	query := `SELECT service_key, subscriptions FROM services`
 
-	rows, err := d.db.QueryContext(ctx, query)
+	rows, err := d.readConn().QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
 	}
@@ -346,15 +796,116 @@ func (d *DatabaseStore) DeleteSubscriptions(ctx context.Context, subscriberKey s
 	return nil
 }
 
-// Close closes the database connection
+// AppendChange appends a record to the change feed and returns its sequence number
+func (d *DatabaseStore) AppendChange(ctx context.Context, change *models.ChangeRecord) (int64, error) {
+	if change == nil {
+		return 0, fmt.Errorf("change cannot be nil")
+	}
+
+	query := `INSERT INTO service_changes
+		(event_type, service_key, service_name, pod_name, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+
+	result, err := d.conn().ExecContext(ctx, query,
+		change.EventType, change.ServiceKey, change.ServiceName,
+		change.PodName, change.Status, change.Timestamp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to append change: %w", err)
+	}
+
+	sequence, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get change sequence: %w", err)
+	}
+
+	return sequence, nil
+}
+
+// GetChangesSince returns change records with a sequence greater than since
+func (d *DatabaseStore) GetChangesSince(ctx context.Context, since int64, limit int) ([]*models.ChangeRecord, error) {
+	query := `SELECT sequence, event_type, service_key, service_name, pod_name, status, created_at
+		FROM service_changes
+		WHERE sequence > ?
+		ORDER BY sequence
+		LIMIT ?`
+
+	rows, err := d.conn().QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query changes: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*models.ChangeRecord
+
+	for rows.Next() {
+		var change models.ChangeRecord
+		if err := rows.Scan(&change.Sequence, &change.EventType, &change.ServiceKey,
+			&change.ServiceName, &change.PodName, &change.Status, &change.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan change: %w", err)
+		}
+		result = append(result, &change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// Close closes the database connections
 func (d *DatabaseStore) Close() error {
-	if d.db != nil {
-		return d.db.Close()
+	if d.replica != nil {
+		d.replica.Close()
+	}
+	if db := d.conn(); db != nil {
+		return db.Close()
 	}
 	return nil
 }
 
 // Ping checks if the database is accessible
 func (d *DatabaseStore) Ping(ctx context.Context) error {
-	return d.db.PingContext(ctx)
+	return d.conn().PingContext(ctx)
+}
+
+// Reconnect replaces the connection pool with a new one, e.g. after the database restarted.
+// The old pool is closed once the new one answers a ping; on failure it is kept.
+// The read replica pool is not replaced.
+func (d *DatabaseStore) Reconnect(ctx context.Context) error {
+	db, err := open(d.cfg)
+	if err != nil {
+		return err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to reconnect: %w", err)
+	}
+
+	d.mu.Lock()
+	old := d.db
+	d.db = db
+	d.mu.Unlock()
+
+	return old.Close()
+}
+
+// conn returns the current connection pool
+func (d *DatabaseStore) conn() *sql.DB {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.db
+}
+
+// readConn returns the pool for read-only queries: the replica if configured, else the primary
+func (d *DatabaseStore) readConn() *sql.DB {
+	if d.replica != nil {
+		return d.replica
+	}
+	return d.conn()
+}
+
+// nullTime maps the zero time to NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
 }
@@ -0,0 +1,12 @@
+package storage
+
+import "context"
+
+// ReconnectingDatabaseStore is implemented by database stores that can replace their
+// connection pool, e.g. after the database restarted and every pooled connection went stale.
+// Stores whose driver reconnects on its own don't need it.
+type ReconnectingDatabaseStore interface {
+	// Reconnect opens a new connection pool, checks it with a ping and swaps it in for the
+	// current one, which is then closed. On failure the current pool is kept.
+	Reconnect(ctx context.Context) error
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chronnie/governance/models"
+)
+
+// repairLog remembers services whose asynchronous database write failed. Only keys are kept:
+// a repair writes whatever the cache holds at that point, which also covers any later
+// changes to the same service.
+// Failures are recorded from background writes, so access is guarded by a mutex.
+type repairLog struct {
+	mu           sync.Mutex
+	keys         map[string]struct{}
+	repaired     uint64
+	failedWrites uint64
+}
+
+func newRepairLog() *repairLog {
+	return &repairLog{keys: make(map[string]struct{})}
+}
+
+// add records a failed write for key
+func (l *repairLog) add(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.keys[key] = struct{}{}
+	l.failedWrites++
+}
+
+// pending returns the keys waiting to be repaired
+func (l *repairLog) pending() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	keys := make([]string, 0, len(l.keys))
+	for key := range l.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// has reports whether key is waiting to be repaired
+func (l *repairLog) has(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, exists := l.keys[key]
+	return exists
+}
+
+// done records the outcome of a retry for key
+func (l *repairLog) done(key string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err != nil {
+		l.failedWrites++
+		return
+	}
+	delete(l.keys, key)
+	l.repaired++
+}
+
+func (l *repairLog) stats() models.RepairStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return models.RepairStats{
+		Pending:      len(l.keys),
+		Repaired:     l.repaired,
+		FailedWrites: l.failedWrites,
+	}
+}
+
+// PendingRepairs returns how many services have a failed asynchronous database write
+// waiting to be retried
+func (d *DualStore) PendingRepairs() int {
+	return d.repairs.stats().Pending
+}
+
+// RepairStats returns counters of failed asynchronous database writes and their retries
+func (d *DualStore) RepairStats() models.RepairStats {
+	return d.repairs.stats()
+}
+
+// RetryFailedWrites brings the database back in line with the cache for every service whose
+// asynchronous write failed: services still cached are saved, others deleted. It stops at the
+// first failure, as the database is most likely still unavailable, and returns how many
+// services were repaired. Like other writes it must be called from the event worker.
+func (d *DualStore) RetryFailedWrites(ctx context.Context) (int, error) {
+	if d.db == nil {
+		return 0, nil
+	}
+
+	repaired := 0
+	for _, key := range d.repairs.pending() {
+		err := d.repair(ctx, key)
+		d.repairs.done(key, err)
+		if err != nil {
+			return repaired, err
+		}
+		repaired++
+	}
+	return repaired, nil
+}
+
+// repair writes the cached state of key to the database
+func (d *DualStore) repair(ctx context.Context, key string) error {
+	if service, err := d.cache.GetService(ctx, key); err == nil {
+		return d.db.SaveService(ctx, service)
+	}
+
+	// No longer cached, so it must not be in the database either. Deleted without looking
+	// it up first, a lookup may be served by a read replica that is behind.
+	if err := d.db.DeleteService(ctx, key); err != nil {
+		return d.db.Ping(ctx) // A reachable database means it is already gone
+	}
+	return nil
+}
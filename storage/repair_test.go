@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chronnie/governance/models"
+)
+
+// flakyStore keeps services in a map and fails every call while down
+type flakyStore struct {
+	DatabaseStore
+	mu       sync.Mutex
+	down     bool
+	services map[string]*models.ServiceInfo
+}
+
+func newFlakyStore() *flakyStore {
+	return &flakyStore{services: make(map[string]*models.ServiceInfo)}
+}
+
+func (s *flakyStore) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+func (s *flakyStore) SaveService(ctx context.Context, service *models.ServiceInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return fmt.Errorf("connection refused")
+	}
+	serviceCopy := *service
+	s.services[service.GetKey()] = &serviceCopy
+	return nil
+}
+
+func (s *flakyStore) GetService(ctx context.Context, key string) (*models.ServiceInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return nil, fmt.Errorf("connection refused")
+	}
+	service, exists := s.services[key]
+	if !exists {
+		return nil, fmt.Errorf("service not found: %s", key)
+	}
+	return service, nil
+}
+
+func (s *flakyStore) DeleteService(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return fmt.Errorf("connection refused")
+	}
+	if _, exists := s.services[key]; !exists {
+		return fmt.Errorf("service not found: %s", key)
+	}
+	delete(s.services, key)
+	return nil
+}
+
+func (s *flakyStore) UpdateHealthStatus(ctx context.Context, key string, status models.ServiceStatus, timestamp time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return fmt.Errorf("connection refused")
+	}
+	service, exists := s.services[key]
+	if !exists {
+		return fmt.Errorf("service not found: %s", key)
+	}
+	service.Status = status
+	service.LastHealthCheck = timestamp
+	return nil
+}
+
+func (s *flakyStore) GetAllServices(ctx context.Context) ([]*models.ServiceInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]*models.ServiceInfo, 0, len(s.services))
+	for _, service := range s.services {
+		serviceCopy := *service
+		result = append(result, &serviceCopy)
+	}
+	return result, nil
+}
+
+func (s *flakyStore) GetAllSubscriptions(ctx context.Context) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func (s *flakyStore) Ping(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return fmt.Errorf("connection refused")
+	}
+	return nil
+}
+
+func TestRetryFailedWritesRepairsDatabase(t *testing.T) {
+	db := newFlakyStore()
+	d := NewDualStore(db)
+	ctx := context.Background()
+
+	// Both pods are in the database before it goes down
+	for _, pod := range []string{"pod-1", "pod-2"} {
+		d.SaveService(ctx, &models.ServiceInfo{ServiceName: "user-service", PodName: pod, Status: models.StatusUnknown})
+	}
+	d.Flush(ctx)
+
+	db.setDown(true)
+	d.UpdateHealthStatus(ctx, "user-service:pod-1", models.StatusHealthy, time.Now())
+	d.DeleteService(ctx, "user-service:pod-2")
+	d.SaveService(ctx, &models.ServiceInfo{ServiceName: "user-service", PodName: "pod-3"})
+	d.Flush(ctx)
+
+	if stats := d.RepairStats(); stats.Pending != 3 || stats.FailedWrites != 3 {
+		t.Fatalf("Expected 3 pending repairs after 3 failed writes, got %+v", stats)
+	}
+
+	// Still down: the pass stops at the first failure
+	if _, err := d.RetryFailedWrites(ctx); err == nil {
+		t.Fatal("Expected retry to fail while the database is down")
+	}
+	if stats := d.RepairStats(); stats.Pending != 3 || stats.FailedWrites != 4 {
+		t.Fatalf("Expected repairs to stay pending, got %+v", stats)
+	}
+
+	db.setDown(false)
+	repaired, err := d.RetryFailedWrites(ctx)
+	if err != nil {
+		t.Fatalf("RetryFailedWrites failed: %v", err)
+	}
+	if repaired != 3 {
+		t.Errorf("Expected 3 repairs, got %d", repaired)
+	}
+	if stats := d.RepairStats(); stats.Pending != 0 || stats.Repaired != 3 {
+		t.Errorf("Expected no pending and 3 repaired, got %+v", stats)
+	}
+
+	if service, err := db.GetService(ctx, "user-service:pod-1"); err != nil || service.Status != models.StatusHealthy {
+		t.Errorf("Expected pod-1 to be healthy in the database, got %+v (%v)", service, err)
+	}
+	if _, err := db.GetService(ctx, "user-service:pod-2"); err == nil {
+		t.Error("Expected pod-2 to be deleted from the database")
+	}
+	if _, err := db.GetService(ctx, "user-service:pod-3"); err != nil {
+		t.Error("Expected pod-3 to be saved to the database")
+	}
+
+	// Nothing left to do
+	if repaired, err := d.RetryFailedWrites(ctx); err != nil || repaired != 0 {
+		t.Errorf("Expected an empty pass, got %d (%v)", repaired, err)
+	}
+}
+
+func TestSyncFromDatabaseSkipsPendingRepairs(t *testing.T) {
+	db := newFlakyStore()
+	d := NewDualStore(db)
+	ctx := context.Background()
+
+	d.SaveService(ctx, &models.ServiceInfo{ServiceName: "user-service", PodName: "pod-1", Status: models.StatusUnknown})
+	d.Flush(ctx)
+
+	db.setDown(true)
+	d.UpdateHealthStatus(ctx, "user-service:pod-1", models.StatusUnhealthy, time.Now())
+	d.Flush(ctx)
+	db.setDown(false)
+
+	// The database still has the old status, which must not overwrite the cache
+	if _, _, err := d.SyncFromDatabase(ctx); err != nil {
+		t.Fatalf("SyncFromDatabase failed: %v", err)
+	}
+	service, err := d.GetService(ctx, "user-service:pod-1")
+	if err != nil {
+		t.Fatalf("GetService failed: %v", err)
+	}
+	if service.Status != models.StatusUnhealthy {
+		t.Errorf("Expected cached status to survive the sync, got %s", service.Status)
+	}
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"slices"
+
+	"github.com/chronnie/governance/models"
+)
+
+// MatchingSubscribers returns the subscribers of serviceGroup from a subscription map keyed
+// by subscribed service group or pattern (see models.MatchSubscription), for stores that keep
+// subscriptions in memory. Exact subscribers come first in subscription order, then those of
+// matching patterns in pattern order; a subscriber matched several times is listed once.
+func MatchingSubscribers(subscriptions map[string][]string, serviceGroup string) []string {
+	result := append([]string{}, subscriptions[serviceGroup]...)
+
+	var patterns []string
+	for subscription := range subscriptions {
+		if subscription != serviceGroup && models.IsSubscriptionPattern(subscription) &&
+			models.MatchSubscription(subscription, serviceGroup) {
+			patterns = append(patterns, subscription)
+		}
+	}
+	if len(patterns) == 0 {
+		return result
+	}
+	slices.Sort(patterns)
+
+	seen := make(map[string]bool, len(result))
+	for _, subscriberKey := range result {
+		seen[subscriberKey] = true
+	}
+	for _, pattern := range patterns {
+		for _, subscriberKey := range subscriptions[pattern] {
+			if !seen[subscriberKey] {
+				seen[subscriberKey] = true
+				result = append(result, subscriberKey)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrWatchUnsupported is returned by WatchServices when the database cannot stream changes,
+// e.g. a standalone MongoDB server. Reconcile then keeps reloading the whole database.
+var ErrWatchUnsupported = errors.New("database does not support watching changes")
+
+// WatchingDatabaseStore is implemented by database stores that can stream changes to stored
+// services as they happen, including changes made by other managers or by hand. While a watch
+// is open, only the services that changed need to be reloaded instead of the whole database.
+type WatchingDatabaseStore interface {
+	// WatchServices opens a stream of changes to stored services.
+	// Returns ErrWatchUnsupported if the database cannot stream changes.
+	WatchServices(ctx context.Context) (ServiceWatch, error)
+}
+
+// ServiceWatch is an open stream of changes to stored services
+type ServiceWatch interface {
+	// Next blocks until a service is saved or deleted and returns its key.
+	// Returns an error once the stream breaks or ctx is done; open a new watch to continue.
+	Next(ctx context.Context) (string, error)
+
+	// Close closes the stream
+	Close(ctx context.Context) error
+}
+
+// inflightLog tracks asynchronous database writes still running per service and chains
+// them, so writes of the same service land in the order they were made. Otherwise a slow
+// save could overwrite a later health status update with stale data.
+// Writes finish in the background, so access is guarded by a mutex.
+type inflightLog struct {
+	mu   sync.Mutex
+	keys map[string]*inflightWrites
+}
+
+// inflightWrites are the unfinished writes of one service
+type inflightWrites struct {
+	count int
+	last  chan struct{} // Closed when the most recent write finishes
+}
+
+func newInflightLog() *inflightLog {
+	return &inflightLog{keys: make(map[string]*inflightWrites)}
+}
+
+// start records a write for key that has not finished yet. The write must wait for previous
+// (nil if there is none) before running and call finish with done afterwards.
+func (l *inflightLog) start(key string) (previous <-chan struct{}, done chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	writes, exists := l.keys[key]
+	if !exists {
+		writes = &inflightWrites{}
+		l.keys[key] = writes
+	}
+	previous, done = writes.last, make(chan struct{})
+	writes.last = done
+	writes.count++
+	return previous, done
+}
+
+// finish records that the write for key started with done finished
+func (l *inflightLog) finish(key string, done chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	close(done)
+	if writes := l.keys[key]; writes != nil {
+		if writes.count--; writes.count <= 0 {
+			delete(l.keys, key)
+		}
+	}
+}
+
+// has reports whether a write for key is still running
+func (l *inflightLog) has(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.keys[key] != nil
+}
+
+// SyncServicesFromDatabase reloads the given services from the database into the cache, e.g.
+// after a database watch reported them changed, and returns how many were reloaded. Services
+// no longer in the database are removed from the cache.
+// Services with an asynchronous write still running or waiting for repair are skipped, the
+// database is behind the cache for them; a running write reports its own change once it lands.
+// Like other writes it must be called from the event worker.
+func (d *DualStore) SyncServicesFromDatabase(ctx context.Context, keys []string) (int, error) {
+	if d.db == nil {
+		return 0, nil
+	}
+
+	synced := 0
+	for _, key := range keys {
+		if d.inflight.has(key) || d.repairs.has(key) {
+			continue
+		}
+
+		service, err := d.db.GetService(ctx, key)
+		if err != nil {
+			// Only a reachable database means the service is gone
+			if pingErr := d.db.Ping(ctx); pingErr != nil {
+				return synced, pingErr
+			}
+			d.cache.RemoveAllSubscriptions(ctx, key)
+			d.cache.DeleteService(ctx, key)
+			synced++
+			continue
+		}
+
+		d.cache.RemoveAllSubscriptions(ctx, key)
+		d.cache.SaveService(ctx, service)
+		if !service.IsTombstone() {
+			for _, serviceGroup := range service.SubscriptionGroups() {
+				d.cache.AddSubscription(ctx, key, serviceGroup)
+			}
+		}
+		synced++
+	}
+	return synced, nil
+}
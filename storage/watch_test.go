@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chronnie/governance/models"
+)
+
+func TestSyncServicesFromDatabase(t *testing.T) {
+	db := newFlakyStore()
+	d := NewDualStore(db)
+	ctx := context.Background()
+
+	service := func(podName string, subscriptions ...string) *models.ServiceInfo {
+		return &models.ServiceInfo{ServiceName: "user-service", PodName: podName, Status: models.StatusHealthy, Subscriptions: subscriptions}
+	}
+	db.SaveService(ctx, service("pod-1", "order-service"))
+	db.SaveService(ctx, service("pod-2"))
+	if _, err := d.SyncServicesFromDatabase(ctx, []string{"user-service:pod-1", "user-service:pod-2"}); err != nil {
+		t.Fatalf("SyncServicesFromDatabase failed: %v", err)
+	}
+	if subscribers, _ := d.GetSubscribers(ctx, "order-service"); len(subscribers) != 1 {
+		t.Fatalf("Expected pod-1's subscription loaded, got %v", subscribers)
+	}
+
+	// Another manager changes pod-1, deletes pod-2 and adds pod-3
+	changed := service("pod-1", "payment-service")
+	changed.Status = models.StatusUnhealthy
+	db.SaveService(ctx, changed)
+	db.DeleteService(ctx, "user-service:pod-2")
+	db.SaveService(ctx, service("pod-3"))
+
+	synced, err := d.SyncServicesFromDatabase(ctx, []string{"user-service:pod-1", "user-service:pod-2", "user-service:pod-3"})
+	if err != nil {
+		t.Fatalf("SyncServicesFromDatabase failed: %v", err)
+	}
+	if synced != 3 {
+		t.Errorf("Expected 3 services synced, got %d", synced)
+	}
+
+	if cached, _ := d.GetService(ctx, "user-service:pod-1"); cached.Status != models.StatusUnhealthy {
+		t.Errorf("Expected pod-1 reloaded as unhealthy, got %s", cached.Status)
+	}
+	if _, err := d.GetService(ctx, "user-service:pod-2"); err == nil {
+		t.Error("Expected pod-2 removed from the cache")
+	}
+	if _, err := d.GetService(ctx, "user-service:pod-3"); err != nil {
+		t.Errorf("Expected pod-3 loaded into the cache: %v", err)
+	}
+	if subscribers, _ := d.GetSubscribers(ctx, "order-service"); len(subscribers) != 0 {
+		t.Errorf("Expected pod-1's old subscription dropped, got %v", subscribers)
+	}
+	if subscribers, _ := d.GetSubscribers(ctx, "payment-service"); len(subscribers) != 1 {
+		t.Errorf("Expected pod-1's new subscription loaded, got %v", subscribers)
+	}
+
+	// An unreachable database is not mistaken for a deleted service
+	db.setDown(true)
+	if _, err := d.SyncServicesFromDatabase(ctx, []string{"user-service:pod-1"}); err == nil {
+		t.Error("Expected an error while the database is down")
+	}
+	if _, err := d.GetService(ctx, "user-service:pod-1"); err != nil {
+		t.Errorf("Expected pod-1 to stay cached while the database is down: %v", err)
+	}
+}